@@ -38,20 +38,24 @@ if test $IS_TERMUX -eq 0; and set -q BREW_BIN; and test -f $BREW_BIN
     eval ($BREW_BIN shellenv)
 end
 
-# Start tmux/zellij
-if not set -q TMUX
+# Installer blocks kept outside the repository when this file is linked
+test -f ~/.config/gentleman/overlay/.config/fish/config.fish; and source ~/.config/gentleman/overlay/.config/fish/config.fish
+
+# >>> gentleman:wm >>>
+if not set -q TMUX; and not set -q GENTLEMAN_SKIP_WM
     tmux
 end
-
-#if not set -q ZELLIJ
-#    zellij
-#end
+# <<< gentleman:wm <<<
 
 # Initialize tools
 starship init fish | source
 zoxide init fish | source
 atuin init fish | source
-fzf --fish | source
+# >>> gentleman:fzf >>>
+if command -v fzf &> /dev/null
+    fzf --fish | source
+end
+# <<< gentleman:fzf <<<
 
 set -x PATH $HOME/.cargo/bin $PATH
 
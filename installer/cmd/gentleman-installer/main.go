@@ -1,12 +1,16 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
 	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/tui"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -26,7 +30,11 @@ type cliFlags struct {
 	nvim            bool
 	zed             bool
 	font            bool
+	fontFamily      string
+	fontSize        float64
 	backup          bool
+	link            bool
+	theme           string
 	aiTools         string
 	aiFramework     bool
 	aiPreset        string
@@ -37,13 +45,26 @@ type cliFlags struct {
 	projectMemory   string
 	projectCI       string
 	projectEngram   bool
-	projectRolePack string // comma-separated: "developer,pm-lead"
-	skillInstall    string // comma-separated skill names to install
-	skillRemove     string // comma-separated skill names to remove
-	repoDir         string // override repo directory name
-	repoURL         string // override repo git URL
+	projectRolePack string          // comma-separated: "developer,pm-lead"
+	skillInstall    string          // comma-separated skill names to install
+	skillRemove     string          // comma-separated skill names to remove
+	repoDir         string          // override repo directory name
+	repoURL         string          // override repo git URL
+	profile         string          // path to a TOML install profile
+	resume          bool            // continue the last unfinished installation
+	onError         string          // abort, skip or retry:N
+	transactional   bool            // roll back when the run aborts
+	output          string          // text or json (NDJSON events)
+	jobs            int             // install steps run at the same time
+	set             map[string]bool // flags explicitly passed on the command line
 }
 
+// out receives human readable messages; with --output=json it is stderr
+var out io.Writer = os.Stdout
+
+// in answers confirmation prompts
+var in io.Reader = os.Stdin
+
 func parseFlags() *cliFlags {
 	flags := &cliFlags{}
 
@@ -53,15 +74,19 @@ func parseFlags() *cliFlags {
 	flag.BoolVar(&flags.help, "h", false, "Show help message (shorthand)")
 	flag.BoolVar(&flags.test, "test", false, "Run in test mode (uses temporary directory)")
 	flag.BoolVar(&flags.test, "t", false, "Run in test mode (shorthand)")
-	flag.BoolVar(&flags.dryRun, "dry-run", false, "Show what would be installed without doing it")
+	flag.BoolVar(&flags.dryRun, "dry-run", false, "Print the install plan without changing anything")
 	flag.BoolVar(&flags.nonInteractive, "non-interactive", false, "Run without TUI, use CLI flags")
-	flag.StringVar(&flags.terminal, "terminal", "", "Terminal: alacritty, wezterm, kitty, ghostty, none")
-	flag.StringVar(&flags.shell, "shell", "", "Shell: fish, zsh, nushell")
-	flag.StringVar(&flags.windowMgr, "wm", "", "Window manager: tmux, zellij, none")
+	flag.StringVar(&flags.terminal, "terminal", "", "Terminal: "+toolList(tui.ValidTerminals))
+	flag.StringVar(&flags.shell, "shell", "", "Shell: "+toolList(tui.ValidShells))
+	flag.StringVar(&flags.windowMgr, "wm", "", "Window manager: "+toolList(tui.ValidWindowManagers))
 	flag.BoolVar(&flags.nvim, "nvim", false, "Install Neovim configuration")
 	flag.BoolVar(&flags.zed, "zed", false, "Install Zed editor with config")
 	flag.BoolVar(&flags.font, "font", false, "Install Nerd Font")
+	flag.StringVar(&flags.fontFamily, "font-family", "", "Nerd Font of the terminals and Zed: "+toolList(tui.ValidFonts)+" (implies --non-interactive)")
+	flag.Float64Var(&flags.fontSize, "font-size", 0, "Font size of the terminals and Zed (implies --non-interactive)")
 	flag.BoolVar(&flags.backup, "backup", true, "Backup existing configs (default: true)")
+	flag.BoolVar(&flags.link, "link", false, "Symlink configs into a permanent clone instead of copying them")
+	flag.StringVar(&flags.theme, "theme", "", "Colors of every installed config: "+toolList(tui.ValidThemes)+" (implies --non-interactive)")
 	flag.StringVar(&flags.aiTools, "ai-tools", "", "AI tools: claude,opencode,gemini,copilot,codex,qwen (comma-separated)")
 	flag.BoolVar(&flags.aiFramework, "ai-framework", false, "Install AI coding framework")
 	flag.StringVar(&flags.aiPreset, "ai-preset", "", "Framework preset: minimal, frontend, backend, fullstack, data, complete")
@@ -79,11 +104,44 @@ func parseFlags() *cliFlags {
 	flag.StringVar(&flags.repoDir, "repo-dir", "", "Override repo directory name (default: Gentleman.Dots, env: REPO_DIR)")
 	flag.StringVar(&flags.repoURL, "repo-url", "", "Override repo git URL (default: upstream Gentleman.Dots, env: REPO_URL)")
 
+	flag.StringVar(&flags.onError, "on-error", "abort", "What to do when a step fails: abort, skip, retry:N")
+	flag.BoolVar(&flags.transactional, "transactional", false, "Roll back every change when a non-interactive run aborts (implies --backup)")
+	flag.StringVar(&flags.output, "output", "text", "Non-interactive progress format: text, json (NDJSON events)")
+	flag.IntVar(&flags.jobs, "jobs", tui.DefaultJobs, "Install steps run at the same time (interactive sudo steps always run alone)")
+	flag.BoolVar(&flags.resume, "resume", false, "Resume the last unfinished installation (implies --non-interactive)")
+	flag.StringVar(&flags.profile, "profile", "", "Load choices from a TOML install profile (implies --non-interactive)")
+
 	flag.Parse()
+
+	flags.set = make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		flags.set[f.Name] = true
+	})
 	return flags
 }
 
+// subcommands run instead of the installer when named as the first argument
+var subcommands = map[string]func(args []string) error{
+	"backup":    runBackup,
+	"doctor":    runDoctor,
+	"restore":   runRestore,
+	"status":    runStatus,
+	"uninstall": runUninstall,
+	"update":    runUpdate,
+}
+
 func main() {
+	system.SetInstallerVersion(Version)
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			if err := run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		}
+	}
+
 	flags := parseFlags()
 
 	if flags.version {
@@ -100,13 +158,45 @@ func main() {
 		setupTestMode()
 	}
 
+	format, err := tui.ParseOutputFormat(flags.output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	tui.SetOutputFormat(format)
+	if format == tui.OutputJSON {
+		// stdout carries only JSON events, messages go to stderr
+		out = os.Stderr
+	}
+
 	if flags.dryRun {
 		os.Setenv("GENTLEMAN_DRY_RUN", "1")
-		fmt.Println("🧪 Dry-run mode: No actual installations will be performed")
+		fmt.Fprintln(out, "🧪 Dry-run mode: commands, copies and rc patches are recorded, not performed")
 	}
 
 	// Non-interactive mode: run installation directly with provided flags
-	if flags.nonInteractive {
+	policy, err := tui.ParseErrorPolicy(flags.onError)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	tui.SetErrorPolicy(policy)
+	tui.SetTransactional(flags.transactional)
+	if flags.jobs < 1 {
+		fmt.Fprintf(os.Stderr, "Error: --jobs must be at least 1, got %d\n", flags.jobs)
+		os.Exit(1)
+	}
+	tui.SetJobs(flags.jobs)
+
+	if flags.resume {
+		if err := runResume(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if flags.nonInteractive || flags.profile != "" || flags.theme != "" || flags.fontFamily != "" || flags.fontSize != 0 {
 		if err := runNonInteractive(flags); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
@@ -130,6 +220,13 @@ func main() {
 	} else if env := os.Getenv("REPO_URL"); env != "" {
 		model.RepoURL = env
 	}
+	model.LinkConfigs = flags.link
+
+	// Offer to continue an installation that did not finish
+	if journal, err := tui.LoadJournal(); err == nil && journal != nil && journal.ResumeStep() != nil {
+		model.PendingJournal = journal
+		model.Screen = tui.ScreenResumeInstall
+	}
 
 	p := tea.NewProgram(
 		model,
@@ -138,54 +235,166 @@ func main() {
 	)
 	tui.SetGlobalProgram(p)
 
-	if _, err := p.Run(); err != nil {
+	finalModel, err := p.Run()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error running installer: %v\n", err)
 		os.Exit(1)
 	}
+
+	// Print the full plan once the alt screen is gone
+	if system.IsDryRun() {
+		if m, ok := finalModel.(tui.Model); ok && len(m.DryRunPlan) > 0 {
+			fmt.Println("🧪 Dry-run plan:")
+			fmt.Print(system.FormatPlan(m.DryRunPlan))
+		}
+	}
 }
 
 // parseRolePacks validates and parses the --project-role-pack flag value.
 // It ensures role packs require obsidian-brain memory, validates pack names,
 // and always prepends "core" for obsidian-brain memory (deduplicated).
 func parseRolePacks(rolePack string, memory string) ([]string, error) {
-	// Validate role pack requires obsidian-brain
-	if rolePack != "" && memory != "obsidian-brain" {
-		return nil, fmt.Errorf("--project-role-pack requires --project-memory=obsidian-brain")
-	}
-
-	// Parse and validate role packs
-	var rolePacks []string
-	if rolePack != "" {
-		validPacks := map[string]bool{"developer": true, "pm-lead": true}
-		for _, pack := range strings.Split(rolePack, ",") {
-			pack = strings.TrimSpace(strings.ToLower(pack))
-			if pack == "" {
-				continue
-			}
-			if pack == "core" {
-				continue // core is implicit
-			}
-			if !validPacks[pack] {
-				return nil, fmt.Errorf("invalid role pack: %s (valid: developer, pm-lead)", pack)
-			}
-			rolePacks = append(rolePacks, pack)
+	return tui.NormalizeRolePacks(splitList(rolePack), memory)
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
 		}
 	}
-	// Core is always included when obsidian-brain
-	if memory == "obsidian-brain" {
-		rolePacks = append([]string{"core"}, rolePacks...)
+	return items
+}
+
+// buildProfile loads --profile (or the defaults) and applies explicitly
+// passed flags on top, so the command line always wins over the file
+func buildProfile(flags *cliFlags) (tui.Profile, error) {
+	profile := tui.DefaultProfile()
+	if flags.profile != "" {
+		loaded, err := tui.LoadProfile(flags.profile)
+		if err != nil {
+			return profile, err
+		}
+		profile = loaded
+		fmt.Printf("📄 Using profile %s\n", flags.profile)
+		if profile.Description != "" {
+			fmt.Printf("   %s\n", profile.Description)
+		}
+	}
+
+	set := flags.set
+	if set["terminal"] {
+		profile.Terminal = flags.terminal
+	}
+	if set["shell"] {
+		profile.Shell = flags.shell
+	}
+	if set["wm"] {
+		profile.WindowMgr = flags.windowMgr
+	}
+	if set["nvim"] {
+		profile.Nvim = flags.nvim
+	}
+	if set["zed"] {
+		profile.Zed = flags.zed
+	}
+	if set["font"] {
+		profile.Font = flags.font
+	}
+	if set["backup"] {
+		profile.Backup = flags.backup
+	}
+	if set["link"] {
+		profile.Link = flags.link
+	}
+	if set["font-family"] {
+		profile.FontFamily = flags.fontFamily
+	}
+	if set["font-size"] {
+		profile.FontSize = flags.fontSize
+	}
+	if set["theme"] {
+		profile.Theme = flags.theme
+	}
+	if set["ai-tools"] {
+		profile.AI.Tools = splitList(flags.aiTools)
+	}
+	if set["ai-framework"] {
+		profile.AI.Framework = flags.aiFramework
+	}
+	if set["ai-preset"] {
+		profile.AI.Preset = flags.aiPreset
+	}
+	if set["ai-modules"] {
+		profile.AI.Modules = splitList(flags.aiModules)
+	}
+	if set["agent-teams-lite"] {
+		profile.AI.AgentTeamsLite = flags.agentTeamsLite
+	}
+	if set["init-project"] {
+		profile.Project.Init = flags.initProject
+	}
+	if set["project-path"] {
+		profile.Project.Path = flags.projectPath
+	}
+	if set["project-memory"] {
+		profile.Project.Memory = flags.projectMemory
+	}
+	if set["project-ci"] {
+		profile.Project.CI = flags.projectCI
+	}
+	if set["project-engram"] {
+		profile.Project.Engram = flags.projectEngram
+	}
+	if set["project-role-pack"] {
+		profile.Project.RolePacks = splitList(flags.projectRolePack)
+	}
+	if set["skill-install"] {
+		profile.Skills = splitList(flags.skillInstall)
 	}
 
-	return rolePacks, nil
+	profile.Normalize()
+	if err := profile.Validate(); err != nil {
+		return profile, err
+	}
+	return profile, nil
+}
+
+// runResume continues the installation recorded in the step journal
+func runResume() error {
+	journal, err := tui.LoadJournal()
+	if err != nil {
+		return err
+	}
+	if journal == nil {
+		return fmt.Errorf("no unfinished installation to resume (%s not found)", tui.JournalPath())
+	}
+	next := journal.ResumeStep()
+	if next == nil {
+		return fmt.Errorf("the last installation already finished, nothing to resume")
+	}
+
+	fmt.Fprintf(out, "⏯️  Resuming installation started %s\n", journal.StartedAt.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(out, "   Continuing from: %s\n\n", next.Name)
+	return tui.ResumeNonInteractive(journal)
 }
 
 func runNonInteractive(flags *cliFlags) error {
+	profile, err := buildProfile(flags)
+	if err != nil {
+		return err
+	}
+	choices := profile.Choices()
+	if flags.transactional {
+		// Configs can only be restored if they were backed up first
+		choices.CreateBackup = true
+	}
+
 	// Handle project init
-	if flags.initProject {
-		if flags.projectPath == "" {
-			return fmt.Errorf("--project-path is required with --init-project")
-		}
-		path := tui.ExpandPath(flags.projectPath)
+	if choices.InitProject {
+		path := tui.ExpandPath(choices.ProjectPath)
 		absPath, err := filepath.Abs(path)
 		if err != nil {
 			return fmt.Errorf("invalid project path: %w", err)
@@ -198,57 +407,30 @@ func runNonInteractive(flags *cliFlags) error {
 			return fmt.Errorf("project path is not a directory: %s", absPath)
 		}
 
-		// Validate memory
-		validMemory := map[string]bool{"obsidian-brain": true, "vibekanban": true, "engram": true, "simple": true, "none": true}
-		memory := strings.ToLower(flags.projectMemory)
-		if !validMemory[memory] {
-			return fmt.Errorf("invalid memory module: %s (valid: obsidian-brain, vibekanban, engram, simple, none)", memory)
-		}
-
-		// Validate CI
-		validCI := map[string]bool{"github": true, "gitlab": true, "woodpecker": true, "none": true}
-		ci := strings.ToLower(flags.projectCI)
-		if !validCI[ci] {
-			return fmt.Errorf("invalid CI provider: %s (valid: github, gitlab, woodpecker, none)", ci)
-		}
-
-		// Validate engram requires obsidian-brain
-		if flags.projectEngram && memory != "obsidian-brain" {
-			return fmt.Errorf("--project-engram requires --project-memory=obsidian-brain")
-		}
-
-		rolePacks, err := parseRolePacks(flags.projectRolePack, memory)
-		if err != nil {
-			return err
-		}
-
-		fmt.Println("📦 Initializing project...")
-		fmt.Printf("  Path:    %s\n", absPath)
-		fmt.Printf("  Memory:  %s\n", memory)
-		fmt.Printf("  CI:      %s\n", ci)
-		if flags.projectEngram {
-			fmt.Printf("  Engram:  yes\n")
+		fmt.Fprintln(out, "📦 Initializing project...")
+		fmt.Fprintf(out, "  Path:    %s\n", absPath)
+		fmt.Fprintf(out, "  Memory:  %s\n", choices.ProjectMemory)
+		fmt.Fprintf(out, "  CI:      %s\n", choices.ProjectCI)
+		if choices.ProjectEngram {
+			fmt.Fprintf(out, "  Engram:  yes\n")
 		}
-		if len(rolePacks) > 0 {
-			fmt.Printf("  Packs:   %s\n", strings.Join(rolePacks, ", "))
+		if len(choices.ProjectRolePacks) > 0 {
+			fmt.Fprintf(out, "  Packs:   %s\n", strings.Join(choices.ProjectRolePacks, ", "))
 		}
-		fmt.Println()
+		fmt.Fprintln(out)
 
 		tui.SetNonInteractiveMode(true)
-		if err := tui.RunProjectInitScript(absPath, memory, ci, flags.projectEngram, rolePacks); err != nil {
+		if err := tui.RunProjectInitScript(absPath, choices.ProjectMemory, choices.ProjectCI, choices.ProjectEngram, choices.ProjectRolePacks); err != nil {
 			return fmt.Errorf("project initialization failed: %w", err)
 		}
-		fmt.Println("✅ Project initialized successfully!")
+		fmt.Fprintln(out, "✅ Project initialized successfully!")
 		return nil // Don't continue to environment installation
 	}
 
 	// Handle skill operations
-	if flags.skillInstall != "" {
-		names := strings.Split(flags.skillInstall, ",")
-		for i := range names {
-			names[i] = strings.TrimSpace(names[i])
-		}
-		fmt.Printf("📥 Installing %d skill(s)...\n", len(names))
+	if len(choices.Skills) > 0 {
+		names := choices.Skills
+		fmt.Fprintf(out, "📥 Installing %d skill(s)...\n", len(names))
 		tui.SetNonInteractiveMode(true)
 
 		// Fetch catalog to get SkillInfo for each requested name
@@ -272,23 +454,20 @@ func runNonInteractive(flags *cliFlags) error {
 
 		logLines, err := tui.InstallSkillSymlinks(toInstall)
 		for _, line := range logLines {
-			fmt.Println("  " + line)
+			fmt.Fprintln(out, "  "+line)
 		}
 		if err != nil {
 			return fmt.Errorf("skill installation: %w", err)
 		}
-		fmt.Println("✅ Skills installed!")
-		if flags.shell == "" {
+		fmt.Fprintln(out, "✅ Skills installed!")
+		if choices.Shell == "" {
 			return nil // Only skill operation, no env install
 		}
 	}
 
 	if flags.skillRemove != "" {
-		names := strings.Split(flags.skillRemove, ",")
-		for i := range names {
-			names[i] = strings.TrimSpace(names[i])
-		}
-		fmt.Printf("🗑️  Removing %d skill(s)...\n", len(names))
+		names := splitList(flags.skillRemove)
+		fmt.Fprintf(out, "🗑️  Removing %d skill(s)...\n", len(names))
 		tui.SetNonInteractiveMode(true)
 
 		// Build SkillInfo from names (we only need the Name field for removal)
@@ -299,131 +478,88 @@ func runNonInteractive(flags *cliFlags) error {
 
 		logLines, err := tui.RemoveSkillSymlinks(toRemove)
 		for _, line := range logLines {
-			fmt.Println("  " + line)
+			fmt.Fprintln(out, "  "+line)
 		}
 		if err != nil {
 			return fmt.Errorf("skill removal: %w", err)
 		}
-		fmt.Println("✅ Skills removed!")
-		if flags.shell == "" {
+		fmt.Fprintln(out, "✅ Skills removed!")
+		if choices.Shell == "" {
 			return nil // Only skill operation, no env install
 		}
 	}
 
-	// Validate required flags for environment installation
-	if flags.shell == "" {
-		return fmt.Errorf("--shell is required (fish, zsh, nushell)")
+	// Handle a font switch on its own
+	if (choices.Font != "" || choices.FontSize > 0) && choices.Shell == "" {
+		if err := tui.RunFontNonInteractive(choices); err != nil {
+			return fmt.Errorf("font: %w", err)
+		}
+		if choices.Theme == "" {
+			return nil // Only a font switch, no env install
+		}
 	}
 
-	// Normalize inputs
-	terminal := strings.ToLower(flags.terminal)
-	shell := strings.ToLower(flags.shell)
-	wm := strings.ToLower(flags.windowMgr)
-
-	// Validate values
-	validTerminals := map[string]bool{"alacritty": true, "wezterm": true, "kitty": true, "ghostty": true, "none": true, "": true}
-	validShells := map[string]bool{"fish": true, "zsh": true, "nushell": true}
-	validWMs := map[string]bool{"tmux": true, "zellij": true, "none": true, "": true}
-
-	if !validTerminals[terminal] {
-		return fmt.Errorf("invalid terminal: %s (valid: alacritty, wezterm, kitty, ghostty, none)", terminal)
-	}
-	if !validShells[shell] {
-		return fmt.Errorf("invalid shell: %s (valid: fish, zsh, nushell)", shell)
-	}
-	if !validWMs[wm] {
-		return fmt.Errorf("invalid window manager: %s (valid: tmux, zellij, none)", wm)
+	// Handle a theme switch on its own
+	if choices.Theme != "" && choices.Shell == "" {
+		fmt.Fprintf(out, "🎨 Applying the %s theme...\n", choices.Theme)
+		styled, err := system.ApplyTheme(choices.Theme)
+		if system.IsDryRun() {
+			fmt.Println("🧪 Dry-run plan:")
+			fmt.Print(system.FormatPlan(system.TakePlan()))
+			return err
+		}
+		if err != nil {
+			return fmt.Errorf("theme: %w", err)
+		}
+		if len(styled) == 0 {
+			fmt.Fprintln(out, "No installed config to restyle")
+			return nil
+		}
+		fmt.Fprintf(out, "✅ Restyled %s\n", strings.Join(styled, ", "))
+		return nil // Only a theme switch, no env install
 	}
 
-	// Default empty values to "none"
-	if terminal == "" {
-		terminal = "none"
-	}
-	if wm == "" {
-		wm = "none"
+	// Validate required flags for environment installation
+	if choices.Shell == "" {
+		return fmt.Errorf("--shell (or 'shell' in the profile) is required (%s)", strings.Join(tui.ValidShells, ", "))
 	}
 
-	// Parse AI tools
-	var aiTools []string
-	if flags.aiTools != "" {
-		validAITools := map[string]bool{"claude": true, "opencode": true, "gemini": true, "copilot": true, "codex": true, "qwen": true}
-		for _, tool := range strings.Split(flags.aiTools, ",") {
-			tool = strings.TrimSpace(strings.ToLower(tool))
-			if !validAITools[tool] {
-				return fmt.Errorf("invalid AI tool: %s (valid: claude, opencode, gemini, copilot, codex, qwen)", tool)
-			}
-			aiTools = append(aiTools, tool)
-		}
+	fmt.Fprintln(out, "🚀 Javi.Dots Non-Interactive Installer")
+	fmt.Fprintln(out, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Fprintf(out, "  Terminal:    %s\n", choices.Terminal)
+	fmt.Fprintf(out, "  Shell:       %s\n", choices.Shell)
+	fmt.Fprintf(out, "  Window Mgr:  %s\n", choices.WindowMgr)
+	fmt.Fprintf(out, "  Neovim:      %v\n", choices.InstallNvim)
+	fmt.Fprintf(out, "  Zed:         %v\n", choices.InstallZed)
+	if choices.Font != "" {
+		fmt.Fprintf(out, "  Font:        %s\n", choices.Font)
+	} else {
+		fmt.Fprintf(out, "  Font:        %v\n", choices.InstallFont)
 	}
-
-	// Validate AI preset
-	aiPreset := strings.ToLower(flags.aiPreset)
-	validPresets := map[string]bool{"minimal": true, "frontend": true, "backend": true, "fullstack": true, "data": true, "complete": true, "": true}
-	if !validPresets[aiPreset] {
-		return fmt.Errorf("invalid AI preset: %s (valid: minimal, frontend, backend, fullstack, data, complete)", aiPreset)
+	fmt.Fprintf(out, "  Backup:      %v\n", choices.CreateBackup)
+	if choices.LinkConfigs {
+		fmt.Fprintf(out, "  Link:        %v\n", choices.LinkConfigs)
+	}
+	if choices.Theme != "" {
+		fmt.Fprintf(out, "  Theme:       %s\n", choices.Theme)
 	}
-
-	// Parse AI features (modules flag accepts feature names: hooks,commands,skills,agents,sdd,mcp)
-	var aiModules []string
-	if flags.aiModules != "" {
-		validFeatures := map[string]bool{"hooks": true, "commands": true, "skills": true, "agents": true, "sdd": true, "mcp": true}
-		for _, mod := range strings.Split(flags.aiModules, ",") {
-			mod = strings.TrimSpace(strings.ToLower(mod))
-			if mod == "" {
-				continue
-			}
-			if !validFeatures[mod] {
-				return fmt.Errorf("invalid AI feature: %s (valid: hooks, commands, skills, agents, sdd, mcp)", mod)
-			}
-			aiModules = append(aiModules, mod)
-		}
-	}
-
-	// Determine if framework should be installed
-	installFramework := flags.aiFramework || aiPreset != "" || len(aiModules) > 0 || flags.agentTeamsLite
-
-	// Create choices
-	choices := tui.UserChoices{
-		Terminal:              terminal,
-		Shell:                 shell,
-		WindowMgr:             wm,
-		InstallNvim:           flags.nvim,
-		InstallZed:            flags.zed,
-		InstallFont:           flags.font,
-		CreateBackup:          flags.backup,
-		AITools:               aiTools,
-		InstallAIFramework:    installFramework,
-		AIFrameworkPreset:     aiPreset,
-		AIFrameworkModules:    aiModules,
-		InstallAgentTeamsLite: flags.agentTeamsLite,
-	}
-
-	fmt.Println("🚀 Javi.Dots Non-Interactive Installer")
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Printf("  Terminal:    %s\n", choices.Terminal)
-	fmt.Printf("  Shell:       %s\n", choices.Shell)
-	fmt.Printf("  Window Mgr:  %s\n", choices.WindowMgr)
-	fmt.Printf("  Neovim:      %v\n", choices.InstallNvim)
-	fmt.Printf("  Zed:         %v\n", choices.InstallZed)
-	fmt.Printf("  Font:        %v\n", choices.InstallFont)
-	fmt.Printf("  Backup:      %v\n", choices.CreateBackup)
 	if len(choices.AITools) > 0 {
-		fmt.Printf("  AI Tools:    %s\n", strings.Join(choices.AITools, ", "))
+		fmt.Fprintf(out, "  AI Tools:    %s\n", strings.Join(choices.AITools, ", "))
 	}
 	if choices.InstallAIFramework {
 		if choices.AIFrameworkPreset != "" {
-			fmt.Printf("  AI Framework: preset=%s\n", choices.AIFrameworkPreset)
+			fmt.Fprintf(out, "  AI Framework: preset=%s\n", choices.AIFrameworkPreset)
 		} else if len(choices.AIFrameworkModules) > 0 {
-			fmt.Printf("  AI Framework: modules=%s\n", strings.Join(choices.AIFrameworkModules, ","))
+			fmt.Fprintf(out, "  AI Framework: modules=%s\n", strings.Join(choices.AIFrameworkModules, ","))
 		} else {
-			fmt.Printf("  AI Framework: yes\n")
+			fmt.Fprintf(out, "  AI Framework: yes\n")
 		}
 		if choices.InstallAgentTeamsLite {
-			fmt.Printf("  Agent Teams:  yes\n")
+			fmt.Fprintf(out, "  Agent Teams:  yes\n")
 		}
 	}
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Println()
+	fmt.Fprintln(out, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Fprintln(out)
 
 	// Resolve repo dir: flag > env > default
 	repoDir := tui.DefaultRepoDir
@@ -445,6 +581,570 @@ func runNonInteractive(flags *cliFlags) error {
 	return tui.RunNonInteractive(choices, repoDir, repoURL)
 }
 
+// runDoctor prints the health report of the existing setup and fails when
+// any check failed
+func runDoctor(args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("doctor takes no arguments, got %s", strings.Join(fs.Args(), " "))
+	}
+
+	checks := tui.RunDoctor(system.Detect())
+	fmt.Fprintln(out, "🩺 Javi.Dots Doctor")
+	fmt.Fprintln(out, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Fprint(out, tui.FormatDoctorReport(checks))
+
+	if _, _, fail := tui.CountChecks(checks); fail > 0 {
+		return fmt.Errorf("%d check(s) failed", fail)
+	}
+	return nil
+}
+
+// confirm asks a yes/no question, defaulting to no
+func confirm(question string) bool {
+	fmt.Fprintf(out, "%s [y/N] ", question)
+	var answer string
+	fmt.Fscanln(in, &answer)
+	answer = strings.ToLower(answer)
+	return answer == "y" || answer == "yes"
+}
+
+// runUninstall reverses the installation of the given components, or of
+// everything. Packages are only removed with --packages, after confirming.
+func runUninstall(args []string) error {
+	fs := flag.NewFlagSet("uninstall", flag.ContinueOnError)
+	packages := fs.Bool("packages", false, "Also remove the packages of the components")
+	yes := fs.Bool("yes", false, "Do not ask for confirmation")
+	dryRun := fs.Bool("dry-run", false, "Print what would be removed without changing anything")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var ids []string
+	for _, arg := range fs.Args() {
+		ids = append(ids, splitList(arg)...)
+	}
+	components, err := tui.ParseUninstallComponents(ids)
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		os.Setenv("GENTLEMAN_DRY_RUN", "1")
+		*yes = true
+	}
+
+	names := make([]string, len(components))
+	for i, c := range components {
+		names[i] = c.Name
+	}
+	fmt.Fprintln(out, "🗑️  Javi.Dots Uninstall")
+	fmt.Fprintln(out, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Fprintf(out, "  Components: %s\n", strings.Join(names, ", "))
+	fmt.Fprintln(out, "  Configs are restored from the latest backup holding them, or removed")
+	fmt.Fprintln(out)
+	if !*yes && !confirm("Uninstall?") {
+		return fmt.Errorf("uninstall cancelled")
+	}
+
+	if *packages && !*yes {
+		list := tui.UninstallPackages(components)
+		*packages = len(list) > 0 && confirm("Also remove these packages: "+strings.Join(list, ", ")+"?")
+	}
+
+	system.TakePlan()
+	report, err := tui.RunUninstall(system.Detect(), components, *packages, func(line string) {
+		fmt.Fprintln(out, "  "+line)
+	})
+	if system.IsDryRun() {
+		fmt.Println("🧪 Dry-run plan:")
+		fmt.Print(system.FormatPlan(system.TakePlan()))
+		return err
+	}
+	fmt.Fprintln(out)
+	fmt.Fprint(out, report.Format())
+	return err
+}
+
+// runStatus compares the install manifest with the disk and the repository,
+// listing files changed locally, changed upstream and deleted
+func runStatus(args []string) error {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	repo := fs.String("repo", "", "Compare sources with this checkout instead of the installed repository")
+	offline := fs.Bool("offline", false, "Do not clone the repository when the installed one is gone")
+	asJSON := fs.Bool("json", false, "Print the status as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("status takes no arguments, got %s", strings.Join(fs.Args(), " "))
+	}
+
+	manifest, err := system.LoadManifest()
+	if err != nil {
+		return err
+	}
+
+	repoDir := *repo
+	if repoDir == "" {
+		if _, err := os.Stat(manifest.Repo); err == nil && manifest.Repo != "" {
+			repoDir = manifest.Repo
+		} else if !*offline && manifest.RepoURL != "" && len(manifest.Files) > 0 {
+			if !*asJSON {
+				fmt.Fprintf(out, "Fetching %s to compare with the repository...\n", manifest.RepoURL)
+			}
+			tmp, err := system.ShallowClone(manifest.RepoURL)
+			if err != nil {
+				return fmt.Errorf("%w (use --offline to skip the comparison)", err)
+			}
+			defer os.RemoveAll(tmp)
+			repoDir = tmp
+		}
+	}
+
+	status := system.CompareManifest(manifest, repoDir)
+	if *asJSON {
+		data, err := json.MarshalIndent(status, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, string(data))
+		return nil
+	}
+	fmt.Fprintln(out, "📋 Javi.Dots Status")
+	fmt.Fprintln(out, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Fprint(out, status.Format())
+	return nil
+}
+
+// runUpdate brings the managed files up to date with the latest repository,
+// merging upstream changes into the ones edited locally
+func runUpdate(args []string) error {
+	fs := flag.NewFlagSet("update", flag.ContinueOnError)
+	repo := fs.String("repo", "", "Update from this checkout instead of cloning the repository")
+	theirs := fs.Bool("theirs", false, "Resolve conflicts by taking the repository version")
+	yes := fs.Bool("yes", false, "Do not ask for confirmation")
+	dryRun := fs.Bool("dry-run", false, "Print what would change without changing anything")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("update takes no arguments, got %s", strings.Join(fs.Args(), " "))
+	}
+
+	manifest, err := system.LoadManifest()
+	if err != nil {
+		return err
+	}
+	if len(manifest.Files) == 0 {
+		return fmt.Errorf("no installed files are tracked yet, run the installer first")
+	}
+
+	fmt.Fprintln(out, "⬆️  Javi.Dots Update")
+	fmt.Fprintln(out, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	repoDir := *repo
+	if repoDir == "" {
+		url := manifest.RepoURL
+		if url == "" {
+			url = tui.DefaultRepoURL
+		}
+		fmt.Fprintf(out, "Fetching %s...\n", url)
+		dir, err := system.ShallowClone(url)
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		repoDir = dir
+	}
+	if *dryRun {
+		os.Setenv("GENTLEMAN_DRY_RUN", "1")
+		*yes = true
+	}
+
+	plan := system.PlanUpdate(manifest, repoDir)
+	fmt.Fprint(out, system.FormatUpdatePlan(plan))
+	if plan.Count(system.UpdateConflict) > 0 {
+		if *theirs {
+			for _, f := range plan.Files {
+				if f.Merge != nil {
+					f.Merge.ResolveAll(system.TakeTheirs)
+				}
+			}
+		} else {
+			plan.Files = slices.DeleteFunc(plan.Files, func(f *system.UpdateFile) bool {
+				return f.Action == system.UpdateConflict
+			})
+			fmt.Fprintln(out, "Files with conflicts are left as they are: resolve them hunk by hunk with")
+			fmt.Fprintln(out, "Update Configs in the TUI, or pass --theirs to take the repository version")
+		}
+	}
+	if len(plan.Files) == 0 {
+		return nil
+	}
+	fmt.Fprintln(out)
+	if !*yes && !confirm(fmt.Sprintf("Update %d file(s)?", len(plan.Files))) {
+		return fmt.Errorf("update cancelled")
+	}
+
+	system.TakePlan()
+	backupDir, err := system.ApplyUpdate(plan)
+	if system.IsDryRun() {
+		fmt.Println("🧪 Dry-run plan:")
+		fmt.Print(system.FormatPlan(system.TakePlan()))
+		return err
+	}
+	if saveErr := system.SaveManifest(repoDir, manifest.RepoURL); err == nil {
+		err = saveErr
+	}
+	if backupDir != "" {
+		fmt.Fprintf(out, "💾 Backup created at: %s\n", backupDir)
+	}
+	if err == nil {
+		fmt.Fprintf(out, "✅ Updated %d file(s)\n", len(plan.Files))
+	}
+	return err
+}
+
+// runRestore restores the configs of a backup, all of them or the ones
+// picked with --only, after showing what changes. The configs it replaces
+// are backed up first, so --undo can put them back.
+func runRestore(args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	only := fs.String("only", "", "Restore only these entries (comma-separated), like tmux,zsh")
+	showDiff := fs.Bool("diff", false, "Show the unified diff of every file that changes")
+	undo := fs.Bool("undo", false, "Undo the last restore")
+	yes := fs.Bool("yes", false, "Do not ask for confirmation")
+	dryRun := fs.Bool("dry-run", false, "Print what would change without changing anything")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("restore takes at most one backup, got %s", strings.Join(fs.Args(), " "))
+	}
+	if *dryRun {
+		os.Setenv("GENTLEMAN_DRY_RUN", "1")
+		*yes = true
+	}
+
+	fmt.Fprintln(out, "🔄 Javi.Dots Restore")
+	fmt.Fprintln(out, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	if *undo {
+		if fs.NArg() > 0 || *only != "" {
+			return fmt.Errorf("--undo takes no backup or --only")
+		}
+		snapshot, ok := system.LatestRestore()
+		if !ok {
+			return fmt.Errorf("no restore to undo")
+		}
+		fmt.Fprintf(out, "  Undo the restore of %s from %s\n\n", strings.Join(snapshot.Manifest.Restored, ", "), snapshot.ID())
+		if !*yes && !confirm("Undo?") {
+			return fmt.Errorf("undo cancelled")
+		}
+		system.TakePlan()
+		err := system.UndoRestore(snapshot.Path)
+		if system.IsDryRun() {
+			fmt.Println("🧪 Dry-run plan:")
+			fmt.Print(system.FormatPlan(system.TakePlan()))
+			return err
+		}
+		if err == nil {
+			fmt.Fprintln(out, "✅ Restore undone")
+		}
+		return err
+	}
+
+	var backup system.BackupInfo
+	if fs.NArg() == 1 {
+		found, ok := system.FindBackup(fs.Arg(0))
+		if !ok {
+			return fmt.Errorf("no backup %q, see backup list", fs.Arg(0))
+		}
+		backup = found
+	} else {
+		found, ok := latestBackup()
+		if !ok {
+			return fmt.Errorf("no backups found")
+		}
+		backup = found
+	}
+
+	var keys []string
+	if *only != "" {
+		keys = splitList(*only)
+		for _, key := range keys {
+			if !slices.Contains(backup.Files, key) {
+				return fmt.Errorf("backup %s has no %s entry (it has: %s)", backup.ID(), key, strings.Join(backup.Files, ", "))
+			}
+		}
+	}
+
+	diffs, err := system.DiffBackup(backup.Path, keys)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "  Backup: %s\n\n", backup.ID())
+	fmt.Fprint(out, system.FormatRestoreDiff(diffs, *showDiff))
+	fmt.Fprintln(out)
+	if !*yes && !confirm(fmt.Sprintf("Restore %d config(s)?", len(diffs))) {
+		return fmt.Errorf("restore cancelled")
+	}
+
+	system.TakePlan()
+	snapshot, err := system.RestoreBackupWithUndo(backup.Path, keys)
+	if system.IsDryRun() {
+		fmt.Println("🧪 Dry-run plan:")
+		fmt.Print(system.FormatPlan(system.TakePlan()))
+		return err
+	}
+	if snapshot != "" {
+		fmt.Fprintf(out, "💾 Current configs saved to: %s (restore --undo puts them back)\n", snapshot)
+	}
+	if err == nil {
+		fmt.Fprintf(out, "✅ Restored %d config(s)\n", len(diffs))
+	}
+	return err
+}
+
+// latestBackup returns the newest backup, leaving out the snapshots taken
+// before restores
+func latestBackup() (system.BackupInfo, bool) {
+	var latest system.BackupInfo
+	found := false
+	for _, b := range system.ListBackups() {
+		if b.Manifest != nil && b.Manifest.Reason == "restore" {
+			continue
+		}
+		if !found || b.Timestamp.After(latest.Timestamp) {
+			latest, found = b, true
+		}
+	}
+	return latest, found
+}
+
+// runBackup lists the backups, prunes them with the retention policy, or
+// sets how new backups are compressed and kept
+func runBackup(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("backup needs a command: list, prune, config, export or import")
+	}
+	switch args[0] {
+	case "list":
+		return runBackupList(args[1:])
+	case "prune":
+		return runBackupPrune(args[1:])
+	case "config":
+		return runBackupConfig(args[1:])
+	case "export":
+		return runBackupExport(args[1:])
+	case "import":
+		return runBackupImport(args[1:])
+	}
+	return fmt.Errorf("unknown backup command %q (valid: list, prune, config, export, import)", args[0])
+}
+
+// runBackupExport writes a backup to an archive another machine can import
+func runBackupExport(args []string) error {
+	fs := flag.NewFlagSet("backup export", flag.ContinueOnError)
+	output := fs.String("o", "", "Archive to write, .tar.gz or .tar.zst (default: gentleman-backup-<id>.tar.gz)")
+	dryRun := fs.Bool("dry-run", false, "Print what would be written without changing anything")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	// The backup may come before the flags
+	id := fs.Arg(0)
+	if fs.NArg() > 0 {
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return err
+		}
+	}
+	if id == "" || fs.NArg() > 0 {
+		return fmt.Errorf("usage: backup export <backup> [-o file.tar.gz]")
+	}
+	backup, ok := system.FindBackup(id)
+	if !ok {
+		return fmt.Errorf("no backup %q, see backup list", id)
+	}
+	if *output == "" {
+		*output = "gentleman-backup-" + backup.ID() + ".tar.gz"
+	}
+	if *dryRun {
+		os.Setenv("GENTLEMAN_DRY_RUN", "1")
+	}
+
+	system.TakePlan()
+	err := system.ExportBackup(backup.Path, *output)
+	if system.IsDryRun() {
+		fmt.Println("🧪 Dry-run plan:")
+		fmt.Print(system.FormatPlan(system.TakePlan()))
+		return err
+	}
+	if err == nil {
+		fmt.Fprintf(out, "📤 Exported %s to %s\n", backup.ID(), *output)
+	}
+	return err
+}
+
+// runBackupImport adds an exported backup to the backups of this machine,
+// checking it against its manifest
+func runBackupImport(args []string) error {
+	fs := flag.NewFlagSet("backup import", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "Check the archive and print what would be written")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: backup import <file.tar.gz>")
+	}
+	if *dryRun {
+		os.Setenv("GENTLEMAN_DRY_RUN", "1")
+	}
+
+	system.TakePlan()
+	backupDir, err := system.ImportBackup(fs.Arg(0))
+	if system.IsDryRun() {
+		fmt.Println("🧪 Dry-run plan:")
+		fmt.Print(system.FormatPlan(system.TakePlan()))
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	backup, _ := system.FindBackup(backupDir)
+	fmt.Fprintf(out, "📥 Imported %s: %s\n", backup.ID(), strings.Join(backup.Files, ", "))
+	fmt.Fprintf(out, "Restore it with: restore %s\n", backup.ID())
+	return nil
+}
+
+func runBackupList(args []string) error {
+	fs := flag.NewFlagSet("backup list", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("backup list takes no arguments, got %s", strings.Join(fs.Args(), " "))
+	}
+
+	backups := system.ListBackups()
+	if len(backups) == 0 {
+		fmt.Fprintln(out, "No backups found")
+		return nil
+	}
+	for _, b := range backups {
+		line := fmt.Sprintf("%s  %s", b.ID(), strings.Join(b.Files, ", "))
+		if b.Manifest != nil {
+			details := []string{formatBytes(b.Manifest.Size())}
+			if b.Manifest.Compression != "" {
+				details = append(details, b.Manifest.Compression)
+			}
+			if b.Manifest.Reason != "" {
+				details = append(details, b.Manifest.Reason)
+			}
+			if b.Manifest.Imported != "" {
+				details = append(details, "imported from "+b.Manifest.Imported)
+			}
+			line += "  (" + strings.Join(details, ", ") + ")"
+		}
+		fmt.Fprintln(out, line)
+	}
+	return nil
+}
+
+func runBackupPrune(args []string) error {
+	settings, err := system.LoadBackupSettings()
+	if err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("backup prune", flag.ContinueOnError)
+	keep := fs.Int("keep", settings.Policy.Keep, "Keep the N newest backups")
+	maxAge := fs.Int("max-age", settings.Policy.MaxAgeDays, "Keep backups younger than D days")
+	dryRun := fs.Bool("dry-run", false, "Print the backups that would be deleted")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("backup prune takes no arguments, got %s", strings.Join(fs.Args(), " "))
+	}
+	policy := system.BackupPolicy{Keep: *keep, MaxAgeDays: *maxAge}
+	if policy.Keep < 0 || policy.MaxAgeDays < 0 {
+		return fmt.Errorf("--keep and --max-age can not be negative")
+	}
+	if !policy.Enabled() {
+		return fmt.Errorf("no retention policy: pass --keep or --max-age, or set one with backup config")
+	}
+	if *dryRun {
+		os.Setenv("GENTLEMAN_DRY_RUN", "1")
+	}
+
+	pruned, err := system.PruneBackups(policy)
+	verb := "Deleted"
+	if system.IsDryRun() {
+		verb = "Would delete"
+		system.TakePlan()
+	}
+	for _, b := range pruned {
+		fmt.Fprintf(out, "%s %s\n", verb, b.ID())
+	}
+	if err == nil && len(pruned) == 0 {
+		fmt.Fprintln(out, "Nothing to prune")
+	}
+	return err
+}
+
+func runBackupConfig(args []string) error {
+	settings, err := system.LoadBackupSettings()
+	if err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("backup config", flag.ContinueOnError)
+	compression := fs.String("compression", settings.Compression, "Compress new backups: "+strings.Join(system.ValidCompressions, ", "))
+	keep := fs.Int("keep", settings.Policy.Keep, "Keep the N newest backups, 0 for no limit")
+	maxAge := fs.Int("max-age", settings.Policy.MaxAgeDays, "Keep backups younger than D days, 0 for no limit")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("backup config takes no arguments, got %s", strings.Join(fs.Args(), " "))
+	}
+
+	settings.Compression = *compression
+	settings.Policy = system.BackupPolicy{Keep: *keep, MaxAgeDays: *maxAge}
+	if fs.NFlag() > 0 {
+		if err := settings.Save(); err != nil {
+			return err
+		}
+	}
+	if settings.Compression == "" {
+		settings.Compression = system.CompressionNone
+	}
+	fmt.Fprintf(out, "Compression: %s\n", settings.Compression)
+	fmt.Fprintf(out, "Keep:        %s\n", policyLimit(settings.Policy.Keep, "newest backups"))
+	fmt.Fprintf(out, "Max age:     %s\n", policyLimit(settings.Policy.MaxAgeDays, "days"))
+	return nil
+}
+
+// policyLimit describes a retention limit, 0 meaning none
+func policyLimit(n int, unit string) string {
+	if n == 0 {
+		return "no limit"
+	}
+	return fmt.Sprintf("%d %s", n, unit)
+}
+
+// formatBytes prints a size in the largest unit that keeps it above 1
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
 func setupTestMode() {
 	// Create a temporary test directory
 	testDir := filepath.Join(os.TempDir(), "gentleman-dots-test")
@@ -468,34 +1168,104 @@ func setupTestMode() {
 }
 
 func printHelp() {
-	fmt.Println(`javi.dots - TUI installer for Javi.Dots terminal environment (fork of Gentleman.Dots)
+	fmt.Printf(`javi.dots - TUI installer for Javi.Dots terminal environment (fork of Gentleman.Dots)
 
 Usage:
   gentleman.dots [flags]
+  gentleman.dots <command>
+
+Commands:
+  backup list          List the config backups with their entries, size and origin
+  backup prune         Delete the backups the retention policy does not keep.
+                       --keep=<n> keeps the n newest, --max-age=<days> the younger
+                       ones (default: the configured policy), --dry-run only lists them
+  backup config        Show or set how new backups are stored: --compression=none,
+                       gzip or zstd (gzip when zstd is missing), --keep=<n> and
+                       --max-age=<days> prune older backups after each backup
+  backup export <backup> [-o <file>]
+                       Write a backup to a .tar.gz (or .tar.zst) archive to move it to
+                       another machine (default: gentleman-backup-<backup>.tar.gz)
+  backup import <file> Add an exported backup to this machine. Every entry is checked
+                       against the manifest and its path rewritten to this home
+  doctor               Check an existing setup: installed binaries, config files,
+                       shell rc syntax, ~/.local/bin on PATH and dangling skill links.
+                       Prints a pass/warn/fail report with a fix hint per problem
+                       and exits non-zero when a check fails
+  restore [<backup>]   Restore the configs of a backup (default: the latest), listing the
+                       files that change first. --only=<entries> picks entries
+                       (comma-separated, like tmux,zsh), --diff shows the unified diffs.
+                       The configs it replaces are backed up, --undo puts them back.
+                       --yes skips the prompt, --dry-run prints what would change
+  status               List the installed files that changed locally, changed in the
+                       repository since install, or were deleted, using the manifest
+                       in ~/.config/gentleman/manifest.json. --repo=<dir> compares
+                       with a checkout, --offline skips cloning the repository when
+                       the installed one is gone, --json prints JSON
+  update               Clone the latest repository and bring the installed configs up to
+                       date: files you did not edit are replaced, upstream changes are
+                       merged into the ones you did. Configs are backed up first.
+                       Files with conflicts are skipped (resolve them in the TUI) unless
+                       --theirs is passed. --repo=<dir> updates from a checkout, --yes
+                       skips the prompt, --dry-run prints what would change
+  uninstall [all|<components>]
+                       Reverse the installation of everything, or of the given components
+                       (comma-separated): engram, skills, nvim, fish, zsh, nushell,
+                       starship, tmux, zellij, alacritty, wezterm, kitty, ghostty, zed, rc.
+                       Configs are restored from the latest backup or removed, the Engram
+                       service, skill links and installer rc blocks are deleted.
+                       --packages also removes the packages, --yes skips the prompts,
+                       --dry-run prints what would change
 
 Interactive Mode (default):
   Just run 'gentleman.dots' to start the TUI installer.
 
 Non-Interactive Mode:
   gentleman.dots --non-interactive --shell=<shell> [options]
+  gentleman.dots --profile=<file.toml> [options]
 
 Flags:
   -h, --help           Show this help message
   -v, --version        Show version information
   -t, --test           Run in test mode (uses temporary directory)
-  --dry-run            Show what would be installed without doing it
+  --dry-run            Print the ordered install plan (commands, packages,
+                       copies, overwrites, rc patches) without changing anything
   --non-interactive    Run without TUI, use CLI flags instead
+  --profile=<file>     Load choices from a TOML install profile (implies --non-interactive).
+                       Flags passed explicitly override the profile values
+  --resume             Continue the last unfinished installation from the failed step,
+                       skipping the steps that already finished (implies --non-interactive)
+  --on-error=<policy>  When a step fails: abort (default), skip, or retry:N.
+                       Optional steps (font, zed) are always skipped on failure
+  --output=<format>    Progress format: text (default) or json, one event per line
+                       on stdout (plan, step_started, log, step_finished, error, summary)
+  --transactional      If the run aborts, restore the backed up configs and delete
+                       everything the installer created (implies --backup)
+  --jobs=<n>           Install steps run at the same time once their dependencies
+                       finished (default: 4). Steps needing sudo always run alone,
+                       --dry-run always uses 1
 
 Non-Interactive Options:
   --repo-dir=<dir>     Override repo directory name (default: Gentleman.Dots, env: REPO_DIR)
   --repo-url=<url>     Override repo git URL (default: upstream Gentleman.Dots, env: REPO_URL)
-  --shell=<shell>      Shell to install (required): fish, zsh, nushell
-  --terminal=<term>    Terminal: alacritty, wezterm, kitty, ghostty, none
-  --wm=<wm>            Window manager: tmux, zellij, none
+  --shell=<shell>      Shell to install (required): %s
+  --terminal=<term>    Terminal: %s
+  --wm=<wm>            Window manager: %s
   --nvim               Install Neovim configuration
   --zed                Install Zed editor with config
   --font               Install Nerd Font
+  --font-family=<font> Nerd Font to install and set in the Alacritty, Kitty, Ghostty,
+                       WezTerm and Zed configs. Alone, it installs the font if
+                       missing and updates the configs. One of:
+                       %s
+  --font-size=<size>   Font size written with the font family (default: keep the size)
   --backup=false       Disable config backup (default: true)
+  --link               Keep the repository in ~/.local/share/javi.dots and symlink the
+                       configs into it instead of copying them. The installer's zsh and
+                       fish blocks go to ~/.config/gentleman/overlay, which they source;
+                       other edited configs become copies. The repository is never changed
+  --theme=<theme>      Colors of the terminals, tmux/zellij, starship, Neovim and
+                       OpenCode: %s. Alone, it restyles the
+                       installed configs; gentleman puts back the colors they had before
 
 AI Options:
   --ai-tools=<tools>   AI tools (comma-separated): claude, opencode, gemini, copilot, codex, qwen
@@ -546,6 +1316,40 @@ Examples:
   # Remove skills
   gentleman.dots --non-interactive --skill-remove=react-19
 
+  # Review what a Fish + Tmux install would do, without touching anything
+  gentleman.dots --dry-run --non-interactive --shell=fish --wm=tmux --nvim
+
+  # Symlink the configs stow-style, so 'git pull' in the clone updates them
+  gentleman.dots --non-interactive --shell=zsh --wm=tmux --nvim --link
+
+  # Use JetBrains Mono at size 13 in every installed terminal and Zed
+  gentleman.dots --font-family=jetbrains-mono --font-size=13
+
+  # Switch every installed config to Tokyo Night, then back
+  gentleman.dots --theme=tokyonight
+  gentleman.dots --theme=gentleman
+
+  # Provision from a shared profile, overriding the shell
+  gentleman.dots --profile=team.toml --shell=zsh
+
+  # Retry flaky downloads up to 3 times before giving up
+  gentleman.dots --non-interactive --shell=fish --nvim --on-error=retry:3
+
+  # Continue an installation that failed halfway
+  gentleman.dots --resume
+
+  # Check the health of an existing setup
+  gentleman.dots doctor
+
+  # See which installed configs you edited or the repository changed
+  gentleman.dots status
+
+  # Pull the latest configs, keeping your own edits
+  gentleman.dots update
+
+  # Remove the Neovim and Tmux configs and their packages
+  gentleman.dots uninstall --packages nvim,tmux
+
   # Verbose output (shows all command logs)
   GENTLEMAN_VERBOSE=1 gentleman.dots --non-interactive --shell=fish --nvim
 
@@ -553,8 +1357,19 @@ Navigation (TUI mode):
   ↑/k, ↓/j        Navigate up/down
   Enter/Space     Select option
   Esc             Go back
+  s               Save current choices as a profile (backup and complete screens)
   q               Quit
   d               Toggle details (during installation)
+  l               Full log viewer (space+l during installation)
+
+Logs:
+  Each installation writes a session log to ~/.config/gentleman/logs/
+
+For more info: https://github.com/Gentleman-Programming/Gentleman.Dots
+`, toolList(tui.ValidShells), toolList(tui.ValidTerminals), toolList(tui.ValidWindowManagers), toolList(tui.ValidFonts), toolList(tui.ValidThemes))
+}
 
-For more info: https://github.com/Gentleman-Programming/Gentleman.Dots`)
+// toolList formats the accepted values of a tool flag for the help
+func toolList(ids []string) string {
+	return strings.Join(ids, ", ")
 }
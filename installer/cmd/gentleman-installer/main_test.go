@@ -1,8 +1,14 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
 )
 
 func TestParseRolePacks(t *testing.T) {
@@ -104,3 +110,439 @@ func TestParseRolePacks(t *testing.T) {
 		}
 	})
 }
+
+func TestBuildProfile(t *testing.T) {
+	t.Run("flags without a profile", func(t *testing.T) {
+		flags := &cliFlags{
+			shell:    "Fish",
+			terminal: "kitty",
+			aiTools:  "claude, opencode",
+			set:      map[string]bool{"shell": true, "terminal": true, "ai-tools": true},
+		}
+		p, err := buildProfile(flags)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		c := p.Choices()
+		if c.Shell != "fish" || c.Terminal != "kitty" || c.WindowMgr != "none" {
+			t.Errorf("unexpected choices: %+v", c)
+		}
+		if strings.Join(c.AITools, ",") != "claude,opencode" {
+			t.Errorf("AI tools = %v", c.AITools)
+		}
+		if !c.CreateBackup {
+			t.Error("backup should default to true")
+		}
+	})
+
+	t.Run("explicit flags override the profile", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "team.toml")
+		os.WriteFile(path, []byte("version = 1\nshell = \"zsh\"\nterminal = \"ghostty\"\nnvim = true\nbackup = false\n"), 0644)
+
+		flags := &cliFlags{
+			profile: path,
+			shell:   "fish",
+			nvim:    false,
+			backup:  true, // default value, but not passed explicitly
+			link:    true,
+			set:     map[string]bool{"profile": true, "shell": true, "nvim": true, "link": true},
+		}
+		p, err := buildProfile(flags)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if p.Shell != "fish" || p.Nvim || !p.Choices().LinkConfigs {
+			t.Errorf("flags should override the profile: %+v", p)
+		}
+		if p.Terminal != "ghostty" || p.Backup {
+			t.Errorf("unset flags should keep profile values: %+v", p)
+		}
+	})
+
+	t.Run("font family and size", func(t *testing.T) {
+		flags := &cliFlags{fontFamily: "Hack", fontSize: 13, set: map[string]bool{"font-family": true, "font-size": true}}
+		p, err := buildProfile(flags)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c := p.Choices(); !c.InstallFont || c.Font != "hack" || c.FontSize != 13 {
+			t.Errorf("--font-family should install and set the font: %+v", c)
+		}
+
+		flags = &cliFlags{fontFamily: "comic-sans", set: map[string]bool{"font-family": true}}
+		if _, err := buildProfile(flags); err == nil || !strings.Contains(err.Error(), "invalid font family") {
+			t.Errorf("expected an invalid font family error, got %v", err)
+		}
+	})
+
+	t.Run("invalid values are rejected", func(t *testing.T) {
+		flags := &cliFlags{windowMgr: "screen", set: map[string]bool{"wm": true}}
+		_, err := buildProfile(flags)
+		if err == nil || !strings.Contains(err.Error(), "invalid window manager: screen") {
+			t.Errorf("expected invalid window manager error, got %v", err)
+		}
+	})
+}
+
+func TestThemeFlag(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+	kitty := filepath.Join(home, ".config", "kitty", "kitty.conf")
+	os.MkdirAll(filepath.Dir(kitty), 0755)
+	os.WriteFile(kitty, []byte("background #06080f\n"), 0644)
+
+	var buf bytes.Buffer
+	out = &buf
+	defer func() { out = os.Stdout }()
+
+	if err := runNonInteractive(&cliFlags{theme: "Kanagawa", set: map[string]bool{"theme": true}}); err != nil {
+		t.Fatalf("a theme alone should not need --shell: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Restyled Kitty") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+	if data, _ := os.ReadFile(kitty); !strings.Contains(string(data), "background #1f1f28") {
+		t.Errorf("kitty should have the kanagawa colors:\n%s", data)
+	}
+
+	if err := runNonInteractive(&cliFlags{theme: "gentleman", set: map[string]bool{"theme": true}}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(kitty); string(data) != "background #06080f\n" {
+		t.Errorf("gentleman should put the original colors back:\n%s", data)
+	}
+
+	if err := runNonInteractive(&cliFlags{theme: "solarized", set: map[string]bool{"theme": true}}); err == nil || !strings.Contains(err.Error(), "invalid theme") {
+		t.Errorf("expected an invalid theme error, got %v", err)
+	}
+}
+
+func TestRunUninstall(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+	tmux := filepath.Join(home, ".tmux.conf")
+	os.WriteFile(tmux, []byte("set -g mouse on\n"), 0644)
+
+	var buf bytes.Buffer
+	out = &buf
+	defer func() { out, in = os.Stdout, os.Stdin }()
+
+	t.Run("declining keeps everything", func(t *testing.T) {
+		in = strings.NewReader("n\n")
+		if err := runUninstall([]string{"tmux"}); err == nil || !strings.Contains(err.Error(), "cancelled") {
+			t.Errorf("expected the uninstall to be cancelled, got %v", err)
+		}
+		if _, err := os.Stat(tmux); err != nil {
+			t.Error("tmux config should be kept")
+		}
+	})
+
+	t.Run("unknown components are rejected", func(t *testing.T) {
+		if err := runUninstall([]string{"emacs"}); err == nil {
+			t.Error("expected an error for an unknown component")
+		}
+	})
+
+	t.Run("confirming removes the config but asks again for packages", func(t *testing.T) {
+		in = strings.NewReader("y\nn\n")
+		buf.Reset()
+		if err := runUninstall([]string{"--packages", "tmux"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := os.Stat(tmux); !os.IsNotExist(err) {
+			t.Error("tmux config should be removed")
+		}
+		if !strings.Contains(buf.String(), "Also remove these packages: tmux?") || strings.Contains(buf.String(), "Packages removed") {
+			t.Errorf("declined packages should not be removed:\n%s", buf.String())
+		}
+	})
+}
+
+func TestRunStatus(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+
+	var buf bytes.Buffer
+	out = &buf
+	defer func() { out = os.Stdout }()
+
+	t.Run("nothing installed", func(t *testing.T) {
+		if err := runStatus(nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(buf.String(), "No installed files are tracked") {
+			t.Errorf("expected the empty message:\n%s", buf.String())
+		}
+	})
+
+	tmux := filepath.Join(home, ".tmux.conf")
+	system.WriteFile(tmux, []byte("set -g mouse on\n"), 0644)
+	if err := system.SaveManifest(filepath.Join(home, "gone"), "https://example.com/dots.git"); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(tmux, []byte("set -g mouse off\n"), 0644)
+
+	t.Run("lists local changes", func(t *testing.T) {
+		buf.Reset()
+		if err := runStatus([]string{"--offline"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(buf.String(), "Changed locally:\n  • "+tmux) || strings.Contains(buf.String(), "Fetching") {
+			t.Errorf("expected the edited tmux config without cloning:\n%s", buf.String())
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		buf.Reset()
+		if err := runStatus([]string{"--offline", "--json"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var status system.ManifestStatus
+		if err := json.Unmarshal(buf.Bytes(), &status); err != nil || status.Count(system.DriftModified) != 1 {
+			t.Errorf("expected one modified file, got %+v, %v", status, err)
+		}
+	})
+}
+
+func TestRunUpdate(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+
+	var buf bytes.Buffer
+	out = &buf
+	defer func() { out, in = os.Stdout, os.Stdin }()
+
+	repo := t.TempDir()
+	os.WriteFile(filepath.Join(repo, "tmux.conf"), []byte("set -g mouse on\n"), 0644)
+	os.WriteFile(filepath.Join(repo, "starship.toml"), []byte("format = ''\n"), 0644)
+	tmux := filepath.Join(home, ".tmux.conf")
+	starship := filepath.Join(home, ".config", "starship.toml")
+	system.EnsureDir(filepath.Dir(starship))
+	system.CopyFile(filepath.Join(repo, "tmux.conf"), tmux)
+	system.CopyFile(filepath.Join(repo, "starship.toml"), starship)
+	system.SaveManifest(repo, "https://example.com/dots.git")
+
+	os.WriteFile(starship, []byte("format = 'mine'\n"), 0644)
+	os.WriteFile(filepath.Join(repo, "tmux.conf"), []byte("set -g mouse off\n"), 0644)
+	os.WriteFile(filepath.Join(repo, "starship.toml"), []byte("format = 'theirs'\n"), 0644)
+
+	t.Run("declining changes nothing", func(t *testing.T) {
+		in = strings.NewReader("n\n")
+		if err := runUpdate([]string{"--repo", repo}); err == nil || !strings.Contains(err.Error(), "cancelled") {
+			t.Errorf("expected the update to be cancelled, got %v", err)
+		}
+		if got, _ := os.ReadFile(tmux); string(got) != "set -g mouse on\n" {
+			t.Errorf("tmux config should be kept, got %q", got)
+		}
+	})
+
+	t.Run("conflicts are skipped unless --theirs", func(t *testing.T) {
+		buf.Reset()
+		if err := runUpdate([]string{"--repo", repo, "--yes"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got, _ := os.ReadFile(tmux); string(got) != "set -g mouse off\n" {
+			t.Errorf("unedited file should be updated, got %q", got)
+		}
+		if got, _ := os.ReadFile(starship); string(got) != "format = 'mine'\n" {
+			t.Errorf("conflicting file should be left alone, got %q", got)
+		}
+		if !strings.Contains(buf.String(), "Updated 1 file(s)") || !strings.Contains(buf.String(), "--theirs") {
+			t.Errorf("unexpected output:\n%s", buf.String())
+		}
+
+		if err := runUpdate([]string{"--repo", repo, "--yes", "--theirs"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got, _ := os.ReadFile(starship); string(got) != "format = 'theirs'\n" {
+			t.Errorf("--theirs should take the repository version, got %q", got)
+		}
+	})
+}
+
+func TestRunBackup(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+	for _, id := range []string{"2024-01-01-000000", "2024-02-01-000000", "2024-03-01-000000"} {
+		dir := filepath.Join(home, system.BackupPrefix+id)
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, "tmux"), []byte("set -g mouse on\n"), 0644)
+	}
+
+	var buf bytes.Buffer
+	out = &buf
+	defer func() { out = os.Stdout }()
+
+	t.Run("list", func(t *testing.T) {
+		buf.Reset()
+		if err := runBackup([]string{"list"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(buf.String(), "2024-02-01-000000  tmux\n") {
+			t.Errorf("expected the backups with their entries:\n%s", buf.String())
+		}
+	})
+
+	t.Run("prune needs a policy", func(t *testing.T) {
+		if err := runBackup([]string{"prune"}); err == nil || !strings.Contains(err.Error(), "no retention policy") {
+			t.Errorf("expected a missing policy error, got %v", err)
+		}
+	})
+
+	t.Run("config sets the policy prune uses", func(t *testing.T) {
+		buf.Reset()
+		if err := runBackup([]string{"config", "--compression=gzip", "--keep=2"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(buf.String(), "Compression: gzip") || !strings.Contains(buf.String(), "Keep:        2 newest backups") {
+			t.Errorf("expected the saved settings:\n%s", buf.String())
+		}
+
+		buf.Reset()
+		if err := runBackup([]string{"prune", "--dry-run"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if buf.String() != "Would delete 2024-01-01-000000\n" || len(system.ListBackups()) != 3 {
+			t.Errorf("dry run should only list the oldest backup:\n%s", buf.String())
+		}
+	})
+
+	t.Run("prune", func(t *testing.T) {
+		t.Setenv("GENTLEMAN_DRY_RUN", "")
+		buf.Reset()
+		if err := runBackup([]string{"prune", "--keep=1"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if backups := system.ListBackups(); len(backups) != 1 || backups[0].ID() != "2024-03-01-000000" {
+			t.Errorf("expected only the newest backup, got %+v", backups)
+		}
+	})
+
+	t.Run("unknown command", func(t *testing.T) {
+		if err := runBackup([]string{"shrink"}); err == nil {
+			t.Error("expected an error for an unknown command")
+		}
+	})
+}
+
+func TestRunRestore(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+
+	tmux := filepath.Join(home, ".tmux.conf")
+	zshrc := filepath.Join(home, ".zshrc")
+	os.WriteFile(tmux, []byte("set -g mouse on\n"), 0644)
+	os.WriteFile(zshrc, []byte("export EDITOR=nvim\n"), 0644)
+	backupDir, err := system.CreateBackupFor([]string{"tmux", "zsh"}, "install", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(tmux, []byte("set -g mouse off\n"), 0644)
+	os.WriteFile(zshrc, []byte("export EDITOR=vim\n"), 0644)
+
+	var buf bytes.Buffer
+	out = &buf
+	defer func() { out, in = os.Stdout, os.Stdin }()
+	read := func(path string) string {
+		data, _ := os.ReadFile(path)
+		return string(data)
+	}
+
+	t.Run("unknown entries are rejected", func(t *testing.T) {
+		if err := runRestore([]string{"--only", "kitty"}); err == nil || !strings.Contains(err.Error(), "no kitty entry") {
+			t.Errorf("expected an unknown entry error, got %v", err)
+		}
+	})
+
+	t.Run("diff then decline", func(t *testing.T) {
+		in = strings.NewReader("n\n")
+		buf.Reset()
+		if err := runRestore([]string{"--only", "tmux", "--diff", strings.TrimPrefix(filepath.Base(backupDir), system.BackupPrefix)}); err == nil {
+			t.Error("expected the restore to be cancelled")
+		}
+		if !strings.Contains(buf.String(), "-set -g mouse off\n+set -g mouse on\n") || strings.Contains(buf.String(), "EDITOR") {
+			t.Errorf("expected only the tmux diff:\n%s", buf.String())
+		}
+		if read(tmux) != "set -g mouse off\n" {
+			t.Error("a declined restore should change nothing")
+		}
+	})
+
+	t.Run("restore only the picked entries", func(t *testing.T) {
+		buf.Reset()
+		if err := runRestore([]string{"--only", "tmux", "--yes"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if read(tmux) != "set -g mouse on\n" || read(zshrc) != "export EDITOR=vim\n" {
+			t.Error("only tmux should be restored")
+		}
+		if !strings.Contains(buf.String(), "restore --undo puts them back") {
+			t.Errorf("expected the snapshot to be reported:\n%s", buf.String())
+		}
+	})
+
+	t.Run("undo", func(t *testing.T) {
+		buf.Reset()
+		if err := runRestore([]string{"--undo", "--yes"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if read(tmux) != "set -g mouse off\n" {
+			t.Error("undo should put the replaced config back")
+		}
+	})
+}
+
+func TestRunBackupExportImport(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+	os.WriteFile(filepath.Join(home, ".tmux.conf"), []byte("set -g mouse on\n"), 0644)
+	backupDir, err := system.CreateBackupFor([]string{"tmux"}, "install", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := strings.TrimPrefix(filepath.Base(backupDir), system.BackupPrefix)
+
+	var buf bytes.Buffer
+	out = &buf
+	defer func() { out = os.Stdout }()
+
+	archive := filepath.Join(t.TempDir(), "dots.tar.gz")
+	t.Run("export", func(t *testing.T) {
+		if err := runBackup([]string{"export", id, "-o", archive}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := os.Stat(archive); err != nil {
+			t.Errorf("expected the archive: %v", err)
+		}
+	})
+
+	t.Run("export needs a known backup", func(t *testing.T) {
+		if err := runBackup([]string{"export", "1999-01-01-000000"}); err == nil {
+			t.Error("expected an error for an unknown backup")
+		}
+	})
+
+	t.Run("import on another machine", func(t *testing.T) {
+		t.Setenv("HOME", t.TempDir())
+		buf.Reset()
+		if err := runBackup([]string{"import", archive}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(buf.String(), ": tmux\n") || !strings.Contains(buf.String(), "Restore it with: restore ") {
+			t.Errorf("expected the imported entries:\n%s", buf.String())
+		}
+
+		buf.Reset()
+		runBackup([]string{"list"})
+		if !strings.Contains(buf.String(), "imported from dots.tar.gz") {
+			t.Errorf("list should show where the backup came from:\n%s", buf.String())
+		}
+	})
+}
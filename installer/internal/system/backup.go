@@ -0,0 +1,834 @@
+package system
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A backup is a ~/.gentleman-backup-<timestamp> directory holding a copy of
+// every config the installer was about to overwrite, one entry per
+// ConfigPaths key, and a manifest describing them. Compressed backups keep
+// the entries in a single tar archive next to the manifest.
+
+// BackupPrefix starts the name of every backup directory in HOME
+const BackupPrefix = ".gentleman-backup-"
+
+// backupTimeFormat is the timestamp at the end of a backup directory name
+const backupTimeFormat = "2006-01-02-150405"
+
+// BackupManifestVersion is bumped when the backup manifest format changes
+// incompatibly
+const BackupManifestVersion = 1
+
+// BackupManifestName is the manifest file inside a backup directory
+const BackupManifestName = "manifest.json"
+
+// Backup compression formats
+const (
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+// ValidCompressions are the accepted backup compression formats
+var ValidCompressions = []string{CompressionNone, CompressionGzip, CompressionZstd}
+
+// installerVersion is recorded in backup manifests
+var installerVersion = "dev"
+
+// SetInstallerVersion sets the installer version recorded in backups
+func SetInstallerVersion(version string) {
+	installerVersion = version
+}
+
+// BackupEntry is a config saved in a backup. Size and SHA256 cover every
+// file below Path, see treeDigest.
+type BackupEntry struct {
+	Key    string `json:"key"`
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// BackupManifest describes a backup: what it holds, where each entry came
+// from, and what triggered it
+type BackupManifest struct {
+	Version     int             `json:"version"`
+	Created     time.Time       `json:"created"`
+	Installer   string          `json:"installer"`
+	Reason      string          `json:"reason,omitempty"`  // install, update, ...
+	Choices     json.RawMessage `json:"choices,omitempty"` // the installer choices of the run
+	Compression string          `json:"compression,omitempty"`
+	Entries     []BackupEntry   `json:"entries"`
+	Restored    []string        `json:"restored,omitempty"`      // keys replaced by the restore this backup undoes
+	Imported    string          `json:"imported_from,omitempty"` // archive the backup was imported from
+}
+
+// Size is the size of the backed up configs, before compression
+func (m *BackupManifest) Size() int64 {
+	var size int64
+	for _, e := range m.Entries {
+		size += e.Size
+	}
+	return size
+}
+
+// BackupInfo contains information about a backup
+type BackupInfo struct {
+	Path      string
+	Timestamp time.Time
+	Files     []string        // entry keys
+	Manifest  *BackupManifest // nil for backups made before manifests
+}
+
+// ID names the backup on the command line: the timestamp of its directory
+func (b BackupInfo) ID() string {
+	return strings.TrimPrefix(filepath.Base(b.Path), BackupPrefix)
+}
+
+// LoadBackupManifest reads the manifest of a backup directory. Backups made
+// before manifests existed return an error wrapping os.ErrNotExist.
+func LoadBackupManifest(backupDir string) (*BackupManifest, error) {
+	data, err := os.ReadFile(filepath.Join(backupDir, BackupManifestName))
+	if err != nil {
+		return nil, err
+	}
+	var manifest BackupManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid backup manifest in %s: %w", backupDir, err)
+	}
+	if manifest.Version > BackupManifestVersion {
+		return nil, fmt.Errorf("backup %s has unsupported manifest version %d", backupDir, manifest.Version)
+	}
+	return &manifest, nil
+}
+
+func (m *BackupManifest) save(backupDir string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(backupDir, BackupManifestName), append(data, '\n'), 0644)
+}
+
+// GetBackupDir returns the backup directory path with timestamp
+func GetBackupDir() string {
+	home := os.Getenv("HOME")
+	timestamp := time.Now().Format(backupTimeFormat)
+	return home + "/" + BackupPrefix + timestamp
+}
+
+// ListBackups returns all existing backups, oldest first
+func ListBackups() []BackupInfo {
+	home := os.Getenv("HOME")
+	backups := []BackupInfo{}
+
+	entries, err := os.ReadDir(home)
+	if err != nil {
+		return backups
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), BackupPrefix) {
+			backupPath := home + "/" + entry.Name()
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			backup := BackupInfo{Path: backupPath, Timestamp: info.ModTime()}
+
+			if manifest, err := LoadBackupManifest(backupPath); err == nil {
+				backup.Manifest = manifest
+				backup.Timestamp = manifest.Created
+			} else if t, err := time.ParseInLocation(backupTimeFormat, backup.ID(), time.Local); err == nil {
+				// Older backups are only named after their time
+				backup.Timestamp = t
+			}
+			backup.Files = backupKeys(backupPath, backup.Manifest)
+
+			backups = append(backups, backup)
+		}
+	}
+
+	return backups
+}
+
+// backupKeys returns the config keys held by a backup: the manifest
+// entries, or the files in the directory for older backups
+func backupKeys(backupDir string, manifest *BackupManifest) []string {
+	keys := []string{}
+	if manifest != nil {
+		for _, e := range manifest.Entries {
+			keys = append(keys, e.Key)
+		}
+		return keys
+	}
+	entries, _ := os.ReadDir(backupDir)
+	for _, entry := range entries {
+		keys = append(keys, entry.Name())
+	}
+	return keys
+}
+
+// FindBackup returns the backup with the given ID or path
+func FindBackup(id string) (BackupInfo, bool) {
+	for _, backup := range ListBackups() {
+		if backup.ID() == id || backup.Path == filepath.Clean(id) {
+			return backup, true
+		}
+	}
+	return BackupInfo{}, false
+}
+
+// OriginalBackup returns the backup holding the config key as it was before
+// the installer first wrote it: the one the install recorded, or for older
+// installs the oldest install backup holding the key. Update backups and
+// restore snapshots hold the installer's own files and are never picked.
+func OriginalBackup(key string) (BackupInfo, bool) {
+	if manifest, err := LoadManifest(); err == nil && manifest.Originals != nil {
+		if id := manifest.Originals[key]; id != "" {
+			return FindBackup(id)
+		}
+		return BackupInfo{}, false
+	}
+	// Backups sort by age, and only installs made them before manifests
+	for _, backup := range ListBackups() {
+		if backup.Manifest != nil && backup.Manifest.Reason != "install" {
+			continue
+		}
+		if slices.Contains(backup.Files, key) {
+			return backup, true
+		}
+	}
+	return BackupInfo{}, false
+}
+
+// backupHas reports whether a backup holds the config key
+func backupHas(backupDir, key string) bool {
+	if manifest, err := LoadBackupManifest(backupDir); err == nil {
+		return slices.ContainsFunc(manifest.Entries, func(e BackupEntry) bool { return e.Key == key })
+	}
+	_, err := os.Lstat(filepath.Join(backupDir, key))
+	return err == nil
+}
+
+// CreateBackup creates a backup of existing configs
+func CreateBackup(configs []string) (string, error) {
+	return CreateBackupFor(configs, "", nil)
+}
+
+// CreateBackupFor creates a backup of existing configs, recording why it
+// was made and the installer choices of the run in its manifest. It is
+// compressed and old backups are pruned as the backup settings say.
+func CreateBackupFor(configs []string, reason string, choices any) (string, error) {
+	manifest := newBackupManifest(reason)
+	if choices != nil {
+		if data, err := json.Marshal(choices); err == nil {
+			manifest.Choices = data
+		}
+	}
+	backupDir, err := createBackup(configs, manifest)
+	if err != nil || IsDryRun() {
+		return backupDir, err
+	}
+	if reason == "install" {
+		if err := recordOriginals(BackupInfo{Path: backupDir}.ID(), backupKeys(backupDir, manifest)); err != nil {
+			return backupDir, err
+		}
+	}
+
+	// Pruning is housekeeping: the backup itself is done
+	if settings, err := LoadBackupSettings(); err == nil && settings.Policy.Enabled() {
+		_, _ = PruneBackups(settings.Policy)
+	}
+	return backupDir, nil
+}
+
+func newBackupManifest(reason string) *BackupManifest {
+	return &BackupManifest{
+		Version:   BackupManifestVersion,
+		Created:   time.Now(),
+		Installer: installerVersion,
+		Reason:    reason,
+		Entries:   []BackupEntry{},
+	}
+}
+
+// createBackup copies the configs into a new backup directory and writes
+// manifest, filled with the entries, next to them
+func createBackup(configs []string, manifest *BackupManifest) (string, error) {
+	backupDir, err := newBackupDir()
+	if err != nil {
+		return "", err
+	}
+
+	configPaths := ConfigPaths()
+	for _, configKey := range configs {
+		// Extract key from "key: path" format if present
+		key := configKey
+		if idx := strings.Index(configKey, ":"); idx > 0 {
+			key = configKey[:idx]
+		}
+
+		srcPath, exists := configPaths[key]
+		if !exists {
+			continue
+		}
+
+		// Check if source exists
+		if _, err := os.Lstat(srcPath); err != nil {
+			continue // File doesn't exist, skip
+		}
+
+		dstPath := backupDir + "/" + key
+		if err := copyEntry(srcPath, dstPath); err != nil {
+			return backupDir, fmt.Errorf("failed to backup %s: %w", key, err)
+		}
+
+		if IsDryRun() {
+			continue
+		}
+		size, sum, err := treeDigest(dstPath)
+		if err != nil {
+			return backupDir, fmt.Errorf("failed to backup %s: %w", key, err)
+		}
+		manifest.Entries = append(manifest.Entries, BackupEntry{Key: key, Path: srcPath, Size: size, SHA256: sum})
+	}
+
+	return backupDir, sealBackup(backupDir, manifest)
+}
+
+// newBackupDir creates the directory of a new backup. Backups made within
+// the same second get a suffix.
+func newBackupDir() (string, error) {
+	base := GetBackupDir()
+	backupDir := base
+	for i := 2; ; i++ {
+		if _, err := os.Lstat(backupDir); err != nil {
+			break
+		}
+		backupDir = fmt.Sprintf("%s-%d", base, i)
+	}
+	if err := ensureDir(backupDir); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	return backupDir, nil
+}
+
+// copyEntry copies a config or backup entry: a file, a directory or a link,
+// which stays a link
+func copyEntry(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		if IsDryRun() {
+			RecordPlan(PlanLink, dst, src)
+			return nil
+		}
+		return copyLink(src, dst)
+	case info.IsDir():
+		return copyDir(src, dst)
+	default:
+		return copyFile(src, dst)
+	}
+}
+
+// sealBackup compresses the entries of a backup as the backup settings say
+// and writes its manifest
+func sealBackup(backupDir string, manifest *BackupManifest) error {
+	if IsDryRun() {
+		return nil
+	}
+
+	settings, err := LoadBackupSettings()
+	if err != nil {
+		return err
+	}
+	if compression := settings.compression(); compression != "" && len(manifest.Entries) > 0 {
+		keys := make([]string, len(manifest.Entries))
+		for i, e := range manifest.Entries {
+			keys[i] = e.Key
+		}
+		if err := writeArchive(filepath.Join(backupDir, archiveName(compression)), backupDir, keys, compression, nil); err != nil {
+			return fmt.Errorf("failed to compress backup: %w", err)
+		}
+		for _, key := range keys {
+			if err := os.RemoveAll(filepath.Join(backupDir, key)); err != nil {
+				return err
+			}
+		}
+		manifest.Compression = compression
+	}
+	if err := manifest.save(backupDir); err != nil {
+		return fmt.Errorf("failed to write backup manifest: %w", err)
+	}
+	return nil
+}
+
+// RestoreBackup restores configs from a backup directory
+func RestoreBackup(backupDir string) error {
+	return RestoreBackupEntries(backupDir, nil)
+}
+
+// RestoreBackupEntries restores only the given config keys from a backup
+// directory. A nil keys slice restores every entry.
+func RestoreBackupEntries(backupDir string, keys []string) error {
+	configPaths := ConfigPaths()
+
+	dir, cleanup, err := openBackup(backupDir)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		key := entry.Name()
+		dstPath, exists := configPaths[key]
+		if !exists {
+			continue
+		}
+		if keys != nil && !slices.Contains(keys, key) {
+			continue
+		}
+
+		srcPath := dir + "/" + key
+
+		// Remove current config, the backup replaces what was installed
+		removeAll(dstPath)
+		forgetManifest(dstPath)
+
+		if err := copyEntry(srcPath, dstPath); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// DeleteBackup removes a backup directory
+func DeleteBackup(backupDir string) error {
+	return os.RemoveAll(backupDir)
+}
+
+// openBackup returns a directory holding the entries of a backup. A
+// compressed backup is extracted to a temporary directory that cleanup
+// removes.
+func openBackup(backupDir string) (string, func(), error) {
+	manifest, err := LoadBackupManifest(backupDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return backupDir, func() {}, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	if manifest.Compression == "" {
+		return backupDir, func() {}, nil
+	}
+
+	tmp, err := os.MkdirTemp("", "gentleman-backup-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(tmp) }
+	if err := extractArchive(filepath.Join(backupDir, archiveName(manifest.Compression)), manifest.Compression, tmp, true); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to extract backup: %w", err)
+	}
+	return tmp, cleanup, nil
+}
+
+// treeDigest returns the size of the files below root and a hash of their
+// paths, contents and link targets, so a copy of the tree can be checked
+// against it
+func treeDigest(root string) (int64, string, error) {
+	h := sha256.New()
+	var size int64
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(h, "link %s %s\n", rel, target)
+		case info.IsDir():
+			fmt.Fprintf(h, "dir %s\n", rel)
+		default:
+			sum, err := fileSHA256(path)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(h, "file %s %s\n", rel, sum)
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, hex.EncodeToString(h.Sum(nil)), err
+}
+
+// archiveName is the archive holding the entries of a compressed backup
+func archiveName(compression string) string {
+	if compression == CompressionZstd {
+		return "entries.tar.zst"
+	}
+	return "entries.tar.gz"
+}
+
+// writeArchive packs the entries keys of dir into a tar archive at path,
+// after the manifest when it is not nil
+func writeArchive(path, dir string, keys []string, compression string, manifest *BackupManifest) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var w io.WriteCloser
+	wait := func() error { return nil }
+	if compression == CompressionZstd {
+		cmd := exec.Command("zstd", "-q", "-c")
+		cmd.Stdout = file
+		if w, err = cmd.StdinPipe(); err != nil {
+			return err
+		}
+		if err := cmd.Start(); err != nil {
+			return err
+		}
+		wait = cmd.Wait
+	} else {
+		w = gzip.NewWriter(file)
+	}
+
+	tw := tar.NewWriter(w)
+	err = func() error {
+		if manifest != nil {
+			data, err := json.MarshalIndent(manifest, "", "  ")
+			if err != nil {
+				return err
+			}
+			data = append(data, '\n')
+			header := &tar.Header{Name: BackupManifestName, Mode: 0644, Size: int64(len(data)), ModTime: manifest.Created}
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+			if _, err := tw.Write(data); err != nil {
+				return err
+			}
+		}
+		for _, key := range keys {
+			if err := addToArchive(tw, dir, key); err != nil {
+				return err
+			}
+		}
+		return tw.Close()
+	}()
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if waitErr := wait(); err == nil {
+		err = waitErr
+	}
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// addToArchive writes dir/key and everything below it to tw
+func addToArchive(tw *tar.Writer, dir, key string) error {
+	return filepath.Walk(filepath.Join(dir, key), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+}
+
+// extractArchive unpacks a backup archive into dst, refusing entries that
+// would land outside of it or be written through a link. Links to files
+// outside of dst are only kept in local archives, which back up linked
+// configs; an imported archive has no business pointing into this machine.
+func extractArchive(path, compression, dst string, local bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if compression != CompressionZstd {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		return unpackTar(gz, dst, local)
+	}
+
+	cmd := exec.Command("zstd", "-q", "-d", "-c")
+	cmd.Stdin = file
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	err = unpackTar(stdout, dst, local)
+	if err != nil {
+		// Nobody reads the rest of the output, zstd would block writing it
+		cmd.Process.Kill()
+	}
+	if waitErr := cmd.Wait(); err == nil {
+		err = waitErr
+	}
+	return err
+}
+
+// unpackTar writes the entries of a tar stream below dst
+func unpackTar(r io.Reader, dst string, local bool) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, filepath.FromSlash(header.Name))
+		if !isWithin(target, dst) {
+			return fmt.Errorf("unsafe path %q in backup archive", header.Name)
+		}
+		if err := checkNoLinks(dst, target); err != nil {
+			return fmt.Errorf("unsafe path %q in backup archive: %w", header.Name, err)
+		}
+		mode := header.FileInfo().Mode().Perm()
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, mode|0700); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			linked := filepath.Join(filepath.Dir(target), header.Linkname)
+			if !local && (filepath.IsAbs(header.Linkname) || !isWithin(linked, dst)) {
+				return fmt.Errorf("unsafe link %q -> %q in backup archive", header.Name, header.Linkname)
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			f.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// checkNoLinks fails when target or one of its parents below dst is a
+// link, which an entry would be written through
+func checkNoLinks(dst, target string) error {
+	rel, err := filepath.Rel(dst, target)
+	if err != nil || rel == "." {
+		return err
+	}
+	path := ""
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		path = filepath.Join(path, part)
+		info, err := os.Lstat(filepath.Join(dst, path))
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("%s is a link", filepath.ToSlash(path))
+		}
+	}
+	return nil
+}
+
+// BackupPolicy is how long backups are kept. A backup is kept while it is
+// one of the Keep newest or younger than MaxAgeDays; zero turns a rule off,
+// and with both off every backup is kept.
+type BackupPolicy struct {
+	Keep       int `json:"keep,omitempty"`
+	MaxAgeDays int `json:"max_age_days,omitempty"`
+}
+
+// Enabled reports whether the policy ever expires a backup
+func (p BackupPolicy) Enabled() bool {
+	return p.Keep > 0 || p.MaxAgeDays > 0
+}
+
+// Expired returns the backups the policy does not keep, oldest first
+func (p BackupPolicy) Expired(backups []BackupInfo, now time.Time) []BackupInfo {
+	if !p.Enabled() {
+		return nil
+	}
+	sorted := slices.Clone(backups)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.After(sorted[j].Timestamp) })
+
+	var expired []BackupInfo
+	for i, backup := range sorted {
+		if p.Keep > 0 && i < p.Keep {
+			continue
+		}
+		if p.MaxAgeDays > 0 && now.Sub(backup.Timestamp) < time.Duration(p.MaxAgeDays)*24*time.Hour {
+			continue
+		}
+		expired = append(expired, backup)
+	}
+	slices.Reverse(expired)
+	return expired
+}
+
+// PruneBackups deletes the backups the policy does not keep and returns
+// them. In dry-run mode it only plans the removals.
+func PruneBackups(policy BackupPolicy) ([]BackupInfo, error) {
+	expired := policy.Expired(ListBackups(), time.Now())
+	for _, backup := range expired {
+		if err := removeAll(backup.Path); err != nil {
+			return expired, fmt.Errorf("failed to delete backup %s: %w", backup.ID(), err)
+		}
+	}
+	return expired, nil
+}
+
+// BackupSettings are the compression and retention of new backups
+type BackupSettings struct {
+	Compression string       `json:"compression,omitempty"` // gzip or zstd, empty for none
+	Policy      BackupPolicy `json:"policy"`
+}
+
+// BackupSettingsPath returns the location of the backup settings
+func BackupSettingsPath() string {
+	return filepath.Join(StateDir(), "backup.json")
+}
+
+// LoadBackupSettings reads the backup settings; without a file backups are
+// uncompressed and kept forever
+func LoadBackupSettings() (BackupSettings, error) {
+	var settings BackupSettings
+	data, err := os.ReadFile(BackupSettingsPath())
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
+	if err != nil {
+		return settings, err
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return settings, fmt.Errorf("invalid backup settings %s: %w", BackupSettingsPath(), err)
+	}
+	return settings, nil
+}
+
+// Save writes the backup settings
+func (s BackupSettings) Save() error {
+	if s.Compression == CompressionNone {
+		s.Compression = ""
+	}
+	if s.Compression != "" && !slices.Contains(ValidCompressions, s.Compression) {
+		return fmt.Errorf("invalid compression: %s (valid: %s)", s.Compression, strings.Join(ValidCompressions, ", "))
+	}
+	if s.Policy.Keep < 0 || s.Policy.MaxAgeDays < 0 {
+		return fmt.Errorf("retention values can not be negative")
+	}
+	if IsDryRun() {
+		return nil
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(StateDir(), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(BackupSettingsPath(), append(data, '\n'), 0644)
+}
+
+// compression is the format new backups are written in. zstd falls back to
+// gzip when the zstd tool is missing.
+func (s BackupSettings) compression() string {
+	switch s.Compression {
+	case CompressionZstd:
+		if _, err := exec.LookPath("zstd"); err != nil {
+			return CompressionGzip
+		}
+		return CompressionZstd
+	case CompressionGzip:
+		return CompressionGzip
+	}
+	return ""
+}
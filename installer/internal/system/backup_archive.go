@@ -0,0 +1,139 @@
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Exported backups are tar archives holding manifest.json and the entries,
+// the layout of an uncompressed backup directory. The archive is gzip
+// compressed, or zstd when its name ends in .zst.
+
+// archiveCompression is the compression of an exported backup, from its name
+func archiveCompression(path string) string {
+	if strings.HasSuffix(path, ".zst") {
+		return CompressionZstd
+	}
+	return CompressionGzip
+}
+
+// ExportBackup writes a backup to a portable archive at dst. Backups made
+// before manifests get one, so the archive can be checked on import.
+func ExportBackup(backupDir, dst string) error {
+	if IsDryRun() {
+		RecordPlan(PlanCopy, dst, backupDir)
+		return nil
+	}
+
+	dir, cleanup, err := openBackup(backupDir)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	manifest, err := LoadBackupManifest(backupDir)
+	if os.IsNotExist(err) {
+		manifest, err = describeBackup(backupDir)
+	}
+	if err != nil {
+		return err
+	}
+	exported := *manifest
+	exported.Compression = ""
+
+	keys := backupKeys(backupDir, manifest)
+	if err := writeArchive(dst, dir, keys, archiveCompression(dst), &exported); err != nil {
+		os.Remove(dst)
+		return fmt.Errorf("failed to export backup: %w", err)
+	}
+	return nil
+}
+
+// describeBackup builds the manifest of a backup made before manifests
+func describeBackup(backupDir string) (*BackupManifest, error) {
+	manifest := newBackupManifest("")
+	if info, err := os.Stat(backupDir); err == nil {
+		manifest.Created = info.ModTime()
+	}
+	manifest.Installer = ""
+	configPaths := ConfigPaths()
+	for _, key := range backupKeys(backupDir, nil) {
+		path, ok := configPaths[key]
+		if !ok {
+			continue
+		}
+		size, sum, err := treeDigest(filepath.Join(backupDir, key))
+		if err != nil {
+			return nil, err
+		}
+		manifest.Entries = append(manifest.Entries, BackupEntry{Key: key, Path: path, Size: size, SHA256: sum})
+	}
+	return manifest, nil
+}
+
+// ImportBackup adds the backup in an exported archive to the backups of
+// this machine and returns its directory. Every entry is checked against
+// the manifest first, and its path is rewritten to where the config lives
+// here.
+func ImportBackup(archive string) (string, error) {
+	tmp, err := os.MkdirTemp("", "gentleman-import-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := extractArchive(archive, archiveCompression(archive), tmp, false); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", archive, err)
+	}
+	manifest, err := LoadBackupManifest(tmp)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("%s is not an exported backup: it has no %s", archive, BackupManifestName)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := verifyBackup(tmp, manifest); err != nil {
+		return "", err
+	}
+
+	configPaths := ConfigPaths()
+	for i := range manifest.Entries {
+		manifest.Entries[i].Path = configPaths[manifest.Entries[i].Key]
+	}
+	manifest.Imported = filepath.Base(archive)
+
+	backupDir, err := newBackupDir()
+	if err != nil {
+		return "", err
+	}
+	for _, e := range manifest.Entries {
+		if err := copyEntry(filepath.Join(tmp, e.Key), filepath.Join(backupDir, e.Key)); err != nil {
+			return backupDir, fmt.Errorf("failed to import %s: %w", e.Key, err)
+		}
+	}
+	return backupDir, sealBackup(backupDir, manifest)
+}
+
+// verifyBackup checks that the entries of an uncompressed backup directory
+// are the ones its manifest describes
+func verifyBackup(dir string, manifest *BackupManifest) error {
+	if manifest.Compression != "" {
+		return fmt.Errorf("unexpected %s compression in an exported backup", manifest.Compression)
+	}
+	configPaths := ConfigPaths()
+	for _, e := range manifest.Entries {
+		if _, ok := configPaths[e.Key]; !ok {
+			return fmt.Errorf("backup has an unknown config %q", e.Key)
+		}
+		size, sum, err := treeDigest(filepath.Join(dir, e.Key))
+		if err != nil {
+			return fmt.Errorf("backup is incomplete: %s is missing", e.Key)
+		}
+		if size != e.Size || sum != e.SHA256 {
+			return fmt.Errorf("backup is corrupt: %s does not match its manifest", e.Key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package system
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportImportBackup(t *testing.T) {
+	oldHome := t.TempDir()
+	t.Setenv("HOME", oldHome)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+
+	os.WriteFile(filepath.Join(oldHome, ".tmux.conf"), []byte("set -g mouse on\n"), 0644)
+	nvim := filepath.Join(oldHome, ".config", "nvim")
+	os.MkdirAll(nvim, 0755)
+	os.WriteFile(filepath.Join(nvim, "init.lua"), []byte("require('config')\n"), 0644)
+	backupDir, err := CreateBackupFor([]string{"tmux", "nvim"}, "install", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archive := filepath.Join(t.TempDir(), "dots.tar.gz")
+	if err := ExportBackup(backupDir, archive); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+
+	// A new laptop: another home
+	newHome := t.TempDir()
+	t.Setenv("HOME", newHome)
+	imported, err := ImportBackup(archive)
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if !strings.HasPrefix(imported, filepath.Join(newHome, BackupPrefix)) {
+		t.Errorf("the backup should be imported into the new home, got %s", imported)
+	}
+	manifest, err := LoadBackupManifest(imported)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Imported != "dots.tar.gz" || manifest.Reason != "install" || len(manifest.Entries) != 2 {
+		t.Errorf("unexpected imported manifest: %+v", manifest)
+	}
+	for _, e := range manifest.Entries {
+		if !strings.HasPrefix(e.Path, newHome) {
+			t.Errorf("%s path should point into the new home, got %s", e.Key, e.Path)
+		}
+	}
+
+	if err := RestoreBackup(imported); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(newHome, ".config", "nvim", "init.lua")); string(data) != "require('config')\n" {
+		t.Errorf("expected the nvim config on the new laptop, got %q", data)
+	}
+}
+
+func TestExportLegacyBackup(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+	dir := filepath.Join(home, BackupPrefix+"2024-03-05-101112")
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, "tmux"), []byte("set -g mouse on\n"), 0644)
+
+	archive := filepath.Join(t.TempDir(), "legacy.tar.gz")
+	if err := ExportBackup(dir, archive); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	imported, err := ImportBackup(archive)
+	if err != nil {
+		t.Fatalf("a legacy backup should get a manifest on export: %v", err)
+	}
+	if backup, ok := FindBackup(imported); !ok || strings.Join(backup.Files, ",") != "tmux" {
+		t.Errorf("expected the imported tmux entry, got %+v", backup)
+	}
+}
+
+func TestImportRejectsBadArchives(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+
+	tmux := filepath.Join(home, ".tmux.conf")
+	os.WriteFile(tmux, []byte("set -g mouse on\n"), 0644)
+	backupDir, err := CreateBackupFor([]string{"tmux"}, "install", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("entry changed after the manifest", func(t *testing.T) {
+		os.WriteFile(filepath.Join(backupDir, "tmux"), []byte("tampered\n"), 0644)
+		archive := filepath.Join(t.TempDir(), "tampered.tar.gz")
+		if err := ExportBackup(backupDir, archive); err != nil {
+			t.Fatal(err)
+		}
+		before := len(ListBackups())
+		if _, err := ImportBackup(archive); err == nil || !strings.Contains(err.Error(), "corrupt") {
+			t.Errorf("expected a corrupt backup error, got %v", err)
+		}
+		if len(ListBackups()) != before {
+			t.Error("a rejected archive should not add a backup")
+		}
+	})
+
+	t.Run("not a backup", func(t *testing.T) {
+		archive := filepath.Join(t.TempDir(), "other.tar.gz")
+		os.WriteFile(filepath.Join(home, "notes.txt"), []byte("hi\n"), 0644)
+		if err := writeArchive(archive, home, []string{"notes.txt"}, CompressionGzip, nil); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ImportBackup(archive); err == nil || !strings.Contains(err.Error(), "not an exported backup") {
+			t.Errorf("expected a missing manifest error, got %v", err)
+		}
+	})
+}
+
+// writeTarGz writes a gzip archive holding the given headers, regular files
+// with their name as content
+func writeTarGz(t *testing.T, path string, headers ...*tar.Header) {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, header := range headers {
+		if header.Typeflag == tar.TypeReg {
+			header.Size = int64(len(header.Name))
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			tw.Write([]byte(header.Name))
+		}
+	}
+	tw.Close()
+	gz.Close()
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImportRejectsLinksOut(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+	victim := t.TempDir()
+
+	tests := []struct {
+		name    string
+		headers []*tar.Header
+	}{
+		{"absolute link", []*tar.Header{
+			{Name: "tmux", Typeflag: tar.TypeSymlink, Linkname: victim},
+			{Name: "tmux/authorized_keys", Typeflag: tar.TypeReg, Mode: 0600},
+		}},
+		{"relative link out", []*tar.Header{
+			{Name: "tmux", Typeflag: tar.TypeSymlink, Linkname: "../../../../../../../../" + victim},
+			{Name: "tmux/authorized_keys", Typeflag: tar.TypeReg, Mode: 0600},
+		}},
+		{"write through a link", []*tar.Header{
+			{Name: "nvim/", Typeflag: tar.TypeDir, Mode: 0755},
+			{Name: "tmux", Typeflag: tar.TypeSymlink, Linkname: "nvim"},
+			{Name: "tmux/init.lua", Typeflag: tar.TypeReg, Mode: 0644},
+		}},
+		{"overwrite a link", []*tar.Header{
+			{Name: "tmux", Typeflag: tar.TypeSymlink, Linkname: "nvim"},
+			{Name: "tmux", Typeflag: tar.TypeReg, Mode: 0644},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive := filepath.Join(t.TempDir(), "evil.tar.gz")
+			writeTarGz(t, archive, tt.headers...)
+			if _, err := ImportBackup(archive); err == nil || !strings.Contains(err.Error(), "unsafe") {
+				t.Errorf("expected an unsafe entry error, got %v", err)
+			}
+			if entries, _ := os.ReadDir(victim); len(entries) != 0 {
+				t.Errorf("nothing should be written outside of the archive, got %v", entries)
+			}
+		})
+	}
+
+	t.Run("local archives keep their links", func(t *testing.T) {
+		archive := filepath.Join(t.TempDir(), "entries.tar.gz")
+		writeTarGz(t, archive, &tar.Header{Name: "nvim", Typeflag: tar.TypeSymlink, Linkname: victim})
+		dst := t.TempDir()
+		if err := extractArchive(archive, CompressionGzip, dst, true); err != nil {
+			t.Fatal(err)
+		}
+		if target, _ := os.Readlink(filepath.Join(dst, "nvim")); target != victim {
+			t.Errorf("expected the link to %s, got %q", victim, target)
+		}
+	})
+}
+
+func TestImportCorruptZstd(t *testing.T) {
+	if _, err := exec.LookPath("zstd"); err != nil {
+		t.Skip("zstd not installed")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+
+	// Far more output than a pipe holds, none of it a tar header
+	garbage := make([]byte, 4<<20)
+	rand.New(rand.NewSource(1)).Read(garbage)
+	archive := filepath.Join(t.TempDir(), "corrupt.tar.zst")
+	cmd := exec.Command("zstd", "-q", "-c")
+	cmd.Stdin = bytes.NewReader(garbage)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(archive, out, 0644)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := ImportBackup(archive)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("a corrupt archive should not import")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("importing a corrupt archive hangs")
+	}
+}
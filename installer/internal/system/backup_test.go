@@ -0,0 +1,227 @@
+package system
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestBackupManifest(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+	SetInstallerVersion("9.9.9")
+	defer SetInstallerVersion("dev")
+
+	os.WriteFile(filepath.Join(home, ".tmux.conf"), []byte("set -g mouse on\n"), 0644)
+	nvim := filepath.Join(home, ".config", "nvim")
+	os.MkdirAll(filepath.Join(nvim, "lua"), 0755)
+	os.WriteFile(filepath.Join(nvim, "init.lua"), []byte("require('config')\n"), 0644)
+	os.WriteFile(filepath.Join(nvim, "lua", "config.lua"), []byte("vim.o.number = true\n"), 0644)
+
+	backupDir, err := CreateBackupFor([]string{"tmux: ~/.tmux.conf", "nvim"}, "install", map[string]string{"shell": "zsh"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	manifest, err := LoadBackupManifest(backupDir)
+	if err != nil {
+		t.Fatalf("expected a manifest: %v", err)
+	}
+	var choices map[string]string
+	json.Unmarshal(manifest.Choices, &choices)
+	if manifest.Installer != "9.9.9" || manifest.Reason != "install" || choices["shell"] != "zsh" {
+		t.Errorf("unexpected manifest header: %+v", manifest)
+	}
+	if len(manifest.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", manifest.Entries)
+	}
+	tmux := manifest.Entries[0]
+	if tmux.Key != "tmux" || tmux.Path != filepath.Join(home, ".tmux.conf") || tmux.Size != 16 || tmux.SHA256 == "" {
+		t.Errorf("unexpected tmux entry: %+v", tmux)
+	}
+	if manifest.Entries[1].Size != int64(len("require('config')\n")+len("vim.o.number = true\n")) {
+		t.Errorf("nvim size should cover every file: %+v", manifest.Entries[1])
+	}
+
+	t.Run("listing reads the manifest", func(t *testing.T) {
+		backups := ListBackups()
+		if len(backups) != 1 || backups[0].Manifest == nil {
+			t.Fatalf("expected the backup with its manifest, got %+v", backups)
+		}
+		if !slices.Equal(backups[0].Files, []string{"tmux", "nvim"}) {
+			t.Errorf("files should be the entry keys, got %v", backups[0].Files)
+		}
+		if !backups[0].Timestamp.Equal(manifest.Created) {
+			t.Errorf("timestamp should come from the manifest, got %v", backups[0].Timestamp)
+		}
+	})
+
+	t.Run("rollback finds entries through the manifest", func(t *testing.T) {
+		if !backupHas(backupDir, "nvim") || backupHas(backupDir, "zsh") || backupHas(backupDir, BackupManifestName) {
+			t.Error("backupHas should follow the manifest entries")
+		}
+	})
+}
+
+func TestLegacyBackupTimestamp(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, BackupPrefix+"2024-03-05-101112")
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, "tmux"), []byte("x"), 0644)
+
+	backups := ListBackups()
+	if len(backups) != 1 || backups[0].Manifest != nil {
+		t.Fatalf("expected one backup without manifest, got %+v", backups)
+	}
+	want := time.Date(2024, 3, 5, 10, 11, 12, 0, time.Local)
+	if !backups[0].Timestamp.Equal(want) || backups[0].ID() != "2024-03-05-101112" {
+		t.Errorf("timestamp should come from the name, got %v (%s)", backups[0].Timestamp, backups[0].ID())
+	}
+}
+
+func TestCompressedBackup(t *testing.T) {
+	compressions := []string{CompressionGzip}
+	if _, err := exec.LookPath("zstd"); err == nil {
+		compressions = append(compressions, CompressionZstd)
+	}
+
+	for _, compression := range compressions {
+		t.Run(compression, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			t.Setenv("GENTLEMAN_DRY_RUN", "")
+			if err := (BackupSettings{Compression: compression}).Save(); err != nil {
+				t.Fatal(err)
+			}
+
+			nvim := filepath.Join(home, ".config", "nvim")
+			os.MkdirAll(filepath.Join(nvim, "lua"), 0755)
+			os.WriteFile(filepath.Join(nvim, "lua", "config.lua"), []byte("vim.o.number = true\n"), 0644)
+			os.Symlink("lua/config.lua", filepath.Join(nvim, "init.lua"))
+
+			backupDir, err := CreateBackupFor([]string{"nvim"}, "install", nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			manifest, err := LoadBackupManifest(backupDir)
+			if err != nil || manifest.Compression != compression {
+				t.Fatalf("expected a %s manifest, got %+v, %v", compression, manifest, err)
+			}
+			if _, err := os.Stat(filepath.Join(backupDir, "nvim")); !os.IsNotExist(err) {
+				t.Error("the raw entry should be replaced by the archive")
+			}
+			if _, err := os.Stat(filepath.Join(backupDir, archiveName(compression))); err != nil {
+				t.Errorf("expected the archive: %v", err)
+			}
+
+			os.RemoveAll(nvim)
+			if err := RestoreBackup(backupDir); err != nil {
+				t.Fatalf("restore failed: %v", err)
+			}
+			content, err := os.ReadFile(filepath.Join(nvim, "init.lua"))
+			if err != nil || string(content) != "vim.o.number = true\n" {
+				t.Errorf("expected the restored tree, got %q, %v", content, err)
+			}
+			if _, sum, _ := treeDigest(nvim); sum != manifest.Entries[0].SHA256 {
+				t.Error("restored tree should match the manifest hash")
+			}
+		})
+	}
+}
+
+func TestBackupPolicy(t *testing.T) {
+	now := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	var backups []BackupInfo
+	for _, days := range []int{1, 40, 3, 10, 90} {
+		backups = append(backups, BackupInfo{Path: strconv.Itoa(days), Timestamp: now.AddDate(0, 0, -days)})
+	}
+	ages := func(list []BackupInfo) []int {
+		var out []int
+		for _, b := range list {
+			out = append(out, int(now.Sub(b.Timestamp).Hours()/24))
+		}
+		return out
+	}
+
+	tests := []struct {
+		name   string
+		policy BackupPolicy
+		want   []int
+	}{
+		{"no policy keeps everything", BackupPolicy{}, nil},
+		{"keep the newest", BackupPolicy{Keep: 2}, []int{90, 40, 10}},
+		{"keep the young", BackupPolicy{MaxAgeDays: 30}, []int{90, 40}},
+		{"either rule keeps a backup", BackupPolicy{Keep: 4, MaxAgeDays: 5}, []int{90}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ages(tt.policy.Expired(backups, now)); !slices.Equal(got, tt.want) {
+				t.Errorf("expired %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPruneBackups(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, id := range []string{"2024-01-01-000000", "2024-02-01-000000", "2024-03-01-000000"} {
+		os.MkdirAll(filepath.Join(home, BackupPrefix+id), 0755)
+	}
+
+	t.Run("dry run only plans", func(t *testing.T) {
+		t.Setenv("GENTLEMAN_DRY_RUN", "1")
+		TakePlan()
+		pruned, err := PruneBackups(BackupPolicy{Keep: 1})
+		if err != nil || len(pruned) != 2 || len(ListBackups()) != 3 {
+			t.Errorf("expected 2 planned removals and no change, got %+v, %v", pruned, err)
+		}
+		if plan := TakePlan(); len(plan) != 2 || plan[0].Kind != PlanRemove {
+			t.Errorf("expected the removals in the plan, got %+v", plan)
+		}
+	})
+
+	t.Run("keeps the newest", func(t *testing.T) {
+		t.Setenv("GENTLEMAN_DRY_RUN", "")
+		if _, err := PruneBackups(BackupPolicy{Keep: 1}); err != nil {
+			t.Fatal(err)
+		}
+		backups := ListBackups()
+		if len(backups) != 1 || backups[0].ID() != "2024-03-01-000000" {
+			t.Errorf("expected only the newest backup, got %+v", backups)
+		}
+	})
+}
+
+func TestBackupSettings(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+
+	settings, err := LoadBackupSettings()
+	if err != nil || settings.Compression != "" || settings.Policy.Enabled() {
+		t.Fatalf("expected defaults without a file, got %+v, %v", settings, err)
+	}
+
+	if err := (BackupSettings{Compression: "lz4"}).Save(); err == nil {
+		t.Error("expected an error for an unknown compression")
+	}
+	if err := (BackupSettings{Policy: BackupPolicy{Keep: -1}}).Save(); err == nil {
+		t.Error("expected an error for a negative limit")
+	}
+
+	want := BackupSettings{Compression: CompressionGzip, Policy: BackupPolicy{Keep: 5, MaxAgeDays: 30}}
+	if err := want.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := LoadBackupSettings(); err != nil || got != want {
+		t.Errorf("expected %+v back, got %+v, %v", want, got, err)
+	}
+}
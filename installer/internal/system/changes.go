@@ -0,0 +1,184 @@
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Change is a path the installer wrote to, and whether it existed before
+// the first write
+type Change struct {
+	Path    string `json:"path"`
+	Existed bool   `json:"existed"`
+}
+
+var (
+	changesMu sync.Mutex
+	changes   []Change
+	seen      = map[string]bool{}
+)
+
+// trackChange records the first write to path. When path does not exist
+// yet, its outermost missing parent is recorded instead, since writing
+// creates it too. Dry runs change nothing.
+func trackChange(path string) {
+	if IsDryRun() || path == "" {
+		return
+	}
+	path = filepath.Clean(path)
+	_, err := os.Lstat(path)
+	existed := err == nil
+	for !existed {
+		parent := filepath.Dir(path)
+		if parent == path {
+			break
+		}
+		if _, err := os.Lstat(parent); err == nil {
+			break
+		}
+		path = parent
+	}
+
+	changesMu.Lock()
+	defer changesMu.Unlock()
+	if seen[path] {
+		return
+	}
+	for _, c := range changes {
+		if !c.Existed && isWithin(path, c.Path) {
+			return // Inside a path the install created, removed with it
+		}
+	}
+	seen[path] = true
+	changes = append(changes, Change{Path: path, Existed: existed})
+}
+
+// Changes returns the paths written so far, in order
+func Changes() []Change {
+	changesMu.Lock()
+	defer changesMu.Unlock()
+	return append([]Change(nil), changes...)
+}
+
+// ResetChanges clears the tracked changes, starting over from prev
+// (the changes of a resumed installation, or nil)
+func ResetChanges(prev []Change) {
+	changesMu.Lock()
+	defer changesMu.Unlock()
+	changes = append([]Change(nil), prev...)
+	seen = map[string]bool{}
+	for _, c := range changes {
+		seen[c.Path] = true
+	}
+}
+
+// RollbackReport lists what a rollback reverted
+type RollbackReport struct {
+	Restored []string `json:"restored"` // "key: path" entries restored from the backup
+	Removed  []string `json:"removed"`  // Paths created by the install and deleted
+	Kept     []string `json:"kept"`     // Paths that existed before but have no backup to restore
+}
+
+// Empty reports whether the rollback had nothing to do
+func (r RollbackReport) Empty() bool {
+	return len(r.Restored) == 0 && len(r.Removed) == 0 && len(r.Kept) == 0
+}
+
+// Rollback reverts every tracked change: configs covered by backupDir are
+// restored from it, paths that did not exist before are deleted, and the
+// remaining ones are reported as kept. backupDir may be empty.
+func Rollback(backupDir string) (RollbackReport, error) {
+	var report RollbackReport
+	configPaths := ConfigPaths()
+
+	// Config keys that were touched and have a backup to restore
+	var restoreKeys []string
+	for _, c := range Changes() {
+		key := configKeyFor(c.Path, configPaths)
+		if key == "" || backupDir == "" || slices.Contains(restoreKeys, key) {
+			continue
+		}
+		if backupHas(backupDir, key) {
+			restoreKeys = append(restoreKeys, key)
+		}
+	}
+	sort.Strings(restoreKeys)
+
+	covered := func(path string) bool {
+		for _, key := range restoreKeys {
+			if isWithin(path, configPaths[key]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Delete created paths, outermost first, before restoring
+	var created []string
+	for _, c := range Changes() {
+		switch {
+		case covered(c.Path):
+		case !c.Existed:
+			created = append(created, c.Path)
+		default:
+			report.Kept = append(report.Kept, c.Path)
+		}
+	}
+	sort.Slice(created, func(i, j int) bool { return len(created[i]) < len(created[j]) })
+	for _, path := range created {
+		if parentRemoved(path, report.Removed) {
+			continue
+		}
+		if _, err := os.Lstat(path); err != nil {
+			continue
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return report, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		forgetManifest(path)
+		report.Removed = append(report.Removed, path)
+	}
+
+	if len(restoreKeys) > 0 {
+		if err := RestoreBackupEntries(backupDir, restoreKeys); err != nil {
+			return report, err
+		}
+		for _, key := range restoreKeys {
+			report.Restored = append(report.Restored, key+": "+configPaths[key])
+		}
+	}
+
+	ResetChanges(nil)
+	return report, nil
+}
+
+// configKeyFor returns the ConfigPaths key whose path contains path
+func configKeyFor(path string, configPaths map[string]string) string {
+	best := ""
+	for key, root := range configPaths {
+		if isWithin(path, root) && (best == "" || len(root) > len(configPaths[best])) {
+			best = key
+		}
+	}
+	return best
+}
+
+// isWithin reports whether path is root or below it
+func isWithin(path, root string) bool {
+	root = filepath.Clean(root)
+	return path == root || strings.HasPrefix(path, root+string(filepath.Separator))
+}
+
+func parentRemoved(path string, removed []string) bool {
+	for _, r := range removed {
+		if isWithin(path, r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTrackChanges(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+	ResetChanges(nil)
+	defer ResetChanges(nil)
+
+	existing := filepath.Join(home, ".tmux.conf")
+	os.WriteFile(existing, []byte("old"), 0644)
+	created := filepath.Join(home, ".config", "starship.toml")
+
+	WriteFile(existing, []byte("new"), 0644)
+	WriteFile(existing, []byte("newer"), 0644)
+	EnsureDir(filepath.Dir(created))
+	WriteFile(created, []byte("format = ''"), 0644)
+
+	got := Changes()
+	if len(got) != 2 {
+		t.Fatalf("expected 2 tracked changes, got %+v", got)
+	}
+	if got[0].Path != existing || !got[0].Existed {
+		t.Errorf("existing file should be tracked as existed: %+v", got[0])
+	}
+	if got[1].Path != filepath.Dir(created) || got[1].Existed {
+		t.Errorf("created directory should be tracked once, covering the new file: %+v", got[1])
+	}
+
+	t.Run("dry run tracks nothing", func(t *testing.T) {
+		ResetChanges(nil)
+		t.Setenv("GENTLEMAN_DRY_RUN", "1")
+		WriteFile(existing, []byte("dry"), 0644)
+		if len(Changes()) != 0 {
+			t.Errorf("dry run should not track changes: %+v", Changes())
+		}
+	})
+
+	t.Run("reset seeds a resumed run", func(t *testing.T) {
+		ResetChanges([]Change{{Path: existing, Existed: true}})
+		WriteFile(existing, []byte("again"), 0644)
+		if got := Changes(); len(got) != 1 || !got[0].Existed {
+			t.Errorf("seeded path should not be tracked twice: %+v", got)
+		}
+	})
+}
+
+func TestRollback(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+	ResetChanges(nil)
+	defer ResetChanges(nil)
+
+	tmux := filepath.Join(home, ".tmux.conf")
+	zshrc := filepath.Join(home, ".zshrc")
+	os.WriteFile(tmux, []byte("user tmux"), 0644)
+	os.WriteFile(zshrc, []byte("user zsh"), 0644)
+
+	backupDir, err := CreateBackup([]string{"tmux"})
+	if err != nil {
+		t.Fatalf("CreateBackup failed: %v", err)
+	}
+
+	// What an install would do: overwrite backed up and unbacked configs,
+	// create a new config directory and a file outside every config
+	WriteFile(tmux, []byte("gentleman tmux"), 0644)
+	AppendToFile(zshrc, "# gentleman")
+	EnsureDir(filepath.Join(home, ".config", "fish", "functions"))
+	WriteFile(filepath.Join(home, ".config", "fish", "config.fish"), []byte("fish"), 0644)
+	EnsureDir(filepath.Join(home, ".local", "bin"))
+	WriteFile(filepath.Join(home, ".local", "bin", "tool"), []byte("#!/bin/sh"), 0755)
+
+	report, err := Rollback(backupDir)
+	if err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+
+	if data, _ := os.ReadFile(tmux); string(data) != "user tmux" {
+		t.Errorf("tmux should be restored from the backup, got %q", data)
+	}
+	if len(report.Restored) != 1 || report.Restored[0] != "tmux: "+tmux {
+		t.Errorf("unexpected restored entries: %v", report.Restored)
+	}
+
+	for _, path := range []string{filepath.Join(home, ".config", "fish"), filepath.Join(home, ".local")} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s was created by the install and should be removed", path)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(home, ".config")); !os.IsNotExist(err) {
+		t.Error("created parent directories should be removed too")
+	}
+
+	if len(report.Kept) != 1 || report.Kept[0] != zshrc {
+		t.Errorf("zshrc has no backup and should be kept, got %v", report.Kept)
+	}
+	if len(Changes()) != 0 {
+		t.Error("rollback should clear the tracked changes")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"os/exec"
 	"runtime"
+	"slices"
 	"strings"
 )
 
@@ -13,9 +14,12 @@ const (
 	OSMac OSType = iota
 	OSLinux
 	OSArch
-	OSDebian  // Debian-based (Debian, Ubuntu, etc.)
-	OSFedora  // Fedora/RHEL-based (Fedora, CentOS, RHEL, etc.)
-	OSTermux  // Termux on Android
+	OSDebian   // Debian-based (Debian, Ubuntu, etc.)
+	OSFedora   // Fedora/RHEL-based (Fedora, CentOS, RHEL, etc.)
+	OSTermux   // Termux on Android
+	OSAlpine   // Alpine Linux (apk)
+	OSOpenSUSE // openSUSE Leap/Tumbleweed and SLES (zypper)
+	OSVoid     // Void Linux (xbps)
 	OSUnknown
 )
 
@@ -63,7 +67,10 @@ func Detect() *SystemInfo {
 		info.OSName = "Linux"
 		info.IsWSL = checkWSL()
 
-		if isArchLinux() {
+		if osType, name, ok := detectFromOSRelease(); ok {
+			info.OS = osType
+			info.OSName = name
+		} else if isArchLinux() {
 			info.OS = OSArch
 			info.OSName = "Arch Linux"
 		} else if isFedora() {
@@ -90,6 +97,65 @@ func checkWSL() bool {
 	return strings.Contains(content, "microsoft") || strings.Contains(content, "wsl")
 }
 
+// osReleasePath is where the distribution is identified
+var osReleasePath = "/etc/os-release"
+
+// distroIDs maps os-release IDs to the distribution family they belong to
+var distroIDs = []struct {
+	ids  []string
+	os   OSType
+	name string
+}{
+	{[]string{"arch", "manjaro", "endeavouros"}, OSArch, "Arch Linux"},
+	{[]string{"fedora", "rhel", "centos", "rocky", "almalinux"}, OSFedora, "Fedora/RHEL"},
+	{[]string{"debian", "ubuntu", "linuxmint", "pop"}, OSDebian, "Debian/Ubuntu"},
+	{[]string{"alpine"}, OSAlpine, "Alpine Linux"},
+	{[]string{"opensuse", "opensuse-leap", "opensuse-tumbleweed", "suse", "sles"}, OSOpenSUSE, "openSUSE"},
+	{[]string{"void"}, OSVoid, "Void Linux"},
+}
+
+// parseOSRelease returns the ID and ID_LIKE entries of an os-release file
+func parseOSRelease(content string) (string, []string) {
+	var id string
+	var idLike []string
+	for _, line := range strings.Split(content, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		value = strings.ToLower(strings.Trim(value, `"'`))
+		switch key {
+		case "ID":
+			id = value
+		case "ID_LIKE":
+			idLike = strings.Fields(value)
+		}
+	}
+	return id, idLike
+}
+
+// distroFromOSRelease classifies a distribution by its ID, then by the
+// distributions it is like
+func distroFromOSRelease(id string, idLike []string) (OSType, string, bool) {
+	for _, candidate := range append([]string{id}, idLike...) {
+		for _, distro := range distroIDs {
+			if slices.Contains(distro.ids, candidate) {
+				return distro.os, distro.name, true
+			}
+		}
+	}
+	return OSUnknown, "", false
+}
+
+// detectFromOSRelease identifies the distribution from /etc/os-release
+func detectFromOSRelease() (OSType, string, bool) {
+	data, err := os.ReadFile(osReleasePath)
+	if err != nil {
+		return OSUnknown, "", false
+	}
+	return distroFromOSRelease(parseOSRelease(string(data)))
+}
+
 func isArchLinux() bool {
 	_, err := os.Stat("/etc/arch-release")
 	return err == nil
@@ -2,6 +2,7 @@ package system
 
 import (
 	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
 )
@@ -16,7 +17,7 @@ func TestOSTypes(t *testing.T) {
 	})
 
 	t.Run("all OS types should be distinct", func(t *testing.T) {
-		osTypes := []OSType{OSMac, OSLinux, OSArch, OSDebian, OSFedora, OSTermux, OSUnknown}
+		osTypes := []OSType{OSMac, OSLinux, OSArch, OSDebian, OSFedora, OSTermux, OSAlpine, OSOpenSUSE, OSVoid, OSUnknown}
 		seen := make(map[OSType]bool)
 		for _, ot := range osTypes {
 			if seen[ot] {
@@ -56,7 +57,7 @@ func TestDetect(t *testing.T) {
 				t.Errorf("Expected OSName to be 'macOS', got '%s'", info.OSName)
 			}
 		case "linux":
-			validNames := []string{"Linux", "Arch Linux", "Debian/Ubuntu", "Fedora/RHEL", "Termux"}
+			validNames := []string{"Linux", "Arch Linux", "Debian/Ubuntu", "Fedora/RHEL", "Termux", "Alpine Linux", "openSUSE", "Void Linux"}
 			found := false
 			for _, name := range validNames {
 				if info.OSName == name {
@@ -167,6 +168,55 @@ func TestIsFedora(t *testing.T) {
 	})
 }
 
+func TestDetectFromOSRelease(t *testing.T) {
+	tests := []struct {
+		name      string
+		osRelease string
+		want      OSType
+		wantName  string
+	}{
+		{"alpine", "NAME=\"Alpine Linux\"\nID=alpine\nVERSION_ID=3.20.0\n", OSAlpine, "Alpine Linux"},
+		{"opensuse tumbleweed", "NAME=\"openSUSE Tumbleweed\"\nID=\"opensuse-tumbleweed\"\nID_LIKE=\"opensuse suse\"\n", OSOpenSUSE, "openSUSE"},
+		{"sles by ID_LIKE", "ID=\"sled\"\nID_LIKE=\"suse\"\n", OSOpenSUSE, "openSUSE"},
+		{"void", "NAME=\"Void\"\nID=\"void\"\n", OSVoid, "Void Linux"},
+		{"ubuntu", "ID=ubuntu\nID_LIKE=debian\n", OSDebian, "Debian/Ubuntu"},
+		{"mint by ID_LIKE", "ID=linuxmint\nID_LIKE=\"ubuntu debian\"\n", OSDebian, "Debian/Ubuntu"},
+		{"rocky by ID_LIKE", "ID=\"rocky\"\nID_LIKE=\"rhel centos fedora\"\n", OSFedora, "Fedora/RHEL"},
+		{"manjaro", "ID=manjaro\nID_LIKE=arch\n", OSArch, "Arch Linux"},
+		{"cachyos by ID_LIKE", "ID=cachyos\nID_LIKE=\"arch\"\n", OSArch, "Arch Linux"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "os-release")
+			os.WriteFile(path, []byte(tt.osRelease), 0644)
+			original := osReleasePath
+			osReleasePath = path
+			defer func() { osReleasePath = original }()
+
+			got, name, ok := detectFromOSRelease()
+			if !ok || got != tt.want || name != tt.wantName {
+				t.Errorf("got %v %q (ok=%v), want %v %q", got, name, ok, tt.want, tt.wantName)
+			}
+		})
+	}
+
+	t.Run("unknown distribution falls back", func(t *testing.T) {
+		if _, _, ok := distroFromOSRelease(parseOSRelease("ID=gentoo\n")); ok {
+			t.Error("unknown distributions should not be classified")
+		}
+	})
+
+	t.Run("missing file falls back", func(t *testing.T) {
+		original := osReleasePath
+		osReleasePath = filepath.Join(t.TempDir(), "missing")
+		defer func() { osReleasePath = original }()
+		if _, _, ok := detectFromOSRelease(); ok {
+			t.Error("a missing os-release should not be classified")
+		}
+	})
+}
+
 func TestIsTermux(t *testing.T) {
 	t.Run("should not panic", func(t *testing.T) {
 		defer func() {
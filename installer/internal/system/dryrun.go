@@ -0,0 +1,116 @@
+package system
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// PlanKind classifies an action recorded while running in dry-run mode
+type PlanKind string
+
+const (
+	PlanCommand   PlanKind = "run"
+	PlanSudo      PlanKind = "sudo"
+	PlanPackage   PlanKind = "package"
+	PlanMkdir     PlanKind = "mkdir"
+	PlanCopy      PlanKind = "copy"
+	PlanOverwrite PlanKind = "overwrite"
+	PlanPatch     PlanKind = "patch"
+	PlanRemove    PlanKind = "remove"
+	PlanLink      PlanKind = "link"
+)
+
+// PlanAction is a single side effect the installer would perform
+type PlanAction struct {
+	Step   string // Install step that produced the action (set by the caller)
+	Kind   PlanKind
+	Target string // Command line or destination path
+	Detail string // Source path, patch description, etc.
+}
+
+func (a PlanAction) String() string {
+	if a.Detail == "" {
+		return fmt.Sprintf("%-9s %s", a.Kind, a.Target)
+	}
+	return fmt.Sprintf("%-9s %s (%s)", a.Kind, a.Target, a.Detail)
+}
+
+var (
+	planMu sync.Mutex
+	plan   []PlanAction
+)
+
+// IsDryRun reports whether side effects should be recorded instead of performed
+func IsDryRun() bool {
+	return os.Getenv("GENTLEMAN_DRY_RUN") == "1"
+}
+
+// RecordPlan appends an action to the dry-run plan
+func RecordPlan(kind PlanKind, target, detail string) {
+	planMu.Lock()
+	defer planMu.Unlock()
+	plan = append(plan, PlanAction{Kind: kind, Target: target, Detail: detail})
+}
+
+// TakePlan returns the actions recorded so far and clears the plan
+func TakePlan() []PlanAction {
+	planMu.Lock()
+	defer planMu.Unlock()
+	actions := plan
+	plan = nil
+	return actions
+}
+
+// FormatPlan renders actions grouped by step, in the order they were recorded
+func FormatPlan(actions []PlanAction) string {
+	var sb strings.Builder
+	lastStep := ""
+	for i, a := range actions {
+		if i == 0 || a.Step != lastStep {
+			if i > 0 {
+				sb.WriteString("\n")
+			}
+			if a.Step != "" {
+				sb.WriteString(fmt.Sprintf("[%s]\n", a.Step))
+			}
+			lastStep = a.Step
+		}
+		sb.WriteString(fmt.Sprintf("  %3d. %s\n", i+1, a))
+	}
+	return sb.String()
+}
+
+// classifyCommand decides which plan kind a shell command belongs to
+func classifyCommand(command string) PlanKind {
+	packageMarkers := []string{
+		"brew install", "pkg install", "pacman -S", "apt-get install",
+		"dnf install", "flatpak install", "npm install -g",
+		"apk add", "zypper --non-interactive install", "xbps-install -y",
+	}
+	for _, marker := range packageMarkers {
+		if strings.Contains(command, marker) {
+			return PlanPackage
+		}
+	}
+	if strings.HasPrefix(command, "sudo ") {
+		return PlanSudo
+	}
+	return PlanCommand
+}
+
+// planCommand records a command and returns a successful empty result
+func planCommand(command string) *ExecResult {
+	RecordPlan(classifyCommand(command), command, "")
+	return &ExecResult{Command: command}
+}
+
+// planWrite records a file write as either a new copy or an overwrite
+func planWrite(dst, src string) {
+	kind := PlanCopy
+	if _, err := os.Lstat(dst); err == nil {
+		kind = PlanOverwrite
+	}
+	RecordPlan(kind, dst, src)
+}
@@ -0,0 +1,114 @@
+package system
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDryRunRecordsInsteadOfExecuting(t *testing.T) {
+	t.Setenv("GENTLEMAN_DRY_RUN", "1")
+	TakePlan()
+
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "marker")
+
+	t.Run("Run does not execute the command", func(t *testing.T) {
+		result := Run(context.Background(), "touch "+marker, nil)
+		if result.Error != nil {
+			t.Fatalf("dry-run Run returned error: %v", result.Error)
+		}
+		if _, err := os.Stat(marker); err == nil {
+			t.Fatal("command was executed in dry-run mode")
+		}
+	})
+
+	t.Run("RunWithLogs and RunSudo are recorded", func(t *testing.T) {
+		RunWithLogs(context.Background(), "echo hi", nil, nil)
+		RunSudo(context.Background(), "apt-get install -y git", nil)
+		RunSudo(context.Background(), "usermod -s /bin/zsh me", nil)
+	})
+
+	t.Run("file operations do not touch the disk", func(t *testing.T) {
+		src := filepath.Join(dir, "src.txt")
+		os.WriteFile(src, []byte("new"), 0644)
+		existing := filepath.Join(dir, "existing.txt")
+		os.WriteFile(existing, []byte("old"), 0644)
+
+		CopyFile(src, filepath.Join(dir, "fresh.txt"))
+		CopyFile(src, existing)
+		CopyDir(dir, filepath.Join(dir, "copy"))
+		AppendToFile(existing, "\n# Added by test\nexport FOO=1\n")
+		PatchZshForWM(filepath.Join(dir, ".zshrc"), "zellij", true)
+
+		if _, err := os.Stat(filepath.Join(dir, "fresh.txt")); err == nil {
+			t.Error("CopyFile wrote a file in dry-run mode")
+		}
+		if _, err := os.Stat(filepath.Join(dir, "copy")); err == nil {
+			t.Error("CopyDir created a directory in dry-run mode")
+		}
+		if data, _ := os.ReadFile(existing); string(data) != "old" {
+			t.Errorf("existing file was modified: %q", data)
+		}
+	})
+
+	plan := TakePlan()
+	want := []struct {
+		kind   PlanKind
+		target string
+	}{
+		{PlanCommand, "touch " + marker},
+		{PlanCommand, "echo hi"},
+		{PlanPackage, "sudo apt-get install -y git"},
+		{PlanSudo, "sudo usermod -s /bin/zsh me"},
+		{PlanCopy, filepath.Join(dir, "fresh.txt")},
+		{PlanOverwrite, filepath.Join(dir, "existing.txt")},
+		{PlanCopy, filepath.Join(dir, "copy")},
+		{PlanPatch, filepath.Join(dir, "existing.txt")},
+		{PlanPatch, filepath.Join(dir, ".zshrc")},
+	}
+	if len(plan) != len(want) {
+		t.Fatalf("expected %d plan actions, got %d: %v", len(want), len(plan), plan)
+	}
+	for i, w := range want {
+		if plan[i].Kind != w.kind || plan[i].Target != w.target {
+			t.Errorf("action %d = %s %s, want %s %s", i, plan[i].Kind, plan[i].Target, w.kind, w.target)
+		}
+	}
+
+	if len(TakePlan()) != 0 {
+		t.Error("TakePlan should clear the recorded plan")
+	}
+}
+
+func TestDryRunDisabledDoesNotRecord(t *testing.T) {
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+	TakePlan()
+
+	result := Run(context.Background(), "true", nil)
+	if result.Error != nil {
+		t.Fatalf("Run failed: %v", result.Error)
+	}
+	if plan := TakePlan(); len(plan) != 0 {
+		t.Errorf("expected no plan outside dry-run, got %v", plan)
+	}
+}
+
+func TestFormatPlan(t *testing.T) {
+	out := FormatPlan([]PlanAction{
+		{Step: "clone", Kind: PlanCommand, Target: "git clone repo"},
+		{Step: "shell", Kind: PlanPackage, Target: "brew install fish"},
+		{Step: "shell", Kind: PlanCopy, Target: "/home/u/.config/fish", Detail: "repo/GentlemanFish/fish"},
+	})
+
+	for _, want := range []string{"[clone]", "[shell]", "1. run", "2. package", "3. copy", "(repo/GentlemanFish/fish)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("FormatPlan output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "[clone]") > strings.Index(out, "[shell]") {
+		t.Error("steps should appear in recorded order")
+	}
+}
@@ -3,6 +3,7 @@ package system
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -82,6 +83,14 @@ func (e *ExecError) Unwrap() error {
 	return e.Wrapped
 }
 
+// ErrCancelled is the cause of a command stopped because its context was
+// cancelled. A timeout keeps the error of the killed command.
+var ErrCancelled = errors.New("cancelled")
+
+// cancelGrace is how long a cancelled command has to exit after SIGTERM
+// before it is killed
+const cancelGrace = 5 * time.Second
+
 type ExecResult struct {
 	Output   string
 	Stderr   string
@@ -145,8 +154,12 @@ func parseCommand(command string) (string, []string) {
 	return executable, args[1:]
 }
 
-// Run executes a command and returns the result with detailed error information
-func Run(command string, opts *ExecOptions) *ExecResult {
+// Run executes a command and returns the result with detailed error information.
+// Cancelling ctx stops the command and everything it started.
+func Run(ctx context.Context, command string, opts *ExecOptions) *ExecResult {
+	if IsDryRun() {
+		return planCommand(command)
+	}
 	if opts == nil {
 		opts = &ExecOptions{}
 	}
@@ -156,23 +169,14 @@ func Run(command string, opts *ExecOptions) *ExecResult {
 		Command: command,
 	}
 
-	ctx := context.Background()
 	if opts.Timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
 		defer cancel()
 	}
 
-	// In Termux, execute commands directly without shell wrapper
-	// Go has issues with fork/exec through shell on Android
-	var cmd *exec.Cmd
-	if isTermux() {
-		executable, args := parseCommand(command)
-		cmd = exec.CommandContext(ctx, executable, args...)
-	} else {
-		// Use available shell to run the command (bash, sh, or zsh)
-		cmd = exec.CommandContext(ctx, GetShell(), "-c", command)
-	}
+	cmd, release := newCommand(ctx, command)
+	defer release()
 
 	if opts.WorkDir != "" {
 		cmd.Dir = opts.WorkDir
@@ -191,10 +195,10 @@ func Run(command string, opts *ExecOptions) *ExecResult {
 		stdoutPipe, _ := cmd.StdoutPipe()
 		stderrPipe, _ := cmd.StderrPipe()
 
-		if err := cmd.Start(); err != nil {
+		if err := startCommand(cmd); err != nil {
 			result.Error = &ExecError{
 				Command: command,
-				Wrapped: err,
+				Wrapped: cancelled(ctx, err),
 			}
 			result.Duration = time.Since(start)
 			return result
@@ -211,7 +215,7 @@ func Run(command string, opts *ExecOptions) *ExecResult {
 				ExitCode: result.ExitCode,
 				Stdout:   stdout.String(),
 				Stderr:   stderr.String(),
-				Wrapped:  err,
+				Wrapped:  cancelled(ctx, err),
 			}
 		}
 	} else {
@@ -219,7 +223,10 @@ func Run(command string, opts *ExecOptions) *ExecResult {
 		cmd.Stdout = &stdout
 		cmd.Stderr = &stderr
 
-		err := cmd.Run()
+		err := startCommand(cmd)
+		if err == nil {
+			err = cmd.Wait()
+		}
 		if err != nil {
 			exitCode := 1
 			if cmd.ProcessState != nil {
@@ -231,7 +238,7 @@ func Run(command string, opts *ExecOptions) *ExecResult {
 				ExitCode: exitCode,
 				Stdout:   stdout.String(),
 				Stderr:   stderr.String(),
-				Wrapped:  err,
+				Wrapped:  cancelled(ctx, err),
 			}
 		}
 	}
@@ -247,6 +254,52 @@ func Run(command string, opts *ExecOptions) *ExecResult {
 	return result
 }
 
+// newCommand runs command through the shell, or directly in Termux where Go
+// has issues with fork/exec through a shell. A command that can be cancelled
+// gets its own process group, so cancelling also stops what it started,
+// unless it needs the terminal: sudo can only prompt from the terminal's
+// group. release has to be called once the command was waited for.
+func newCommand(ctx context.Context, command string) (cmd *exec.Cmd, release func()) {
+	if isTermux() {
+		executable, args := parseCommand(command)
+		cmd = exec.CommandContext(ctx, executable, args...)
+	} else {
+		// Use available shell to run the command (bash, sh, or zsh)
+		cmd = exec.CommandContext(ctx, GetShell(), "-c", command)
+	}
+	if ctx.Done() == nil {
+		return cmd, func() {}
+	}
+
+	release = setCancel(cmd, !needsTerminal(command))
+	// Don't wait on pipes still held by children that ignored SIGTERM
+	cmd.WaitDelay = cancelGrace
+	return cmd, release
+}
+
+// needsTerminal reports whether command runs sudo, which reads the password
+// from the terminal
+func needsTerminal(command string) bool {
+	words := strings.FieldsFunc(command, func(r rune) bool {
+		return strings.ContainsRune(" \t\n'\"();&|", r)
+	})
+	for _, word := range words {
+		if word == "sudo" || strings.HasSuffix(word, "/sudo") {
+			return true
+		}
+	}
+	return false
+}
+
+// cancelled reports a command stopped by the cancellation of ctx as
+// ErrCancelled
+func cancelled(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return ErrCancelled
+	}
+	return err
+}
+
 func streamOutput(r io.Reader, w *strings.Builder) {
 	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
@@ -256,34 +309,71 @@ func streamOutput(r io.Reader, w *strings.Builder) {
 	}
 }
 
+// pkgManagerMu serializes package manager commands. Install steps may run
+// concurrently, but brew, apt, pacman, dnf and pkg hold exclusive locks.
+var pkgManagerMu sync.Mutex
+
 // RunSudo runs a command with sudo
-func RunSudo(command string, opts *ExecOptions) *ExecResult {
-	return Run("sudo "+command, opts)
+func RunSudo(ctx context.Context, command string, opts *ExecOptions) *ExecResult {
+	pkgManagerMu.Lock()
+	defer pkgManagerMu.Unlock()
+	return Run(ctx, "sudo "+command, opts)
 }
 
 // RunBrew runs a brew command
-func RunBrew(args string, opts *ExecOptions) *ExecResult {
+func RunBrew(ctx context.Context, args string, opts *ExecOptions) *ExecResult {
+	pkgManagerMu.Lock()
+	defer pkgManagerMu.Unlock()
 	brewPath := GetBrewPrefix() + "/bin/brew"
-	return Run(brewPath+" "+args, opts)
+	return Run(ctx, brewPath+" "+args, opts)
 }
 
 // RunPkg runs a Termux pkg command (install packages)
-func RunPkg(args string, opts *ExecOptions) *ExecResult {
-	return Run("pkg "+args, opts)
+func RunPkg(ctx context.Context, args string, opts *ExecOptions) *ExecResult {
+	pkgManagerMu.Lock()
+	defer pkgManagerMu.Unlock()
+	return Run(ctx, "pkg "+args, opts)
 }
 
 // RunPkgWithLogs runs a Termux pkg command with log streaming
-func RunPkgWithLogs(args string, opts *ExecOptions, logFunc func(string)) *ExecResult {
-	return RunWithLogs("pkg "+args, opts, logFunc)
+func RunPkgWithLogs(ctx context.Context, args string, opts *ExecOptions, logFunc func(string)) *ExecResult {
+	pkgManagerMu.Lock()
+	defer pkgManagerMu.Unlock()
+	return RunWithLogs(ctx, "pkg "+args, opts, logFunc)
 }
 
 // RunPkgInstall runs pkg install with -y flag for non-interactive installs
-func RunPkgInstall(packages string, opts *ExecOptions, logFunc func(string)) *ExecResult {
-	return RunWithLogs("pkg install -y "+packages, opts, logFunc)
+func RunPkgInstall(ctx context.Context, packages string, opts *ExecOptions, logFunc func(string)) *ExecResult {
+	pkgManagerMu.Lock()
+	defer pkgManagerMu.Unlock()
+	return RunWithLogs(ctx, "pkg install -y "+packages, opts, logFunc)
 }
 
-// CopyFile copies a file from src to dst
+// CopyFile copies a file from src to dst. In link mode, files from the
+// linked repository are symlinked instead.
 func CopyFile(src, dst string) error {
+	trackChange(dst)
+	if source, ok := linkSource(src); ok {
+		forgetManifest(dst)
+		return linkFile(source, dst)
+	}
+	if !IsDryRun() {
+		if err := detach(dst); err != nil {
+			return err
+		}
+	}
+	if err := copyFile(src, dst); err != nil {
+		return err
+	}
+	recordCopy(src, dst)
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	if IsDryRun() {
+		planWrite(dst, src)
+		return nil
+	}
 	input, err := os.ReadFile(src)
 	if err != nil {
 		return err
@@ -291,11 +381,35 @@ func CopyFile(src, dst string) error {
 	return os.WriteFile(dst, input, 0644)
 }
 
-// CopyDir recursively copies a directory using native Go (shell-independent)
+// CopyDir recursively copies a directory using native Go (shell-independent).
+// In link mode, directories from the linked repository are symlinked instead.
 func CopyDir(src, dst string) error {
+	trackChange(dst)
+	if source, ok := linkSource(src); ok {
+		forgetManifest(dst)
+		return linkDir(source, dst)
+	}
+	if !IsDryRun() {
+		if err := detachTree(dst); err != nil {
+			return err
+		}
+	}
+	if err := copyDir(src, dst); err != nil {
+		return err
+	}
+	recordCopy(src, dst)
+	return nil
+}
+
+func copyDir(src, dst string) error {
 	// Clean paths - remove trailing /* or /. if present
 	src = strings.TrimSuffix(strings.TrimSuffix(src, "/*"), "/.")
 
+	if IsDryRun() {
+		planWrite(dst, src)
+		return nil
+	}
+
 	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -311,188 +425,186 @@ func CopyDir(src, dst string) error {
 		if info.IsDir() {
 			return os.MkdirAll(dstPath, info.Mode())
 		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return copyLink(path, dstPath)
+		}
 
 		// Copy file
-		return CopyFile(path, dstPath)
+		return copyFile(path, dstPath)
 	})
 }
 
 // EnsureDir creates a directory if it doesn't exist
 func EnsureDir(path string) error {
-	return os.MkdirAll(path, 0755)
-}
-
-// BackupInfo contains information about a backup
-type BackupInfo struct {
-	Path      string
-	Timestamp time.Time
-	Files     []string
-}
-
-// ConfigPaths returns all config paths that Gentleman.Dots will modify
-func ConfigPaths() map[string]string {
-	home := os.Getenv("HOME")
-	return map[string]string{
-		"nvim":      home + "/.config/nvim",
-		"fish":      home + "/.config/fish",
-		"zsh":       home + "/.zshrc",
-		"zsh_p10k":  home + "/.p10k.zsh",
-		"oh-my-zsh": home + "/.oh-my-zsh",
-		"nushell":   home + "/.config/nushell",
-		"tmux":      home + "/.tmux.conf",
-		"zellij":    home + "/.config/zellij",
-		"alacritty": home + "/.config/alacritty",
-		"wezterm":   home + "/.wezterm.lua",
-		"kitty":     home + "/.config/kitty",
-		"ghostty":   home + "/.config/ghostty",
-		"zed":       home + "/.config/zed",
-		"starship":  home + "/.config/starship.toml",
+	if _, err := os.Stat(path); err != nil {
+		trackChange(path)
+	}
+	if !IsDryRun() {
+		if err := unfoldParents(path); err != nil {
+			return err
+		}
 	}
+	return ensureDir(path)
 }
 
-// DetectExistingConfigs checks which config files/directories already exist
-func DetectExistingConfigs() []string {
-	existing := []string{}
-	for name, path := range ConfigPaths() {
-		if _, err := os.Stat(path); err == nil {
-			existing = append(existing, name+": "+path)
+func ensureDir(path string) error {
+	if IsDryRun() {
+		if _, err := os.Stat(path); err != nil {
+			RecordPlan(PlanMkdir, path, "")
 		}
+		return nil
 	}
-	return existing
+	return os.MkdirAll(path, 0755)
 }
 
-// GetBackupDir returns the backup directory path with timestamp
-func GetBackupDir() string {
-	home := os.Getenv("HOME")
-	timestamp := time.Now().Format("2006-01-02-150405")
-	return home + "/.gentleman-backup-" + timestamp
+// WriteFile writes data to path, replacing any existing content
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	trackChange(path)
+	if IsDryRun() {
+		planWrite(path, "")
+		return nil
+	}
+	if err := unlink(path); err != nil {
+		return err
+	}
+	return patched(path, os.WriteFile(path, data, perm))
 }
 
-// ListBackups returns all existing backups
-func ListBackups() []BackupInfo {
-	home := os.Getenv("HOME")
-	backups := []BackupInfo{}
-
-	entries, err := os.ReadDir(home)
+// AppendToFile appends content to path, creating the file if needed
+func AppendToFile(path, content string) error {
+	trackChange(path)
+	if IsDryRun() {
+		RecordPlan(PlanPatch, path, "append "+strings.TrimSpace(strings.SplitN(strings.TrimSpace(content), "\n", 2)[0]))
+		return nil
+	}
+	if err := unlink(path); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
-		return backups
+		return err
 	}
-
-	for _, entry := range entries {
-		if entry.IsDir() && strings.HasPrefix(entry.Name(), ".gentleman-backup-") {
-			backupPath := home + "/" + entry.Name()
-			info, err := entry.Info()
-			if err != nil {
-				continue
-			}
-
-			// List files in backup
-			files := []string{}
-			subEntries, _ := os.ReadDir(backupPath)
-			for _, sub := range subEntries {
-				files = append(files, sub.Name())
-			}
-
-			backups = append(backups, BackupInfo{
-				Path:      backupPath,
-				Timestamp: info.ModTime(),
-				Files:     files,
-			})
-		}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return err
 	}
-
-	return backups
+	return patched(path, f.Close())
 }
 
-// CreateBackup creates a backup of existing configs
-func CreateBackup(configs []string) (string, error) {
-	backupDir := GetBackupDir()
-	if err := EnsureDir(backupDir); err != nil {
-		return "", fmt.Errorf("failed to create backup directory: %w", err)
+// ReplaceInFile replaces the first occurrence of old with new inside path
+func ReplaceInFile(path, old, new string) error {
+	trackChange(path)
+	if IsDryRun() {
+		RecordPlan(PlanPatch, path, "replace "+old)
+		return nil
 	}
+	if err := unlink(path); err != nil {
+		return err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return patched(path, os.WriteFile(path, []byte(strings.Replace(string(content), old, new, 1)), 0644))
+}
 
-	configPaths := ConfigPaths()
-
-	for _, configKey := range configs {
-		// Extract key from "key: path" format if present
-		key := configKey
-		if idx := strings.Index(configKey, ":"); idx > 0 {
-			key = configKey[:idx]
-		}
-
-		srcPath, exists := configPaths[key]
-		if !exists {
-			continue
-		}
+// patched records a file the installer wrote in the install manifest once
+// the write succeeded
+func patched(path string, err error) error {
+	if err == nil {
+		recordPatch(path)
+	}
+	return err
+}
 
-		// Check if source exists
-		info, err := os.Stat(srcPath)
-		if err != nil {
-			continue // File doesn't exist, skip
+// RemoveAll deletes path and everything below it. A linked config is
+// unlinked, the repository it points into is left alone.
+func RemoveAll(path string) error {
+	trackChange(path)
+	forgetManifest(path)
+	if !IsDryRun() {
+		if err := unfoldParents(path); err != nil {
+			return err
 		}
+	}
+	return removeAll(path)
+}
 
-		// Determine destination path
-		dstPath := backupDir + "/" + key
-
-		if info.IsDir() {
-			// Copy directory
-			if err := CopyDir(srcPath, dstPath); err != nil {
-				return backupDir, fmt.Errorf("failed to backup %s: %w", key, err)
-			}
-		} else {
-			// Copy file
-			if err := CopyFile(srcPath, dstPath); err != nil {
-				return backupDir, fmt.Errorf("failed to backup %s: %w", key, err)
-			}
+func removeAll(path string) error {
+	if IsDryRun() {
+		if _, err := os.Lstat(path); err == nil {
+			RecordPlan(PlanRemove, path, "")
 		}
+		return nil
 	}
-
-	return backupDir, nil
+	return os.RemoveAll(path)
 }
 
-// RestoreBackup restores configs from a backup directory
-func RestoreBackup(backupDir string) error {
-	configPaths := ConfigPaths()
-
-	entries, err := os.ReadDir(backupDir)
-	if err != nil {
-		return fmt.Errorf("failed to read backup directory: %w", err)
+// Symlink creates newname as a symbolic link to oldname
+func Symlink(oldname, newname string) error {
+	trackChange(newname)
+	if IsDryRun() {
+		RecordPlan(PlanLink, newname, oldname)
+		return nil
 	}
+	return os.Symlink(oldname, newname)
+}
 
-	for _, entry := range entries {
-		key := entry.Name()
-		dstPath, exists := configPaths[key]
-		if !exists {
-			continue
-		}
-
-		srcPath := backupDir + "/" + key
-
-		// Remove current config
-		os.RemoveAll(dstPath)
+// AIConfigs are the AI tool configs the installer overwrites, relative to
+// HOME and keyed by the name of their backup entry. Every file is an entry
+// of its own, so a hand-tuned settings.json can be restored alone.
+var AIConfigs = map[string]string{
+	"claude_md":       ".claude/CLAUDE.md",
+	"claude_settings": ".claude/settings.json",
+	"opencode_config": ".config/opencode/opencode.json",
+	"opencode_agents": ".config/opencode/agents",
+	"codex_agents":    ".codex/AGENTS.md",
+	"qwen_settings":   ".qwen/settings.json",
+}
 
-		srcInfo, err := os.Stat(srcPath)
-		if err != nil {
-			continue
+// ConfigPaths returns all config paths that Gentleman.Dots will modify,
+// keyed by the name of their backup entry
+func ConfigPaths() map[string]string {
+	home := os.Getenv("HOME")
+	paths := map[string]string{
+		"nvim":     home + "/.config/nvim",
+		"zed":      home + "/.config/zed",
+		"starship": home + "/.config/starship.toml",
+	}
+	for _, tool := range Tools {
+		for key, path := range tool.Configs {
+			paths[key] = filepath.Join(home, path)
 		}
+	}
+	for key, path := range AIConfigs {
+		paths[key] = filepath.Join(home, path)
+	}
+	return paths
+}
 
-		if srcInfo.IsDir() {
-			if err := CopyDir(srcPath, dstPath); err != nil {
-				return fmt.Errorf("failed to restore %s: %w", key, err)
-			}
-		} else {
-			if err := CopyFile(srcPath, dstPath); err != nil {
-				return fmt.Errorf("failed to restore %s: %w", key, err)
-			}
+// IsAIConfig reports whether a backup entry, or a "key: path" line of
+// DetectExistingConfigs, is an AI tool config
+func IsAIConfig(entry string) bool {
+	key, _, _ := strings.Cut(entry, ":")
+	_, ok := AIConfigs[key]
+	return ok
+}
+
+// DetectExistingConfigs checks which config files/directories already exist
+func DetectExistingConfigs() []string {
+	existing := []string{}
+	for name, path := range ConfigPaths() {
+		if _, err := os.Stat(path); err == nil {
+			existing = append(existing, name+": "+path)
 		}
 	}
-
-	return nil
+	return existing
 }
 
-// DeleteBackup removes a backup directory
-func DeleteBackup(backupDir string) error {
-	return os.RemoveAll(backupDir)
+// StateDir returns the directory where the installer keeps its own state
+// (profiles, journals, logs)
+func StateDir() string {
+	return filepath.Join(os.Getenv("HOME"), ".config", "gentleman")
 }
 
 // LogCallback is a function that receives log lines during command execution
@@ -500,7 +612,10 @@ type LogCallback func(line string)
 
 // RunWithLogs executes a command and streams output to a callback function
 // This allows the TUI to display real-time installation progress
-func RunWithLogs(command string, opts *ExecOptions, onLog LogCallback) *ExecResult {
+func RunWithLogs(ctx context.Context, command string, opts *ExecOptions, onLog LogCallback) *ExecResult {
+	if IsDryRun() {
+		return planCommand(command)
+	}
 	if opts == nil {
 		opts = &ExecOptions{}
 	}
@@ -510,21 +625,14 @@ func RunWithLogs(command string, opts *ExecOptions, onLog LogCallback) *ExecResu
 		Command: command,
 	}
 
-	ctx := context.Background()
 	if opts.Timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
 		defer cancel()
 	}
 
-	// In Termux, execute commands directly without shell wrapper
-	var cmd *exec.Cmd
-	if isTermux() {
-		executable, args := parseCommand(command)
-		cmd = exec.CommandContext(ctx, executable, args...)
-	} else {
-		cmd = exec.CommandContext(ctx, GetShell(), "-c", command)
-	}
+	cmd, release := newCommand(ctx, command)
+	defer release()
 
 	if opts.WorkDir != "" {
 		cmd.Dir = opts.WorkDir
@@ -551,8 +659,8 @@ func RunWithLogs(command string, opts *ExecOptions, onLog LogCallback) *ExecResu
 		return result
 	}
 
-	if err := cmd.Start(); err != nil {
-		result.Error = &ExecError{Command: command, Wrapped: err}
+	if err := startCommand(cmd); err != nil {
+		result.Error = &ExecError{Command: command, Wrapped: cancelled(ctx, err)}
 		result.Duration = time.Since(start)
 		return result
 	}
@@ -600,7 +708,7 @@ func RunWithLogs(command string, opts *ExecOptions, onLog LogCallback) *ExecResu
 			ExitCode: exitCode,
 			Stdout:   stdout.String(),
 			Stderr:   stderr.String(),
-			Wrapped:  err,
+			Wrapped:  cancelled(ctx, err),
 		}
 	}
 
@@ -616,233 +724,130 @@ func RunWithLogs(command string, opts *ExecOptions, onLog LogCallback) *ExecResu
 }
 
 // RunBrewWithLogs runs a brew command with log streaming
-func RunBrewWithLogs(args string, opts *ExecOptions, onLog LogCallback) *ExecResult {
+func RunBrewWithLogs(ctx context.Context, args string, opts *ExecOptions, onLog LogCallback) *ExecResult {
+	pkgManagerMu.Lock()
+	defer pkgManagerMu.Unlock()
 	brewPath := GetBrewPrefix() + "/bin/brew"
-	return RunWithLogs(brewPath+" "+args, opts, onLog)
+	return RunWithLogs(ctx, brewPath+" "+args, opts, onLog)
 }
 
 // RunSudoWithLogs runs a sudo command with log streaming
-func RunSudoWithLogs(command string, opts *ExecOptions, onLog LogCallback) *ExecResult {
-	return RunWithLogs("sudo "+command, opts, onLog)
+func RunSudoWithLogs(ctx context.Context, command string, opts *ExecOptions, onLog LogCallback) *ExecResult {
+	pkgManagerMu.Lock()
+	defer pkgManagerMu.Unlock()
+	return RunWithLogs(ctx, "sudo "+command, opts, onLog)
 }
 
-// PatchZshForWM modifies .zshrc based on window manager choice
-// If wm is "none", removes WM-related lines
-// If wm is "zellij", changes tmux references to zellij
-// If wm is "tmux", leaves as-is (default)
-func PatchZshForWM(zshrcPath string, wm string, installNvim bool) error {
-	content, err := os.ReadFile(zshrcPath)
-	if err != nil {
-		return err
+// setShellBlock sets block id of a shell config the installer copied, or
+// removes it when body is "". The config has to be there already, outside
+// of dry-run mode.
+func setShellBlock(path, id, body string) error {
+	if !IsDryRun() {
+		if _, err := os.Stat(path); err != nil {
+			return err
+		}
 	}
-
-	lines := strings.Split(string(content), "\n")
-	var newLines []string
-
-	// Lines to remove if WM is "none"
-	wmLinesToRemove := map[string]bool{
-		`WM_VAR="/$TMUX"`:      true,
-		`WM_VAR="$ZELLIJ"`:     true,
-		`WM_CMD="tmux"`:        true,
-		`WM_CMD="zellij"`:      true,
-		`# change with ZELLIJ`: true,
-		`# change with zellij`: true,
-		`start_if_needed`:      true,
+	var err error
+	if body == "" {
+		_, err = RemoveRcBlock(path, id)
+	} else {
+		_, err = SetRcBlock(path, id, body)
 	}
+	return err
+}
 
-	inStartIfNeeded := false
-
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-
-		// Handle fzf line - wrap with command check if nvim not installed
-		if strings.Contains(line, `eval "$(fzf --zsh)"`) {
-			if !installNvim {
-				newLines = append(newLines, `if command -v fzf &> /dev/null; then`)
-				newLines = append(newLines, line)
-				newLines = append(newLines, `fi`)
-			} else {
-				newLines = append(newLines, line)
-			}
-			continue
-		}
-
-		// Handle WM based on choice
-		if wm == "none" {
-			// Skip WM-related lines entirely
-			if wmLinesToRemove[trimmed] {
-				continue
-			}
-			// Skip function start_if_needed block
-			if strings.HasPrefix(trimmed, "function start_if_needed") {
-				inStartIfNeeded = true
-				continue
-			}
-			if inStartIfNeeded {
-				if trimmed == "}" {
-					inStartIfNeeded = false
-				}
-				continue
-			}
-			// Skip the call to start_if_needed
-			if trimmed == "start_if_needed" {
-				continue
-			}
-			newLines = append(newLines, line)
-		} else if wm == "zellij" {
-			// Replace tmux with zellij
-			modified := line
-			if strings.Contains(line, `WM_VAR="/$TMUX"`) {
-				modified = `WM_VAR="$ZELLIJ"`
-			} else if strings.Contains(line, `WM_CMD="tmux"`) {
-				modified = `WM_CMD="zellij"`
-			} else if strings.Contains(line, "# change with ZELLIJ") {
-				continue // Remove this comment
-			} else if strings.Contains(line, "# change with zellij") {
-				continue // Remove this comment
-			}
-			newLines = append(newLines, modified)
-		} else {
-			// tmux is default, keep as-is
-			newLines = append(newLines, line)
+// PatchZshForWM sets the wm block of .zshrc, which starts tmux or zellij in
+// interactive shells not already inside it; any other wm removes it. The
+// block does nothing once the overlay of a linked .zshrc replaced it.
+// Without nvim, fzf may be missing, so its key bindings are only loaded
+// when it is installed.
+func PatchZshForWM(zshrcPath string, wm string, installNvim bool) error {
+	if !installNvim {
+		body := `if command -v fzf &> /dev/null; then
+    eval "$(fzf --zsh)"
+fi`
+		if err := setShellBlock(zshrcPath, RcBlockFzf, body); err != nil {
+			return err
 		}
 	}
 
-	return os.WriteFile(zshrcPath, []byte(strings.Join(newLines, "\n")), 0644)
-}
-
-// PatchFishForWM modifies config.fish based on window manager choice
-func PatchFishForWM(configPath string, wm string, installNvim bool) error {
-	content, err := os.ReadFile(configPath)
-	if err != nil {
-		return err
+	var body string
+	switch wm {
+	case "tmux":
+		body = zshWMBlock(`"/$TMUX"`, wm)
+	case "zellij":
+		body = zshWMBlock(`"$ZELLIJ"`, wm)
 	}
+	return setShellBlock(zshrcPath, RcBlockWM, body)
+}
 
-	lines := strings.Split(string(content), "\n")
-	var newLines []string
-
-	inTmuxBlock := false
-	inZellijBlock := false
+func zshWMBlock(envVar, wm string) string {
+	return fmt.Sprintf(`WM_VAR=%s
+WM_CMD="%s"
 
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
+function start_if_needed() {
+    if [[ $- == *i* ]] && [[ -z "${WM_VAR#/}" ]] && [[ -t 1 ]] && [[ -z "$GENTLEMAN_SKIP_WM" ]]; then
+        exec $WM_CMD
+    fi
+}
 
-		// Handle fzf line
-		if strings.Contains(line, "fzf --fish | source") {
-			if !installNvim {
-				newLines = append(newLines, `if command -v fzf &> /dev/null`)
-				newLines = append(newLines, line)
-				newLines = append(newLines, `end`)
-			} else {
-				newLines = append(newLines, line)
-			}
-			continue
-		}
+start_if_needed`, envVar, wm)
+}
 
-		if wm == "none" {
-			// Skip tmux block
-			if trimmed == "if not set -q TMUX" {
-				inTmuxBlock = true
-				continue
-			}
-			if inTmuxBlock {
-				if trimmed == "end" {
-					inTmuxBlock = false
-				}
-				continue
-			}
-			// Skip zellij commented block
-			if strings.HasPrefix(trimmed, "#if not set -q ZELLIJ") || strings.HasPrefix(trimmed, "#  zellij") || strings.HasPrefix(trimmed, "#end") {
-				continue
-			}
-			newLines = append(newLines, line)
-		} else if wm == "zellij" {
-			// Comment out tmux, uncomment zellij
-			if trimmed == "if not set -q TMUX" {
-				inTmuxBlock = true
-				continue
-			}
-			if inTmuxBlock {
-				if trimmed == "end" {
-					inTmuxBlock = false
-				}
-				continue
-			}
-			// Uncomment zellij block
-			if trimmed == "#if not set -q ZELLIJ" {
-				newLines = append(newLines, "if not set -q ZELLIJ")
-				inZellijBlock = true
-				continue
-			}
-			if inZellijBlock && strings.HasPrefix(trimmed, "#") {
-				if trimmed == "#end" {
-					newLines = append(newLines, "end")
-					inZellijBlock = false
-				} else {
-					// Remove leading # and space
-					newLines = append(newLines, strings.TrimPrefix(trimmed, "#"))
-				}
-				continue
-			}
-			newLines = append(newLines, line)
-		} else {
-			// tmux - keep as is
-			newLines = append(newLines, line)
+// PatchFishForWM sets the wm and fzf blocks of config.fish, like
+// PatchZshForWM
+func PatchFishForWM(configPath string, wm string, installNvim bool) error {
+	if !installNvim {
+		body := `if command -v fzf &> /dev/null
+    fzf --fish | source
+end`
+		if err := setShellBlock(configPath, RcBlockFzf, body); err != nil {
+			return err
 		}
 	}
 
-	return os.WriteFile(configPath, []byte(strings.Join(newLines, "\n")), 0644)
+	var body string
+	if wm == "tmux" || wm == "zellij" {
+		body = fmt.Sprintf("if not set -q %s; and not set -q GENTLEMAN_SKIP_WM\n    %s\nend", strings.ToUpper(wm), wm)
+	}
+	return setShellBlock(configPath, RcBlockWM, body)
 }
 
-// PatchNushellForWM modifies config.nu based on window manager choice
+// PatchNushellForWM sets the wm block of config.nu, like PatchZshForWM
 func PatchNushellForWM(configPath string, wm string) error {
-	content, err := os.ReadFile(configPath)
-	if err != nil {
-		return err
-	}
-
-	lines := strings.Split(string(content), "\n")
-	var newLines []string
-
-	inMultiplexerBlock := false
-
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
+	var body string
+	if wm == "tmux" || wm == "zellij" {
+		body = fmt.Sprintf(`let MULTIPLEXER = "%s"
+let MULTIPLEXER_ENV_PREFIX = "%s"
+
+def start_multiplexer [] {
+  if $MULTIPLEXER_ENV_PREFIX not-in ($env | columns) {
+    run-external $MULTIPLEXER
+  }
+}
 
-		if wm == "none" {
-			// Skip multiplexer-related lines
-			if strings.HasPrefix(trimmed, "let MULTIPLEXER =") ||
-				strings.HasPrefix(trimmed, "let MULTIPLEXER_ENV_PREFIX =") {
-				continue
-			}
-			if strings.HasPrefix(trimmed, "def start_multiplexer") {
-				inMultiplexerBlock = true
-				continue
-			}
-			if inMultiplexerBlock {
-				if trimmed == "}" {
-					inMultiplexerBlock = false
-				}
-				continue
-			}
-			if trimmed == "start_multiplexer" {
-				continue
-			}
-			newLines = append(newLines, line)
-		} else if wm == "zellij" {
-			// Replace tmux with zellij
-			if strings.Contains(line, `let MULTIPLEXER = "tmux"`) {
-				newLines = append(newLines, `let MULTIPLEXER = "zellij"`)
-			} else if strings.Contains(line, `let MULTIPLEXER_ENV_PREFIX = "TMUX"`) {
-				newLines = append(newLines, `let MULTIPLEXER_ENV_PREFIX = "ZELLIJ"`)
-			} else {
-				newLines = append(newLines, line)
-			}
-		} else {
-			// tmux - keep as is
-			newLines = append(newLines, line)
-		}
+start_multiplexer`, wm, strings.ToUpper(wm))
 	}
+	return setShellBlock(configPath, RcBlockWM, body)
+}
 
-	return os.WriteFile(configPath, []byte(strings.Join(newLines, "\n")), 0644)
+// PatchBashForWM sets the multiplexer auto-start block at the end of
+// .bashrc: tmux or zellij is started in interactive shells not already
+// inside it. Any other wm removes the block.
+func PatchBashForWM(bashrcPath string, wm string) error {
+	var env string
+	switch wm {
+	case "tmux":
+		env = "TMUX"
+	case "zellij":
+		env = "ZELLIJ"
+	default:
+		_, err := RemoveRcBlock(bashrcPath, RcBlockWM)
+		return err
+	}
+	body := fmt.Sprintf(`if [[ $- == *i* ]] && [[ -z "$%s" ]] && [[ -t 1 ]] && command -v %s &> /dev/null; then
+    exec %s
+fi`, env, wm, wm)
+	_, err := SetRcBlock(bashrcPath, RcBlockWM, body)
+	return err
 }
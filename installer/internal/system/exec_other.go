@@ -0,0 +1,19 @@
+//go:build !unix
+
+package system
+
+import "os/exec"
+
+// setCancel leaves cancelling cmd to exec.CommandContext, which kills it:
+// there are no process groups to signal here
+func setCancel(cmd *exec.Cmd, group bool) func() {
+	return func() {}
+}
+
+// startCommand starts cmd
+func startCommand(cmd *exec.Cmd) error {
+	return cmd.Start()
+}
+
+// KillGroups does nothing: cancelling a command already kills it
+func KillGroups() {}
@@ -1,8 +1,11 @@
 package system
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -10,7 +13,7 @@ import (
 
 func TestRun(t *testing.T) {
 	t.Run("should execute simple command", func(t *testing.T) {
-		result := Run("echo hello", nil)
+		result := Run(context.Background(), "echo hello", nil)
 		if result.Error != nil {
 			t.Errorf("Unexpected error: %v", result.Error)
 		}
@@ -20,7 +23,7 @@ func TestRun(t *testing.T) {
 	})
 
 	t.Run("should capture exit code on failure", func(t *testing.T) {
-		result := Run("exit 1", nil)
+		result := Run(context.Background(), "exit 1", nil)
 		if result.Error == nil {
 			t.Error("Expected error for exit 1")
 		}
@@ -31,7 +34,7 @@ func TestRun(t *testing.T) {
 
 	t.Run("should respect timeout", func(t *testing.T) {
 		start := time.Now()
-		result := Run("sleep 10", &ExecOptions{Timeout: 100 * time.Millisecond})
+		result := Run(context.Background(), "sleep 10", &ExecOptions{Timeout: 100 * time.Millisecond})
 		elapsed := time.Since(start)
 
 		if elapsed > 2*time.Second {
@@ -43,7 +46,7 @@ func TestRun(t *testing.T) {
 	})
 
 	t.Run("should use working directory", func(t *testing.T) {
-		result := Run("pwd", &ExecOptions{WorkDir: "/tmp"})
+		result := Run(context.Background(), "pwd", &ExecOptions{WorkDir: "/tmp"})
 		if result.Error != nil {
 			t.Errorf("Unexpected error: %v", result.Error)
 		}
@@ -54,7 +57,7 @@ func TestRun(t *testing.T) {
 	})
 
 	t.Run("should track duration", func(t *testing.T) {
-		result := Run("sleep 0.1", nil)
+		result := Run(context.Background(), "sleep 0.1", nil)
 		if result.Duration < 50*time.Millisecond {
 			t.Errorf("Duration seems too short: %v", result.Duration)
 		}
@@ -62,7 +65,7 @@ func TestRun(t *testing.T) {
 
 	t.Run("should handle environment variables", func(t *testing.T) {
 		// Use sh -c to ensure variable expansion works across all shells
-		result := Run(`sh -c 'echo "$TEST_VAR"'`, &ExecOptions{
+		result := Run(context.Background(), `sh -c 'echo "$TEST_VAR"'`, &ExecOptions{
 			Env: []string{"TEST_VAR=gentleman"},
 		})
 		if result.Error != nil {
@@ -74,6 +77,82 @@ func TestRun(t *testing.T) {
 	})
 }
 
+func TestRunCancel(t *testing.T) {
+	// The background sleep holds the output pipe: the command only returns
+	// quickly if its whole process group is stopped
+	const command = "sleep 30 & sleep 30"
+	runners := map[string]func(ctx context.Context) *ExecResult{
+		"Run": func(ctx context.Context) *ExecResult {
+			return Run(ctx, command, nil)
+		},
+		"RunWithLogs": func(ctx context.Context) *ExecResult {
+			return RunWithLogs(ctx, command, nil, nil)
+		},
+	}
+	for name, run := range runners {
+		t.Run(name+" stops the process group", func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(100*time.Millisecond, cancel)
+			start := time.Now()
+			result := run(ctx)
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("cancelling should stop every process, took %v", elapsed)
+			}
+			if !errors.Is(result.Error, ErrCancelled) {
+				t.Errorf("expected ErrCancelled, got %v", result.Error)
+			}
+		})
+	}
+
+	t.Run("an already cancelled context runs nothing", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		marker := filepath.Join(t.TempDir(), "ran")
+		result := Run(ctx, "touch "+marker, nil)
+		if !errors.Is(result.Error, ErrCancelled) {
+			t.Errorf("expected ErrCancelled, got %v", result.Error)
+		}
+		if _, err := os.Stat(marker); err == nil {
+			t.Error("the command should not run")
+		}
+	})
+
+	t.Run("a timeout is not a cancellation", func(t *testing.T) {
+		result := Run(context.Background(), "sleep 10", &ExecOptions{Timeout: 100 * time.Millisecond})
+		if result.Error == nil || errors.Is(result.Error, ErrCancelled) {
+			t.Errorf("expected the timeout error, got %v", result.Error)
+		}
+	})
+}
+
+func TestNeedsTerminal(t *testing.T) {
+	tests := []struct {
+		command string
+		want    bool
+	}{
+		{"sudo apt-get install -y git", true},
+		{"/usr/bin/sudo usermod -s /bin/zsh me", true},
+		{"sh -c 'sudo tee -a /etc/shells'", true},
+		{"cd /tmp && sudo make install", true},
+		{"brew install fzf", false},
+		{"git clone https://example.com/sudoers.git", false},
+	}
+	for _, tt := range tests {
+		if got := needsTerminal(tt.command); got != tt.want {
+			t.Errorf("needsTerminal(%q) = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+
+	// sudo stays in the terminal's process group, where it can prompt
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cmd, release := newCommand(ctx, "sudo true")
+	release()
+	if cmd.SysProcAttr != nil {
+		t.Error("sudo should not get its own process group")
+	}
+}
+
 func TestEnsureDir(t *testing.T) {
 	t.Run("should create directory if not exists", func(t *testing.T) {
 		testDir := filepath.Join(os.TempDir(), "gentleman-test-dir")
@@ -577,7 +656,7 @@ func TestRunPkg(t *testing.T) {
 		// We can't really test pkg on non-Termux systems,
 		// but we can verify the command construction by checking
 		// that it at least attempts to run "pkg" with the args
-		result := RunPkg("--help", nil)
+		result := RunPkg(context.Background(), "--help", nil)
 
 		// On non-Termux systems, this will fail with "command not found"
 		// which is expected behavior - we just verify it tried
@@ -591,7 +670,7 @@ func TestRunPkg(t *testing.T) {
 			WorkDir: "/tmp",
 			Timeout: 100 * time.Millisecond,
 		}
-		result := RunPkg("--version", opts)
+		result := RunPkg(context.Background(), "--version", opts)
 
 		// Just verify it ran with options
 		if result.Command != "pkg --version" {
@@ -608,7 +687,7 @@ func TestRunPkgWithLogs(t *testing.T) {
 		}
 
 		// Use echo to simulate pkg output
-		result := RunWithLogs("echo 'test output'", nil, logFunc)
+		result := RunWithLogs(context.Background(), "echo 'test output'", nil, logFunc)
 
 		if result.Error != nil {
 			t.Errorf("Unexpected error: %v", result.Error)
@@ -632,7 +711,7 @@ func TestRunPkgWithLogs(t *testing.T) {
 	})
 
 	t.Run("should construct correct pkg command", func(t *testing.T) {
-		result := RunPkgWithLogs("--help", nil, nil)
+		result := RunPkgWithLogs(context.Background(), "--help", nil, nil)
 
 		if result.Command != "pkg --help" {
 			t.Errorf("Expected command 'pkg --help', got '%s'", result.Command)
@@ -642,7 +721,7 @@ func TestRunPkgWithLogs(t *testing.T) {
 
 func TestRunPkgInstall(t *testing.T) {
 	t.Run("should construct correct install command", func(t *testing.T) {
-		result := RunPkgInstall("vim git", nil, nil)
+		result := RunPkgInstall(context.Background(), "vim git", nil, nil)
 
 		// Verify command includes -y flag for non-interactive
 		expectedCmd := "pkg install -y vim git"
@@ -652,7 +731,7 @@ func TestRunPkgInstall(t *testing.T) {
 	})
 
 	t.Run("should include -y flag for non-interactive installs", func(t *testing.T) {
-		result := RunPkgInstall("neovim", nil, nil)
+		result := RunPkgInstall(context.Background(), "neovim", nil, nil)
 
 		if !strings.Contains(result.Command, "-y") {
 			t.Errorf("Command should include -y flag, got: %s", result.Command)
@@ -666,7 +745,7 @@ func TestRunPkgInstall(t *testing.T) {
 		}
 
 		// This will fail on non-Termux but we're just testing the callback wiring
-		RunPkgInstall("somepackage", nil, logFunc)
+		RunPkgInstall(context.Background(), "somepackage", nil, logFunc)
 
 		// Note: on non-Termux systems, pkg won't exist so there might be no output
 		// The important thing is the function doesn't panic
@@ -681,7 +760,7 @@ func TestRunWithLogs(t *testing.T) {
 			logs = append(logs, line)
 		}
 
-		result := RunWithLogs("echo stdout && echo stderr >&2", nil, logFunc)
+		result := RunWithLogs(context.Background(), "echo stdout && echo stderr >&2", nil, logFunc)
 
 		if result.Error != nil {
 			t.Errorf("Unexpected error: %v", result.Error)
@@ -701,7 +780,7 @@ func TestRunWithLogs(t *testing.T) {
 
 	t.Run("should handle nil callback gracefully", func(t *testing.T) {
 		// Should not panic with nil callback
-		result := RunWithLogs("echo test", nil, nil)
+		result := RunWithLogs(context.Background(), "echo test", nil, nil)
 
 		if result.Error != nil {
 			t.Errorf("Unexpected error: %v", result.Error)
@@ -714,7 +793,7 @@ func TestRunWithLogs(t *testing.T) {
 
 	t.Run("should respect timeout", func(t *testing.T) {
 		start := time.Now()
-		result := RunWithLogs("sleep 10", &ExecOptions{Timeout: 100 * time.Millisecond}, nil)
+		result := RunWithLogs(context.Background(), "sleep 10", &ExecOptions{Timeout: 100 * time.Millisecond}, nil)
 		elapsed := time.Since(start)
 
 		if elapsed > 2*time.Second {
@@ -727,7 +806,7 @@ func TestRunWithLogs(t *testing.T) {
 
 	t.Run("should capture exit code on failure", func(t *testing.T) {
 		// Use sh -c to ensure exit works correctly across all shells
-		result := RunWithLogs("sh -c 'exit 42'", nil, nil)
+		result := RunWithLogs(context.Background(), "sh -c 'exit 42'", nil, nil)
 
 		if result.Error == nil {
 			t.Error("Expected error for non-zero exit")
@@ -737,3 +816,158 @@ func TestRunWithLogs(t *testing.T) {
 		}
 	})
 }
+
+func TestStripInstallerBlocks(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		found   bool
+	}{
+		{"PATH line", "alias v=nvim\n\n# Added by Javi.Dots installer\nexport PATH=\"/home/u/.local/bin:$PATH\"\n",
+			"alias v=nvim\n", true},
+		{"Termux auto-start", "export A=1\n\n# Gentleman.Dots shell auto-start\nif [ -x \"/bin/fish\" ]; then\n    exec /bin/fish\nfi\nexport B=2\n",
+			"export A=1\nexport B=2\n", true},
+		{"both blocks", "\n# Added by Javi.Dots installer\nfish_add_path ~/.local/bin\n\n# Gentleman.Dots shell auto-start\nif true; then\nfi\n",
+			"", true},
+		{"managed blocks", "alias v=nvim\n\n# >>> gentleman:brew >>>\neval x\n# <<< gentleman:brew <<<\n\n# >>> gentleman:local-bin >>>\nexport P\n# <<< gentleman:local-bin <<<\n",
+			"alias v=nvim\n", true},
+		{"no blocks", "set -gx EDITOR nvim\n", "set -gx EDITOR nvim\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "rc")
+			os.WriteFile(path, []byte(tt.content), 0644)
+
+			found, err := StripInstallerBlocks(path)
+			if err != nil || found != tt.found {
+				t.Fatalf("StripInstallerBlocks = %v, %v, want %v", found, err, tt.found)
+			}
+			if got, _ := os.ReadFile(path); string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		if found, err := StripInstallerBlocks(filepath.Join(t.TempDir(), "nope")); found || err != nil {
+			t.Errorf("a missing rc file has nothing to strip, got %v, %v", found, err)
+		}
+	})
+}
+
+func TestOriginalBackup(t *testing.T) {
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+
+	t.Run("older installs use the oldest backup", func(t *testing.T) {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		for _, dir := range []string{".gentleman-backup-2026-01-02-100000/tmux", ".gentleman-backup-2026-03-01-090000/nvim", ".gentleman-backup-2026-02-01-090000/tmux"} {
+			os.MkdirAll(filepath.Join(home, dir), 0755)
+		}
+
+		backup, ok := OriginalBackup("tmux")
+		if !ok || filepath.Base(backup.Path) != ".gentleman-backup-2026-01-02-100000" {
+			t.Errorf("expected the oldest backup holding tmux, got %q", backup.Path)
+		}
+		if _, ok := OriginalBackup("zed"); ok {
+			t.Error("no backup holds zed")
+		}
+	})
+
+	t.Run("the first install backup is kept", func(t *testing.T) {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		tmux := filepath.Join(home, ".tmux.conf")
+		os.WriteFile(tmux, []byte("# mine\n"), 0644)
+		first, err := CreateBackupFor([]string{"tmux"}, "install", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The install writes its own config, which a second install backs
+		// up again
+		src := filepath.Join(t.TempDir(), "tmux.conf")
+		os.WriteFile(src, []byte("# gentleman\n"), 0644)
+		if err := CopyFile(src, tmux); err != nil {
+			t.Fatal(err)
+		}
+		if err := SaveManifest("", ""); err != nil {
+			t.Fatal(err)
+		}
+		for _, reason := range []string{"install"} {
+			if _, err := CreateBackupFor([]string{"tmux"}, reason, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		backup, ok := OriginalBackup("tmux")
+		if !ok || backup.Path != first {
+			t.Errorf("expected the backup of the first install %s, got %q", first, backup.Path)
+		}
+
+		if err := ForgetOriginal("tmux"); err != nil {
+			t.Fatal(err)
+		}
+		if backup, ok := OriginalBackup("tmux"); ok {
+			t.Errorf("a config put back should not be restored again, got %q", backup.Path)
+		}
+	})
+}
+
+func TestAIConfigBackup(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+	write := func(rel, content string) {
+		path := filepath.Join(home, rel)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+	read := func(rel string) string {
+		data, _ := os.ReadFile(filepath.Join(home, rel))
+		return string(data)
+	}
+	write(".claude/settings.json", `{"permissions": {"allow": ["Bash(go test:*)"]}}`)
+	write(".claude/CLAUDE.md", "my rules")
+	write(".config/opencode/agents/reviewer.md", "my agent")
+
+	existing := DetectExistingConfigs()
+	for _, key := range []string{"claude_settings", "claude_md", "opencode_agents"} {
+		want := key + ": " + filepath.Join(home, AIConfigs[key])
+		if !slices.Contains(existing, want) {
+			t.Errorf("%q should be detected, got %v", want, existing)
+		}
+	}
+	if !IsAIConfig("claude_settings: /x") || IsAIConfig("tmux") {
+		t.Error("IsAIConfig should only match AI tool configs")
+	}
+
+	backupDir, err := CreateBackup(existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// What the installer does to them
+	write(".claude/settings.json", "{}")
+	write(".claude/CLAUDE.md", "installer rules")
+	os.RemoveAll(filepath.Join(home, ".config/opencode/agents"))
+
+	if err := RestoreBackupEntries(backupDir, []string{"claude_settings"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(".claude/settings.json"); !strings.Contains(got, "go test") {
+		t.Errorf("settings.json should be restored, got %q", got)
+	}
+	if got := read(".claude/CLAUDE.md"); got != "installer rules" {
+		t.Errorf("CLAUDE.md should be left alone when only the settings are restored, got %q", got)
+	}
+
+	if err := RestoreBackupEntries(backupDir, []string{"opencode_agents"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(".config/opencode/agents/reviewer.md"); got != "my agent" {
+		t.Errorf("the OpenCode agents should be restored, got %q", got)
+	}
+}
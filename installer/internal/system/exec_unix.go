@@ -0,0 +1,73 @@
+//go:build unix
+
+package system
+
+import (
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// groups holds the process groups of the commands running now, by the pid
+// of their leader
+var (
+	groupsMu sync.Mutex
+	groups   = map[int]bool{}
+)
+
+// setCancel makes cancelling cmd send it SIGTERM. With group, cmd gets its
+// own process group and the whole group is signalled, then killed if it is
+// still there after cancelGrace. The returned func stops that kill, to be
+// called once cmd was waited for: the group id may be reused after that.
+func setCancel(cmd *exec.Cmd, group bool) func() {
+	if !group {
+		// WaitDelay kills cmd if it ignores SIGTERM
+		cmd.Cancel = func() error { return cmd.Process.Signal(syscall.SIGTERM) }
+		return func() {}
+	}
+
+	// Wait returns after Cancel did, so release sees the timer
+	var kill *time.Timer
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		pgid := cmd.Process.Pid
+		kill = time.AfterFunc(cancelGrace, func() { _ = syscall.Kill(-pgid, syscall.SIGKILL) })
+		return syscall.Kill(-pgid, syscall.SIGTERM)
+	}
+	return func() {
+		if kill != nil {
+			kill.Stop()
+		}
+		if cmd.Process != nil {
+			groupsMu.Lock()
+			delete(groups, cmd.Process.Pid)
+			groupsMu.Unlock()
+		}
+	}
+}
+
+// startCommand starts cmd and keeps its process group, if it got one, for
+// KillGroups
+func startCommand(cmd *exec.Cmd) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	if cmd.SysProcAttr != nil && cmd.SysProcAttr.Setpgid {
+		groupsMu.Lock()
+		groups[cmd.Process.Pid] = true
+		groupsMu.Unlock()
+	}
+	return nil
+}
+
+// KillGroups kills the process groups of the commands still running, for
+// quitting without waiting for them. The kill that follows cancelling a
+// command runs on a timer, which does not outlive the installer.
+func KillGroups() {
+	groupsMu.Lock()
+	defer groupsMu.Unlock()
+	for pgid := range groups {
+		_ = syscall.Kill(-pgid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,34 @@
+//go:build unix
+
+package system
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestKillGroups(t *testing.T) {
+	// The group ignores SIGTERM, so only KillGroups stops it before the
+	// kill timer
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan *ExecResult)
+	go func() { done <- Run(ctx, "trap '' TERM; sleep 30 & sleep 30", nil) }()
+	for running := false; !running; time.Sleep(10 * time.Millisecond) {
+		groupsMu.Lock()
+		running = len(groups) > 0
+		groupsMu.Unlock()
+	}
+	cancel()
+	KillGroups()
+	select {
+	case result := <-done:
+		if !errors.Is(result.Error, ErrCancelled) {
+			t.Errorf("expected ErrCancelled, got %v", result.Error)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("KillGroups should not leave the group to the kill timer")
+	}
+}
@@ -0,0 +1,215 @@
+package system
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Font is a Nerd Font the installer can set up and point the terminal and
+// editor configs at
+type Font struct {
+	ID      string // flag value, "jetbrains-mono"
+	Name    string // display name
+	Family  string // family name the configs use
+	Package string // brew package, a cask on macOS
+	Archive string // nerd-fonts release archive, without .zip
+	File    string // prefix of the font files, "JetBrainsMonoNerdFont"
+	Termux  string // regular TTF under patched-fonts, for ~/.termux/font.ttf
+}
+
+// DefaultFont is the font the configs ship with
+const DefaultFont = "iosevka-term"
+
+// DefaultFontSize is the size the configs ship with
+const DefaultFontSize = 14.0
+
+// NerdFontsVersion is the nerd-fonts release the archives are downloaded
+// from
+const NerdFontsVersion = "v3.3.0"
+
+// Fonts is the registry of the Nerd Fonts the installer offers, in menu
+// order
+var Fonts = []Font{
+	{
+		ID: "iosevka-term", Name: "Iosevka Term", Family: "IosevkaTerm Nerd Font",
+		Package: "iosevka-term-nerd-font", Archive: "IosevkaTerm", File: "IosevkaTermNerdFont",
+		Termux: "IosevkaTerm/IosevkaTermNerdFont-Regular.ttf",
+	},
+	{
+		ID: "jetbrains-mono", Name: "JetBrains Mono", Family: "JetBrainsMono Nerd Font",
+		Package: "jetbrains-mono-nerd-font", Archive: "JetBrainsMono", File: "JetBrainsMonoNerdFont",
+		Termux: "JetBrainsMono/Ligatures/Regular/JetBrainsMonoNerdFont-Regular.ttf",
+	},
+	{
+		ID: "fira-code", Name: "Fira Code", Family: "FiraCode Nerd Font",
+		Package: "fira-code-nerd-font", Archive: "FiraCode", File: "FiraCodeNerdFont",
+		Termux: "FiraCode/Regular/FiraCodeNerdFont-Regular.ttf",
+	},
+	{
+		ID: "caskaydia-cove", Name: "Caskaydia Cove", Family: "CaskaydiaCove Nerd Font",
+		Package: "caskaydia-cove-nerd-font", Archive: "CascadiaCode", File: "CaskaydiaCoveNerdFont",
+		Termux: "CascadiaCode/Regular/CaskaydiaCoveNerdFont-Regular.ttf",
+	},
+	{
+		ID: "hack", Name: "Hack", Family: "Hack Nerd Font",
+		Package: "hack-nerd-font", Archive: "Hack", File: "HackNerdFont",
+		Termux: "Hack/Regular/HackNerdFont-Regular.ttf",
+	},
+	{
+		ID: "meslo", Name: "Meslo LGS", Family: "MesloLGS Nerd Font",
+		Package: "meslo-lg-nerd-font", Archive: "Meslo", File: "MesloLGSNerdFont",
+		Termux: "Meslo/S/Regular/MesloLGSNerdFont-Regular.ttf",
+	},
+}
+
+// FontIDs returns the IDs of the registered fonts
+func FontIDs() []string {
+	ids := make([]string, len(Fonts))
+	for i, f := range Fonts {
+		ids[i] = f.ID
+	}
+	return ids
+}
+
+// FindFont returns the registered font with the given ID
+func FindFont(id string) (Font, bool) {
+	for _, f := range Fonts {
+		if f.ID == id {
+			return f, true
+		}
+	}
+	return Font{}, false
+}
+
+// ArchiveURL is where the release archive of the font is downloaded from
+func (f Font) ArchiveURL() string {
+	return fmt.Sprintf("https://github.com/ryanoasis/nerd-fonts/releases/download/%s/%s.zip", NerdFontsVersion, f.Archive)
+}
+
+// TermuxURL is where the single TTF Termux uses is downloaded from
+func (f Font) TermuxURL() string {
+	return "https://github.com/ryanoasis/nerd-fonts/raw/HEAD/patched-fonts/" + f.Termux
+}
+
+// fontFamilies lists the families in the fontconfig cache, one font per
+// line. It is empty without fc-list.
+var fontFamilies = func() string {
+	out, err := exec.Command("fc-list", ":", "family").Output()
+	if err != nil {
+		return ""
+	}
+	return string(out)
+}
+
+// fontDirs are the macOS font directories, which fontconfig may not know
+func fontDirs() []string {
+	return []string{filepath.Join(os.Getenv("HOME"), "Library", "Fonts"), "/Library/Fonts"}
+}
+
+// FontInstalled reports whether the font is in the fontconfig cache or the
+// macOS font directories. The lookup changes nothing, so it also runs in
+// dry-run mode.
+func FontInstalled(f Font) bool {
+	for _, line := range strings.Split(fontFamilies(), "\n") {
+		for _, family := range strings.Split(line, ",") {
+			if strings.EqualFold(strings.TrimSpace(family), f.Family) {
+				return true
+			}
+		}
+	}
+	for _, dir := range fontDirs() {
+		if matches, _ := filepath.Glob(filepath.Join(dir, f.File+"*")); len(matches) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// fontTarget is a config file that names a font. It is only rewritten when
+// it exists. The first submatch of each pattern is the value.
+type fontTarget struct {
+	name   string // tool, for reports
+	path   string // relative to HOME
+	family []*regexp.Regexp
+	size   []*regexp.Regexp
+}
+
+// fontTargets are the configs a font is written into, in report order
+var fontTargets = []fontTarget{
+	{
+		name: "Alacritty", path: ".config/alacritty/alacritty.toml",
+		family: []*regexp.Regexp{tomlValue("font.normal", "family")},
+		size:   []*regexp.Regexp{regexp.MustCompile(`(?m)^\[font\][^\[]*?^\s*size\s*=\s*([0-9.]+)`)},
+	},
+	{
+		name: "Kitty", path: ".config/kitty/kitty.conf",
+		family: []*regexp.Regexp{regexp.MustCompile(`(?m)^font_family[ \t]+(.*\S)`)},
+		size:   []*regexp.Regexp{regexp.MustCompile(`(?m)^font_size[ \t]+([0-9.]+)`)},
+	},
+	{
+		name: "Ghostty", path: ".config/ghostty/config",
+		family: []*regexp.Regexp{regexp.MustCompile(`(?m)^font-family[ \t]*=[ \t]*(.*\S)`)},
+		size:   []*regexp.Regexp{regexp.MustCompile(`(?m)^font-size[ \t]*=[ \t]*([0-9.]+)`)},
+	},
+	{
+		name: "WezTerm", path: ".config/wezterm/wezterm.lua",
+		family: []*regexp.Regexp{regexp.MustCompile(`(?m)^config\.font\s*=\s*wezterm\.font\("([^"]*)"`)},
+		size:   []*regexp.Regexp{regexp.MustCompile(`(?m)^config\.font_size\s*=\s*([0-9.]+)`)},
+	},
+	{
+		name: "Zed", path: ".config/zed/settings.json",
+		family: []*regexp.Regexp{
+			regexp.MustCompile(`"buffer_font_family":\s*"([^"]*)"`),
+			regexp.MustCompile(`"terminal":\s*\{[^}]*?"font_family":\s*"([^"]*)"`),
+		},
+		size: []*regexp.Regexp{
+			regexp.MustCompile(`"buffer_font_size":\s*([0-9.]+)`),
+			regexp.MustCompile(`"terminal":\s*\{[^}]*?"font_size":\s*([0-9.]+)`),
+		},
+	},
+}
+
+// ApplyFont writes the family of font id, and size when it is not 0, into
+// every installed terminal and editor config. It returns the names of the
+// tools it updated.
+func ApplyFont(id string, size float64) ([]string, error) {
+	font, ok := FindFont(id)
+	if !ok {
+		return nil, fmt.Errorf("unknown font %q (valid: %s)", id, strings.Join(FontIDs(), ", "))
+	}
+	if size < 0 {
+		return nil, fmt.Errorf("invalid font size %v", size)
+	}
+
+	home := os.Getenv("HOME")
+	var updated []string
+	for _, target := range fontTargets {
+		path := filepath.Join(home, target.path)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		text := string(content)
+		for _, pattern := range target.family {
+			text, _, _ = setValue(text, pattern, font.Family)
+		}
+		if size > 0 {
+			for _, pattern := range target.size {
+				text, _, _ = setValue(text, pattern, strconv.FormatFloat(size, 'f', -1, 64))
+			}
+		}
+		if text != string(content) {
+			if err := WriteFile(path, []byte(text), 0644); err != nil {
+				return updated, fmt.Errorf("failed to set the font of %s: %w", target.name, err)
+			}
+		}
+		updated = append(updated, target.name)
+	}
+	return updated, SaveManifest("", "")
+}
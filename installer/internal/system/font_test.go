@@ -0,0 +1,82 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFontInstalled(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	defer func(list func() string) { fontFamilies = list }(fontFamilies)
+	fontFamilies = func() string {
+		return "DejaVu Sans\nJetBrainsMono Nerd Font,JetBrainsMono NF\n"
+	}
+
+	jetbrains, _ := FindFont("jetbrains-mono")
+	hack, _ := FindFont("hack")
+	if !FontInstalled(jetbrains) {
+		t.Error("a family in the fontconfig cache should be installed")
+	}
+	if FontInstalled(hack) {
+		t.Error("hack is neither cached nor in a font directory")
+	}
+
+	fonts := filepath.Join(home, "Library", "Fonts")
+	os.MkdirAll(fonts, 0755)
+	os.WriteFile(filepath.Join(fonts, "HackNerdFont-Regular.ttf"), nil, 0644)
+	if !FontInstalled(hack) {
+		t.Error("a font in ~/Library/Fonts should be installed")
+	}
+}
+
+func TestApplyFont(t *testing.T) {
+	home := themeHome(t)
+
+	updated, err := ApplyFont("fira-code", 13.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(updated, ",") != "Alacritty,Kitty,Ghostty,WezTerm,Zed" {
+		t.Errorf("every installed terminal and Zed should be updated, got %v", updated)
+	}
+
+	for rel, want := range map[string][]string{
+		".config/alacritty/alacritty.toml": {`family = "FiraCode Nerd Font"`, "size = 13.5"},
+		".config/kitty/kitty.conf":         {"font_family      FiraCode Nerd Font\n", "font_size        13.5\n"},
+		".config/ghostty/config":           {"font-family = FiraCode Nerd Font\n", "font-size = 13.5\n"},
+		".config/wezterm/wezterm.lua":      {`wezterm.font("FiraCode Nerd Font")`, "config.font_size = 13.5\n"},
+		".config/zed/settings.json":        {`"buffer_font_family": "FiraCode Nerd Font"`, `"buffer_font_size": 13.5`, `"font_family": "FiraCode Nerd Font"`, `"font_size": 13.5`},
+	} {
+		got := readHome(t, home, rel)
+		for _, w := range want {
+			if !strings.Contains(got, w) {
+				t.Errorf("%s should contain %q", rel, w)
+			}
+		}
+	}
+	if zed := readHome(t, home, ".config/zed/settings.json"); !strings.Contains(zed, `"ui_font_size": 14`) {
+		t.Error("the Zed UI size is not a font setting of the buffer or terminal")
+	}
+
+	t.Run("zero keeps the size", func(t *testing.T) {
+		if _, err := ApplyFont("hack", 0); err != nil {
+			t.Fatal(err)
+		}
+		got := readHome(t, home, ".config/ghostty/config")
+		if !strings.Contains(got, "font-family = Hack Nerd Font\n") || !strings.Contains(got, "font-size = 13.5\n") {
+			t.Errorf("only the family should change:\n%s", got)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := ApplyFont("comic-sans", 0); err == nil {
+			t.Error("expected an error for an unknown font")
+		}
+		if _, err := ApplyFont("hack", -1); err == nil {
+			t.Error("expected an error for a negative size")
+		}
+	})
+}
@@ -0,0 +1,311 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var (
+	linkMu   sync.Mutex
+	linkRepo string
+)
+
+// LinkRepoDir returns where --link installs keep the repository. The
+// linked configs point into it, so it has to outlive the installation.
+func LinkRepoDir() string {
+	return filepath.Join(os.Getenv("HOME"), ".local", "share", "javi.dots")
+}
+
+// OverlayDir returns where the installer's blocks for linked shell configs
+// are kept, so the repository stays untouched
+func OverlayDir() string {
+	return filepath.Join(StateDir(), "overlay")
+}
+
+// SetLinkRepo turns link mode on for the repository at dir: CopyFile and
+// CopyDir symlink the configs inside it instead of copying them. An empty
+// dir turns link mode off.
+func SetLinkRepo(dir string) {
+	if dir != "" {
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+	}
+	linkMu.Lock()
+	defer linkMu.Unlock()
+	linkRepo = dir
+}
+
+// LinkRepo returns the repository configs are linked into, or "" when
+// configs are copied
+func LinkRepo() string {
+	linkMu.Lock()
+	defer linkMu.Unlock()
+	return linkRepo
+}
+
+// linkSource returns the absolute path src is linked from, when link mode
+// is on and src is inside the linked repository
+func linkSource(src string) (string, bool) {
+	repo := LinkRepo()
+	if repo == "" {
+		return "", false
+	}
+	src = strings.TrimSuffix(strings.TrimSuffix(src, "/*"), "/.")
+	abs, err := filepath.Abs(src)
+	if err != nil || !isWithin(abs, repo) {
+		return "", false
+	}
+	return abs, true
+}
+
+// repoLink returns the target of path when it is a symlink into the linked
+// repository, the links the installer creates
+func repoLink(path string) (string, bool) {
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return "", false
+	}
+	target, err := os.Readlink(path)
+	if err != nil {
+		return "", false
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(path), target)
+	}
+	for _, root := range []string{LinkRepo(), LinkRepoDir()} {
+		if root != "" && isWithin(target, root) {
+			return target, true
+		}
+	}
+	return "", false
+}
+
+// LinkTarget reports where a config installed in link mode points to. A
+// broken link, whose target no longer exists, reports ok false.
+func LinkTarget(path string) (target string, linked, ok bool) {
+	target, linked = repoLink(path)
+	if !linked {
+		return "", false, false
+	}
+	_, err := os.Stat(path)
+	return target, true, err == nil
+}
+
+// IsOverlay reports whether path is a linked config whose installer blocks
+// live in its overlay file
+func IsOverlay(path string) bool {
+	overlayPath, ok := rcOverlay(path)
+	if !ok {
+		return false
+	}
+	content, err := readRcFile(overlayPath)
+	return err == nil && strings.TrimSpace(content) != ""
+}
+
+// BrokenLinks returns the installer links at or below path whose target no
+// longer exists
+func BrokenLinks(path string) []string {
+	var broken []string
+	filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if _, linked, ok := LinkTarget(p); linked && !ok {
+			broken = append(broken, p)
+		}
+		return nil
+	})
+	return broken
+}
+
+// OverlayPath returns the overlay file of a linked config, mirroring its
+// place under HOME
+func OverlayPath(path string) string {
+	rel, err := filepath.Rel(os.Getenv("HOME"), path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = strings.TrimPrefix(path, string(filepath.Separator))
+	}
+	return filepath.Join(OverlayDir(), rel)
+}
+
+// linkFile replaces dst with a symlink to src
+func linkFile(src, dst string) error {
+	if IsDryRun() {
+		RecordPlan(PlanLink, dst, src)
+		return nil
+	}
+	if err := unfoldParents(dst); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dst); err != nil {
+		return err
+	}
+	return os.Symlink(src, dst)
+}
+
+// linkDir links dst to the directory src. A real directory already at dst
+// is kept and its entries are linked one by one, so files that are not in
+// the repository survive.
+func linkDir(src, dst string) error {
+	if IsDryRun() {
+		RecordPlan(PlanLink, dst, src)
+		return nil
+	}
+	if err := unfoldParents(dst); err != nil {
+		return err
+	}
+	info, err := os.Lstat(dst)
+	if err != nil || !info.IsDir() {
+		return linkFile(src, dst)
+	}
+
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		s, d := filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())
+		if entry.IsDir() {
+			if err := linkDir(s, d); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := linkFile(s, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unfold replaces a linked directory with a real one holding a link per
+// entry, so one entry can be changed without touching the repository
+func unfold(dir, target string) error {
+	entries, err := os.ReadDir(target)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(dir); err != nil {
+		return err
+	}
+	if err := os.Mkdir(dir, 0755); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := os.Symlink(filepath.Join(target, entry.Name()), filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unfoldParents unfolds the linked directories above path, outermost
+// first, so writing to path cannot reach into the repository
+func unfoldParents(path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	var parents []string
+	for dir := filepath.Dir(path); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		parents = append(parents, dir)
+	}
+	for i := len(parents) - 1; i >= 0; i-- {
+		target, ok := repoLink(parents[i])
+		if !ok {
+			continue
+		}
+		if info, err := os.Stat(target); err != nil || !info.IsDir() {
+			continue
+		}
+		if err := unfold(parents[i], target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rcOverlay returns the overlay file of path when path links to a shell
+// config of the repository that sources it. The installer's blocks for such
+// a config go to the overlay, so the link keeps following the repository.
+func rcOverlay(path string) (string, bool) {
+	target, ok := repoLink(path)
+	if !ok {
+		return "", false
+	}
+	content, err := os.ReadFile(target)
+	if err != nil {
+		return "", false
+	}
+	overlayPath := OverlayPath(path)
+	rel, err := filepath.Rel(filepath.Dir(StateDir()), overlayPath)
+	if err != nil || !strings.Contains(string(content), filepath.ToSlash(rel)) {
+		return "", false
+	}
+	return overlayPath, true
+}
+
+// unlink prepares a linked config for an edit it has no overlay for: the
+// link is replaced with a copy of the repository file, which the config no
+// longer follows. Paths that are not linked are left alone.
+func unlink(path string) error {
+	if err := unfoldParents(path); err != nil {
+		return err
+	}
+	target, ok := repoLink(path)
+	if !ok {
+		return nil
+	}
+	data, err := os.ReadFile(target)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// detach removes a link into the repository at path, and unfolds the
+// linked directories above it, before a plain copy is written there
+func detach(path string) error {
+	if err := unfoldParents(path); err != nil {
+		return err
+	}
+	if _, ok := repoLink(path); ok {
+		return os.Remove(path)
+	}
+	return nil
+}
+
+// detachTree detaches path and removes the links into the repository
+// below it, before a directory is copied over it
+func detachTree(path string) error {
+	if err := detach(path); err != nil {
+		return err
+	}
+	return filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if _, ok := repoLink(p); ok {
+			return os.Remove(p)
+		}
+		return nil
+	})
+}
+
+// copyLink recreates the symlink src at dst
+func copyLink(src, dst string) error {
+	target, err := os.Readlink(src)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dst); err != nil {
+		return err
+	}
+	return os.Symlink(target, dst)
+}
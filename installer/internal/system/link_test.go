@@ -0,0 +1,181 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLinkMode(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+	manifestPending = map[string]ManifestEntry{}
+
+	repo := LinkRepoDir()
+	write := func(path, content string) {
+		t.Helper()
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(path string) string {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	zshrcSource := "source ~/.config/gentleman/overlay/.zshrc\n\n" + FormatRcBlock(".zshrc", RcBlockWM, zshWMBlock(`"/$TMUX"`, "tmux"))
+	write(filepath.Join(repo, "GentlemanZsh", ".zshrc"), zshrcSource)
+	write(filepath.Join(repo, "GentlemanFish", "fish", "config.fish"), "set -gx EDITOR nvim\n")
+	write(filepath.Join(repo, "GentlemanFish", "fish", "functions", "tmux.fish"), "function tmux\nend\n")
+	write(filepath.Join(repo, "GentlemanFish", "fish", "fish_plugins"), "jorgebucaran/fisher\n")
+
+	SetLinkRepo(repo)
+	t.Cleanup(func() { SetLinkRepo("") })
+
+	zshrc := filepath.Join(home, ".zshrc")
+	fish := filepath.Join(home, ".config", "fish")
+	if err := CopyFile(filepath.Join(repo, "GentlemanZsh", ".zshrc"), zshrc); err != nil {
+		t.Fatal(err)
+	}
+	EnsureDir(filepath.Join(home, ".config"))
+	if err := CopyDir(filepath.Join(repo, "GentlemanFish", "fish"), fish); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("configs are linked", func(t *testing.T) {
+		for path, want := range map[string]string{
+			zshrc: filepath.Join(repo, "GentlemanZsh", ".zshrc"),
+			fish:  filepath.Join(repo, "GentlemanFish", "fish"),
+		} {
+			target, linked, ok := LinkTarget(path)
+			if !linked || !ok || target != want {
+				t.Errorf("%s should link to %s, got %q (linked=%v ok=%v)", path, want, target, linked, ok)
+			}
+		}
+		if _, ok := manifestPending[zshrc]; ok {
+			t.Error("linked files should not be tracked as copies")
+		}
+	})
+
+	t.Run("blocks go to the overlay", func(t *testing.T) {
+		if err := PatchZshForWM(zshrc, "tmux", true); err != nil {
+			t.Fatal(err)
+		}
+		if IsOverlay(zshrc) {
+			t.Error("a block the linked config already has needs no overlay")
+		}
+
+		if err := PatchZshForWM(zshrc, "none", false); err != nil {
+			t.Fatal(err)
+		}
+		if got := read(filepath.Join(repo, "GentlemanZsh", ".zshrc")); got != zshrcSource {
+			t.Errorf("the repository copy should be untouched, got %q", got)
+		}
+		if target, _, _ := LinkTarget(zshrc); target != filepath.Join(repo, "GentlemanZsh", ".zshrc") {
+			t.Fatalf(".zshrc should still link to the repository, got %q", target)
+		}
+		if !IsOverlay(zshrc) {
+			t.Fatal(".zshrc should have an overlay")
+		}
+		if body, _ := RcBlock(OverlayPath(zshrc), RcBlockWM); body != "GENTLEMAN_SKIP_WM=1" {
+			t.Errorf("the overlay should skip the linked wm block, got %q", body)
+		}
+		if _, ok := RcBlock(OverlayPath(zshrc), RcBlockFzf); !ok {
+			t.Error("the overlay should hold the fzf block")
+		}
+
+		if _, err := StripInstallerBlocks(zshrc); err != nil {
+			t.Fatal(err)
+		}
+		if IsOverlay(zshrc) || read(filepath.Join(repo, "GentlemanZsh", ".zshrc")) != zshrcSource {
+			t.Error("stripping should empty the overlay and leave the repository alone")
+		}
+	})
+
+	t.Run("other edits replace the link with a copy", func(t *testing.T) {
+		tmuxConf := filepath.Join(home, ".tmux.conf")
+		write(filepath.Join(repo, "GentlemanTmux", ".tmux.conf"), "# GENTLEMAN_DEFAULT_SHELL\n")
+		if err := CopyFile(filepath.Join(repo, "GentlemanTmux", ".tmux.conf"), tmuxConf); err != nil {
+			t.Fatal(err)
+		}
+		if err := ReplaceInFile(tmuxConf, "# GENTLEMAN_DEFAULT_SHELL", "set -g default-shell /bin/zsh"); err != nil {
+			t.Fatal(err)
+		}
+		if _, linked, _ := LinkTarget(tmuxConf); linked {
+			t.Error(".tmux.conf should be a copy now")
+		}
+		if got := read(tmuxConf); got != "set -g default-shell /bin/zsh\n" {
+			t.Errorf("the copy should hold the edit, got %q", got)
+		}
+		if got := read(filepath.Join(repo, "GentlemanTmux", ".tmux.conf")); got != "# GENTLEMAN_DEFAULT_SHELL\n" {
+			t.Errorf("the repository copy should be untouched, got %q", got)
+		}
+	})
+
+	t.Run("writing inside a linked directory unfolds it", func(t *testing.T) {
+		if err := AppendToFile(filepath.Join(fish, "config.fish"), "fish_add_path ~/.local/bin\n"); err != nil {
+			t.Fatal(err)
+		}
+		if err := RemoveAll(filepath.Join(fish, "functions", "tmux.fish")); err != nil {
+			t.Fatal(err)
+		}
+		if got := read(filepath.Join(repo, "GentlemanFish", "fish", "config.fish")); got != "set -gx EDITOR nvim\n" {
+			t.Errorf("the repository copy should be untouched, got %q", got)
+		}
+		if _, err := os.Stat(filepath.Join(repo, "GentlemanFish", "fish", "functions", "tmux.fish")); err != nil {
+			t.Error("removing a linked file should not delete it from the repository")
+		}
+		if info, err := os.Lstat(fish); err != nil || !info.IsDir() {
+			t.Fatal("the fish directory should be a real directory now")
+		}
+		if _, linked, _ := LinkTarget(filepath.Join(fish, "fish_plugins")); !linked {
+			t.Error("untouched entries should stay linked")
+		}
+	})
+
+	t.Run("backup and restore keep links", func(t *testing.T) {
+		backupDir, err := CreateBackup([]string{"zsh"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info, err := os.Lstat(filepath.Join(backupDir, "zsh")); err != nil || info.Mode()&os.ModeSymlink == 0 {
+			t.Fatal("the backup should hold the link, not a copy")
+		}
+		os.Remove(zshrc)
+		if err := RestoreBackupEntries(backupDir, []string{"zsh"}); err != nil {
+			t.Fatal(err)
+		}
+		if _, linked, ok := LinkTarget(zshrc); !linked || !ok {
+			t.Error("restoring should recreate the link")
+		}
+	})
+
+	t.Run("broken links", func(t *testing.T) {
+		os.Rename(repo, repo+".moved")
+		defer os.Rename(repo+".moved", repo)
+		if broken := BrokenLinks(fish); len(broken) == 0 {
+			t.Error("links into a moved repository should be reported")
+		}
+	})
+
+	t.Run("copy mode replaces links", func(t *testing.T) {
+		SetLinkRepo("")
+		if err := CopyDir(filepath.Join(repo, "GentlemanFish", "fish"), fish); err != nil {
+			t.Fatal(err)
+		}
+		if broken := BrokenLinks(fish); len(broken) != 0 {
+			t.Errorf("no links should be left, got %v", broken)
+		}
+		if _, linked, _ := LinkTarget(filepath.Join(fish, "fish_plugins")); linked {
+			t.Error("linked entries should be replaced with copies")
+		}
+		if got := read(filepath.Join(repo, "GentlemanFish", "fish", "config.fish")); got != "set -gx EDITOR nvim\n" {
+			t.Errorf("copying over links should not write into the repository, got %q", got)
+		}
+	})
+}
@@ -0,0 +1,474 @@
+package system
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ManifestVersion is bumped when the manifest format changes incompatibly
+const ManifestVersion = 1
+
+// ManifestEntry is a file the installer wrote. Source is relative to the
+// repository when the file was copied from it, and empty for files that were
+// generated; SourceSHA256 is the hash of the source at install time, so
+// later repo changes can be told apart from patches applied after the copy.
+type ManifestEntry struct {
+	Source       string    `json:"source,omitempty"`
+	SourceSHA256 string    `json:"source_sha256,omitempty"`
+	Dest         string    `json:"dest"`
+	SHA256       string    `json:"sha256"`
+	Time         time.Time `json:"time"`
+}
+
+// Manifest lists every file the installer wrote, with the repository of the
+// last install so sources can be found again. Originals maps a config key to
+// the ID of the backup holding what the user had before the installer wrote
+// it, "" once uninstall put it back.
+type Manifest struct {
+	Version   int               `json:"version"`
+	Repo      string            `json:"repo,omitempty"`
+	RepoURL   string            `json:"repo_url,omitempty"`
+	Files     []ManifestEntry   `json:"files"`
+	Originals map[string]string `json:"originals,omitempty"`
+}
+
+// ManifestPath returns the location of the install manifest
+func ManifestPath() string {
+	return filepath.Join(StateDir(), "manifest.json")
+}
+
+var (
+	manifestMu      sync.Mutex
+	manifestPending = map[string]ManifestEntry{}
+)
+
+// fileSHA256 returns the hex SHA-256 of a file's content
+func fileSHA256(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return dataSHA256(data), nil
+}
+
+func dataSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// blobPath returns where the installed content of a source is kept, so
+// updates can merge against it
+func blobPath(sum string) string {
+	return filepath.Join(StateDir(), "blobs", sum)
+}
+
+// storeBlob keeps a copy of installed source content under its hash
+func storeBlob(sum string, data []byte) {
+	path := blobPath(sum)
+	if _, err := os.Stat(path); err == nil {
+		return
+	}
+	if os.MkdirAll(filepath.Dir(path), 0755) == nil {
+		os.WriteFile(path, data, 0644)
+	}
+}
+
+// ReadBlob returns the source content installed with the given hash
+func ReadBlob(sum string) ([]byte, error) {
+	return os.ReadFile(blobPath(sum))
+}
+
+// recordCopy records the files copied from src to dst; both may be
+// directories
+func recordCopy(src, dst string) {
+	if IsDryRun() {
+		return
+	}
+	src = strings.TrimSuffix(strings.TrimSuffix(src, "/*"), "/.")
+	if abs, err := filepath.Abs(src); err == nil {
+		src = abs
+	}
+	filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return nil
+		}
+		dest := filepath.Join(dst, rel)
+		data, err := os.ReadFile(dest)
+		if err != nil {
+			return nil
+		}
+		sum := dataSHA256(data)
+		storeBlob(sum, data)
+		recordEntry(ManifestEntry{Source: path, SourceSHA256: sum, Dest: dest, SHA256: sum})
+		return nil
+	})
+}
+
+// recordPatch records the new content of a file the installer modified in
+// place. A patched copy keeps its source, so repo changes are still seen.
+func recordPatch(path string) {
+	if IsDryRun() {
+		return
+	}
+	sum, err := fileSHA256(path)
+	if err != nil {
+		return
+	}
+	entry := ManifestEntry{Dest: path, SHA256: sum}
+	manifestMu.Lock()
+	if prev, ok := manifestPending[filepath.Clean(path)]; ok {
+		entry.Source, entry.SourceSHA256 = prev.Source, prev.SourceSHA256
+	}
+	manifestMu.Unlock()
+	if entry.Source == "" {
+		if saved := savedEntry(path); saved != nil {
+			entry.Source, entry.SourceSHA256 = saved.Source, saved.SourceSHA256
+		}
+	}
+	recordEntry(entry)
+}
+
+// recordUpdate records a file rewritten from a newer version of its source
+func recordUpdate(dest, source string) {
+	if IsDryRun() {
+		return
+	}
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return
+	}
+	sum, err := fileSHA256(dest)
+	if err != nil {
+		return
+	}
+	if abs, err := filepath.Abs(source); err == nil {
+		source = abs
+	}
+	sourceSum := dataSHA256(data)
+	storeBlob(sourceSum, data)
+	recordEntry(ManifestEntry{Source: source, SourceSHA256: sourceSum, Dest: dest, SHA256: sum})
+}
+
+func recordEntry(entry ManifestEntry) {
+	entry.Dest = filepath.Clean(entry.Dest)
+	if abs, err := filepath.Abs(entry.Dest); err == nil {
+		entry.Dest = abs
+	}
+	entry.Time = time.Now()
+
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+	manifestPending[entry.Dest] = entry
+}
+
+// savedEntry returns the entry of path in the saved manifest, if any
+func savedEntry(path string) *ManifestEntry {
+	manifest, err := LoadManifest()
+	if err != nil {
+		return nil
+	}
+	path = filepath.Clean(path)
+	for _, entry := range manifest.Files {
+		if entry.Dest == path {
+			return &entry
+		}
+	}
+	return nil
+}
+
+// forgetManifest drops the entries at or below path, for files the
+// installer removed or replaced with a backup
+func forgetManifest(path string) {
+	if IsDryRun() || path == "" {
+		return
+	}
+	path = filepath.Clean(path)
+
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+	for dest := range manifestPending {
+		if isWithin(dest, path) {
+			delete(manifestPending, dest)
+		}
+	}
+
+	manifest, err := LoadManifest()
+	if err != nil {
+		return
+	}
+	kept := manifest.Files[:0]
+	for _, entry := range manifest.Files {
+		if !isWithin(entry.Dest, path) {
+			kept = append(kept, entry)
+		}
+	}
+	if len(kept) != len(manifest.Files) {
+		manifest.Files = kept
+		writeManifest(manifest)
+	}
+}
+
+// LoadManifest reads the install manifest. It returns an empty manifest
+// when nothing was installed yet.
+func LoadManifest() (*Manifest, error) {
+	data, err := os.ReadFile(ManifestPath())
+	if os.IsNotExist(err) {
+		return &Manifest{Version: ManifestVersion}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", ManifestPath(), err)
+	}
+	if manifest.Version != ManifestVersion {
+		return nil, fmt.Errorf("manifest %s has unsupported version %d", ManifestPath(), manifest.Version)
+	}
+	return &manifest, nil
+}
+
+// SaveManifest merges the files written since the last save into the
+// manifest. Sources inside repoDir are stored relative to it; an empty
+// repoDir or repoURL keeps the one of the previous install.
+func SaveManifest(repoDir, repoURL string) error {
+	if IsDryRun() {
+		return nil
+	}
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+
+	manifest, err := LoadManifest()
+	if err != nil {
+		return err
+	}
+	if len(manifestPending) == 0 && (repoDir == "" || repoDir == manifest.Repo) && (repoURL == "" || repoURL == manifest.RepoURL) {
+		return nil
+	}
+	if repoDir != "" {
+		manifest.Repo = repoDir
+	}
+	if repoURL != "" {
+		manifest.RepoURL = repoURL
+	}
+
+	index := map[string]int{}
+	for i, entry := range manifest.Files {
+		index[entry.Dest] = i
+	}
+	for _, entry := range manifestPending {
+		if manifest.Repo != "" && filepath.IsAbs(entry.Source) && isWithin(entry.Source, manifest.Repo) {
+			entry.Source, _ = filepath.Rel(manifest.Repo, entry.Source)
+		}
+		if i, ok := index[entry.Dest]; ok {
+			manifest.Files[i] = entry
+		} else {
+			manifest.Files = append(manifest.Files, entry)
+		}
+	}
+	sort.Slice(manifest.Files, func(i, j int) bool { return manifest.Files[i].Dest < manifest.Files[j].Dest })
+
+	if err := writeManifest(manifest); err != nil {
+		return err
+	}
+	manifestPending = map[string]ManifestEntry{}
+	return nil
+}
+
+// installerWrote reports whether the config at path is the installer's: a
+// file it wrote is at or below path, or path links into the repository
+func installerWrote(manifest *Manifest, path string) bool {
+	if _, linked := repoLink(path); linked {
+		return true
+	}
+	return slices.ContainsFunc(manifest.Files, func(entry ManifestEntry) bool {
+		return isWithin(entry.Dest, filepath.Clean(path))
+	})
+}
+
+// recordOriginals notes an install backup as the one holding the user's own
+// version of the keys the installer has not written yet. Configs it already
+// wrote keep the backup of their first install.
+func recordOriginals(backupID string, keys []string) error {
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+
+	manifest, err := LoadManifest()
+	if err != nil {
+		return err
+	}
+	configPaths := ConfigPaths()
+	changed := false
+	for _, key := range keys {
+		path, ok := configPaths[key]
+		if !ok || installerWrote(manifest, path) {
+			continue
+		}
+		if manifest.Originals == nil {
+			manifest.Originals = map[string]string{}
+		}
+		manifest.Originals[key] = backupID
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return writeManifest(manifest)
+}
+
+// ForgetOriginal clears the original backup recorded for a config once it
+// was put back, so it is not restored again
+func ForgetOriginal(key string) error {
+	if IsDryRun() {
+		return nil
+	}
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+
+	manifest, err := LoadManifest()
+	if err != nil {
+		return err
+	}
+	if manifest.Originals[key] == "" {
+		return nil
+	}
+	manifest.Originals[key] = ""
+	return writeManifest(manifest)
+}
+
+// writeManifest saves the manifest atomically
+func writeManifest(manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	path := ManifestPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Drift is the difference between a manifest entry and the disk
+type Drift string
+
+const (
+	DriftModified Drift = "modified" // Changed locally since install
+	DriftUpstream Drift = "upstream" // Changed in the repository since install
+	DriftDeleted  Drift = "deleted"  // Removed since install
+)
+
+// DriftEntry is a manifest file that no longer matches what was installed
+type DriftEntry struct {
+	Drift Drift  `json:"drift"`
+	Dest  string `json:"dest"`
+	// Source is the repository file, set for upstream changes
+	Source string `json:"source,omitempty"`
+}
+
+// ManifestStatus is the result of comparing the manifest with the disk
+type ManifestStatus struct {
+	Tracked   int          `json:"tracked"`
+	Drifted   []DriftEntry `json:"drifted"`
+	Unchecked int          `json:"unchecked"` // Copied files whose source could not be compared
+}
+
+// Count returns how many files drifted in the given way
+func (s *ManifestStatus) Count(drift Drift) int {
+	n := 0
+	for _, entry := range s.Drifted {
+		if entry.Drift == drift {
+			n++
+		}
+	}
+	return n
+}
+
+// CompareManifest compares the manifest with the disk. Copied files are
+// also compared with their source in repoDir; an empty repoDir skips that.
+// A file changed both locally and upstream is reported twice.
+func CompareManifest(manifest *Manifest, repoDir string) *ManifestStatus {
+	status := &ManifestStatus{Tracked: len(manifest.Files), Drifted: []DriftEntry{}}
+	for _, entry := range manifest.Files {
+		sum, err := fileSHA256(entry.Dest)
+		switch {
+		case os.IsNotExist(err):
+			status.Drifted = append(status.Drifted, DriftEntry{Drift: DriftDeleted, Dest: entry.Dest})
+			continue
+		case err == nil && sum != entry.SHA256:
+			status.Drifted = append(status.Drifted, DriftEntry{Drift: DriftModified, Dest: entry.Dest})
+		}
+
+		if entry.Source == "" {
+			continue
+		}
+		source := entry.Source
+		if !filepath.IsAbs(source) {
+			if repoDir == "" {
+				status.Unchecked++
+				continue
+			}
+			source = filepath.Join(repoDir, source)
+		}
+		sourceSum, err := fileSHA256(source)
+		if err != nil {
+			status.Unchecked++
+			continue
+		}
+		if sourceSum != entry.SourceSHA256 {
+			status.Drifted = append(status.Drifted, DriftEntry{Drift: DriftUpstream, Dest: entry.Dest, Source: source})
+		}
+	}
+	return status
+}
+
+// Format renders the status grouped by drift
+func (s *ManifestStatus) Format() string {
+	var sb strings.Builder
+	if s.Tracked == 0 {
+		return "No installed files are tracked yet, run the installer first\n"
+	}
+	sections := []struct {
+		drift Drift
+		title string
+	}{
+		{DriftModified, "Changed locally"},
+		{DriftUpstream, "Changed in the repository"},
+		{DriftDeleted, "Deleted"},
+	}
+	for _, section := range sections {
+		if s.Count(section.drift) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "%s:\n", section.title)
+		for _, entry := range s.Drifted {
+			if entry.Drift == section.drift {
+				fmt.Fprintf(&sb, "  • %s\n", entry.Dest)
+			}
+		}
+		sb.WriteString("\n")
+	}
+	fmt.Fprintf(&sb, "%d tracked file(s): %d changed locally, %d changed in the repository, %d deleted\n",
+		s.Tracked, s.Count(DriftModified), s.Count(DriftUpstream), s.Count(DriftDeleted))
+	if s.Unchecked > 0 {
+		fmt.Fprintf(&sb, "%d file(s) could not be compared with the repository\n", s.Unchecked)
+	}
+	return sb.String()
+}
@@ -0,0 +1,105 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManifest(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+	manifestPending = map[string]ManifestEntry{}
+
+	repo := filepath.Join(t.TempDir(), "Javi.Dots")
+	os.MkdirAll(filepath.Join(repo, "nvim", "lua"), 0755)
+	os.WriteFile(filepath.Join(repo, "nvim", "init.lua"), []byte("require('config')\n"), 0644)
+	os.WriteFile(filepath.Join(repo, "nvim", "lua", "config.lua"), []byte("vim.o.nu = true\n"), 0644)
+	os.WriteFile(filepath.Join(repo, ".zshrc"), []byte("plugins=(git)\n"), 0644)
+
+	nvim := filepath.Join(home, ".config", "nvim")
+	zshrc := filepath.Join(home, ".zshrc")
+	starship := filepath.Join(home, ".config", "starship.toml")
+	if err := CopyDir(filepath.Join(repo, "nvim"), nvim); err != nil {
+		t.Fatal(err)
+	}
+	CopyFile(filepath.Join(repo, ".zshrc"), zshrc)
+	AppendToFile(zshrc, "\n# Added by Javi.Dots installer\n")
+	WriteFile(starship, []byte("format = ''\n"), 0644)
+	if err := SaveManifest(repo, "https://example.com/dots.git"); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, err := LoadManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("records copies and patches", func(t *testing.T) {
+		if len(manifest.Files) != 4 || manifest.Repo != repo {
+			t.Fatalf("expected 4 files from %s, got %+v", repo, manifest)
+		}
+		byDest := map[string]ManifestEntry{}
+		for _, entry := range manifest.Files {
+			byDest[entry.Dest] = entry
+		}
+		if e := byDest[filepath.Join(nvim, "lua", "config.lua")]; e.Source != filepath.Join("nvim", "lua", "config.lua") || e.SHA256 != e.SourceSHA256 {
+			t.Errorf("copied file should keep its repo-relative source and hash: %+v", e)
+		}
+		if e := byDest[zshrc]; e.Source != ".zshrc" || e.SHA256 == e.SourceSHA256 {
+			t.Errorf("patched copy should keep its source with a new hash: %+v", e)
+		}
+		if e := byDest[starship]; e.Source != "" || e.SHA256 == "" || e.Time.IsZero() {
+			t.Errorf("generated file should have no source: %+v", e)
+		}
+	})
+
+	t.Run("clean install has no drift", func(t *testing.T) {
+		status := CompareManifest(manifest, repo)
+		if len(status.Drifted) != 0 || status.Unchecked != 0 || status.Tracked != 4 {
+			t.Errorf("expected no drift, got %+v", status)
+		}
+	})
+
+	t.Run("detects local, upstream and deleted changes", func(t *testing.T) {
+		os.WriteFile(zshrc, []byte("plugins=(git fzf)\n"), 0644)
+		os.WriteFile(filepath.Join(repo, "nvim", "init.lua"), []byte("require('lazy')\n"), 0644)
+		os.Remove(starship)
+
+		status := CompareManifest(manifest, repo)
+		want := map[Drift]string{
+			DriftModified: zshrc,
+			DriftUpstream: filepath.Join(nvim, "init.lua"),
+			DriftDeleted:  starship,
+		}
+		if len(status.Drifted) != len(want) {
+			t.Fatalf("expected %d drifted files, got %+v", len(want), status.Drifted)
+		}
+		for _, entry := range status.Drifted {
+			if want[entry.Drift] != entry.Dest {
+				t.Errorf("unexpected drift %+v", entry)
+			}
+		}
+
+		if status := CompareManifest(manifest, ""); status.Unchecked != 3 || status.Count(DriftUpstream) != 0 {
+			t.Errorf("without a repo, sources should be unchecked: %+v", status)
+		}
+	})
+
+	t.Run("removed paths are forgotten", func(t *testing.T) {
+		RemoveAll(nvim)
+		manifest, _ := LoadManifest()
+		if len(manifest.Files) != 2 {
+			t.Errorf("nvim files should be dropped, got %+v", manifest.Files)
+		}
+	})
+
+	t.Run("dry run records nothing", func(t *testing.T) {
+		t.Setenv("GENTLEMAN_DRY_RUN", "1")
+		WriteFile(filepath.Join(home, ".tmux.conf"), []byte("set -g mouse on\n"), 0644)
+		if len(manifestPending) != 0 {
+			t.Errorf("dry run should not record files: %+v", manifestPending)
+		}
+	})
+}
@@ -0,0 +1,310 @@
+package system
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Resolution is how a merge conflict is settled
+type Resolution int
+
+const (
+	Unresolved Resolution = iota // Not picked yet, keeps the local version
+	KeepOurs                     // Keep the local lines
+	TakeTheirs                   // Take the upstream lines
+	KeepBoth                     // Local lines followed by the upstream ones
+)
+
+// MergeConflict is a hunk changed differently by both sides
+type MergeConflict struct {
+	Base       []string
+	Ours       []string
+	Theirs     []string
+	Resolution Resolution
+}
+
+// Lines returns the lines the conflict resolves to
+func (c *MergeConflict) Lines() []string {
+	switch c.Resolution {
+	case TakeTheirs:
+		return c.Theirs
+	case KeepBoth:
+		return append(slices.Clone(c.Ours), c.Theirs...)
+	}
+	return c.Ours
+}
+
+// MergeChunk is either merged lines or a conflict
+type MergeChunk struct {
+	Lines    []string
+	Conflict *MergeConflict
+}
+
+// Merge is the line-based three-way merge of a file
+type Merge struct {
+	Chunks []MergeChunk
+}
+
+// Conflicts returns the conflicts in file order
+func (m *Merge) Conflicts() []*MergeConflict {
+	var conflicts []*MergeConflict
+	for _, chunk := range m.Chunks {
+		if chunk.Conflict != nil {
+			conflicts = append(conflicts, chunk.Conflict)
+		}
+	}
+	return conflicts
+}
+
+// Unresolved returns how many conflicts are still unresolved
+func (m *Merge) Unresolved() int {
+	n := 0
+	for _, c := range m.Conflicts() {
+		if c.Resolution == Unresolved {
+			n++
+		}
+	}
+	return n
+}
+
+// ResolveAll settles every conflict the same way
+func (m *Merge) ResolveAll(r Resolution) {
+	for _, c := range m.Conflicts() {
+		c.Resolution = r
+	}
+}
+
+// Text returns the merged file. Unresolved conflicts keep the local lines.
+func (m *Merge) Text() string {
+	var sb strings.Builder
+	for _, chunk := range m.Chunks {
+		lines := chunk.Lines
+		if chunk.Conflict != nil {
+			lines = chunk.Conflict.Lines()
+		}
+		for _, line := range lines {
+			sb.WriteString(line)
+		}
+	}
+	return sb.String()
+}
+
+// splitLines splits text into lines that keep their newline, so joining
+// them gives the text back
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// maxDiffCells bounds the LCS table; larger changed regions are treated as
+// entirely different instead
+const maxDiffCells = 16 << 20
+
+// matchLines returns, for each line of a, the index of the line of b it
+// is matched with in a longest common subsequence, or -1
+func matchLines(a, b []string) []int {
+	match := make([]int, len(a))
+	for i := range match {
+		match[i] = -1
+	}
+
+	// Common prefix and suffix need no table
+	start := 0
+	for start < len(a) && start < len(b) && a[start] == b[start] {
+		match[start] = start
+		start++
+	}
+	endA, endB := len(a), len(b)
+	for endA > start && endB > start && a[endA-1] == b[endB-1] {
+		endA--
+		endB--
+		match[endA] = endB
+	}
+
+	n, m := endA-start, endB-start
+	if n == 0 || m == 0 || n*m > maxDiffCells {
+		return match
+	}
+
+	// lcs[i][j] is the LCS length of a[start+i:endA] and b[start+j:endB]
+	lcs := make([][]int32, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[start+i] == b[start+j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case a[start+i] == b[start+j]:
+			match[start+i] = start + j
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return match
+}
+
+// Merge3 merges the changes from base to ours and from base to theirs,
+// line by line as diff3 does. Hunks changed the same way on both sides, or
+// on one side only, merge cleanly; the others become conflicts.
+func Merge3(base, ours, theirs string) *Merge {
+	b, o, t := splitLines(base), splitLines(ours), splitLines(theirs)
+	mo, mt := matchLines(b, o), matchLines(b, t)
+	merge := &Merge{}
+
+	i, j, k := 0, 0, 0
+	for {
+		// Lines unchanged on both sides
+		start := i
+		for i < len(b) && mo[i] == j && mt[i] == k {
+			i++
+			j++
+			k++
+		}
+		if i > start {
+			merge.add(b[start:i])
+		}
+
+		// The changed region ends at the next base line both sides kept
+		next := i
+		for next < len(b) && (mo[next] < 0 || mt[next] < 0) {
+			next++
+		}
+		endB, endO, endT := len(b), len(o), len(t)
+		if next < len(b) {
+			endB, endO, endT = next, mo[next], mt[next]
+		}
+		if endB == i && endO == j && endT == k {
+			break
+		}
+
+		baseLines, ourLines, theirLines := b[i:endB], o[j:endO], t[k:endT]
+		switch {
+		case slices.Equal(ourLines, baseLines):
+			merge.add(theirLines)
+		case slices.Equal(theirLines, baseLines), slices.Equal(ourLines, theirLines):
+			merge.add(ourLines)
+		default:
+			merge.Chunks = append(merge.Chunks, MergeChunk{Conflict: &MergeConflict{
+				Base: baseLines, Ours: ourLines, Theirs: theirLines,
+			}})
+		}
+		i, j, k = endB, endO, endT
+	}
+	return merge
+}
+
+// add appends merged lines, joining them with the previous merged chunk
+func (m *Merge) add(lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	if n := len(m.Chunks); n > 0 && m.Chunks[n-1].Conflict == nil {
+		m.Chunks[n-1].Lines = append(m.Chunks[n-1].Lines, lines...)
+		return
+	}
+	m.Chunks = append(m.Chunks, MergeChunk{Lines: slices.Clone(lines)})
+}
+
+// diffContext is the number of unchanged lines around a unified diff hunk
+const diffContext = 3
+
+// UnifiedDiff returns the changes from a to b as a unified diff, or "" when
+// they are the same
+func UnifiedDiff(fromName, toName, a, b string) string {
+	if a == b {
+		return ""
+	}
+	from, to := splitLines(a), splitLines(b)
+
+	// Edit script: ' ' keeps a line, '-' drops one of from, '+' adds one of to
+	type edit struct {
+		op   byte
+		i, j int // line of from and of to the edit is at
+	}
+	var edits []edit
+	j := 0
+	for i, m := range matchLines(from, to) {
+		if m < 0 {
+			edits = append(edits, edit{'-', i, j})
+			continue
+		}
+		for ; j < m; j++ {
+			edits = append(edits, edit{'+', i, j})
+		}
+		edits = append(edits, edit{' ', i, j})
+		j++
+	}
+	for ; j < len(to); j++ {
+		edits = append(edits, edit{'+', len(from), j})
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+	for start := 0; start < len(edits); {
+		// A hunk runs from the first change until the changes are further
+		// apart than twice the context
+		for start < len(edits) && edits[start].op == ' ' {
+			start++
+		}
+		if start == len(edits) {
+			break
+		}
+		end := start
+		for k := start; k < len(edits) && k-end <= 2*diffContext; k++ {
+			if edits[k].op != ' ' {
+				end = k + 1
+			}
+		}
+		lo, hi := max(start-diffContext, 0), min(end+diffContext, len(edits))
+
+		fromLen, toLen := 0, 0
+		for _, e := range edits[lo:hi] {
+			if e.op != '+' {
+				fromLen++
+			}
+			if e.op != '-' {
+				toLen++
+			}
+		}
+		fromStart, toStart := edits[lo].i, edits[lo].j
+		if fromLen > 0 {
+			fromStart++
+		}
+		if toLen > 0 {
+			toStart++
+		}
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", fromStart, fromLen, toStart, toLen)
+		for _, e := range edits[lo:hi] {
+			line := ""
+			switch e.op {
+			case '-':
+				line = from[e.i]
+			default:
+				line = to[e.j]
+			}
+			sb.WriteByte(e.op)
+			sb.WriteString(line)
+			if !strings.HasSuffix(line, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		start = hi
+	}
+	return sb.String()
+}
@@ -0,0 +1,114 @@
+package system
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMerge3(t *testing.T) {
+	base := "a\nb\nc\nd\ne\n"
+	tests := []struct {
+		name      string
+		ours      string
+		theirs    string
+		want      string
+		conflicts int
+	}{
+		{"unchanged", base, base, base, 0},
+		{"only ours changed", "a\nB\nc\nd\ne\n", base, "a\nB\nc\nd\ne\n", 0},
+		{"only theirs changed", base, "a\nb\nc\nD\ne\n", "a\nb\nc\nD\ne\n", 0},
+		{"different hunks", "a\nB\nc\nd\ne\n", "a\nb\nc\nD\ne\n", "a\nB\nc\nD\ne\n", 0},
+		{"same change on both sides", "a\nX\nc\nd\ne\n", "a\nX\nc\nd\ne\n", "a\nX\nc\nd\ne\n", 0},
+		{"insert and delete", "z\na\nb\nc\nd\ne\n", "a\nb\nc\nd\n", "z\na\nb\nc\nd\n", 0},
+		{"same line changed differently", "a\nours\nc\nd\ne\n", "a\ntheirs\nc\nd\ne\n", "a\nours\nc\nd\ne\n", 1},
+		{"both append", base + "ours\n", base + "theirs\n", base + "ours\n", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merge := Merge3(base, tt.ours, tt.theirs)
+			if got := len(merge.Conflicts()); got != tt.conflicts {
+				t.Fatalf("expected %d conflict(s), got %d: %+v", tt.conflicts, got, merge.Chunks)
+			}
+			if got := merge.Text(); got != tt.want {
+				t.Errorf("merged text = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("resolutions", func(t *testing.T) {
+		merge := Merge3("a\nb\nc\n", "a\nours\nc\n", "a\ntheirs\nc\n")
+		conflict := merge.Conflicts()[0]
+		if merge.Unresolved() != 1 || len(conflict.Base) != 1 || conflict.Base[0] != "b\n" {
+			t.Fatalf("expected one unresolved conflict over b, got %+v", conflict)
+		}
+		want := map[Resolution]string{
+			KeepOurs:   "a\nours\nc\n",
+			TakeTheirs: "a\ntheirs\nc\n",
+			KeepBoth:   "a\nours\ntheirs\nc\n",
+		}
+		for resolution, text := range want {
+			merge.ResolveAll(resolution)
+			if got := merge.Text(); got != text {
+				t.Errorf("resolution %d: got %q, want %q", resolution, got, text)
+			}
+		}
+		if merge.Unresolved() != 0 {
+			t.Error("resolved conflicts should not count as unresolved")
+		}
+	})
+
+	t.Run("no base", func(t *testing.T) {
+		merge := Merge3("", "a\n", "b\n")
+		if len(merge.Conflicts()) != 1 || merge.Text() != "a\n" {
+			t.Errorf("without a base, differing files should conflict, got %+v", merge.Chunks)
+		}
+	})
+
+	t.Run("missing final newline", func(t *testing.T) {
+		merge := Merge3("a\nb\nc", "A\nb\nc", "a\nb\nc\nd")
+		if got := merge.Text(); got != "A\nb\nc\nd" {
+			t.Errorf("unexpected merge %q", got)
+		}
+	})
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	t.Run("same text", func(t *testing.T) {
+		if diff := UnifiedDiff("a", "b", "x\n", "x\n"); diff != "" {
+			t.Errorf("expected no diff, got %q", diff)
+		}
+	})
+
+	t.Run("one hunk with context", func(t *testing.T) {
+		from := "1\n2\n3\n4\n5\n6\n7\n"
+		to := "1\n2\n3\nfour\n5\n6\n7\n"
+		want := "--- old\n+++ new\n@@ -1,7 +1,7 @@\n 1\n 2\n 3\n-4\n+four\n 5\n 6\n 7\n"
+		if diff := UnifiedDiff("old", "new", from, to); diff != want {
+			t.Errorf("got\n%s\nwant\n%s", diff, want)
+		}
+	})
+
+	t.Run("distant changes get their own hunk", func(t *testing.T) {
+		var from, to strings.Builder
+		for i := range 20 {
+			fmt.Fprintf(&from, "%d\n", i)
+			if i == 1 || i == 18 {
+				fmt.Fprintf(&to, "changed %d\n", i)
+			} else {
+				fmt.Fprintf(&to, "%d\n", i)
+			}
+		}
+		diff := UnifiedDiff("old", "new", from.String(), to.String())
+		if !strings.Contains(diff, "@@ -1,5 +1,5 @@\n") || !strings.Contains(diff, "@@ -16,5 +16,5 @@\n") {
+			t.Errorf("expected two hunks:\n%s", diff)
+		}
+	})
+
+	t.Run("new file and missing newline", func(t *testing.T) {
+		want := "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n\\ No newline at end of file\n"
+		if diff := UnifiedDiff("old", "new", "", "a\nb"); diff != want {
+			t.Errorf("got\n%q\nwant\n%q", diff, want)
+		}
+	})
+}
@@ -0,0 +1,372 @@
+package system
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// PackageManager installs packages by their logical names, mapping them to
+// the real package names of the tool
+type PackageManager interface {
+	Name() string
+	// Install installs packages, streaming the output to onLog
+	Install(ctx context.Context, onLog LogCallback, packages ...string) *ExecResult
+	// IsInstalled reports whether every real package behind pkg is installed
+	IsInstalled(pkg string) bool
+	// Update refreshes the package index
+	Update(ctx context.Context, onLog LogCallback) *ExecResult
+	// Remove uninstalls packages, streaming the output to onLog
+	Remove(ctx context.Context, onLog LogCallback, packages ...string) *ExecResult
+}
+
+// Package manager names
+const (
+	ManagerBrew    = "brew"
+	ManagerPacman  = "pacman"
+	ManagerApt     = "apt"
+	ManagerDnf     = "dnf"
+	ManagerPkg     = "pkg"
+	ManagerFlatpak = "flatpak"
+	ManagerApk     = "apk"
+	ManagerZypper  = "zypper"
+	ManagerXbps    = "xbps"
+)
+
+// casked marks a brew package that is installed with --cask
+const casked = "--cask "
+
+// packageManager is a PackageManager driven by a command line tool
+type packageManager struct {
+	name string
+	// run runs a subcommand of the tool
+	run     func(ctx context.Context, args string, onLog LogCallback) *ExecResult
+	install string   // subcommand installing packages
+	update  string   // subcommand refreshing the index
+	remove  string   // subcommand removing packages
+	query   []string // command that succeeds when a package is installed
+	// names maps logical names to real packages (space separated). An empty
+	// value means the package is not needed, a missing one that it has the
+	// same name.
+	names map[string]string
+}
+
+func (p *packageManager) Name() string {
+	return p.name
+}
+
+// realNames resolves logical package names, keeping their order
+func (p *packageManager) realNames(packages []string) []string {
+	var real []string
+	for _, pkg := range packages {
+		name, ok := p.names[pkg]
+		if !ok {
+			name = pkg
+		}
+		if strings.HasPrefix(name, casked) {
+			real = append(real, name)
+			continue
+		}
+		real = append(real, strings.Fields(name)...)
+	}
+	return real
+}
+
+func (p *packageManager) Install(ctx context.Context, onLog LogCallback, packages ...string) *ExecResult {
+	return p.apply(ctx, p.install, onLog, packages)
+}
+
+func (p *packageManager) Remove(ctx context.Context, onLog LogCallback, packages ...string) *ExecResult {
+	return p.apply(ctx, p.remove, onLog, packages)
+}
+
+// apply runs subcommand on the real packages, casks in a separate call
+func (p *packageManager) apply(ctx context.Context, subcommand string, onLog LogCallback, packages []string) *ExecResult {
+	var plain, casks []string
+	for _, name := range p.realNames(packages) {
+		if cask, ok := strings.CutPrefix(name, casked); ok {
+			casks = append(casks, cask)
+		} else {
+			plain = append(plain, name)
+		}
+	}
+
+	result := &ExecResult{}
+	if len(plain) > 0 {
+		result = p.run(ctx, subcommand+" "+strings.Join(plain, " "), onLog)
+		if result.Error != nil {
+			return result
+		}
+	}
+	if len(casks) > 0 {
+		result = p.run(ctx, subcommand+" "+casked+strings.Join(casks, " "), onLog)
+	}
+	return result
+}
+
+// IsInstalled queries the tool directly; the query changes nothing, so it
+// also runs in dry-run mode
+func (p *packageManager) IsInstalled(pkg string) bool {
+	for _, name := range p.realNames([]string{pkg}) {
+		name = strings.TrimPrefix(name, casked)
+		args := append(append([]string{}, p.query[1:]...), name)
+		if exec.Command(p.query[0], args...).Run() != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *packageManager) Update(ctx context.Context, onLog LogCallback) *ExecResult {
+	return p.run(ctx, p.update, onLog)
+}
+
+// geteuid is swapped in tests to run as root or as a user
+var geteuid = os.Geteuid
+
+// Privileged prefixes command with sudo, unless the installer already runs
+// as root: minimal containers, Alpine above all, often have no sudo
+func Privileged(command string) string {
+	if geteuid() == 0 {
+		return command
+	}
+	return "sudo " + command
+}
+
+// runLocked runs a package manager command that needs no sudo
+func runLocked(ctx context.Context, command string, onLog LogCallback) *ExecResult {
+	pkgManagerMu.Lock()
+	defer pkgManagerMu.Unlock()
+	return RunWithLogs(ctx, command, nil, onLog)
+}
+
+// NewBrewManager returns Homebrew. Casks only exist on macOS, so GUI apps
+// map to other names on Linux.
+func NewBrewManager(info *SystemInfo) PackageManager {
+	names := map[string]string{
+		"build-tools": "",
+		"file":        "",
+		"c-compiler":  "gcc",
+		"wezterm":     "wez/wezterm-linuxbrew/wezterm",
+	}
+	if info.OS == OSMac {
+		for _, app := range []string{"alacritty", "wezterm", "kitty", "ghostty", "obsidian", "zed"} {
+			names[app] = casked + app
+		}
+		for _, font := range Fonts {
+			names[font.Package] = casked + "font-" + font.Package
+		}
+	}
+	return &packageManager{
+		name: ManagerBrew,
+		run: func(ctx context.Context, args string, onLog LogCallback) *ExecResult {
+			return RunBrewWithLogs(ctx, args, nil, onLog)
+		},
+		install: "install",
+		update:  "update",
+		remove:  "uninstall",
+		query:   []string{GetBrewPrefix() + "/bin/brew", "list"},
+		names:   names,
+	}
+}
+
+// NewPacmanManager returns pacman for Arch Linux
+func NewPacmanManager() PackageManager {
+	return &packageManager{
+		name: ManagerPacman,
+		run: func(ctx context.Context, args string, onLog LogCallback) *ExecResult {
+			return RunSudoWithLogs(ctx, "pacman "+args, nil, onLog)
+		},
+		install: "-S --needed --noconfirm",
+		update:  "-Syu --noconfirm",
+		remove:  "-Rns --noconfirm",
+		query:   []string{"pacman", "-Q"},
+		names: map[string]string{
+			"build-tools": "base-devel",
+			"c-compiler":  "gcc",
+			"node":        "nodejs",
+		},
+	}
+}
+
+// NewAptManager returns apt for Debian and Ubuntu
+func NewAptManager() PackageManager {
+	return &packageManager{
+		name: ManagerApt,
+		run: func(ctx context.Context, args string, onLog LogCallback) *ExecResult {
+			return RunSudoWithLogs(ctx, "apt-get "+args, nil, onLog)
+		},
+		install: "install -y",
+		update:  "update",
+		remove:  "remove -y",
+		query:   []string{"dpkg", "-s"},
+		names: map[string]string{
+			"build-tools": "build-essential",
+			"c-compiler":  "gcc",
+			"node":        "nodejs",
+			"fd":          "fd-find",
+		},
+	}
+}
+
+// NewDnfManager returns dnf for Fedora and RHEL
+func NewDnfManager() PackageManager {
+	return &packageManager{
+		name: ManagerDnf,
+		run: func(ctx context.Context, args string, onLog LogCallback) *ExecResult {
+			return RunSudoWithLogs(ctx, "dnf "+args, nil, onLog)
+		},
+		install: "install -y",
+		update:  "makecache",
+		remove:  "remove -y",
+		query:   []string{"rpm", "-q"},
+		names: map[string]string{
+			"build-tools": "@development-tools",
+			"c-compiler":  "gcc",
+			"node":        "nodejs",
+		},
+	}
+}
+
+// NewApkManager returns apk for Alpine Linux
+func NewApkManager() PackageManager {
+	return &packageManager{
+		name: ManagerApk,
+		run: func(ctx context.Context, args string, onLog LogCallback) *ExecResult {
+			return runLocked(ctx, Privileged("apk "+args), onLog)
+		},
+		install: "add",
+		update:  "update",
+		remove:  "del",
+		query:   []string{"apk", "info", "-e"},
+		names: map[string]string{
+			"build-tools": "build-base",
+			"c-compiler":  "gcc",
+			"node":        "nodejs",
+			// apk also installs the command line tools, named as in Alpine
+			"bash-completion@2": "bash-completion",
+			"powerlevel10k":     "zsh-theme-powerlevel10k",
+			"tree-sitter":       "tree-sitter-cli",
+			"carapace":          "", // only in the testing repository
+			"zsh-autocomplete":  "",
+		},
+	}
+}
+
+// NewZypperManager returns zypper for openSUSE and SLES
+func NewZypperManager() PackageManager {
+	return &packageManager{
+		name: ManagerZypper,
+		run: func(ctx context.Context, args string, onLog LogCallback) *ExecResult {
+			return runLocked(ctx, Privileged("zypper --non-interactive "+args), onLog)
+		},
+		install: "install",
+		update:  "refresh",
+		remove:  "remove",
+		query:   []string{"rpm", "-q"},
+		names: map[string]string{
+			"build-tools": "gcc gcc-c++ make",
+			"c-compiler":  "gcc",
+			"node":        "nodejs",
+		},
+	}
+}
+
+// NewXbpsManager returns xbps for Void Linux
+func NewXbpsManager() PackageManager {
+	return &packageManager{
+		name: ManagerXbps,
+		run: func(ctx context.Context, args string, onLog LogCallback) *ExecResult {
+			return runLocked(ctx, Privileged(args), onLog)
+		},
+		install: "xbps-install -y",
+		update:  "xbps-install -S",
+		remove:  "xbps-remove -R -y",
+		query:   []string{"xbps-query"},
+		names: map[string]string{
+			"build-tools": "base-devel",
+			"c-compiler":  "gcc",
+		},
+	}
+}
+
+// NewPkgManager returns the Termux package manager
+func NewPkgManager() PackageManager {
+	return &packageManager{
+		name: ManagerPkg,
+		run: func(ctx context.Context, args string, onLog LogCallback) *ExecResult {
+			return RunPkgWithLogs(ctx, args, nil, onLog)
+		},
+		install: "install -y",
+		update:  "update",
+		remove:  "uninstall -y",
+		query:   []string{"dpkg", "-s"},
+		names: map[string]string{
+			"build-tools": "",
+			"c-compiler":  "clang",
+			"node":        "nodejs",
+			"coreutils":   "",
+			"tree-sitter": "",
+			"carapace":    "",
+			"atuin":       "",
+		},
+	}
+}
+
+// NewFlatpakManager returns Flatpak with the Flathub remote
+func NewFlatpakManager() PackageManager {
+	return &packageManager{
+		name: ManagerFlatpak,
+		run: func(ctx context.Context, args string, onLog LogCallback) *ExecResult {
+			return runLocked(ctx, "flatpak "+args, onLog)
+		},
+		install: "install -y flathub",
+		update:  "update -y --appstream",
+		remove:  "uninstall -y",
+		query:   []string{"flatpak", "info"},
+		names: map[string]string{
+			"obsidian": "md.obsidian.Obsidian",
+			"zed":      "dev.zed.Zed",
+		},
+	}
+}
+
+// PackageManagerFor returns the native package manager of the system,
+// Homebrew on macOS
+func PackageManagerFor(info *SystemInfo) PackageManager {
+	switch info.OS {
+	case OSTermux:
+		return NewPkgManager()
+	case OSArch:
+		return NewPacmanManager()
+	case OSDebian, OSLinux:
+		// apt is the fallback for unrecognized Linux distributions
+		return NewAptManager()
+	case OSFedora:
+		return NewDnfManager()
+	case OSAlpine:
+		return NewApkManager()
+	case OSOpenSUSE:
+		return NewZypperManager()
+	case OSVoid:
+		return NewXbpsManager()
+	}
+	if info.IsTermux {
+		return NewPkgManager()
+	}
+	return NewBrewManager(info)
+}
+
+// ToolManagerFor returns the manager for command line tools: Homebrew
+// everywhere, so versions match across systems, except on Termux and on
+// Alpine, where Homebrew does not run on musl
+func ToolManagerFor(info *SystemInfo) PackageManager {
+	if info.IsTermux || info.OS == OSTermux {
+		return NewPkgManager()
+	}
+	if info.OS == OSAlpine {
+		return NewApkManager()
+	}
+	return NewBrewManager(info)
+}
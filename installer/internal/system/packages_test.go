@@ -0,0 +1,182 @@
+package system
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// plannedInstall installs packages in dry-run mode and returns the commands
+func plannedInstall(t *testing.T, pm PackageManager, packages ...string) []string {
+	t.Helper()
+	t.Setenv("GENTLEMAN_DRY_RUN", "1")
+	TakePlan()
+	if result := pm.Install(context.Background(), nil, packages...); result.Error != nil {
+		t.Fatalf("dry-run install failed: %v", result.Error)
+	}
+	var commands []string
+	for _, action := range TakePlan() {
+		if action.Kind != PlanPackage {
+			t.Errorf("install should be planned as a package action, got %s", action)
+		}
+		commands = append(commands, strings.TrimPrefix(action.Target, GetBrewPrefix()+"/bin/"))
+	}
+	return commands
+}
+
+// asUser makes Privileged see a regular user or root for the test
+func asUser(t *testing.T, uid int) {
+	t.Helper()
+	saved := geteuid
+	geteuid = func() int { return uid }
+	t.Cleanup(func() { geteuid = saved })
+}
+
+func TestPackageManagerInstall(t *testing.T) {
+	asUser(t, 1000)
+	tests := []struct {
+		name     string
+		pm       PackageManager
+		packages []string
+		want     []string
+	}{
+		{"pacman maps logical names", NewPacmanManager(), []string{"build-tools", "git", "node"},
+			[]string{"sudo pacman -S --needed --noconfirm base-devel git nodejs"}},
+		{"apt", NewAptManager(), []string{"build-tools", "fd"},
+			[]string{"sudo apt-get install -y build-essential fd-find"}},
+		{"dnf", NewDnfManager(), []string{"build-tools", "curl"},
+			[]string{"sudo dnf install -y @development-tools curl"}},
+		{"pkg skips packages Termux does not need", NewPkgManager(), []string{"neovim", "c-compiler", "tree-sitter"},
+			[]string{"pkg install -y neovim clang"}},
+		{"flatpak uses app IDs", NewFlatpakManager(), []string{"obsidian"},
+			[]string{"flatpak install -y flathub md.obsidian.Obsidian"}},
+		{"brew installs casks separately on macOS", NewBrewManager(&SystemInfo{OS: OSMac}), []string{"git", "ghostty", "iosevka-term-nerd-font"},
+			[]string{"brew install git", "brew install --cask ghostty font-iosevka-term-nerd-font"}},
+		{"brew on Linux has no casks", NewBrewManager(&SystemInfo{OS: OSLinux}), []string{"wezterm"},
+			[]string{"brew install wez/wezterm-linuxbrew/wezterm"}},
+		{"apk", NewApkManager(), []string{"build-tools", "alacritty"},
+			[]string{"sudo apk add build-base alacritty"}},
+		{"zypper", NewZypperManager(), []string{"build-tools", "unzip"},
+			[]string{"sudo zypper --non-interactive install gcc gcc-c++ make unzip"}},
+		{"xbps", NewXbpsManager(), []string{"build-tools", "fontconfig"},
+			[]string{"sudo xbps-install -y base-devel fontconfig"}},
+		{"nothing to install", NewPkgManager(), []string{"build-tools"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := plannedInstall(t, tt.pm, tt.packages...)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPackageManagerUpdate(t *testing.T) {
+	t.Setenv("GENTLEMAN_DRY_RUN", "1")
+	TakePlan()
+	NewAptManager().Update(context.Background(), nil)
+	NewPacmanManager().Update(context.Background(), nil)
+
+	plan := TakePlan()
+	if len(plan) != 2 || plan[0].Target != "sudo apt-get update" || plan[1].Target != "sudo pacman -Syu --noconfirm" {
+		t.Errorf("unexpected update commands: %v", plan)
+	}
+}
+
+func TestPackageManagerIsInstalled(t *testing.T) {
+	pm := &packageManager{query: []string{"true"}, names: map[string]string{"absent": "", "gone": "x"}}
+	if !pm.IsInstalled("git") || !pm.IsInstalled("absent") {
+		t.Error("a successful query should report the package as installed")
+	}
+	pm.query = []string{"false"}
+	if pm.IsInstalled("gone") {
+		t.Error("a failing query should report the package as missing")
+	}
+	if !pm.IsInstalled("absent") {
+		t.Error("packages not needed by the manager count as installed")
+	}
+}
+
+func TestPackageManagerAsRoot(t *testing.T) {
+	asUser(t, 0)
+	tests := []struct {
+		name     string
+		pm       PackageManager
+		packages []string
+		want     []string
+	}{
+		{"apk runs without sudo", NewApkManager(), []string{"build-tools"}, []string{"apk add build-base"}},
+		{"zypper runs without sudo", NewZypperManager(), []string{"unzip"}, []string{"zypper --non-interactive install unzip"}},
+		{"xbps runs without sudo", NewXbpsManager(), []string{"fontconfig"}, []string{"xbps-install -y fontconfig"}},
+		{"apk installs the shell tools", ToolManagerFor(&SystemInfo{OS: OSAlpine}), toolPackages(t, "bash"),
+			[]string{"apk add bash bash-completion zoxide atuin fzf starship"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := plannedInstall(t, tt.pm, tt.packages...)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// toolPackages returns the logical packages of a registered tool
+func toolPackages(t *testing.T, id string) []string {
+	t.Helper()
+	tool, ok := FindTool(id)
+	if !ok {
+		t.Fatalf("unknown tool %s", id)
+	}
+	return tool.PackagesFor(ManagerApk)
+}
+
+func TestPackageManagerFor(t *testing.T) {
+	tests := []struct {
+		info   SystemInfo
+		native string
+		tools  string
+		name   string
+	}{
+		{SystemInfo{OS: OSMac}, ManagerBrew, ManagerBrew, "macOS"},
+		{SystemInfo{OS: OSArch}, ManagerPacman, ManagerBrew, "Arch"},
+		{SystemInfo{OS: OSDebian}, ManagerApt, ManagerBrew, "Debian"},
+		{SystemInfo{OS: OSFedora}, ManagerDnf, ManagerBrew, "Fedora"},
+		{SystemInfo{OS: OSLinux}, ManagerApt, ManagerBrew, "other Linux"},
+		{SystemInfo{OS: OSTermux, IsTermux: true}, ManagerPkg, ManagerPkg, "Termux"},
+		{SystemInfo{OS: OSAlpine}, ManagerApk, ManagerApk, "Alpine"},
+		{SystemInfo{OS: OSOpenSUSE}, ManagerZypper, ManagerBrew, "openSUSE"},
+		{SystemInfo{OS: OSVoid}, ManagerXbps, ManagerBrew, "Void"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PackageManagerFor(&tt.info).Name(); got != tt.native {
+				t.Errorf("native manager = %s, want %s", got, tt.native)
+			}
+			if got := ToolManagerFor(&tt.info).Name(); got != tt.tools {
+				t.Errorf("tool manager = %s, want %s", got, tt.tools)
+			}
+		})
+	}
+}
+
+func TestPackageManagerRemove(t *testing.T) {
+	asUser(t, 1000)
+	t.Setenv("GENTLEMAN_DRY_RUN", "1")
+	TakePlan()
+	NewBrewManager(&SystemInfo{OS: OSMac}).Remove(context.Background(), nil, "tmux", "ghostty")
+	NewAptManager().Remove(context.Background(), nil, "fd")
+	NewXbpsManager().Remove(context.Background(), nil, "fish")
+
+	var got []string
+	for _, action := range TakePlan() {
+		got = append(got, strings.TrimPrefix(action.Target, GetBrewPrefix()+"/bin/"))
+	}
+	want := []string{"brew uninstall tmux", "brew uninstall --cask ghostty", "sudo apt-get remove -y fd-find", "sudo xbps-remove -R -y fish"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -16,10 +16,16 @@ fi
 
 export ZSH="$HOME/.oh-my-zsh"
 
+alias fzfbat='fzf --preview="bat --theme=gruvbox-dark --color=always {}"'
+
+# >>> gentleman:fzf >>>
+eval "$(fzf --zsh)"
+# <<< gentleman:fzf <<<
+eval "$(zoxide init zsh)"
+
+# >>> gentleman:wm >>>
 WM_VAR="/$TMUX"
-# change with ZELLIJ
 WM_CMD="tmux"
-# change with zellij
 
 function start_if_needed() {
     if [[ $- == *i* ]] && [[ -z "${WM_VAR#/}" ]] && [[ -t 1 ]]; then
@@ -27,12 +33,8 @@ function start_if_needed() {
     fi
 }
 
-alias fzfbat='fzf --preview="bat --theme=gruvbox-dark --color=always {}"'
-
-eval "$(fzf --zsh)"
-eval "$(zoxide init zsh)"
-
 start_if_needed
+# <<< gentleman:wm <<<
 `
 
 	tests := []struct {
@@ -150,17 +152,17 @@ if test (uname) = Darwin
     end
 end
 
+# >>> gentleman:wm >>>
 if not set -q TMUX
     tmux
 end
-
-#if not set -q ZELLIJ 
-#  zellij
-#end
+# <<< gentleman:wm <<<
 
 starship init fish | source
 zoxide init fish | source
+# >>> gentleman:fzf >>>
 fzf --fish | source
+# <<< gentleman:fzf <<<
 
 alias fzfbat='fzf --preview="bat --theme=gruvbox-dark --color=always {}"'
 `
@@ -270,7 +272,8 @@ def fzfbat [] {
 source ~/.zoxide.nu
 source ~/.cache/carapace/init.nu
 
-let MULTIPLEXER = "tmux" 
+# >>> gentleman:wm >>>
+let MULTIPLEXER = "tmux"
 let MULTIPLEXER_ENV_PREFIX = "TMUX"
 
 def start_multiplexer [] {
@@ -280,6 +283,7 @@ def start_multiplexer [] {
 }
 
 start_multiplexer
+# <<< gentleman:wm <<<
 `
 
 	tests := []struct {
@@ -362,6 +366,49 @@ start_multiplexer
 	}
 }
 
+// TestPatchShippedConfigsForWM checks the blocks of the configs the
+// repository ships: tmux, the default, leaves them as they are and uninstall
+// strips every multiplexer auto-start
+func TestPatchShippedConfigsForWM(t *testing.T) {
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+	repo := filepath.Join("..", "..", "..")
+	patches := map[string]func(path, wm string) error{
+		"GentlemanZsh/.zshrc":            func(path, wm string) error { return PatchZshForWM(path, wm, true) },
+		"GentlemanFish/fish/config.fish": func(path, wm string) error { return PatchFishForWM(path, wm, true) },
+		"GentlemanNushell/config.nu":     PatchNushellForWM,
+	}
+	for src, patch := range patches {
+		t.Run(src, func(t *testing.T) {
+			shipped, err := os.ReadFile(filepath.Join(repo, src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(t.TempDir(), filepath.Base(src))
+			os.WriteFile(path, shipped, 0644)
+
+			if err := patch(path, "tmux"); err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := os.ReadFile(path); string(got) != string(shipped) {
+				t.Errorf("tmux should keep the shipped config, got:\n%s", got)
+			}
+
+			if err := patch(path, "zellij"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := StripInstallerBlocks(path); err != nil {
+				t.Fatal(err)
+			}
+			got, _ := os.ReadFile(path)
+			for _, wm := range []string{"tmux", "zellij", "TMUX", "ZELLIJ"} {
+				if strings.Contains(string(got), wm) {
+					t.Errorf("stripping the blocks should remove %q, got:\n%s", wm, got)
+				}
+			}
+		})
+	}
+}
+
 func TestPatchZshForWM_FileNotFound(t *testing.T) {
 	err := PatchZshForWM("/nonexistent/path/.zshrc", "none", true)
 	if err == nil {
@@ -382,3 +429,99 @@ func TestPatchNushellForWM_FileNotFound(t *testing.T) {
 		t.Error("Expected error for non-existent file, got nil")
 	}
 }
+
+func TestPatchBashForWM(t *testing.T) {
+	bashrcContent := `alias fzfbat='fzf --preview="bat --theme=gruvbox-dark --color=always {}"'
+
+eval "$(zoxide init bash)"
+eval "$(starship init bash)"
+`
+
+	tests := []struct {
+		name           string
+		wm             string
+		wantContain    []string
+		wantNotContain []string
+	}{
+		{
+			name: "WM tmux should start tmux outside of it",
+			wm:   "tmux",
+			wantContain: []string{
+				"# >>> gentleman:wm >>>",
+				`[[ -z "$TMUX" ]]`,
+				"exec tmux",
+				"# <<< gentleman:wm <<<",
+			},
+			wantNotContain: []string{"zellij"},
+		},
+		{
+			name: "WM zellij should start zellij outside of it",
+			wm:   "zellij",
+			wantContain: []string{
+				`[[ -z "$ZELLIJ" ]]`,
+				"exec zellij",
+			},
+			wantNotContain: []string{"tmux"},
+		},
+		{
+			name:           "WM none should not start a multiplexer",
+			wm:             "none",
+			wantContain:    []string{`eval "$(starship init bash)"`},
+			wantNotContain: []string{"gentleman:wm", "exec"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bashrcPath := filepath.Join(t.TempDir(), ".bashrc")
+			if err := os.WriteFile(bashrcPath, []byte(bashrcContent), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := PatchBashForWM(bashrcPath, tt.wm); err != nil {
+				t.Fatalf("PatchBashForWM() error = %v", err)
+			}
+
+			content, _ := os.ReadFile(bashrcPath)
+			contentStr := string(content)
+			if !strings.HasPrefix(contentStr, bashrcContent) {
+				t.Errorf("the shipped config should be kept, got:\n%s", contentStr)
+			}
+			for _, want := range tt.wantContain {
+				if !strings.Contains(contentStr, want) {
+					t.Errorf("Expected content to contain %q, got:\n%s", want, contentStr)
+				}
+			}
+			for _, notWant := range tt.wantNotContain {
+				if strings.Contains(contentStr, notWant) {
+					t.Errorf("Expected content NOT to contain %q, got:\n%s", notWant, contentStr)
+				}
+			}
+		})
+	}
+
+	t.Run("switching WM replaces the block", func(t *testing.T) {
+		bashrcPath := filepath.Join(t.TempDir(), ".bashrc")
+		os.WriteFile(bashrcPath, []byte(bashrcContent), 0644)
+
+		for _, wm := range []string{"tmux", "zellij", "zellij"} {
+			if err := PatchBashForWM(bashrcPath, wm); err != nil {
+				t.Fatal(err)
+			}
+		}
+		content, _ := os.ReadFile(bashrcPath)
+		if n := strings.Count(string(content), "gentleman:wm >>>"); n != 1 {
+			t.Errorf("expected a single wm block, got %d:\n%s", n, content)
+		}
+		if strings.Contains(string(content), "tmux") {
+			t.Errorf("the tmux auto-start should be replaced, got:\n%s", content)
+		}
+
+		if err := PatchBashForWM(bashrcPath, "none"); err != nil {
+			t.Fatal(err)
+		}
+		if content, _ := os.ReadFile(bashrcPath); string(content) != bashrcContent {
+			t.Errorf("removing the block should restore the file, got %q", content)
+		}
+	})
+}
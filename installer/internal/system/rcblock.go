@@ -0,0 +1,384 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Every change the installer makes to a shell rc file lives in a named
+// block, so it can be updated in place and removed without touching the
+// rest of the file:
+//
+//	# >>> gentleman:local-bin >>>
+//	export PATH="$HOME/.local/bin:$PATH"
+//	# <<< gentleman:local-bin <<<
+
+// Block ids used by the installer
+const (
+	RcBlockLocalBin     = "local-bin"     // ~/.local/bin on PATH
+	RcBlockBrew         = "brew"          // Homebrew shellenv
+	RcBlockAutoStart    = "autostart"     // Termux shell auto-start in .bashrc
+	RcBlockDefaultShell = "default-shell" // Zellij default_shell
+	RcBlockWM           = "wm"            // Multiplexer auto-start in the shell config
+	RcBlockFzf          = "fzf"           // fzf key bindings in the shell config
+	RcBlockTheme        = "theme"         // Colors of the selected theme
+)
+
+// RcBlockShell is the block listing a shell in etc/shells
+func RcBlockShell(shell string) string {
+	return "shell-" + shell
+}
+
+// legacyRcBlocks are the blocks older installers appended without end
+// markers: a marker comment and the lines after it, through the end line
+// ("" means the single line after the marker). They are replaced by the
+// managed block with the same id.
+var legacyRcBlocks = []struct{ id, marker, end string }{
+	{RcBlockLocalBin, "# Added by Javi.Dots installer", ""},
+	{RcBlockAutoStart, "# Gentleman.Dots shell auto-start", "fi"},
+}
+
+// rcComment returns the line comment of the rc file at path
+func rcComment(path string) string {
+	switch filepath.Ext(path) {
+	case ".kdl":
+		return "//"
+	case ".lua":
+		return "--"
+	}
+	return "#"
+}
+
+func rcBlockStart(comment, id string) string {
+	return fmt.Sprintf("%s >>> gentleman:%s >>>", comment, id)
+}
+
+func rcBlockStop(comment, id string) string {
+	return fmt.Sprintf("%s <<< gentleman:%s <<<", comment, id)
+}
+
+// RcBlockMarkers returns the lines that start and end block id in the rc
+// file at path
+func RcBlockMarkers(path, id string) (start, stop string) {
+	comment := rcComment(path)
+	return rcBlockStart(comment, id), rcBlockStop(comment, id)
+}
+
+// FormatRcBlock returns block id holding body, with its markers, as it is
+// written to the rc file at path
+func FormatRcBlock(path, id, body string) string {
+	comment := rcComment(path)
+	return rcBlockStart(comment, id) + "\n" + strings.TrimRight(body, "\n") + "\n" + rcBlockStop(comment, id) + "\n"
+}
+
+// rcBlockAt returns the id and last line of the block starting at lines[i],
+// managed or legacy, if one starts there. A start marker without its end
+// is a block of one line.
+func rcBlockAt(lines []string, i int, comment string) (string, int, bool) {
+	line := strings.TrimSpace(lines[i])
+	prefix, suffix := comment+" >>> gentleman:", " >>>"
+	if strings.HasPrefix(line, prefix) && strings.HasSuffix(line, suffix) {
+		id := strings.TrimSuffix(strings.TrimPrefix(line, prefix), suffix)
+		for j := i + 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) == rcBlockStop(comment, id) {
+				return id, j, true
+			}
+		}
+		return id, i, true
+	}
+
+	for _, block := range legacyRcBlocks {
+		if line != block.marker {
+			continue
+		}
+		if block.end == "" {
+			return block.id, min(i+1, len(lines)-1), true
+		}
+		for j := i + 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) == block.end {
+				return block.id, j, true
+			}
+		}
+		return block.id, i, true
+	}
+	return "", 0, false
+}
+
+// removeRcBlocks drops the blocks whose id drop reports true for, with the
+// blank line before each. It reports whether any was dropped.
+func removeRcBlocks(content, comment string, drop func(id string) bool) (string, bool) {
+	lines := strings.Split(content, "\n")
+	var kept []string
+	found := false
+	for i := 0; i < len(lines); i++ {
+		id, end, ok := rcBlockAt(lines, i, comment)
+		if !ok || !drop(id) {
+			kept = append(kept, lines[i])
+			continue
+		}
+		found = true
+		if n := len(kept); n > 0 && strings.TrimSpace(kept[n-1]) == "" {
+			kept = kept[:n-1]
+		}
+		i = end
+	}
+	return strings.Join(kept, "\n"), found
+}
+
+// setRcBlock returns content with block id holding body. An existing block
+// is replaced where it is, otherwise the block is appended after a blank
+// line.
+func setRcBlock(content, comment, id, body string) string {
+	block := rcBlockStart(comment, id) + "\n" + strings.TrimRight(body, "\n") + "\n" + rcBlockStop(comment, id)
+
+	lines := strings.Split(content, "\n")
+	for i := 0; i < len(lines); i++ {
+		blockID, end, ok := rcBlockAt(lines, i, comment)
+		if !ok || blockID != id {
+			continue
+		}
+		out := append(lines[:i:i], block)
+		out = append(out, lines[end+1:]...)
+		// Drop any older copies further down
+		rest, _ := removeRcBlocks(strings.Join(out[i+1:], "\n"), comment, func(other string) bool { return other == id })
+		return strings.Join(out[:i+1], "\n") + "\n" + rest
+	}
+
+	if content == "" {
+		return block + "\n"
+	}
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if !strings.HasSuffix(content, "\n\n") {
+		content += "\n"
+	}
+	return content + block + "\n"
+}
+
+// setRcBlockBefore is setRcBlock for files where the block has to come
+// before a given line, like the return of a Lua config. A new block is
+// inserted before the last line equal to before, or appended when there is
+// none or before is "".
+func setRcBlockBefore(content, comment, id, body, before string) string {
+	if _, found := removeRcBlocks(content, comment, func(other string) bool { return other == id }); found || before == "" {
+		return setRcBlock(content, comment, id, body)
+	}
+	lines := strings.Split(content, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.TrimSpace(lines[i]) != before {
+			continue
+		}
+		block := rcBlockStart(comment, id) + "\n" + strings.TrimRight(body, "\n") + "\n" + rcBlockStop(comment, id)
+		out := append(lines[:i:i], block, "")
+		return strings.Join(append(out, lines[i:]...), "\n")
+	}
+	return setRcBlock(content, comment, id, body)
+}
+
+// writeRcFile writes the new content of an rc file the installer changed
+func writeRcFile(path, content, detail string) error {
+	trackChange(path)
+	if IsDryRun() {
+		RecordPlan(PlanPatch, path, detail)
+		return nil
+	}
+	if err := unlink(path); err != nil {
+		return err
+	}
+	return patched(path, os.WriteFile(path, []byte(content), 0644))
+}
+
+// writeRootRcFile writes the new content of a file only root can change,
+// like /etc/shells, through sudo. The backup leaves such files alone.
+func writeRootRcFile(ctx context.Context, path, content, detail string) error {
+	if IsDryRun() {
+		RecordPlan(PlanSudo, path, detail)
+		return nil
+	}
+	tmp, err := os.CreateTemp("", "gentleman-rc-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.WriteString(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	// cp keeps the owner and mode of the file it overwrites
+	return Run(ctx, Privileged(fmt.Sprintf("cp %q %q", tmp.Name(), path)), nil).Error
+}
+
+// readRcFile returns the content of an rc file, "" when it does not exist
+func readRcFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	return string(content), err
+}
+
+// SetRcBlock inserts or updates block id in the rc file at path, creating
+// the file if needed. It reports whether the file changed: running it again
+// with the same body is a no-op. A linked config that sources its overlay
+// gets the block there instead.
+func SetRcBlock(path, id, body string) (bool, error) {
+	if overlayPath, ok := rcOverlay(path); ok {
+		return setOverlayBlock(path, overlayPath, id, body)
+	}
+	return setRcBlockFile(path, id, body, writeRcFile)
+}
+
+// SetRootRcBlock is SetRcBlock for files only root can write, like
+// /etc/shells
+func SetRootRcBlock(ctx context.Context, path, id, body string) (bool, error) {
+	return setRcBlockFile(path, id, body, func(path, content, detail string) error {
+		return writeRootRcFile(ctx, path, content, detail)
+	})
+}
+
+func setRcBlockFile(path, id, body string, write func(path, content, detail string) error) (bool, error) {
+	content, err := readRcFile(path)
+	if err != nil {
+		return false, err
+	}
+	updated := setRcBlock(content, rcComment(path), id, body)
+	if updated == content {
+		return false, nil
+	}
+	return true, write(path, updated, "block "+id)
+}
+
+// RemoveRcBlock removes block id from the rc file at path. It reports
+// whether the block was there.
+func RemoveRcBlock(path, id string) (bool, error) {
+	if overlayPath, ok := rcOverlay(path); ok {
+		return setOverlayBlock(path, overlayPath, id, "")
+	}
+	return removeRcBlockFile(path, id)
+}
+
+func removeRcBlockFile(path, id string) (bool, error) {
+	content, err := readRcFile(path)
+	if err != nil || content == "" {
+		return false, err
+	}
+	updated, found := removeRcBlocks(content, rcComment(path), func(other string) bool { return other == id })
+	if !found {
+		return false, nil
+	}
+	return true, writeRcFile(path, updated, "remove block "+id)
+}
+
+// rcSkip returns the line that ends an overlay block replacing block id of
+// the linked config. The linked config skips its own block when it is set,
+// as the wm blocks of the shipped shell configs do.
+func rcSkip(path, id string) string {
+	name := "GENTLEMAN_SKIP_" + strings.ToUpper(strings.ReplaceAll(id, "-", "_"))
+	if filepath.Ext(path) == ".fish" {
+		return "set -g " + name + " 1"
+	}
+	return name + "=1"
+}
+
+// setOverlayBlock sets block id of the linked config at path in its overlay
+// file, or removes it when body is "". The overlay is sourced before the
+// linked config's own blocks: one that differs from body is skipped, one
+// that matches it needs nothing in the overlay.
+func setOverlayBlock(path, overlayPath, id, body string) (bool, error) {
+	content, err := readRcFile(path)
+	if err != nil {
+		return false, err
+	}
+	comment := rcComment(path)
+	if _, shipped := removeRcBlocks(content, comment, func(other string) bool { return other == id }); shipped {
+		switch {
+		case body == "":
+			body = rcSkip(path, id)
+		case setRcBlock(content, comment, id, body) == content:
+			body = ""
+		default:
+			body += "\n" + rcSkip(path, id)
+		}
+	}
+	if body == "" {
+		return removeRcBlockFile(overlayPath, id)
+	}
+	if err := EnsureDir(filepath.Dir(overlayPath)); err != nil {
+		return false, err
+	}
+	return setRcBlockFile(overlayPath, id, body, writeRcFile)
+}
+
+// RcBlock returns the body of block id in the rc file at path
+func RcBlock(path, id string) (string, bool) {
+	content, err := readRcFile(path)
+	if err != nil {
+		return "", false
+	}
+	comment := rcComment(path)
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != rcBlockStart(comment, id) {
+			continue
+		}
+		for j := i + 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) == rcBlockStop(comment, id) {
+				return strings.Join(lines[i+1:j], "\n"), true
+			}
+		}
+	}
+	return "", false
+}
+
+// RcFileWithout returns the content of the rc file at path without block
+// id, what the user wrote around it
+func RcFileWithout(path, id string) (string, error) {
+	content, err := readRcFile(path)
+	if err != nil {
+		return "", err
+	}
+	content, _ = removeRcBlocks(content, rcComment(path), func(other string) bool { return other == id })
+	return content, nil
+}
+
+// StripInstallerBlocks removes every block the installer added to an rc
+// file, with the blank line before each. It reports whether any was found.
+// The blocks of a linked config are the repository's, only its overlay is
+// stripped.
+func StripInstallerBlocks(path string) (bool, error) {
+	if _, linked := repoLink(path); linked {
+		overlayPath, ok := rcOverlay(path)
+		if !ok {
+			return false, nil
+		}
+		path = overlayPath
+	}
+	return stripInstallerBlocks(path, writeRcFile)
+}
+
+// StripRootInstallerBlocks is StripInstallerBlocks for files only root can
+// write, like /etc/shells
+func StripRootInstallerBlocks(ctx context.Context, path string) (bool, error) {
+	return stripInstallerBlocks(path, func(path, content, detail string) error {
+		return writeRootRcFile(ctx, path, content, detail)
+	})
+}
+
+func stripInstallerBlocks(path string, write func(path, content, detail string) error) (bool, error) {
+	content, err := readRcFile(path)
+	if err != nil || content == "" {
+		return false, err
+	}
+	updated, found := removeRcBlocks(content, rcComment(path), func(string) bool { return true })
+	if !found {
+		return false, nil
+	}
+	return true, write(path, updated, "strip installer blocks")
+}
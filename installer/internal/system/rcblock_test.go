@@ -0,0 +1,186 @@
+package system
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetRcBlock(t *testing.T) {
+	block := "# >>> gentleman:local-bin >>>\nexport PATH=\"$HOME/.local/bin:$PATH\"\n# <<< gentleman:local-bin <<<\n"
+	body := `export PATH="$HOME/.local/bin:$PATH"`
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"empty file", "", block},
+		{"appended after a blank line", "alias v=nvim\n", "alias v=nvim\n\n" + block},
+		{"no final newline", "alias v=nvim", "alias v=nvim\n\n" + block},
+		{"already blank line", "alias v=nvim\n\n", "alias v=nvim\n\n" + block},
+		{"unchanged block", "alias v=nvim\n\n" + block, "alias v=nvim\n\n" + block},
+		{"updated in place", "a=1\n\n# >>> gentleman:local-bin >>>\nold\n# <<< gentleman:local-bin <<<\nb=2\n",
+			"a=1\n\n" + block + "b=2\n"},
+		{"other blocks kept", "# >>> gentleman:brew >>>\neval x\n# <<< gentleman:brew <<<\n",
+			"# >>> gentleman:brew >>>\neval x\n# <<< gentleman:brew <<<\n\n" + block},
+		{"duplicates collapsed", block + "a=1\n\n" + block, block + "a=1\n"},
+		{"legacy block replaced", "a=1\n\n# Added by Javi.Dots installer\nexport PATH=\"/old:$PATH\"\nb=2\n",
+			"a=1\n\n" + block + "b=2\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := setRcBlock(tt.content, "#", RcBlockLocalBin, body)
+			if got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+			if again := setRcBlock(got, "#", RcBlockLocalBin, body); again != got {
+				t.Errorf("setting the block again should not change the file, got %q", again)
+			}
+		})
+	}
+}
+
+func TestRemoveRcBlocks(t *testing.T) {
+	for _, content := range []string{"", "alias v=nvim\n", "alias v=nvim", "a=1\n\nb=2\n", "\n"} {
+		withBlock := setRcBlock(content, "#", RcBlockBrew, "eval x")
+		got, found := removeRcBlocks(withBlock, "#", func(id string) bool { return id == RcBlockBrew })
+		if !found {
+			t.Errorf("block not found in %q", withBlock)
+		}
+		want := content
+		if content == "alias v=nvim" {
+			want += "\n" // The final newline added with the block stays
+		}
+		if got != want {
+			t.Errorf("set then remove on %q gave %q", content, got)
+		}
+	}
+
+	t.Run("only the given block", func(t *testing.T) {
+		content := setRcBlock(setRcBlock("a=1\n", "#", RcBlockBrew, "eval x"), "#", RcBlockLocalBin, "export P")
+		got, _ := removeRcBlocks(content, "#", func(id string) bool { return id == RcBlockBrew })
+		if want := "a=1\n\n# >>> gentleman:local-bin >>>\nexport P\n# <<< gentleman:local-bin <<<\n"; got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+}
+
+func TestSetRcBlockBefore(t *testing.T) {
+	content := "local config = {}\n\nreturn config\n"
+	got := setRcBlockBefore(content, "--", RcBlockTheme, "config.x = 1", "return config")
+	want := "local config = {}\n\n-- >>> gentleman:theme >>>\nconfig.x = 1\n-- <<< gentleman:theme <<<\n\nreturn config\n"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if again := setRcBlockBefore(got, "--", RcBlockTheme, "config.x = 2", "return config"); strings.Count(again, "config.x") != 1 {
+		t.Errorf("an existing block should be updated in place, got %q", again)
+	}
+	if removed, _ := removeRcBlocks(got, "--", func(id string) bool { return id == RcBlockTheme }); removed != content {
+		t.Errorf("removing the block should give back %q, got %q", content, removed)
+	}
+	if got := setRcBlockBefore("a = 1\n", "#", RcBlockTheme, "b = 2", "return config"); got != setRcBlock("a = 1\n", "#", RcBlockTheme, "b = 2") {
+		t.Errorf("without the line the block is appended, got %q", got)
+	}
+}
+
+func TestRcBlockFiles(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+	dir := t.TempDir()
+
+	for _, name := range []string{".bashrc", ".zshrc", "config.fish", "env.nu"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			os.WriteFile(path, []byte("# my settings\nalias v=nvim\n"), 0644)
+
+			if changed, err := SetRcBlock(path, RcBlockLocalBin, "fish_add_path ~/.local/bin"); !changed || err != nil {
+				t.Fatalf("SetRcBlock = %v, %v", changed, err)
+			}
+			if changed, _ := SetRcBlock(path, RcBlockLocalBin, "fish_add_path ~/.local/bin"); changed {
+				t.Error("setting the same block twice should be a no-op")
+			}
+			if body, ok := RcBlock(path, RcBlockLocalBin); !ok || body != "fish_add_path ~/.local/bin" {
+				t.Errorf("RcBlock = %q, %v", body, ok)
+			}
+			if outside, _ := RcFileWithout(path, RcBlockLocalBin); outside != "# my settings\nalias v=nvim\n" {
+				t.Errorf("RcFileWithout = %q", outside)
+			}
+			if removed, err := RemoveRcBlock(path, RcBlockLocalBin); !removed || err != nil {
+				t.Fatalf("RemoveRcBlock = %v, %v", removed, err)
+			}
+			if got, _ := os.ReadFile(path); string(got) != "# my settings\nalias v=nvim\n" {
+				t.Errorf("user content should be preserved, got %q", got)
+			}
+			if removed, _ := RemoveRcBlock(path, RcBlockLocalBin); removed {
+				t.Error("removing a missing block should report false")
+			}
+		})
+	}
+
+	t.Run("kdl comments", func(t *testing.T) {
+		path := filepath.Join(dir, "config.kdl")
+		SetRcBlock(path, RcBlockDefaultShell, `default_shell "fish"`)
+		want := "// >>> gentleman:default-shell >>>\ndefault_shell \"fish\"\n// <<< gentleman:default-shell <<<\n"
+		if got, _ := os.ReadFile(path); string(got) != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("missing file is created", func(t *testing.T) {
+		path := filepath.Join(dir, "shells")
+		if _, err := SetRcBlock(path, RcBlockShell("fish"), "/usr/bin/fish"); err != nil {
+			t.Fatal(err)
+		}
+		if body, ok := RcBlock(path, "shell-fish"); !ok || body != "/usr/bin/fish" {
+			t.Errorf("RcBlock = %q, %v", body, ok)
+		}
+		if removed, err := RemoveRcBlock(filepath.Join(dir, "nope"), RcBlockBrew); removed || err != nil {
+			t.Errorf("a missing file has no block, got %v, %v", removed, err)
+		}
+	})
+
+	t.Run("root files", func(t *testing.T) {
+		// Root copies the new content over the file without sudo
+		asUser(t, 0)
+		path := filepath.Join(dir, "etc-shells")
+		os.WriteFile(path, []byte("/bin/sh\n/bin/bash\n"), 0644)
+
+		if changed, err := SetRootRcBlock(context.Background(), path, RcBlockShell("zsh"), "/usr/bin/zsh"); !changed || err != nil {
+			t.Fatalf("SetRootRcBlock = %v, %v", changed, err)
+		}
+		if body, ok := RcBlock(path, "shell-zsh"); !ok || body != "/usr/bin/zsh" {
+			t.Errorf("RcBlock = %q, %v", body, ok)
+		}
+		if stripped, err := StripRootInstallerBlocks(context.Background(), path); !stripped || err != nil {
+			t.Fatalf("StripRootInstallerBlocks = %v, %v", stripped, err)
+		}
+		if got, _ := os.ReadFile(path); string(got) != "/bin/sh\n/bin/bash\n" {
+			t.Errorf("the listed shells should be preserved, got %q", got)
+		}
+
+		t.Setenv("GENTLEMAN_DRY_RUN", "1")
+		TakePlan()
+		SetRootRcBlock(context.Background(), path, RcBlockShell("fish"), "/usr/bin/fish")
+		if plan := TakePlan(); len(plan) != 1 || plan[0].Kind != PlanSudo || plan[0].Target != path {
+			t.Errorf("unexpected plan %+v", plan)
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		t.Setenv("GENTLEMAN_DRY_RUN", "1")
+		TakePlan()
+		path := filepath.Join(dir, "dry.zshrc")
+		if changed, err := SetRcBlock(path, RcBlockBrew, "eval x"); !changed || err != nil {
+			t.Fatalf("SetRcBlock = %v, %v", changed, err)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Error("a dry run should not write the file")
+		}
+		if plan := TakePlan(); len(plan) != 1 || plan[0].Kind != PlanPatch || plan[0].Detail != "block brew" {
+			t.Errorf("unexpected plan %+v", plan)
+		}
+	})
+}
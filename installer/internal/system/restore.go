@@ -0,0 +1,245 @@
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Restore changes, what restoring a backup entry does to a file
+const (
+	RestoreAdded    = "added"    // only in the backup, restoring creates it
+	RestoreRemoved  = "removed"  // only in the current config, restoring deletes it
+	RestoreModified = "modified" // restoring replaces it
+)
+
+// maxDiffSize is the largest file shown as a unified diff
+const maxDiffSize = 1 << 20
+
+// RestoreFile is a file restoring a backup entry changes
+type RestoreFile struct {
+	Path   string // absolute path of the file in the current config
+	Change string
+	Diff   string // unified diff from the current file to the backup, empty for binaries
+}
+
+// RestoreDiff is what restoring a backup entry changes
+type RestoreDiff struct {
+	Key   string
+	Path  string // the config the entry is restored to
+	Files []RestoreFile
+}
+
+// DiffBackup compares the entries of a backup with the current configs. A
+// nil keys slice compares every entry.
+func DiffBackup(backupDir string, keys []string) ([]RestoreDiff, error) {
+	dir, cleanup, err := openBackup(backupDir)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	configPaths := ConfigPaths()
+	var diffs []RestoreDiff
+	for _, key := range backupKeys(dir, nil) {
+		dst, ok := configPaths[key]
+		if !ok || (keys != nil && !slices.Contains(keys, key)) {
+			continue
+		}
+		backupFiles, err := snapshotTree(filepath.Join(dir, key))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup of %s: %w", key, err)
+		}
+		currentFiles, err := snapshotTree(dst)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", dst, err)
+		}
+
+		diff := RestoreDiff{Key: key, Path: dst}
+		for _, rel := range unionKeys(backupFiles, currentFiles) {
+			saved, inBackup := backupFiles[rel]
+			current, inCurrent := currentFiles[rel]
+			file := RestoreFile{Path: filepath.Join(dst, rel)}
+			switch {
+			case !inCurrent:
+				file.Change = RestoreAdded
+			case !inBackup:
+				file.Change = RestoreRemoved
+			case current == saved:
+				continue
+			default:
+				file.Change = RestoreModified
+			}
+			if isText(current) && isText(saved) {
+				file.Diff = UnifiedDiff(file.Path+" (current)", file.Path+" (backup)", current, saved)
+			}
+			diff.Files = append(diff.Files, file)
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs, nil
+}
+
+// snapshotTree reads the files and links below root, keyed by their path
+// relative to it. Links read as their target. A missing root is empty.
+func snapshotTree(root string) (map[string]string, error) {
+	files := map[string]string{}
+	if _, err := os.Lstat(root); os.IsNotExist(err) {
+		return files, nil
+	}
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			files[rel] = "symlink to " + target + "\n"
+		case info.Mode().IsRegular():
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			files[rel] = string(data)
+		}
+		return nil
+	})
+	return files, err
+}
+
+func unionKeys(a, b map[string]string) []string {
+	var keys []string
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// isText reports whether content is small, valid UTF-8 without NUL bytes
+func isText(content string) bool {
+	return len(content) <= maxDiffSize && utf8.ValidString(content) && !strings.Contains(content, "\x00")
+}
+
+// FormatRestoreDiff lists what restoring changes, per entry and file. With
+// full it adds the unified diffs.
+func FormatRestoreDiff(diffs []RestoreDiff, full bool) string {
+	var sb strings.Builder
+	marks := map[string]string{RestoreAdded: "+", RestoreRemoved: "-", RestoreModified: "~"}
+	for _, d := range diffs {
+		if len(d.Files) == 0 {
+			fmt.Fprintf(&sb, "%s (%s): unchanged\n", d.Key, d.Path)
+			continue
+		}
+		fmt.Fprintf(&sb, "%s (%s):\n", d.Key, d.Path)
+		for _, f := range d.Files {
+			fmt.Fprintf(&sb, "  %s %s (%s)\n", marks[f.Change], f.Path, f.Change)
+		}
+		if !full {
+			continue
+		}
+		for _, f := range d.Files {
+			switch {
+			case f.Diff != "":
+				sb.WriteString(f.Diff)
+			case f.Change == RestoreModified:
+				fmt.Fprintf(&sb, "Binary file %s differs\n", f.Path)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// RestoreBackupWithUndo restores the given config keys from a backup, nil
+// meaning every entry, after backing up the configs it replaces. It returns
+// that snapshot: UndoRestore puts the configs back as they were.
+func RestoreBackupWithUndo(backupDir string, keys []string) (string, error) {
+	if keys == nil {
+		manifest, _ := LoadBackupManifest(backupDir)
+		keys = backupKeys(backupDir, manifest)
+	}
+	configPaths := ConfigPaths()
+	keys = slices.DeleteFunc(slices.Clone(keys), func(key string) bool {
+		_, ok := configPaths[key]
+		return !ok || !backupHas(backupDir, key)
+	})
+	if len(keys) == 0 {
+		return "", fmt.Errorf("the backup holds none of the configs to restore")
+	}
+
+	manifest := newBackupManifest("restore")
+	manifest.Restored = keys
+	snapshot, err := createBackup(keys, manifest)
+	if err != nil {
+		return snapshot, fmt.Errorf("failed to save the current configs: %w", err)
+	}
+	if err := RestoreBackupEntries(backupDir, keys); err != nil {
+		return snapshot, err
+	}
+
+	// Pruning never takes the backup being restored: it is done by now
+	if settings, err := LoadBackupSettings(); err == nil && settings.Policy.Enabled() && !IsDryRun() {
+		_, _ = PruneBackups(settings.Policy)
+	}
+	return snapshot, nil
+}
+
+// UndoRestore reverts the restore a snapshot from RestoreBackupWithUndo was
+// taken for: configs the restore replaced come back, the ones it created
+// are removed
+func UndoRestore(snapshotDir string) error {
+	manifest, err := LoadBackupManifest(snapshotDir)
+	if err != nil {
+		return fmt.Errorf("not a restore snapshot: %w", err)
+	}
+	if manifest.Reason != "restore" {
+		return fmt.Errorf("%s is not a restore snapshot", snapshotDir)
+	}
+
+	configPaths := ConfigPaths()
+	saved := backupKeys(snapshotDir, manifest)
+	for _, key := range manifest.Restored {
+		if slices.Contains(saved, key) {
+			continue
+		}
+		if path, ok := configPaths[key]; ok {
+			if err := removeAll(path); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", key, err)
+			}
+			forgetManifest(path)
+		}
+	}
+	return RestoreBackupEntries(snapshotDir, saved)
+}
+
+// LatestRestore returns the snapshot of the most recent restore
+func LatestRestore() (BackupInfo, bool) {
+	var latest BackupInfo
+	found := false
+	for _, backup := range ListBackups() {
+		if backup.Manifest == nil || backup.Manifest.Reason != "restore" {
+			continue
+		}
+		if !found || backup.Timestamp.After(latest.Timestamp) {
+			latest = backup
+			found = true
+		}
+	}
+	return latest, found
+}
@@ -0,0 +1,143 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffBackup(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+
+	tmux := filepath.Join(home, ".tmux.conf")
+	nvim := filepath.Join(home, ".config", "nvim")
+	os.WriteFile(tmux, []byte("set -g mouse on\n"), 0644)
+	os.MkdirAll(nvim, 0755)
+	os.WriteFile(filepath.Join(nvim, "init.lua"), []byte("require('old')\n"), 0644)
+	os.WriteFile(filepath.Join(nvim, "old.lua"), []byte("return {}\n"), 0644)
+	backupDir, err := CreateBackupFor([]string{"tmux", "nvim"}, "install", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.WriteFile(filepath.Join(nvim, "init.lua"), []byte("require('new')\n"), 0644)
+	os.Remove(filepath.Join(nvim, "old.lua"))
+	os.WriteFile(filepath.Join(nvim, "new.lua"), []byte("return {}\n"), 0644)
+
+	diffs, err := DiffBackup(backupDir, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(diffs) != 2 {
+		t.Fatalf("expected both entries, got %+v", diffs)
+	}
+	changes := map[string]string{}
+	for _, d := range diffs {
+		for _, f := range d.Files {
+			changes[f.Path] = f.Change
+		}
+	}
+	want := map[string]string{
+		filepath.Join(nvim, "init.lua"): RestoreModified,
+		filepath.Join(nvim, "old.lua"):  RestoreAdded,
+		filepath.Join(nvim, "new.lua"):  RestoreRemoved,
+	}
+	if len(changes) != len(want) {
+		t.Errorf("expected %v, got %v", want, changes)
+	}
+	for path, change := range want {
+		if changes[path] != change {
+			t.Errorf("%s: expected %s, got %s", path, change, changes[path])
+		}
+	}
+
+	t.Run("only the selected entries", func(t *testing.T) {
+		diffs, _ := DiffBackup(backupDir, []string{"tmux"})
+		if len(diffs) != 1 || diffs[0].Key != "tmux" || len(diffs[0].Files) != 0 {
+			t.Errorf("expected an unchanged tmux entry, got %+v", diffs)
+		}
+	})
+
+	t.Run("format", func(t *testing.T) {
+		summary := FormatRestoreDiff(diffs, false)
+		if !strings.Contains(summary, "tmux ("+tmux+"): unchanged") || !strings.Contains(summary, "~ "+filepath.Join(nvim, "init.lua")+" (modified)") {
+			t.Errorf("unexpected summary:\n%s", summary)
+		}
+		if strings.Contains(summary, "@@") {
+			t.Error("the summary should not hold diffs")
+		}
+		if full := FormatRestoreDiff(diffs, true); !strings.Contains(full, "-require('new')\n+require('old')\n") {
+			t.Errorf("expected the unified diff from current to backup:\n%s", full)
+		}
+	})
+}
+
+func TestRestoreBackupWithUndo(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+
+	tmux := filepath.Join(home, ".tmux.conf")
+	zshrc := filepath.Join(home, ".zshrc")
+	starship := filepath.Join(home, ".config", "starship.toml")
+	os.WriteFile(tmux, []byte("backed up tmux\n"), 0644)
+	os.WriteFile(zshrc, []byte("backed up zsh\n"), 0644)
+	os.MkdirAll(filepath.Dir(starship), 0755)
+	os.WriteFile(starship, []byte("backed up starship\n"), 0644)
+	backupDir, err := CreateBackupFor([]string{"tmux", "zsh", "starship"}, "install", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.WriteFile(tmux, []byte("current tmux\n"), 0644)
+	os.WriteFile(zshrc, []byte("current zsh\n"), 0644)
+	os.Remove(starship)
+
+	snapshot, err := RestoreBackupWithUndo(backupDir, []string{"tmux", "starship"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	read := func(path string) string {
+		data, _ := os.ReadFile(path)
+		return string(data)
+	}
+	if read(tmux) != "backed up tmux\n" || read(starship) != "backed up starship\n" {
+		t.Error("the selected entries should be restored")
+	}
+	if read(zshrc) != "current zsh\n" {
+		t.Error("entries left out should not be touched")
+	}
+
+	latest, ok := LatestRestore()
+	if !ok || latest.Path != snapshot {
+		t.Fatalf("expected the snapshot as the latest restore, got %+v", latest)
+	}
+	if strings.Join(latest.Files, ",") != "tmux" || strings.Join(latest.Manifest.Restored, ",") != "tmux,starship" {
+		t.Errorf("snapshot should hold the replaced configs, got %v / %v", latest.Files, latest.Manifest.Restored)
+	}
+
+	if err := UndoRestore(snapshot); err != nil {
+		t.Fatalf("undo failed: %v", err)
+	}
+	if read(tmux) != "current tmux\n" {
+		t.Error("undo should put the replaced config back")
+	}
+	if _, err := os.Stat(starship); !os.IsNotExist(err) {
+		t.Error("undo should remove the configs the restore created")
+	}
+
+	t.Run("only restore snapshots can be undone", func(t *testing.T) {
+		if err := UndoRestore(backupDir); err == nil {
+			t.Error("expected an error for an install backup")
+		}
+	})
+
+	t.Run("nothing to restore", func(t *testing.T) {
+		if _, err := RestoreBackupWithUndo(backupDir, []string{"kitty"}); err == nil {
+			t.Error("expected an error for entries the backup lacks")
+		}
+	})
+}
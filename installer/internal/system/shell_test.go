@@ -1,6 +1,7 @@
 package system
 
 import (
+	"context"
 	"os/exec"
 	"testing"
 )
@@ -47,7 +48,7 @@ func TestGetShell(t *testing.T) {
 
 func TestRunWithDynamicShell(t *testing.T) {
 	t.Run("Run should work with detected shell", func(t *testing.T) {
-		result := Run("echo 'test'", nil)
+		result := Run(context.Background(), "echo 'test'", nil)
 
 		if result.Error != nil {
 			t.Errorf("Run failed: %v", result.Error)
@@ -60,7 +61,7 @@ func TestRunWithDynamicShell(t *testing.T) {
 
 	t.Run("Run should handle complex commands", func(t *testing.T) {
 		// Use sh -c to ensure && is interpreted correctly across all shells
-		result := Run("sh -c 'test -d / && echo exists'", nil)
+		result := Run(context.Background(), "sh -c 'test -d / && echo exists'", nil)
 
 		if result.Error != nil {
 			t.Errorf("Run failed with complex command: %v", result.Error)
@@ -68,7 +69,7 @@ func TestRunWithDynamicShell(t *testing.T) {
 	})
 
 	t.Run("Run should handle environment variables", func(t *testing.T) {
-		result := Run("echo $HOME", nil)
+		result := Run(context.Background(), "echo $HOME", nil)
 
 		if result.Error != nil {
 			t.Errorf("Run failed with env var: %v", result.Error)
@@ -1,6 +1,7 @@
 package system
 
 import (
+	"context"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -115,7 +116,7 @@ exit 0
 // TestRunFunction tests the Run function with various commands
 func TestRunFunction(t *testing.T) {
 	t.Run("simple command", func(t *testing.T) {
-		result := Run("echo 'hello'", nil)
+		result := Run(context.Background(), "echo 'hello'", nil)
 		if result.Error != nil {
 			t.Errorf("Run failed: %v", result.Error)
 		}
@@ -125,14 +126,14 @@ func TestRunFunction(t *testing.T) {
 	})
 
 	t.Run("command with pipes", func(t *testing.T) {
-		result := Run("echo 'hello world' | wc -w", nil)
+		result := Run(context.Background(), "echo 'hello world' | wc -w", nil)
 		if result.Error != nil {
 			t.Errorf("Pipe command failed: %v", result.Error)
 		}
 	})
 
 	t.Run("command that fails", func(t *testing.T) {
-		result := Run("exit 1", nil)
+		result := Run(context.Background(), "exit 1", nil)
 		if result.Error == nil {
 			t.Error("Expected error for failing command")
 		}
@@ -143,14 +144,14 @@ func TestRunFunction(t *testing.T) {
 
 	t.Run("command with working directory", func(t *testing.T) {
 		tmpDir := t.TempDir()
-		result := Run("pwd", &ExecOptions{WorkDir: tmpDir})
+		result := Run(context.Background(), "pwd", &ExecOptions{WorkDir: tmpDir})
 		if result.Error != nil {
 			t.Errorf("WorkDir command failed: %v", result.Error)
 		}
 	})
 
 	t.Run("command with environment", func(t *testing.T) {
-		result := Run("echo $MY_TEST_VAR", &ExecOptions{
+		result := Run(context.Background(), "echo $MY_TEST_VAR", &ExecOptions{
 			Env: []string{"MY_TEST_VAR=test_value"},
 		})
 		if result.Error != nil {
@@ -174,7 +175,7 @@ func TestGitCloneCompatibility(t *testing.T) {
 
 	t.Run("git clone with progress", func(t *testing.T) {
 		// Use a small public repo for testing
-		result := Run("git clone --depth 1 https://github.com/octocat/Hello-World.git test-repo", &ExecOptions{
+		result := Run(context.Background(), "git clone --depth 1 https://github.com/octocat/Hello-World.git test-repo", &ExecOptions{
 			WorkDir: tmpDir,
 		})
 
@@ -202,14 +203,14 @@ func TestBrewCommandCompatibility(t *testing.T) {
 	}
 
 	t.Run("brew --version", func(t *testing.T) {
-		result := RunBrew("--version", nil)
+		result := RunBrew(context.Background(), "--version", nil)
 		if result.Error != nil {
 			t.Errorf("brew --version failed: %v", result.Error)
 		}
 	})
 
 	t.Run("brew list (should not fail)", func(t *testing.T) {
-		result := RunBrew("list --versions | head -1", nil)
+		result := RunBrew(context.Background(), "list --versions | head -1", nil)
 		// This might fail if brew has no packages, but shouldn't error
 		if result.Error != nil && result.ExitCode != 0 && result.ExitCode != 1 {
 			t.Errorf("brew list failed unexpectedly: %v", result.Error)
@@ -0,0 +1,608 @@
+package system
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// A theme restyles every installed config with one palette. Colors are
+// written where each tool reads them: a managed theme block where a later
+// setting overrides an earlier one, the values themselves where it does
+// not, and generated files for Zellij and OpenCode. Whatever a theme
+// replaced is kept in the theme state, so going back to the default theme
+// puts it all back.
+
+// Palette is the set of colors a theme is made of
+type Palette struct {
+	Background string
+	Foreground string
+	Cursor     string
+	Selection  string
+	Muted      string    // comments, inactive tabs
+	Surface    string    // borders, panels
+	Accent     string    // orange highlight
+	Normal     [8]string // ANSI black, red, green, yellow, blue, magenta, cyan, white
+	Bright     [8]string
+}
+
+// Theme is a palette with the names tools already know it by
+type Theme struct {
+	ID   string // flag value, "tokyonight"
+	Name string // display name, "Tokyo Night"
+	Palette
+	Nvim    string // Neovim colorscheme
+	Lualine string // lualine theme
+}
+
+// DefaultTheme is the look the configs ship with
+const DefaultTheme = "gentleman"
+
+// Themes is the registry of the themes the installer can apply, in menu
+// order
+var Themes = []Theme{
+	{
+		ID: "gentleman", Name: "Gentleman", Nvim: "gentleman-kanagawa-blur", Lualine: "gentleman-kanagawa-blur",
+		Palette: Palette{
+			Background: "#06080f", Foreground: "#f3f6f9", Cursor: "#e0c15a", Selection: "#263356",
+			Muted: "#5c6170", Surface: "#232a40", Accent: "#deba87",
+			Normal: [8]string{"#06080f", "#cb7c94", "#b7cc85", "#ffe066", "#7fb4ca", "#ff8dd7", "#7aa89f", "#f3f6f9"},
+			Bright: [8]string{"#8a8fa3", "#de8fa8", "#d1e8a9", "#fff7b1", "#a3d4d5", "#ffaeea", "#7fb4ca", "#f3f6f9"},
+		},
+	},
+	{
+		ID: "kanagawa", Name: "Kanagawa", Nvim: "kanagawa-wave", Lualine: "kanagawa",
+		Palette: Palette{
+			Background: "#1f1f28", Foreground: "#dcd7ba", Cursor: "#c8c093", Selection: "#2d4f67",
+			Muted: "#727169", Surface: "#363646", Accent: "#ffa066",
+			Normal: [8]string{"#090618", "#c34043", "#76946a", "#c0a36e", "#7e9cd8", "#957fb8", "#6a9589", "#c8c093"},
+			Bright: [8]string{"#727169", "#e82424", "#98bb6c", "#e6c384", "#7fb4ca", "#938aa9", "#7aa89f", "#dcd7ba"},
+		},
+	},
+	{
+		ID: "catppuccin", Name: "Catppuccin Mocha", Nvim: "catppuccin-mocha", Lualine: "catppuccin",
+		Palette: Palette{
+			Background: "#1e1e2e", Foreground: "#cdd6f4", Cursor: "#f5e0dc", Selection: "#45475a",
+			Muted: "#6c7086", Surface: "#313244", Accent: "#fab387",
+			Normal: [8]string{"#45475a", "#f38ba8", "#a6e3a1", "#f9e2af", "#89b4fa", "#f5c2e7", "#94e2d5", "#bac2de"},
+			Bright: [8]string{"#585b70", "#f38ba8", "#a6e3a1", "#f9e2af", "#89b4fa", "#f5c2e7", "#94e2d5", "#a6adc8"},
+		},
+	},
+	{
+		ID: "tokyonight", Name: "Tokyo Night", Nvim: "tokyonight-night", Lualine: "tokyonight",
+		Palette: Palette{
+			Background: "#1a1b26", Foreground: "#c0caf5", Cursor: "#c0caf5", Selection: "#283457",
+			Muted: "#565f89", Surface: "#292e42", Accent: "#ff9e64",
+			Normal: [8]string{"#15161e", "#f7768e", "#9ece6a", "#e0af68", "#7aa2f7", "#bb9af7", "#7dcfff", "#a9b1d6"},
+			Bright: [8]string{"#414868", "#f7768e", "#9ece6a", "#e0af68", "#7aa2f7", "#bb9af7", "#7dcfff", "#c0caf5"},
+		},
+	},
+}
+
+// ThemeIDs returns the IDs of the registered themes
+func ThemeIDs() []string {
+	ids := make([]string, len(Themes))
+	for i, t := range Themes {
+		ids[i] = t.ID
+	}
+	return ids
+}
+
+// FindTheme returns the registered theme with the given ID
+func FindTheme(id string) (Theme, bool) {
+	for _, t := range Themes {
+		if t.ID == id {
+			return t, true
+		}
+	}
+	return Theme{}, false
+}
+
+// expand fills the {bg}, {fg}, {cursor}, {selection}, {muted}, {surface},
+// {accent} and {color0} to {color15} placeholders of a template
+func (t Theme) expand(template string) string {
+	pairs := []string{
+		"{bg}", t.Background, "{fg}", t.Foreground, "{cursor}", t.Cursor, "{selection}", t.Selection,
+		"{muted}", t.Muted, "{surface}", t.Surface, "{accent}", t.Accent,
+	}
+	for i, color := range append(t.Normal[:], t.Bright[:]...) {
+		pairs = append(pairs, fmt.Sprintf("{color%d}", i), color)
+	}
+	return strings.NewReplacer(pairs...).Replace(template)
+}
+
+// themeValue is a setting a theme rewrites in place. The first submatch of
+// pattern is the value.
+type themeValue struct {
+	name    string
+	pattern *regexp.Regexp
+	value   func(t Theme) string
+}
+
+// themeTarget is a config file a theme writes colors into. It is only
+// styled when it exists.
+type themeTarget struct {
+	name   string // tool, for reports
+	path   string // relative to HOME
+	values []themeValue
+	block  string            // template of the theme block
+	before string            // line the block goes in front of, appended when ""
+	files  map[string]string // templates of whole files, relative to HOME
+}
+
+// tomlValue matches the quoted value of key in a TOML table
+func tomlValue(table, key string) *regexp.Regexp {
+	return regexp.MustCompile(`(?m)^\[` + regexp.QuoteMeta(table) + `\][^\[]*?^\s*` + key + `\s*=\s*"([^"]*)"`)
+}
+
+// alacrittyValues rewrites the color tables of alacritty.toml, which can
+// not be defined twice
+func alacrittyValues() []themeValue {
+	color := func(name, table, key string, value func(t Theme) string) themeValue {
+		return themeValue{name, tomlValue(table, key), value}
+	}
+	values := []themeValue{
+		color("background", "colors.primary", "background", func(t Theme) string { return t.Background }),
+		color("foreground", "colors.primary", "foreground", func(t Theme) string { return t.Foreground }),
+		color("cursor", "colors.cursor", "cursor", func(t Theme) string { return t.Cursor }),
+		color("cursor text", "colors.cursor", "text", func(t Theme) string { return t.Background }),
+		color("selection", "colors.selection", "background", func(t Theme) string { return t.Selection }),
+		color("selection text", "colors.selection", "text", func(t Theme) string { return t.Foreground }),
+	}
+	for i, key := range []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"} {
+		values = append(values,
+			color("normal "+key, "colors.normal", key, func(t Theme) string { return t.Normal[i] }),
+			color("bright "+key, "colors.bright", key, func(t Theme) string { return t.Bright[i] }))
+	}
+	return values
+}
+
+// themeTargets are the configs a theme styles, in report order
+var themeTargets = []themeTarget{
+	{name: "Alacritty", path: ".config/alacritty/alacritty.toml", values: alacrittyValues()},
+	{name: "Kitty", path: ".config/kitty/kitty.conf", block: kittyTheme},
+	{name: "Ghostty", path: ".config/ghostty/config", block: ghosttyTheme},
+	{name: "WezTerm", path: ".config/wezterm/wezterm.lua", block: weztermTheme, before: "return config"},
+	{name: "Tmux", path: ".tmux.conf", block: tmuxTheme},
+	{
+		name: "Zellij", path: ".config/zellij/config.kdl",
+		values: []themeValue{
+			{"theme", regexp.MustCompile(`(?m)^theme\s+"([^"]*)"`), func(Theme) string { return "gentleman-theme" }},
+			{"layout", regexp.MustCompile(`(?m)^default_layout\s+"([^"]*)"`), func(Theme) string { return "gentleman-theme" }},
+		},
+		files: map[string]string{
+			".config/zellij/themes/gentleman-theme.kdl":  zellijTheme,
+			".config/zellij/layouts/gentleman-theme.kdl": zellijLayout,
+		},
+	},
+	{
+		name: "Starship", path: ".config/starship.toml", block: starshipTheme,
+		values: []themeValue{
+			{"palette", regexp.MustCompile(`(?m)^palette\s*=\s*"([^"]*)"`), func(Theme) string { return "gentleman_theme" }},
+		},
+	},
+	{
+		name: "Neovim", path: ".config/nvim/lua/plugins/colorscheme.lua",
+		values: []themeValue{
+			{"colorscheme", regexp.MustCompile(`(?m)^\s*colorscheme\s*=\s*"([^"]*)"`), func(t Theme) string { return t.Nvim }},
+		},
+	},
+	{
+		name: "Lualine", path: ".config/nvim/lua/plugins/ui.lua",
+		values: []themeValue{
+			{"theme", regexp.MustCompile(`(?m)^\s*theme\s*=\s*"([^"]*)"`), func(t Theme) string { return t.Lualine }},
+		},
+	},
+	{
+		name: "OpenCode", path: ".config/opencode/themes/gentleman.json",
+		files: map[string]string{".config/opencode/themes/gentleman.json": openCodeTheme},
+	},
+}
+
+// themeState is the current theme and what it replaced
+type themeState struct {
+	Theme  string                       `json:"theme"`
+	Values map[string]map[string]string `json:"values,omitempty"` // file → value name → original
+	Files  map[string]*string           `json:"files,omitempty"`  // file → original content, null when there was none
+}
+
+// ThemeStatePath returns the location of the theme state
+func ThemeStatePath() string {
+	return filepath.Join(StateDir(), "theme.json")
+}
+
+func loadThemeState() (*themeState, error) {
+	state := &themeState{Theme: DefaultTheme, Values: map[string]map[string]string{}, Files: map[string]*string{}}
+	data, err := os.ReadFile(ThemeStatePath())
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse theme state %s: %w", ThemeStatePath(), err)
+	}
+	if state.Values == nil {
+		state.Values = map[string]map[string]string{}
+	}
+	if state.Files == nil {
+		state.Files = map[string]*string{}
+	}
+	return state, nil
+}
+
+func (s *themeState) save() error {
+	if IsDryRun() {
+		return nil
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode theme state: %w", err)
+	}
+	if err := os.MkdirAll(StateDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	return os.WriteFile(ThemeStatePath(), data, 0644)
+}
+
+// CurrentTheme returns the ID of the applied theme
+func CurrentTheme() string {
+	state, err := loadThemeState()
+	if err != nil {
+		return DefaultTheme
+	}
+	return state.Theme
+}
+
+// setValue replaces the value pattern matches in content, returning the new
+// content and the value it replaced
+func setValue(content string, pattern *regexp.Regexp, value string) (string, string, bool) {
+	loc := pattern.FindStringSubmatchIndex(content)
+	if loc == nil {
+		return content, "", false
+	}
+	return content[:loc[2]] + value + content[loc[3]:], content[loc[2]:loc[3]], true
+}
+
+// ApplyTheme writes the colors of theme id into every installed config that
+// has them. Applying the default theme resets them. It returns the names of
+// the tools it restyled.
+func ApplyTheme(id string) ([]string, error) {
+	theme, ok := FindTheme(id)
+	if !ok {
+		return nil, fmt.Errorf("unknown theme %q (valid: %s)", id, strings.Join(ThemeIDs(), ", "))
+	}
+	if id == DefaultTheme {
+		return ResetTheme()
+	}
+	state, err := loadThemeState()
+	if err != nil {
+		return nil, err
+	}
+
+	home := os.Getenv("HOME")
+	var styled []string
+	for _, target := range themeTargets {
+		path := filepath.Join(home, target.path)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		updated := string(content)
+		for _, v := range target.values {
+			var old string
+			var found bool
+			if updated, old, found = setValue(updated, v.pattern, v.value(theme)); !found {
+				continue
+			}
+			if state.Values[target.path] == nil {
+				state.Values[target.path] = map[string]string{}
+			}
+			if _, kept := state.Values[target.path][v.name]; !kept {
+				state.Values[target.path][v.name] = old
+			}
+		}
+		if target.block != "" {
+			updated = setRcBlockBefore(updated, rcComment(path), RcBlockTheme, theme.expand(target.block), target.before)
+		}
+		if updated != string(content) {
+			if err := WriteFile(path, []byte(updated), 0644); err != nil {
+				return styled, fmt.Errorf("failed to style %s: %w", target.name, err)
+			}
+		}
+
+		for rel, template := range target.files {
+			file := filepath.Join(home, rel)
+			if _, kept := state.Files[rel]; !kept {
+				if original, err := os.ReadFile(file); err == nil {
+					text := string(original)
+					state.Files[rel] = &text
+				} else {
+					state.Files[rel] = nil
+				}
+			}
+			if err := EnsureDir(filepath.Dir(file)); err != nil {
+				return styled, err
+			}
+			if err := WriteFile(file, []byte(theme.expand(template)), 0644); err != nil {
+				return styled, fmt.Errorf("failed to style %s: %w", target.name, err)
+			}
+		}
+		styled = append(styled, target.name)
+	}
+
+	state.Theme = id
+	if err := state.save(); err != nil {
+		return styled, err
+	}
+	return styled, SaveManifest("", "")
+}
+
+// ResetTheme puts back what the applied theme replaced, returning the
+// configs to the default theme. It returns the names of the tools it
+// restyled.
+func ResetTheme() ([]string, error) {
+	state, err := loadThemeState()
+	if err != nil {
+		return nil, err
+	}
+
+	home := os.Getenv("HOME")
+	var styled []string
+	for _, target := range themeTargets {
+		path := filepath.Join(home, target.path)
+		changed := false
+		if content, err := os.ReadFile(path); err == nil {
+			updated := string(content)
+			for _, v := range target.values {
+				if original, kept := state.Values[target.path][v.name]; kept {
+					updated, _, _ = setValue(updated, v.pattern, original)
+				}
+			}
+			updated, _ = removeRcBlocks(updated, rcComment(path), func(id string) bool { return id == RcBlockTheme })
+			if updated != string(content) {
+				if err := WriteFile(path, []byte(updated), 0644); err != nil {
+					return styled, fmt.Errorf("failed to reset %s: %w", target.name, err)
+				}
+				changed = true
+			}
+		}
+
+		for rel := range target.files {
+			original, kept := state.Files[rel]
+			if !kept {
+				continue
+			}
+			file := filepath.Join(home, rel)
+			if original == nil {
+				err = RemoveAll(file)
+			} else {
+				err = WriteFile(file, []byte(*original), 0644)
+			}
+			if err != nil {
+				return styled, fmt.Errorf("failed to reset %s: %w", target.name, err)
+			}
+			changed = true
+		}
+		if changed {
+			styled = append(styled, target.name)
+		}
+	}
+
+	if !IsDryRun() {
+		if err := os.Remove(ThemeStatePath()); err != nil && !os.IsNotExist(err) {
+			return styled, err
+		}
+	}
+	return styled, SaveManifest("", "")
+}
+
+const kittyTheme = `background {bg}
+foreground {fg}
+cursor {cursor}
+cursor_text_color {bg}
+selection_background {selection}
+selection_foreground {fg}
+url_color {color4}
+active_tab_background {selection}
+active_tab_foreground {fg}
+inactive_tab_background {bg}
+inactive_tab_foreground {muted}
+color0  {color0}
+color1  {color1}
+color2  {color2}
+color3  {color3}
+color4  {color4}
+color5  {color5}
+color6  {color6}
+color7  {color7}
+color8  {color8}
+color9  {color9}
+color10 {color10}
+color11 {color11}
+color12 {color12}
+color13 {color13}
+color14 {color14}
+color15 {color15}`
+
+const ghosttyTheme = `background = {bg}
+foreground = {fg}
+cursor-color = {cursor}
+selection-background = {selection}
+selection-foreground = {fg}
+palette = 0={color0}
+palette = 1={color1}
+palette = 2={color2}
+palette = 3={color3}
+palette = 4={color4}
+palette = 5={color5}
+palette = 6={color6}
+palette = 7={color7}
+palette = 8={color8}
+palette = 9={color9}
+palette = 10={color10}
+palette = 11={color11}
+palette = 12={color12}
+palette = 13={color13}
+palette = 14={color14}
+palette = 15={color15}`
+
+const weztermTheme = `config.colors = {
+	foreground = "{fg}",
+	background = "{bg}",
+	cursor_bg = "{cursor}",
+	cursor_fg = "{bg}",
+	cursor_border = "{cursor}",
+	selection_fg = "{fg}",
+	selection_bg = "{selection}",
+	ansi = { "{color0}", "{color1}", "{color2}", "{color3}", "{color4}", "{color5}", "{color6}", "{color7}" },
+	brights = { "{color8}", "{color9}", "{color10}", "{color11}", "{color12}", "{color13}", "{color14}", "{color15}" },
+}`
+
+// The tmux block comes after TPM runs, so it replaces the status bar of the
+// kanagawa plugin
+const tmuxTheme = `set -g status-style "bg={bg},fg={fg}"
+set -g status-left "#[bg={color4},fg={bg},bold] #S #[default] "
+set -g status-right "#[fg={muted}]%a %d %b #[fg={color4},bold]%H:%M "
+set -g window-status-format "#[fg={muted}] #I:#W "
+set -g window-status-current-format "#[fg={accent},bold] #I:#W "
+set -g pane-border-style "fg={surface}"
+set -g pane-active-border-style "fg={color4}"
+set -g message-style "bg={surface},fg={fg}"
+set -g mode-style "bg={selection},fg={fg}"`
+
+const starshipTheme = `[palettes.gentleman_theme]
+text = "{fg}"
+red = "{color1}"
+green = "{color2}"
+yellow = "{color3}"
+blue = "{color4}"
+mauve = "{color13}"
+pink = "{color5}"
+teal = "{color6}"
+peach = "{accent}"
+subtext0 = "{muted}"
+overlay0 = "{surface}"`
+
+const zellijTheme = `themes {
+    gentleman-theme {
+        fg "{fg}"
+        bg "{bg}"
+        red "{color1}"
+        green "{color2}"
+        yellow "{color3}"
+        blue "{color4}"
+        magenta "{color5}"
+        cyan "{color6}"
+        orange "{accent}"
+        black "{color0}"
+        white "{color7}"
+    }
+}
+`
+
+const zellijLayout = `layout {
+    tab name="nvim" focus=true {
+        pane
+    }
+
+    tab name="shell" {
+        pane
+    }
+
+    default_tab_template {
+        pane size=1 borderless=true {
+            plugin location="file:~/.config/zellij/plugins/zjstatus.wasm" {
+              format_left   "{mode} #[fg={color2},bold]{session}{tabs}"
+              format_right  "{command_git_branch} {datetime}"
+              format_space  ""
+
+              border_enabled  "false"
+              border_char     "─"
+              border_format   "#[fg={surface}]{char}"
+              border_position "top"
+
+              hide_frame_for_single_pane "true"
+
+              mode_normal  "#[bg={color4}] "
+              mode_tmux    "#[bg={accent}] "
+
+              tab_normal   "#[fg={muted}] {name} "
+              tab_active   "#[fg={color1},bold,italic] {name} "
+
+              command_git_branch_command     "git rev-parse --abbrev-ref HEAD"
+              command_git_branch_format      "#[fg={color2}] {stdout} "
+              command_git_branch_interval    "10"
+              command_git_branch_rendermode  "static"
+
+              datetime        "#[fg={color4},bold] {format} "
+              datetime_format "%A, %d %b %Y %H:%M"
+              datetime_timezone "Europe/Berlin"
+            }
+        }
+        children
+    }
+}
+`
+
+const openCodeTheme = `{
+  "$schema": "https://opencode.ai/theme.json",
+  "theme": {
+    "background": "none",
+    "backgroundPanel": "{bg}",
+    "backgroundElement": "{bg}",
+    "text": "{fg}",
+    "textMuted": "{muted}",
+    "primary": "{color4}",
+    "secondary": "{color12}",
+    "accent": "{cursor}",
+    "error": "{color1}",
+    "warning": "{accent}",
+    "success": "{color2}",
+    "info": "{color4}",
+    "border": "{surface}",
+    "borderActive": "{color4}",
+    "borderSubtle": "{surface}",
+    "diffAdded": "{color2}",
+    "diffRemoved": "{color1}",
+    "diffContext": "{muted}",
+    "diffHunkHeader": "{muted}",
+    "diffHighlightAdded": "{color10}",
+    "diffHighlightRemoved": "{color9}",
+    "diffAddedBg": "{surface}",
+    "diffRemovedBg": "{surface}",
+    "diffContextBg": "{bg}",
+    "diffLineNumber": "{muted}",
+    "diffAddedLineNumberBg": "{surface}",
+    "diffRemovedLineNumberBg": "{surface}",
+    "markdownText": "{fg}",
+    "markdownHeading": "{color5}",
+    "markdownLink": "{color4}",
+    "markdownLinkText": "{color12}",
+    "markdownCode": "{color2}",
+    "markdownBlockQuote": "{accent}",
+    "markdownEmph": "{color6}",
+    "markdownStrong": "{accent}",
+    "markdownHorizontalRule": "{muted}",
+    "markdownListItem": "{color4}",
+    "markdownListEnumeration": "{color12}",
+    "markdownImage": "{color4}",
+    "markdownImageText": "{color12}",
+    "markdownCodeBlock": "{fg}",
+    "syntaxComment": "{muted}",
+    "syntaxKeyword": "{color5}",
+    "syntaxFunction": "{color4}",
+    "syntaxVariable": "{fg}",
+    "syntaxString": "{color2}",
+    "syntaxNumber": "{accent}",
+    "syntaxType": "{color3}",
+    "syntaxOperator": "{color6}",
+    "syntaxPunctuation": "{muted}"
+  }
+}
+`
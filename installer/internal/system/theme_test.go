@@ -0,0 +1,155 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// themeHome installs the configs the repository ships in a fresh HOME
+func themeHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+
+	repo := filepath.Join("..", "..", "..")
+	for src, dst := range map[string]string{
+		"alacritty.toml":                                 ".config/alacritty/alacritty.toml",
+		"GentlemanKitty/kitty.conf":                      ".config/kitty/kitty.conf",
+		"GentlemanGhostty/config":                        ".config/ghostty/config",
+		".wezterm.lua":                                   ".config/wezterm/wezterm.lua",
+		"GentlemanTmux/tmux.conf":                        ".tmux.conf",
+		"GentlemanZellij/zellij/config.kdl":              ".config/zellij/config.kdl",
+		"starship.toml":                                  ".config/starship.toml",
+		"GentlemanNvim/nvim/lua/plugins/colorscheme.lua": ".config/nvim/lua/plugins/colorscheme.lua",
+		"GentlemanNvim/nvim/lua/plugins/ui.lua":          ".config/nvim/lua/plugins/ui.lua",
+		"GentlemanOpenCode/themes/gentleman.json":        ".config/opencode/themes/gentleman.json",
+		"GentlemanZed/zed/settings.json":                 ".config/zed/settings.json",
+	} {
+		data, err := os.ReadFile(filepath.Join(repo, src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(home, dst)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, data, 0644)
+	}
+	return home
+}
+
+func readHome(t *testing.T, home, rel string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(home, rel))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestApplyTheme(t *testing.T) {
+	home := themeHome(t)
+	originals := map[string]string{}
+	for _, target := range themeTargets {
+		originals[target.path] = readHome(t, home, target.path)
+	}
+
+	styled, err := ApplyTheme("tokyonight")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(styled) != len(themeTargets) {
+		t.Errorf("every shipped config should be styled, got %v", styled)
+	}
+	if CurrentTheme() != "tokyonight" {
+		t.Errorf("CurrentTheme = %q", CurrentTheme())
+	}
+
+	for rel, want := range map[string]string{
+		".config/alacritty/alacritty.toml":          `background = "#1a1b26"`,
+		".config/kitty/kitty.conf":                  "color9  #f7768e",
+		".config/ghostty/config":                    "palette = 4=#7aa2f7",
+		".config/wezterm/wezterm.lua":               `background = "#1a1b26"`,
+		".tmux.conf":                                "status-style \"bg=#1a1b26",
+		".config/zellij/config.kdl":                 `theme "gentleman-theme"`,
+		".config/zellij/themes/gentleman-theme.kdl": `bg "#1a1b26"`,
+		".config/starship.toml":                     `palette = "gentleman_theme"`,
+		".config/nvim/lua/plugins/colorscheme.lua":  `colorscheme = "tokyonight-night"`,
+		".config/nvim/lua/plugins/ui.lua":           `theme = "tokyonight"`,
+		".config/opencode/themes/gentleman.json":    `"backgroundPanel": "#1a1b26"`,
+	} {
+		if got := readHome(t, home, rel); !strings.Contains(got, want) {
+			t.Errorf("%s should contain %q", rel, want)
+		}
+	}
+
+	t.Run("blocks keep the file valid", func(t *testing.T) {
+		wezterm := readHome(t, home, ".config/wezterm/wezterm.lua")
+		if !strings.HasSuffix(strings.TrimSpace(wezterm), "return config") {
+			t.Error("the wezterm block should come before the return")
+		}
+		alacritty := readHome(t, home, ".config/alacritty/alacritty.toml")
+		if strings.Count(alacritty, "[colors.primary]") != 1 || strings.Contains(alacritty, "#06080f") {
+			t.Error("alacritty colors should be rewritten in place")
+		}
+		if !strings.Contains(alacritty, `black   = "#414868"`) {
+			t.Error("bright black should be rewritten in the bright table")
+		}
+	})
+
+	t.Run("switching keeps the originals", func(t *testing.T) {
+		if _, err := ApplyTheme("catppuccin"); err != nil {
+			t.Fatal(err)
+		}
+		if got := readHome(t, home, ".config/kitty/kitty.conf"); strings.Count(got, "gentleman:theme >>>") != 1 {
+			t.Error("the theme block should be replaced, not repeated")
+		}
+		state, _ := loadThemeState()
+		if state.Values[".config/starship.toml"]["palette"] != "gentleman" {
+			t.Errorf("the palette before the first theme should be kept, got %v", state.Values)
+		}
+	})
+
+	t.Run("default theme resets", func(t *testing.T) {
+		styled, err := ApplyTheme(DefaultTheme)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Contains(styled, "OpenCode") {
+			t.Errorf("reset should restore OpenCode, got %v", styled)
+		}
+		for path, want := range originals {
+			if got := readHome(t, home, path); got != want {
+				t.Errorf("%s differs from the original after the reset", path)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(home, ".config/zellij/themes/gentleman-theme.kdl")); !os.IsNotExist(err) {
+			t.Error("generated zellij theme should be removed")
+		}
+		if CurrentTheme() != DefaultTheme {
+			t.Errorf("CurrentTheme = %q after reset", CurrentTheme())
+		}
+	})
+
+	t.Run("unknown theme", func(t *testing.T) {
+		if _, err := ApplyTheme("solarized"); err == nil {
+			t.Error("expected an error for an unknown theme")
+		}
+	})
+}
+
+func TestApplyThemeMissingConfigs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+
+	styled, err := ApplyTheme("kanagawa")
+	if err != nil || len(styled) != 0 {
+		t.Errorf("nothing installed, nothing styled: %v, %v", styled, err)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".config/zellij")); !os.IsNotExist(err) {
+		t.Error("a theme should not create configs of tools that are not installed")
+	}
+}
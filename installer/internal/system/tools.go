@@ -0,0 +1,195 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+)
+
+// ToolCategory is the installer question a tool answers
+type ToolCategory string
+
+const (
+	ToolTerminal ToolCategory = "terminal"
+	ToolShell    ToolCategory = "shell"
+	ToolWM       ToolCategory = "wm"
+)
+
+// ToolFile is a file or directory of the repository a tool installs
+type ToolFile struct {
+	Source    string // path in the repository
+	Dest      string // path relative to HOME
+	DestMacOS string // Dest on macOS, when it differs
+}
+
+// Tool describes a terminal, shell or window manager the installer offers.
+// The CLI flags, TUI menus, install steps and backups are built from it.
+type Tool struct {
+	ID       string // flag and profile value, "nushell"
+	Name     string // display name, "Nushell"
+	Category ToolCategory
+	Command  string              // binary, also used as the login shell ("nu")
+	App      string              // macOS app bundle, when it is one
+	Packages map[string][]string // logical packages per manager name, "" for any other
+	Files    []ToolFile
+	Configs  map[string]string // backup keys and the paths they cover, relative to HOME
+}
+
+// Tools is the registry of the tools the installer can set up, in menu order
+var Tools = []Tool{
+	{
+		ID: "alacritty", Name: "Alacritty", Category: ToolTerminal, Command: "alacritty", App: "Alacritty.app",
+		Packages: map[string][]string{"": {"alacritty"}},
+		Files:    []ToolFile{{Source: "alacritty.toml", Dest: ".config/alacritty/alacritty.toml"}},
+		Configs:  map[string]string{"alacritty": ".config/alacritty"},
+	},
+	{
+		ID: "wezterm", Name: "WezTerm", Category: ToolTerminal, Command: "wezterm", App: "WezTerm.app",
+		Packages: map[string][]string{"": {"wezterm"}},
+		Files:    []ToolFile{{Source: ".wezterm.lua", Dest: ".config/wezterm/wezterm.lua"}},
+		Configs:  map[string]string{"wezterm": ".wezterm.lua"},
+	},
+	{
+		ID: "kitty", Name: "Kitty", Category: ToolTerminal, Command: "kitty", App: "kitty.app",
+		Packages: map[string][]string{"": {"kitty"}},
+		Files:    []ToolFile{{Source: "GentlemanKitty", Dest: ".config/kitty"}},
+		Configs:  map[string]string{"kitty": ".config/kitty"},
+	},
+	{
+		ID: "ghostty", Name: "Ghostty", Category: ToolTerminal, Command: "ghostty", App: "Ghostty.app",
+		Packages: map[string][]string{"": {"ghostty"}},
+		Files:    []ToolFile{{Source: "GentlemanGhostty", Dest: ".config/ghostty"}},
+		Configs:  map[string]string{"ghostty": ".config/ghostty"},
+	},
+	{
+		ID: "fish", Name: "Fish", Category: ToolShell, Command: "fish",
+		Packages: map[string][]string{"": {"fish", "carapace", "zoxide", "atuin", "starship"}},
+		Files: []ToolFile{
+			{Source: "starship.toml", Dest: ".config/starship.toml"},
+			{Source: "GentlemanFish/fish", Dest: ".config/fish"},
+		},
+		Configs: map[string]string{"fish": ".config/fish"},
+	},
+	{
+		ID: "zsh", Name: "Zsh", Category: ToolShell, Command: "zsh",
+		Packages: map[string][]string{
+			"": {"zsh", "carapace", "zoxide", "atuin", "zsh-autosuggestions", "zsh-syntax-highlighting", "zsh-autocomplete", "powerlevel10k"},
+			// Termux has zsh in pkg, but plugins need to be installed differently
+			ManagerPkg: {"zsh", "starship", "zoxide"},
+		},
+		Files: []ToolFile{
+			{Source: "GentlemanZsh/.zshrc", Dest: ".zshrc"},
+			{Source: "GentlemanZsh/.p10k.zsh", Dest: ".p10k.zsh"},
+			{Source: "GentlemanZsh/.oh-my-zsh", Dest: ".oh-my-zsh"},
+		},
+		Configs: map[string]string{"zsh": ".zshrc", "zsh_p10k": ".p10k.zsh", "oh-my-zsh": ".oh-my-zsh"},
+	},
+	{
+		ID: "nushell", Name: "Nushell", Category: ToolShell, Command: "nu",
+		Packages: map[string][]string{"": {"nushell", "carapace", "zoxide", "atuin", "jq", "bash", "starship"}},
+		Files: []ToolFile{
+			{Source: "starship.toml", Dest: ".config/starship.toml"},
+			{Source: "bash-env-json", Dest: ".config/bash-env-json"},
+			{Source: "bash-env.nu", Dest: ".config/bash-env.nu"},
+			{Source: "GentlemanNushell", Dest: ".config/nushell", DestMacOS: "Library/Application Support/nushell"},
+		},
+		Configs: map[string]string{"nushell": ".config/nushell"},
+	},
+	{
+		ID: "bash", Name: "Bash", Category: ToolShell, Command: "bash",
+		Packages: map[string][]string{
+			"":         {"bash", "bash-completion@2", "carapace", "zoxide", "atuin", "fzf", "starship"},
+			ManagerPkg: {"bash", "bash-completion", "starship", "zoxide", "fzf"},
+		},
+		Files: []ToolFile{
+			{Source: "starship.toml", Dest: ".config/starship.toml"},
+			{Source: "GentlemanBash/.bashrc", Dest: ".bashrc"},
+		},
+		Configs: map[string]string{"bash": ".bashrc"},
+	},
+	{
+		ID: "tmux", Name: "Tmux", Category: ToolWM, Command: "tmux",
+		Packages: map[string][]string{"": {"tmux"}},
+		Files: []ToolFile{
+			{Source: "GentlemanTmux/plugins", Dest: ".tmux/plugins"},
+			{Source: "GentlemanTmux/tmux.conf", Dest: ".tmux.conf"},
+		},
+		Configs: map[string]string{"tmux": ".tmux.conf"},
+	},
+	{
+		ID: "zellij", Name: "Zellij", Category: ToolWM, Command: "zellij",
+		Packages: map[string][]string{"": {"zellij"}},
+		Files:    []ToolFile{{Source: "GentlemanZellij/zellij", Dest: ".config/zellij"}},
+		Configs:  map[string]string{"zellij": ".config/zellij"},
+	},
+}
+
+// ToolsIn returns the registered tools of a category, in menu order
+func ToolsIn(category ToolCategory) []Tool {
+	var tools []Tool
+	for _, t := range Tools {
+		if t.Category == category {
+			tools = append(tools, t)
+		}
+	}
+	return tools
+}
+
+// ToolIDs returns the IDs of the tools of a category, followed by extra
+func ToolIDs(category ToolCategory, extra ...string) []string {
+	var ids []string
+	for _, t := range ToolsIn(category) {
+		ids = append(ids, t.ID)
+	}
+	return append(ids, extra...)
+}
+
+// FindTool returns the registered tool with the given ID
+func FindTool(id string) (Tool, bool) {
+	i := slices.IndexFunc(Tools, func(t Tool) bool { return t.ID == id })
+	if i < 0 {
+		return Tool{}, false
+	}
+	return Tools[i], true
+}
+
+// PackagesFor returns the packages to install the tool with the given
+// package manager
+func (t Tool) PackagesFor(manager string) []string {
+	if packages, ok := t.Packages[manager]; ok {
+		return packages
+	}
+	return t.Packages[""]
+}
+
+// DestPath returns the absolute path the file is installed to on this
+// system
+func (f ToolFile) DestPath() string {
+	dest := f.Dest
+	if runtime.GOOS == "darwin" && f.DestMacOS != "" {
+		dest = f.DestMacOS
+	}
+	return filepath.Join(os.Getenv("HOME"), dest)
+}
+
+// InstallFiles copies the tool files from the repository, or links them in
+// link mode, creating the parent directories as needed
+func (t Tool) InstallFiles(repoDir string) error {
+	for _, f := range t.Files {
+		src, dst := filepath.Join(repoDir, f.Source), f.DestPath()
+		if err := EnsureDir(filepath.Dir(dst)); err != nil {
+			return err
+		}
+		var err error
+		if info, statErr := os.Stat(src); statErr == nil && info.IsDir() {
+			err = CopyDir(src, dst)
+		} else {
+			err = CopyFile(src, dst)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
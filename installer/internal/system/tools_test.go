@@ -0,0 +1,76 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestToolRegistry(t *testing.T) {
+	t.Run("ids are unique", func(t *testing.T) {
+		seen := map[string]bool{}
+		for _, tool := range Tools {
+			if seen[tool.ID] {
+				t.Errorf("duplicate tool %q", tool.ID)
+			}
+			seen[tool.ID] = true
+			if tool.Name == "" || tool.Command == "" || len(tool.PackagesFor("")) == 0 {
+				t.Errorf("tool %q is missing a name, command or packages", tool.ID)
+			}
+		}
+	})
+
+	t.Run("configs are backed up", func(t *testing.T) {
+		paths := ConfigPaths()
+		for _, tool := range Tools {
+			for key := range tool.Configs {
+				if _, ok := paths[key]; !ok {
+					t.Errorf("config %q of %s missing from ConfigPaths", key, tool.ID)
+				}
+			}
+		}
+	})
+
+	t.Run("ids by category", func(t *testing.T) {
+		if got := ToolIDs(ToolWM, "none"); !slices.Equal(got, []string{"tmux", "zellij", "none"}) {
+			t.Errorf("ToolIDs(wm) = %v", got)
+		}
+		if got := ToolIDs(ToolShell); !slices.Equal(got, []string{"fish", "zsh", "nushell", "bash"}) {
+			t.Errorf("ToolIDs(shell) = %v", got)
+		}
+		if _, ok := FindTool("none"); ok {
+			t.Error("none is not a tool")
+		}
+	})
+
+	t.Run("packages per manager", func(t *testing.T) {
+		zsh, _ := FindTool("zsh")
+		if got := zsh.PackagesFor(ManagerPkg); slices.Contains(got, "powerlevel10k") {
+			t.Errorf("Termux zsh packages = %v", got)
+		}
+		if got := zsh.PackagesFor(ManagerBrew); !slices.Contains(got, "powerlevel10k") {
+			t.Errorf("brew zsh packages = %v", got)
+		}
+	})
+}
+
+func TestToolInstallFiles(t *testing.T) {
+	home, repo := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+
+	os.MkdirAll(filepath.Join(repo, "GentlemanTmux", "plugins", "tpm"), 0755)
+	os.WriteFile(filepath.Join(repo, "GentlemanTmux", "plugins", "tpm", "tpm"), []byte("#!/bin/sh\n"), 0755)
+	os.WriteFile(filepath.Join(repo, "GentlemanTmux", "tmux.conf"), []byte("set -g mouse on\n"), 0644)
+
+	tmux, _ := FindTool("tmux")
+	if err := tmux.InstallFiles(repo); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{".tmux.conf", ".tmux/plugins/tpm/tpm"} {
+		if _, err := os.Stat(filepath.Join(home, path)); err != nil {
+			t.Errorf("%s not installed: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// UpdateAction is what an update does to a managed file
+type UpdateAction string
+
+const (
+	UpdateFastForward UpdateAction = "fast-forward" // Not edited locally, replaced with the new version
+	UpdateMerged      UpdateAction = "merged"       // Local edits kept, upstream changes merged cleanly
+	UpdateConflict    UpdateAction = "conflict"     // Both sides changed the same lines
+)
+
+// UpdateFile is a managed file that changed in the repository
+type UpdateFile struct {
+	Dest   string
+	Source string // The new version in the repository
+	Action UpdateAction
+	Merge  *Merge // Set for merged and conflicting files
+}
+
+// Content returns what the file is updated to
+func (f *UpdateFile) Content() ([]byte, error) {
+	if f.Merge != nil {
+		return []byte(f.Merge.Text()), nil
+	}
+	return os.ReadFile(f.Source)
+}
+
+// UpdatePlan lists the managed files to update from a newer repository
+type UpdatePlan struct {
+	Repo    string
+	Files   []*UpdateFile
+	Skipped []string // "path: reason" for changed files that are left alone
+}
+
+// Count returns how many files get the action
+func (p *UpdatePlan) Count(action UpdateAction) int {
+	n := 0
+	for _, f := range p.Files {
+		if f.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// Unresolved returns how many conflicts are still unresolved
+func (p *UpdatePlan) Unresolved() int {
+	n := 0
+	for _, f := range p.Files {
+		if f.Merge != nil {
+			n += f.Merge.Unresolved()
+		}
+	}
+	return n
+}
+
+// PlanUpdate compares every copied file in the manifest with its source in
+// repoDir. Files the user did not edit are fast-forwarded; for edited ones
+// the upstream changes are merged into the local copy, using the content
+// installed originally as the base.
+func PlanUpdate(manifest *Manifest, repoDir string) *UpdatePlan {
+	plan := &UpdatePlan{Repo: repoDir}
+	for _, entry := range manifest.Files {
+		if entry.Source == "" {
+			continue
+		}
+		source := entry.Source
+		if !filepath.IsAbs(source) {
+			source = filepath.Join(repoDir, source)
+		}
+
+		theirs, err := os.ReadFile(source)
+		if err != nil {
+			plan.Skipped = append(plan.Skipped, entry.Dest+": removed from the repository")
+			continue
+		}
+		theirSum := dataSHA256(theirs)
+		if theirSum == entry.SourceSHA256 {
+			continue
+		}
+		ours, err := os.ReadFile(entry.Dest)
+		if err != nil {
+			plan.Skipped = append(plan.Skipped, entry.Dest+": deleted locally")
+			continue
+		}
+
+		file := &UpdateFile{Dest: entry.Dest, Source: source, Action: UpdateFastForward}
+		if ourSum := dataSHA256(ours); ourSum != entry.SourceSHA256 && ourSum != theirSum {
+			// Without the installed content every difference is a conflict
+			base, _ := ReadBlob(entry.SourceSHA256)
+			file.Merge = Merge3(string(base), string(ours), string(theirs))
+			file.Action = UpdateMerged
+			if len(file.Merge.Conflicts()) > 0 {
+				file.Action = UpdateConflict
+			}
+		}
+		plan.Files = append(plan.Files, file)
+	}
+	return plan
+}
+
+// ApplyUpdate backs up the configs holding the files, then writes them and
+// records their new sources. It returns the backup directory, which holds
+// the installer's files, so uninstall never restores it.
+func ApplyUpdate(plan *UpdatePlan) (string, error) {
+	if len(plan.Files) == 0 {
+		return "", nil
+	}
+
+	configPaths := ConfigPaths()
+	var keys []string
+	for _, f := range plan.Files {
+		if key := configKeyFor(f.Dest, configPaths); key != "" && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	backupDir := ""
+	if len(keys) > 0 {
+		dir, err := CreateBackupFor(keys, "update", nil)
+		if err != nil {
+			return dir, err
+		}
+		backupDir = dir
+	}
+
+	var errs []error
+	for _, f := range plan.Files {
+		content, err := f.Content()
+		if err == nil {
+			err = WriteFile(f.Dest, content, 0644)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to update %s: %w", f.Dest, err))
+			continue
+		}
+		recordUpdate(f.Dest, f.Source)
+	}
+	return backupDir, errors.Join(errs...)
+}
+
+// ShallowClone clones the latest version of a repository into a new
+// temporary directory, which the caller removes
+func ShallowClone(url string) (string, error) {
+	dir, err := os.MkdirTemp("", "javi-dots-")
+	if err != nil {
+		return "", err
+	}
+	// Run directly rather than with Run: the clone only reads, so dry runs
+	// need it too
+	if output, err := exec.Command("git", "clone", "--depth", "1", url, dir).CombinedOutput(); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("failed to clone %s: %w: %s", url, err, strings.TrimSpace(string(output)))
+	}
+	return dir, nil
+}
+
+// FormatUpdatePlan renders the plan grouped by action
+func FormatUpdatePlan(plan *UpdatePlan) string {
+	var sb strings.Builder
+	if len(plan.Files) == 0 && len(plan.Skipped) == 0 {
+		return "Everything is up to date\n"
+	}
+	sections := []struct {
+		action UpdateAction
+		title  string
+	}{
+		{UpdateFastForward, "New version (not edited locally)"},
+		{UpdateMerged, "Merged with your changes"},
+		{UpdateConflict, "Conflicts"},
+	}
+	for _, section := range sections {
+		if plan.Count(section.action) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "%s:\n", section.title)
+		for _, f := range plan.Files {
+			if f.Action != section.action {
+				continue
+			}
+			if f.Merge != nil && f.Action == UpdateConflict {
+				fmt.Fprintf(&sb, "  • %s (%d conflict(s))\n", f.Dest, len(f.Merge.Conflicts()))
+			} else {
+				fmt.Fprintf(&sb, "  • %s\n", f.Dest)
+			}
+		}
+	}
+	if len(plan.Skipped) > 0 {
+		sb.WriteString("Skipped:\n")
+		for _, s := range plan.Skipped {
+			fmt.Fprintf(&sb, "  • %s\n", s)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,110 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPlanUpdate(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+	manifestPending = map[string]ManifestEntry{}
+
+	repo := filepath.Join(t.TempDir(), "Javi.Dots")
+	write := func(path, content string) {
+		t.Helper()
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(repo, "tmux", "tmux.conf"), "set -g mouse on\nset -g base-index 1\n")
+	write(filepath.Join(repo, "zsh", ".zshrc"), "plugins=(git)\nexport EDITOR=nvim\nalias ll='ls -l'\n")
+	write(filepath.Join(repo, "starship.toml"), "format = ''\n")
+	write(filepath.Join(repo, "kitty.conf"), "font_size 12\n")
+
+	tmux := filepath.Join(home, ".tmux.conf")
+	zshrc := filepath.Join(home, ".zshrc")
+	starship := filepath.Join(home, ".config", "starship.toml")
+	kitty := filepath.Join(home, ".config", "kitty", "kitty.conf")
+	CopyFile(filepath.Join(repo, "tmux", "tmux.conf"), tmux)
+	CopyFile(filepath.Join(repo, "zsh", ".zshrc"), zshrc)
+	EnsureDir(filepath.Dir(starship))
+	CopyFile(filepath.Join(repo, "starship.toml"), starship)
+	EnsureDir(filepath.Dir(kitty))
+	CopyFile(filepath.Join(repo, "kitty.conf"), kitty)
+	if err := SaveManifest(repo, "https://example.com/dots.git"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Local edits, then a newer repository
+	write(zshrc, "plugins=(git fzf)\nexport EDITOR=nvim\nalias ll='ls -l'\n")
+	write(starship, "format = '$all'\n")
+	write(filepath.Join(repo, "tmux", "tmux.conf"), "set -g mouse on\nset -g base-index 0\n")
+	write(filepath.Join(repo, "zsh", ".zshrc"), "plugins=(git)\nexport EDITOR=nvim\nalias ll='eza -l'\n")
+	write(filepath.Join(repo, "starship.toml"), "format = '$directory'\n")
+	os.Remove(filepath.Join(repo, "kitty.conf"))
+
+	manifest, _ := LoadManifest()
+	plan := PlanUpdate(manifest, repo)
+	actions := map[string]UpdateAction{}
+	for _, f := range plan.Files {
+		actions[f.Dest] = f.Action
+	}
+
+	t.Run("plan", func(t *testing.T) {
+		want := map[string]UpdateAction{tmux: UpdateFastForward, zshrc: UpdateMerged, starship: UpdateConflict}
+		if len(actions) != len(want) {
+			t.Fatalf("expected %d files, got %v", len(want), actions)
+		}
+		for dest, action := range want {
+			if actions[dest] != action {
+				t.Errorf("%s: got %q, want %q", dest, actions[dest], action)
+			}
+		}
+		if len(plan.Skipped) != 1 || !strings.Contains(plan.Skipped[0], "removed from the repository") {
+			t.Errorf("kitty.conf should be skipped, got %v", plan.Skipped)
+		}
+		if plan.Unresolved() != 1 {
+			t.Errorf("expected one unresolved conflict, got %d", plan.Unresolved())
+		}
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		for _, f := range plan.Files {
+			if f.Merge != nil {
+				f.Merge.ResolveAll(TakeTheirs)
+			}
+		}
+		backupDir, err := ApplyUpdate(plan)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(filepath.Join(backupDir, "zsh")); err != nil {
+			t.Error("configs should be backed up before updating")
+		}
+		if backup, ok := OriginalBackup("zsh"); ok {
+			t.Errorf("uninstall should not restore the update backup, got %s", backup.Path)
+		}
+		if got, _ := os.ReadFile(zshrc); string(got) != "plugins=(git fzf)\nexport EDITOR=nvim\nalias ll='eza -l'\n" {
+			t.Errorf("local edit and upstream change should both be kept, got %q", got)
+		}
+		if got, _ := os.ReadFile(starship); string(got) != "format = '$directory'\n" {
+			t.Errorf("conflict should take upstream, got %q", got)
+		}
+
+		if err := SaveManifest(repo, ""); err != nil {
+			t.Fatal(err)
+		}
+		manifest, _ := LoadManifest()
+		if plan := PlanUpdate(manifest, repo); len(plan.Files) != 0 {
+			t.Errorf("nothing should be left to update, got %+v", plan.Files)
+		}
+		if status := CompareManifest(manifest, repo); status.Count(DriftModified)+status.Count(DriftUpstream) != 0 {
+			t.Errorf("updated files should not drift, got %+v", status.Drifted)
+		}
+	})
+}
@@ -0,0 +1,216 @@
+package tui
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Options of ScreenInstallCancelled
+const (
+	quitCancelledOption     = "🛑 Quit and resume later"
+	rollbackCancelledOption = "↩️  Roll back changes"
+)
+
+// ctx is the context the commands of a step run under
+func (m *Model) ctx() context.Context {
+	if m.InstallCtx == nil {
+		return context.Background()
+	}
+	return m.InstallCtx
+}
+
+// startInstallContext gives the installation a fresh context, unless it
+// already runs under one that can still be cancelled
+func (m *Model) startInstallContext() {
+	if m.InstallCtx != nil && m.InstallCtx.Err() == nil {
+		return
+	}
+	m.InstallCtx, m.CancelInstall = context.WithCancel(context.Background())
+	m.Cancelling = false
+}
+
+// installRunning reports whether ctrl+c should cancel the installation
+// instead of quitting
+func (m Model) installRunning() bool {
+	onInstall := m.Screen == ScreenInstalling || (m.Screen == ScreenLogViewer && m.LogPrevScreen == ScreenInstalling)
+	return onInstall && runningSteps(m.Steps) > 0
+}
+
+// handleCancelKey asks before cancelling the installation. Pressing ctrl+c
+// again confirms, and quits right away once the steps are being stopped.
+func (m Model) handleCancelKey() (tea.Model, tea.Cmd) {
+	switch {
+	case m.Cancelling:
+		return m.quitNow()
+	case m.CancelConfirm:
+		return m.cancelInstall()
+	}
+	// The question is on the installing screen
+	if m.Screen == ScreenLogViewer {
+		m.Screen = ScreenInstalling
+	}
+	m.CancelConfirm = true
+	return m, nil
+}
+
+// quitNow quits without waiting for the running steps. Their commands run
+// in their own process groups, out of reach of the terminal's hangup, so
+// they are cancelled and killed first.
+func (m Model) quitNow() (tea.Model, tea.Cmd) {
+	if m.CancelInstall != nil {
+		m.CancelInstall()
+	}
+	system.KillGroups()
+	m.Quitting = true
+	return m, tea.Quit
+}
+
+func (m Model) handleCancelConfirmKeys(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "y", "Y", "enter":
+		return m.cancelInstall()
+	case "n", "N", "esc":
+		m.CancelConfirm = false
+	}
+	return m, nil
+}
+
+// cancelInstall stops the commands of the running steps. Each step reports
+// back through finishCancelledStep.
+func (m Model) cancelInstall() (tea.Model, tea.Cmd) {
+	m.CancelConfirm = false
+	m.Cancelling = true
+	m.logStepEvent("", "⏹ cancelling the installation")
+	if m.CancelInstall != nil {
+		m.CancelInstall()
+	}
+	if runningSteps(m.Steps) == 0 {
+		return m.installCancelled(), nil
+	}
+	return m, nil
+}
+
+// cancelThenRollback stops the steps still running after a failure and
+// rolls back once they returned, so none of their changes are left behind
+func (m Model) cancelThenRollback() (tea.Model, tea.Cmd) {
+	m.RollbackNext = true
+	m.Screen = ScreenInstalling
+	return m.cancelInstall()
+}
+
+// finishCancelledStep records a step that returned after the installation
+// was cancelled. A step that did not finish goes back to pending, so
+// resuming runs it again.
+func (m Model) finishCancelledStep(stepID string, err error) Model {
+	for i := range m.Steps {
+		if m.Steps[i].ID != stepID {
+			continue
+		}
+		if err == nil {
+			m.Steps[i].Status = StatusDone
+			m.Steps[i].Progress = 1.0
+			m.recordStep(stepID, nil)
+			m.logStepEvent(stepID, "✓ done")
+		} else {
+			m.Steps[i].Status = StatusPending
+			m.Steps[i].Progress = 0
+			if m.Journal != nil {
+				_ = m.Journal.Record(stepID, StatusPending, system.ErrCancelled)
+			}
+			m.logStepEvent(stepID, "⏹ cancelled")
+		}
+		break
+	}
+
+	if runningSteps(m.Steps) == 0 {
+		return m.installCancelled()
+	}
+	return m
+}
+
+// installCancelled shows the cancelled installation once no step runs, or
+// rolls it back when that was asked for
+func (m Model) installCancelled() Model {
+	m.Cancelling = false
+	if m.RollbackNext {
+		m.RollbackNext = false
+		return m.rollbackInstall()
+	}
+	m.Screen = ScreenInstallCancelled
+	m.Cursor = 0
+	return m
+}
+
+func (m Model) handleInstallCancelledKeys(key string) (tea.Model, tea.Cmd) {
+	options := m.GetCurrentOptions()
+
+	switch key {
+	case "up", "k":
+		if m.Cursor > 0 {
+			m.Cursor--
+		}
+	case "down", "j":
+		if m.Cursor < len(options)-1 {
+			m.Cursor++
+		}
+	case "l":
+		return m.openLogViewer(), nil
+	case "enter", " ":
+		if options[m.Cursor] == rollbackCancelledOption {
+			return m.rollbackInstall(), nil
+		}
+		m.Quitting = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m Model) renderInstallCancelled() string {
+	var s strings.Builder
+
+	s.WriteString(TitleStyle.Render(m.GetScreenTitle()))
+	s.WriteString("\n\n")
+
+	var done, left []string
+	for _, step := range m.Steps {
+		switch step.Status {
+		case StatusDone, StatusSkipped:
+			done = append(done, step.Name)
+		default:
+			left = append(left, step.Name)
+		}
+	}
+	if len(done) > 0 {
+		s.WriteString(SuccessStyle.Render("✓ Finished: " + strings.Join(done, ", ")))
+		s.WriteString("\n")
+	}
+	if len(left) > 0 {
+		s.WriteString(MutedStyle.Render("○ Not done: " + strings.Join(left, ", ")))
+		s.WriteString("\n")
+	}
+	s.WriteString("\n")
+	if m.Journal != nil {
+		s.WriteString(InfoStyle.Render("Your progress is saved: run the installer again to resume."))
+		s.WriteString("\n\n")
+	}
+
+	for i, opt := range m.GetCurrentOptions() {
+		cursor := "  "
+		style := UnselectedStyle
+		if i == m.Cursor {
+			cursor = "▸ "
+			style = SelectedStyle
+		}
+		s.WriteString(style.Render(cursor + opt))
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	s.WriteString(m.renderLogPath())
+	s.WriteString(HelpStyle.Render("↑/k up • ↓/j down • [Enter] select • [l] full log"))
+
+	return s.String()
+}
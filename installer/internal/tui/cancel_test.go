@@ -0,0 +1,121 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCancelInstall(t *testing.T) {
+	ctrlC := tea.KeyMsg{Type: tea.KeyCtrlC}
+	update := func(m Model, msg tea.Msg) (Model, tea.Cmd) {
+		result, cmd := m.Update(msg)
+		return result.(Model), cmd
+	}
+
+	m := journalModel(t)
+	m.Steps = []InstallStep{
+		{ID: "clone", Name: "Clone", Status: StatusDone},
+		{ID: "shell", Name: "Shell", Status: StatusRunning},
+		{ID: "nvim", Name: "Neovim", Status: StatusRunning},
+		{ID: "cleanup", Name: "Cleanup", Status: StatusPending},
+	}
+	m.Journal = NewJournal(&m)
+	m.Screen = ScreenInstalling
+	m.startInstallContext()
+	ctx := m.ctx()
+
+	m, cmd := update(m, ctrlC)
+	if !m.CancelConfirm || cmd != nil || m.Quitting {
+		t.Fatal("ctrl+c should ask before cancelling")
+	}
+	if m = press(m, "n"); m.CancelConfirm || ctx.Err() != nil {
+		t.Fatal("n should keep installing")
+	}
+
+	m, _ = update(m, ctrlC)
+	m = press(m, "y")
+	if !m.Cancelling || !errors.Is(ctx.Err(), context.Canceled) {
+		t.Fatal("y should cancel the running commands")
+	}
+
+	// One step finished before the cancel reached it, the other was stopped
+	m, _ = update(m, stepCompleteMsg{stepID: "shell"})
+	if m.Screen != ScreenInstalling || m.Steps[1].Status != StatusDone {
+		t.Fatalf("a finished step stays done, got %v on %v", m.Steps[1].Status, m.Screen)
+	}
+	m, cmd = update(m, stepCompleteMsg{stepID: "nvim", err: &system.ExecError{Wrapped: system.ErrCancelled}})
+	if m.Screen != ScreenInstallCancelled || cmd != nil {
+		t.Fatalf("expected the cancelled screen once no step runs, got %v", m.Screen)
+	}
+	if m.Steps[2].Status != StatusPending || m.Steps[3].Status != StatusPending {
+		t.Error("the stopped step should be pending and nothing else should start")
+	}
+
+	j, err := LoadJournal()
+	if err != nil || j == nil {
+		t.Fatalf("the journal should be kept: %v", err)
+	}
+	for _, step := range j.Steps {
+		if step.Status == StatusRunning.String() {
+			t.Errorf("no step should be left running: %+v", step)
+		}
+	}
+	if !j.IsDone("shell") || j.IsDone("nvim") || j.ResumeStep().ID != "nvim" {
+		t.Errorf("resuming should start at the stopped step, got %+v", j.Steps)
+	}
+
+	if m, cmd = update(m, tea.KeyMsg{Type: tea.KeyEnter}); !m.Quitting || cmd == nil {
+		t.Error("quitting should keep the journal and exit")
+	}
+}
+
+func TestCtrlCQuitsOutsideInstall(t *testing.T) {
+	m := NewModel()
+	m.Screen = ScreenMainMenu
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	if !result.(Model).Quitting || cmd == nil {
+		t.Error("ctrl+c should still quit right away")
+	}
+}
+
+func TestQuitCancelsRunningSteps(t *testing.T) {
+	steps := func() []InstallStep {
+		return []InstallStep{
+			{ID: "shell", Name: "Shell", Status: StatusFailed},
+			{ID: "nvim", Name: "Neovim", Status: StatusRunning},
+		}
+	}
+
+	t.Run("aborting after a failed step", func(t *testing.T) {
+		m := NewModel()
+		m.Steps = steps()
+		m.Screen = ScreenError
+		m.startInstallContext()
+		ctx := m.ctx()
+
+		if m = press(m, "a"); !m.Quitting || ctx.Err() == nil {
+			t.Error("abort should cancel the step still running")
+		}
+	})
+
+	t.Run("ctrl+c while cancelling", func(t *testing.T) {
+		m := NewModel()
+		m.Steps = steps()
+		m.Screen = ScreenInstalling
+		cancelled := false
+		m.CancelInstall = func() { cancelled = true }
+		m.Cancelling = true
+
+		result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+		if !result.(Model).Quitting || cmd == nil {
+			t.Fatal("a second ctrl+c should quit right away")
+		}
+		if !cancelled {
+			t.Error("quitting should cancel the steps still running")
+		}
+	})
+}
@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -192,9 +193,9 @@ func TestRestoreBackupOptions(t *testing.T) {
 	opts := m.GetCurrentOptions()
 
 	t.Run("should list all backups", func(t *testing.T) {
-		// 2 backups + separator + back = 4
-		if len(opts) != 4 {
-			t.Errorf("Expected 4 options, got %d: %v", len(opts), opts)
+		// 2 backups + separator + import + back = 5
+		if len(opts) != 5 {
+			t.Errorf("Expected 5 options, got %d: %v", len(opts), opts)
 		}
 	})
 
@@ -877,13 +878,13 @@ func TestFontSelect(t *testing.T) {
 	t.Run("no skips font", func(t *testing.T) {
 		m := NewModel()
 		m.Screen = ScreenFontSelect
-		m.Cursor = 1 // No
+		m.Cursor = len(m.GetCurrentOptions()) - 1 // No
 
 		result, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
 		newModel := result.(Model)
 
 		if newModel.Choices.InstallFont {
-			t.Error("Cursor 1 should set InstallFont false")
+			t.Error("The last option should set InstallFont false")
 		}
 	})
 }
@@ -974,6 +975,18 @@ func TestBackupConfirmWithBackup(t *testing.T) {
 	}
 }
 
+func TestBackupConfirmListsAIConfigs(t *testing.T) {
+	m := NewModel()
+	m.Screen = ScreenBackupConfirm
+	m.ExistingConfigs = []string{"tmux: /home/u/.tmux.conf", "claude_settings: /home/u/.claude/settings.json"}
+
+	view := m.View()
+	heading := strings.Index(view, "AI tool configs:")
+	if heading < 0 || strings.Index(view, "claude_settings") < heading || strings.Index(view, "tmux") > heading {
+		t.Errorf("AI tool configs should be listed under their own heading:\n%s", view)
+	}
+}
+
 func TestBackupConfirmWithoutBackup(t *testing.T) {
 	m := NewModel()
 	m.Screen = ScreenBackupConfirm
@@ -1083,7 +1096,7 @@ func TestRestoreConfirmCancel(t *testing.T) {
 		{Path: "/backup1", Timestamp: time.Now(), Files: []string{"a"}},
 	}
 	m.SelectedBackup = 0
-	m.Cursor = 2 // Cancel
+	m.Cursor = slices.Index(m.GetCurrentOptions(), restoreCancelOption)
 
 	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
 	newModel := result.(Model)
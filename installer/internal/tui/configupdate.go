@@ -0,0 +1,136 @@
+package tui
+
+import (
+	"errors"
+	"os"
+
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// configUpdatePlanMsg carries the update plan computed against a fresh clone
+type configUpdatePlanMsg struct {
+	plan *system.UpdatePlan
+	err  error
+}
+
+// configUpdateAppliedMsg carries the result of applying an update plan
+type configUpdateAppliedMsg struct {
+	backupDir string
+	err       error
+}
+
+// planConfigUpdateCmd clones the repository the configs were installed
+// from, repoURL when unknown, and compares it with the install manifest
+func planConfigUpdateCmd(repoURL string) tea.Cmd {
+	return func() tea.Msg {
+		manifest, err := system.LoadManifest()
+		if err != nil {
+			return configUpdatePlanMsg{err: err}
+		}
+		if len(manifest.Files) == 0 {
+			return configUpdatePlanMsg{err: errors.New("no installed files are tracked yet, run the installer first")}
+		}
+		if manifest.RepoURL != "" {
+			repoURL = manifest.RepoURL
+		}
+		dir, err := system.ShallowClone(repoURL)
+		if err != nil {
+			return configUpdatePlanMsg{err: err}
+		}
+		return configUpdatePlanMsg{plan: system.PlanUpdate(manifest, dir)}
+	}
+}
+
+// applyConfigUpdateCmd writes the update plan, then removes the clone
+func applyConfigUpdateCmd(plan *system.UpdatePlan) tea.Cmd {
+	return func() tea.Msg {
+		defer os.RemoveAll(plan.Repo)
+		backupDir, err := system.ApplyUpdate(plan)
+		if saveErr := system.SaveManifest(plan.Repo, ""); err == nil {
+			err = saveErr
+		}
+		return configUpdateAppliedMsg{backupDir: backupDir, err: err}
+	}
+}
+
+// conflictFiles returns the files of the plan that have conflicts
+func conflictFiles(plan *system.UpdatePlan) []*system.UpdateFile {
+	if plan == nil {
+		return nil
+	}
+	var files []*system.UpdateFile
+	for _, f := range plan.Files {
+		if f.Action == system.UpdateConflict {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// currentConflict returns the conflict shown on the conflict screen
+func (m Model) currentConflict() (*system.UpdateFile, *system.MergeConflict) {
+	files := conflictFiles(m.ConfigUpdatePlan)
+	if m.ConflictFile >= len(files) {
+		return nil, nil
+	}
+	file := files[m.ConflictFile]
+	conflicts := file.Merge.Conflicts()
+	if m.ConflictHunk >= len(conflicts) {
+		return file, nil
+	}
+	return file, conflicts[m.ConflictHunk]
+}
+
+// nextConflict moves to the following hunk, across files. It reports
+// false on the last one.
+func (m *Model) nextConflict() bool {
+	files := conflictFiles(m.ConfigUpdatePlan)
+	if m.ConflictFile >= len(files) {
+		return false
+	}
+	if m.ConflictHunk < len(files[m.ConflictFile].Merge.Conflicts())-1 {
+		m.ConflictHunk++
+		return true
+	}
+	if m.ConflictFile < len(files)-1 {
+		m.ConflictFile++
+		m.ConflictHunk = 0
+		return true
+	}
+	return false
+}
+
+// prevConflict moves to the preceding hunk, across files
+func (m *Model) prevConflict() {
+	if m.ConflictHunk > 0 {
+		m.ConflictHunk--
+		return
+	}
+	if m.ConflictFile > 0 {
+		m.ConflictFile--
+		files := conflictFiles(m.ConfigUpdatePlan)
+		m.ConflictHunk = len(files[m.ConflictFile].Merge.Conflicts()) - 1
+	}
+}
+
+// discardConfigUpdate drops an update plan that was not applied
+func (m *Model) discardConfigUpdate() {
+	if m.ConfigUpdatePlan != nil && !m.ConfigUpdateApplied {
+		os.RemoveAll(m.ConfigUpdatePlan.Repo)
+	}
+	m.ConfigUpdatePlan = nil
+}
+
+// resolutionLabel describes how a conflict is settled
+func resolutionLabel(r system.Resolution) string {
+	switch r {
+	case system.KeepOurs:
+		return "keeping yours"
+	case system.TakeTheirs:
+		return "taking upstream"
+	case system.KeepBoth:
+		return "keeping both"
+	}
+	return "unresolved (keeps yours)"
+}
@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
+)
+
+func TestConfigUpdateScreens(t *testing.T) {
+	doctorHome(t)
+	m := NewModel()
+	m.Screen = ScreenMainMenu
+	for i, opt := range m.GetCurrentOptions() {
+		if strings.Contains(opt, "Update Configs") {
+			m.Cursor = i
+		}
+	}
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = result.(Model)
+	if m.Screen != ScreenConfigUpdate || !m.ConfigUpdateRunning || cmd == nil {
+		t.Fatalf("selecting Update Configs should fetch the repository, got %v", m.Screen)
+	}
+
+	plan := &system.UpdatePlan{Repo: t.TempDir(), Files: []*system.UpdateFile{
+		{Dest: "/home/u/.tmux.conf", Action: system.UpdateFastForward},
+		{Dest: "/home/u/.zshrc", Action: system.UpdateConflict, Merge: system.Merge3("a\nb\nc\nd\n", "A\nb\nc\nD1\n", "a2\nb\nc\nD2\n")},
+	}}
+	result, _ = m.Update(configUpdatePlanMsg{plan: plan})
+	m = result.(Model)
+	view := m.View()
+	for _, want := range []string{"/home/u/.tmux.conf", "/home/u/.zshrc (2 conflict(s))", "2 conflict(s) unresolved"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("plan view should contain %q:\n%s", want, view)
+		}
+	}
+
+	// Resolve conflicts hunk by hunk
+	m = press(m, "enter")
+	if m.Screen != ScreenConfigConflicts {
+		t.Fatalf("expected the conflict screen, got %v", m.Screen)
+	}
+	if view := m.View(); !strings.Contains(view, "Conflict 1/2") || !strings.Contains(view, "A") {
+		t.Errorf("conflict screen should show the first hunk:\n%s", view)
+	}
+	m = press(m, "t")
+	if m.ConflictHunk != 1 {
+		t.Errorf("resolving should move to the next conflict, got %d", m.ConflictHunk)
+	}
+	m = press(m, "o")
+	if text := plan.Files[1].Merge.Text(); text != "a2\nb\nc\nD1\n" {
+		t.Errorf("unexpected merge %q", text)
+	}
+	if !strings.Contains(m.View(), "All conflicts resolved") {
+		t.Error("conflict screen should report everything resolved")
+	}
+
+	m = press(m, "esc")
+	if m.Screen != ScreenConfigUpdate {
+		t.Fatalf("esc should go back to the plan, got %v", m.Screen)
+	}
+	opts := m.GetCurrentOptions()
+	if len(opts) != 3 || !strings.Contains(opts[1], "Apply update") {
+		t.Fatalf("unexpected options %v", opts)
+	}
+	m.Cursor = 1
+	result, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = result.(Model)
+	if !m.ConfigUpdateRunning || cmd == nil {
+		t.Fatal("applying should start the update")
+	}
+
+	result, _ = m.Update(configUpdateAppliedMsg{backupDir: "/home/u/.gentleman-backup-x"})
+	m = result.(Model)
+	if view := m.View(); !strings.Contains(view, "Updated 2 file(s)") || !strings.Contains(view, ".gentleman-backup-x") {
+		t.Errorf("result should show the update and backup:\n%s", view)
+	}
+	if m = press(m, "enter"); m.Screen != ScreenMainMenu {
+		t.Errorf("enter should go back to the main menu, got %v", m.Screen)
+	}
+}
@@ -0,0 +1,348 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// CheckStatus is the outcome of a doctor check
+type CheckStatus string
+
+const (
+	CheckPass CheckStatus = "pass"
+	CheckWarn CheckStatus = "warn"
+	CheckFail CheckStatus = "fail"
+)
+
+// Doctor check categories, in report order
+const (
+	CategoryBinaries = "Binaries"
+	CategoryConfigs  = "Configs"
+	CategoryShells   = "Shell syntax"
+	CategoryEnv      = "Environment"
+)
+
+// DoctorCheck is one line of the doctor report. Hint says how to fix a
+// warning or failure.
+type DoctorCheck struct {
+	Category string
+	Name     string
+	Status   CheckStatus
+	Detail   string
+	Hint     string
+}
+
+// doctorTool is a tool the installer configures
+type doctorTool struct {
+	name     string
+	binaries []string // any of them on PATH counts as installed
+	app      string   // macOS app bundle, for GUI apps not on PATH
+	config   string   // ConfigPaths key
+}
+
+var doctorTools = []doctorTool{
+	{name: "Neovim", binaries: []string{"nvim"}, config: "nvim"},
+	{name: "Fish", binaries: []string{"fish"}, config: "fish"},
+	{name: "Zsh", binaries: []string{"zsh"}, config: "zsh"},
+	{name: "Nushell", binaries: []string{"nu"}, config: "nushell"},
+	{name: "Tmux", binaries: []string{"tmux"}, config: "tmux"},
+	{name: "Zellij", binaries: []string{"zellij"}, config: "zellij"},
+	{name: "Starship", binaries: []string{"starship"}, config: "starship"},
+	{name: "Alacritty", binaries: []string{"alacritty"}, app: "Alacritty.app", config: "alacritty"},
+	{name: "WezTerm", binaries: []string{"wezterm"}, app: "WezTerm.app", config: "wezterm"},
+	{name: "Kitty", binaries: []string{"kitty"}, app: "kitty.app", config: "kitty"},
+	{name: "Ghostty", binaries: []string{"ghostty"}, app: "Ghostty.app", config: "ghostty"},
+	{name: "Zed", binaries: []string{"zed", "zeditor"}, app: "Zed.app", config: "zed"},
+}
+
+// installed reports whether the tool binary (or macOS app) is present
+func (t doctorTool) installed() bool {
+	for _, bin := range t.binaries {
+		if system.CommandExists(bin) {
+			return true
+		}
+	}
+	if t.app != "" {
+		if _, err := os.Stat(filepath.Join("/Applications", t.app)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// doctorRun runs a read-only check command; tests replace it
+var doctorRun = func(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).CombinedOutput()
+}
+
+// RunDoctor checks the health of an existing setup. Only tools that are
+// installed or configured are checked, the others are not part of it.
+func RunDoctor(info *system.SystemInfo) []DoctorCheck {
+	var checks []DoctorCheck
+	paths := system.ConfigPaths()
+	manager := system.ToolManagerFor(info).Name()
+
+	for _, tool := range doctorTools {
+		path := paths[tool.config]
+		_, err := os.Lstat(path)
+		configured := err == nil
+		installed := tool.installed()
+		if !configured && !installed {
+			continue
+		}
+
+		if installed {
+			checks = append(checks, DoctorCheck{Category: CategoryBinaries, Name: tool.name, Status: CheckPass,
+				Detail: "installed"})
+		} else {
+			checks = append(checks, DoctorCheck{Category: CategoryBinaries, Name: tool.name, Status: CheckFail,
+				Detail: "config found but " + tool.binaries[0] + " is not installed",
+				Hint:   fmt.Sprintf("Install it with %s, or re-run the installer selecting %s", manager, tool.name)})
+		}
+
+		if configured {
+			checks = append(checks, configCheck(tool.name, path))
+		} else {
+			checks = append(checks, DoctorCheck{Category: CategoryConfigs, Name: tool.name, Status: CheckWarn,
+				Detail: "installed but " + path + " is missing",
+				Hint:   fmt.Sprintf("Re-run the installer selecting %s, or restore a backup", tool.name)})
+		}
+	}
+
+	checks = append(checks, shellSyntaxChecks()...)
+	checks = append(checks, localBinCheck())
+	checks = append(checks, skillLinkChecks()...)
+	return checks
+}
+
+// shellSyntaxChecks parses the rc files of the installed shells
+func shellSyntaxChecks() []DoctorCheck {
+	home := os.Getenv("HOME")
+	shells := []struct {
+		name string
+		bin  string
+		file string
+		args []string
+	}{
+		{"Zsh", "zsh", filepath.Join(home, ".zshrc"), []string{"-n"}},
+		{"Bash", "bash", filepath.Join(home, ".bashrc"), []string{"-n"}},
+		{"Fish", "fish", filepath.Join(home, ".config", "fish", "config.fish"), []string{"-n"}},
+		{"Nushell", "nu", filepath.Join(home, ".config", "nushell", "env.nu"), []string{"--ide-check", "10"}},
+		{"Nushell", "nu", filepath.Join(home, ".config", "nushell", "config.nu"), []string{"--ide-check", "10"}},
+	}
+
+	var checks []DoctorCheck
+	for _, sh := range shells {
+		if _, err := os.Stat(sh.file); err != nil || !system.CommandExists(sh.bin) {
+			continue
+		}
+		name := sh.name + " " + filepath.Base(sh.file)
+		command := sh.bin + " " + strings.Join(sh.args, " ") + " " + sh.file
+
+		output, err := doctorRun(sh.bin, append(slices.Clone(sh.args), sh.file)...)
+		problem := ""
+		if sh.bin == "nu" {
+			problem = nuDiagnostic(output)
+		}
+		if err != nil && problem == "" {
+			problem = firstLine(strings.TrimSpace(string(output)))
+			if problem == "" {
+				problem = err.Error()
+			}
+		}
+
+		if problem == "" {
+			checks = append(checks, DoctorCheck{Category: CategoryShells, Name: name, Status: CheckPass,
+				Detail: "parses cleanly"})
+			continue
+		}
+		checks = append(checks, DoctorCheck{Category: CategoryShells, Name: name, Status: CheckFail,
+			Detail: problem,
+			Hint:   "Fix the syntax error (run: " + command + "), or restore a backup"})
+	}
+	return checks
+}
+
+// nuDiagnostic returns the first error reported by nu --ide-check, which
+// prints one JSON diagnostic per line
+func nuDiagnostic(output []byte) string {
+	for _, line := range strings.Split(string(output), "\n") {
+		var diag struct {
+			Type     string `json:"type"`
+			Severity string `json:"severity"`
+			Message  string `json:"message"`
+		}
+		if json.Unmarshal([]byte(line), &diag) != nil {
+			continue
+		}
+		if diag.Type == "diagnostic" && strings.EqualFold(diag.Severity, "error") {
+			return diag.Message
+		}
+	}
+	return ""
+}
+
+// localBinCheck checks that ~/.local/bin, where engram and other tools are
+// installed, is on PATH
+func localBinCheck() DoctorCheck {
+	home := os.Getenv("HOME")
+	localBin := filepath.Join(home, ".local", "bin")
+	check := DoctorCheck{Category: CategoryEnv, Name: "~/.local/bin on PATH"}
+
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if filepath.Clean(dir) == localBin {
+			check.Status = CheckPass
+			check.Detail = localBin
+			return check
+		}
+	}
+
+	for _, rc := range []string{".zshrc", ".config/fish/config.fish", ".config/nushell/env.nu", ".bashrc"} {
+		content, err := os.ReadFile(filepath.Join(home, rc))
+		if err == nil && strings.Contains(string(content), ".local/bin") {
+			check.Status = CheckWarn
+			check.Detail = "added in ~/" + rc + " but not on PATH of this shell"
+			check.Hint = "Open a new terminal or reload ~/" + rc
+			return check
+		}
+	}
+
+	check.Status = CheckFail
+	check.Detail = localBin + " is not on PATH"
+	check.Hint = `Add it to your shell rc: export PATH="$HOME/.local/bin:$PATH" (fish: fish_add_path ~/.local/bin)`
+	return check
+}
+
+// configCheck checks an installed config. Configs installed with --link
+// must point into the repository.
+func configCheck(name, path string) DoctorCheck {
+	check := DoctorCheck{Category: CategoryConfigs, Name: name, Status: CheckPass, Detail: path}
+	if broken := system.BrokenLinks(path); len(broken) > 0 {
+		check.Status = CheckFail
+		check.Detail = fmt.Sprintf("%d broken link(s): %s", len(broken), strings.Join(broken, ", "))
+		check.Hint = "The linked repository moved or was deleted: re-run the installer with --link, or restore a backup"
+		return check
+	}
+	if target, linked, _ := system.LinkTarget(path); linked {
+		check.Detail = path + " → " + target
+	}
+	if system.IsOverlay(path) {
+		check.Detail += " (installer blocks in " + system.OverlayPath(path) + ")"
+	}
+	return check
+}
+
+// skillLinkChecks looks for skill symlinks whose target no longer exists
+func skillLinkChecks() []DoctorCheck {
+	home := os.Getenv("HOME")
+	var checks []DoctorCheck
+	for _, dir := range []string{"~/.claude/skills", "~/.agents/skills"} {
+		entries, err := os.ReadDir(filepath.Join(home, strings.TrimPrefix(dir, "~/")))
+		if err != nil {
+			continue
+		}
+
+		linked := 0
+		var dangling []string
+		for _, entry := range entries {
+			if entry.Type()&os.ModeSymlink == 0 {
+				continue
+			}
+			linked++
+			path := filepath.Join(home, strings.TrimPrefix(dir, "~/"), entry.Name())
+			if _, err := os.Stat(path); err != nil {
+				dangling = append(dangling, entry.Name())
+			}
+		}
+
+		check := DoctorCheck{Category: CategoryEnv, Name: "Skills in " + dir}
+		if len(dangling) == 0 {
+			check.Status = CheckPass
+			check.Detail = fmt.Sprintf("%d skill link(s) OK", linked)
+		} else {
+			check.Status = CheckFail
+			check.Detail = fmt.Sprintf("%d dangling link(s): %s", len(dangling), strings.Join(dangling, ", "))
+			check.Hint = "Remove them with --skill-remove=" + strings.Join(dangling, ",") +
+				", or reinstall them from the Skill Manager"
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// CountChecks returns how many checks passed, warned and failed
+func CountChecks(checks []DoctorCheck) (pass, warn, fail int) {
+	for _, check := range checks {
+		switch check.Status {
+		case CheckPass:
+			pass++
+		case CheckWarn:
+			warn++
+		case CheckFail:
+			fail++
+		}
+	}
+	return pass, warn, fail
+}
+
+// checkIcon returns the report icon of a status
+func checkIcon(status CheckStatus) string {
+	switch status {
+	case CheckPass:
+		return "✅"
+	case CheckWarn:
+		return "⚠️ "
+	}
+	return "❌"
+}
+
+// FormatDoctorReport renders the checks grouped by category, with the fix
+// hint under each problem
+func FormatDoctorReport(checks []DoctorCheck) string {
+	var s strings.Builder
+	for _, category := range []string{CategoryBinaries, CategoryConfigs, CategoryShells, CategoryEnv} {
+		header := false
+		for _, check := range checks {
+			if check.Category != category {
+				continue
+			}
+			if !header {
+				fmt.Fprintf(&s, "%s\n", category)
+				header = true
+			}
+			fmt.Fprintf(&s, "  %s %s: %s\n", checkIcon(check.Status), check.Name, check.Detail)
+			if check.Hint != "" {
+				fmt.Fprintf(&s, "     → %s\n", check.Hint)
+			}
+		}
+		if header {
+			s.WriteString("\n")
+		}
+	}
+	if len(checks) == 0 {
+		s.WriteString("Nothing to check: no Gentleman.Dots tools are installed or configured\n\n")
+	}
+	pass, warn, fail := CountChecks(checks)
+	fmt.Fprintf(&s, "%d passed, %d warning(s), %d failed\n", pass, warn, fail)
+	return s.String()
+}
+
+// doctorCompleteMsg carries the result of the doctor checks
+type doctorCompleteMsg struct {
+	checks []DoctorCheck
+}
+
+// runDoctorCmd runs the doctor checks in the background
+func runDoctorCmd(info *system.SystemInfo) tea.Cmd {
+	return func() tea.Msg {
+		return doctorCompleteMsg{checks: RunDoctor(info)}
+	}
+}
@@ -0,0 +1,206 @@
+package tui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
+)
+
+// doctorHome creates a HOME and a PATH holding fake binaries
+func doctorHome(t *testing.T, binaries ...string) string {
+	t.Helper()
+	home := t.TempDir()
+	bin := t.TempDir()
+	for _, name := range binaries {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("HOME", home)
+	t.Setenv("PATH", bin)
+	return home
+}
+
+func writeHomeFile(t *testing.T, home, rel, content string) {
+	t.Helper()
+	path := filepath.Join(home, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func findCheck(t *testing.T, checks []DoctorCheck, category, name string) DoctorCheck {
+	t.Helper()
+	for _, check := range checks {
+		if check.Category == category && check.Name == name {
+			return check
+		}
+	}
+	t.Fatalf("no %s check named %q in %+v", category, name, checks)
+	return DoctorCheck{}
+}
+
+func TestRunDoctor(t *testing.T) {
+	home := doctorHome(t, "zsh", "tmux")
+	writeHomeFile(t, home, ".zshrc", "if [ -n \"$x\" ]; then\n")
+	writeHomeFile(t, home, ".config/fish/config.fish", "set -gx EDITOR nvim\n")
+
+	skills := filepath.Join(home, ".claude", "skills")
+	writeHomeFile(t, home, ".gentleman/skills/react-19/SKILL.md", "# React 19\n")
+	if err := os.MkdirAll(skills, 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.Symlink(filepath.Join(home, ".gentleman/skills/react-19"), filepath.Join(skills, "react-19"))
+	os.Symlink(filepath.Join(home, ".gentleman/skills/gone"), filepath.Join(skills, "gone"))
+
+	prevRun := doctorRun
+	defer func() { doctorRun = prevRun }()
+	var ran []string
+	doctorRun = func(name string, args ...string) ([]byte, error) {
+		ran = append(ran, name+" "+strings.Join(args, " "))
+		return []byte(".zshrc:2: parse error near `\\n'\n"), errors.New("exit status 1")
+	}
+
+	checks := RunDoctor(&system.SystemInfo{OS: system.OSLinux})
+
+	t.Run("binaries and configs of configured tools", func(t *testing.T) {
+		if c := findCheck(t, checks, CategoryBinaries, "Zsh"); c.Status != CheckPass {
+			t.Errorf("zsh is installed, got %+v", c)
+		}
+		if c := findCheck(t, checks, CategoryBinaries, "Fish"); c.Status != CheckFail || !strings.Contains(c.Hint, "brew") {
+			t.Errorf("fish config without fish should fail with an install hint, got %+v", c)
+		}
+		if c := findCheck(t, checks, CategoryConfigs, "Tmux"); c.Status != CheckWarn || c.Hint == "" {
+			t.Errorf("tmux without config should warn, got %+v", c)
+		}
+		for _, check := range checks {
+			if check.Name == "Neovim" {
+				t.Errorf("tools neither installed nor configured should not be checked: %+v", check)
+			}
+		}
+	})
+
+	t.Run("shell syntax", func(t *testing.T) {
+		c := findCheck(t, checks, CategoryShells, "Zsh .zshrc")
+		if c.Status != CheckFail || !strings.Contains(c.Detail, "parse error") || !strings.Contains(c.Hint, "zsh -n") {
+			t.Errorf("broken .zshrc should fail with the parse error, got %+v", c)
+		}
+		if len(ran) != 1 || ran[0] != "zsh -n "+filepath.Join(home, ".zshrc") {
+			t.Errorf("only installed shells should parse their rc files, ran %v", ran)
+		}
+	})
+
+	t.Run("environment", func(t *testing.T) {
+		if c := findCheck(t, checks, CategoryEnv, "~/.local/bin on PATH"); c.Status != CheckFail {
+			t.Errorf("~/.local/bin is not on PATH, got %+v", c)
+		}
+		c := findCheck(t, checks, CategoryEnv, "Skills in ~/.claude/skills")
+		if c.Status != CheckFail || !strings.Contains(c.Detail, "gone") || strings.Contains(c.Detail, "react-19") {
+			t.Errorf("only the dangling link should be reported, got %+v", c)
+		}
+	})
+
+	t.Run("report", func(t *testing.T) {
+		report := FormatDoctorReport(checks)
+		for _, want := range []string{"Binaries", "Shell syntax", "❌ Fish", "→ Remove them with --skill-remove=gone", "failed"} {
+			if !strings.Contains(report, want) {
+				t.Errorf("report should contain %q:\n%s", want, report)
+			}
+		}
+	})
+}
+
+func TestDoctorLinkedConfigs(t *testing.T) {
+	home := doctorHome(t, "tmux", "starship")
+	repo := system.LinkRepoDir()
+	writeHomeFile(t, home, ".local/share/javi.dots/GentlemanTmux/tmux.conf", "set -g mouse on\n")
+	if err := os.Symlink(filepath.Join(repo, "GentlemanTmux", "tmux.conf"), filepath.Join(home, ".tmux.conf")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(home, ".config"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(repo, "starship.toml"), filepath.Join(home, ".config", "starship.toml")); err != nil {
+		t.Fatal(err)
+	}
+
+	checks := RunDoctor(&system.SystemInfo{OS: system.OSLinux})
+	if c := findCheck(t, checks, CategoryConfigs, "Tmux"); c.Status != CheckPass || !strings.Contains(c.Detail, "→ "+repo) {
+		t.Errorf("a linked config should pass and show its target, got %+v", c)
+	}
+	if c := findCheck(t, checks, CategoryConfigs, "Starship"); c.Status != CheckFail || !strings.Contains(c.Hint, "--link") {
+		t.Errorf("a broken link should fail with a hint, got %+v", c)
+	}
+}
+
+func TestLocalBinCheck(t *testing.T) {
+	t.Run("on PATH", func(t *testing.T) {
+		home := doctorHome(t)
+		t.Setenv("PATH", "/usr/bin"+string(os.PathListSeparator)+filepath.Join(home, ".local", "bin"))
+		if c := localBinCheck(); c.Status != CheckPass {
+			t.Errorf("expected pass, got %+v", c)
+		}
+	})
+
+	t.Run("added to rc but not loaded", func(t *testing.T) {
+		home := doctorHome(t)
+		writeHomeFile(t, home, ".config/fish/config.fish", "fish_add_path "+home+"/.local/bin\n")
+		if c := localBinCheck(); c.Status != CheckWarn || !strings.Contains(c.Hint, "config.fish") {
+			t.Errorf("expected a reload warning, got %+v", c)
+		}
+	})
+}
+
+func TestNuDiagnostic(t *testing.T) {
+	output := `{"type":"hint","typename":"string","position":{"start":0,"end":3}}
+{"type":"diagnostic","severity":"Warning","message":"unused"}
+{"type":"diagnostic","severity":"Error","message":"Unclosed delimiter"}`
+	if got := nuDiagnostic([]byte(output)); got != "Unclosed delimiter" {
+		t.Errorf("expected the first error, got %q", got)
+	}
+	if got := nuDiagnostic([]byte(`{"type":"diagnostic","severity":"Warning","message":"unused"}`)); got != "" {
+		t.Errorf("warnings are not failures, got %q", got)
+	}
+}
+
+func TestDoctorScreen(t *testing.T) {
+	doctorHome(t)
+	m := NewModel()
+	m.Screen = ScreenMainMenu
+	for i, opt := range m.GetCurrentOptions() {
+		if strings.Contains(opt, "Doctor") {
+			m.Cursor = i
+		}
+	}
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = result.(Model)
+	if m.Screen != ScreenDoctor || !m.DoctorRunning || cmd == nil {
+		t.Fatalf("selecting Doctor should start the checks, got screen %v", m.Screen)
+	}
+
+	result, _ = m.Update(doctorCompleteMsg{checks: []DoctorCheck{
+		{Category: CategoryBinaries, Name: "Zsh", Status: CheckPass, Detail: "installed"},
+		{Category: CategoryEnv, Name: "~/.local/bin on PATH", Status: CheckFail, Detail: "missing", Hint: "add it"},
+	}})
+	m = result.(Model)
+	view := m.View()
+	for _, want := range []string{"1 passed, 0 warning(s), 1 failed", "Zsh", "→ add it"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view should contain %q", want)
+		}
+	}
+
+	if m = press(m, "enter"); m.Screen != ScreenMainMenu {
+		t.Errorf("enter should go back to the main menu, got %v", m.Screen)
+	}
+}
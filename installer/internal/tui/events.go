@@ -0,0 +1,156 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
+)
+
+// OutputFormat is how non-interactive runs report progress
+type OutputFormat string
+
+const (
+	OutputText OutputFormat = "text"
+	OutputJSON OutputFormat = "json" // One JSON event per line (NDJSON)
+)
+
+// ValidOutputFormats lists the accepted --output values
+var ValidOutputFormats = []string{string(OutputText), string(OutputJSON)}
+
+// ParseOutputFormat parses an --output value
+func ParseOutputFormat(value string) (OutputFormat, error) {
+	value = strings.TrimSpace(strings.ToLower(value))
+	if value == "" {
+		return OutputText, nil
+	}
+	if err := ValidateOption("--output", value, ValidOutputFormats); err != nil {
+		return "", err
+	}
+	return OutputFormat(value), nil
+}
+
+var (
+	outputFormat = OutputText
+	// textOut receives the human readable progress, eventOut the JSON events
+	textOut  io.Writer = os.Stdout
+	eventOut io.Writer = os.Stdout
+	eventMu  sync.Mutex
+)
+
+// SetOutputFormat selects text or JSON events for non-interactive runs.
+// With JSON, stdout carries only events.
+func SetOutputFormat(format OutputFormat) {
+	outputFormat = format
+	textOut = os.Stdout
+	if format == OutputJSON {
+		textOut = io.Discard
+	}
+}
+
+// jsonOutput reports whether progress is emitted as JSON events
+func jsonOutput() bool {
+	return outputFormat == OutputJSON
+}
+
+// textf prints human readable progress, unless JSON events replace it
+func textf(format string, args ...any) {
+	fmt.Fprintf(textOut, format, args...)
+}
+
+// Event types of the JSON stream
+const (
+	EventPlan         = "plan"
+	EventStepStarted  = "step_started"
+	EventLog          = "log"
+	EventStepFinished = "step_finished"
+	EventError        = "error"
+	EventSummary      = "summary"
+)
+
+// Event is a single line of the JSON event stream. Only the fields that
+// apply to the event type are set.
+type Event struct {
+	Type       string        `json:"type"`
+	Time       time.Time     `json:"time"`
+	Step       string        `json:"step,omitempty"`
+	Name       string        `json:"name,omitempty"`
+	Index      int           `json:"index,omitempty"` // 1-based position of the step
+	Total      int           `json:"total,omitempty"`
+	Status     string        `json:"status,omitempty"`
+	DurationMs *int64        `json:"duration_ms,omitempty"` // Set on step_finished
+	Line       string        `json:"line,omitempty"`
+	Actions    []string      `json:"actions,omitempty"` // Dry-run plan of a step
+	Steps      []EventStep   `json:"steps,omitempty"`
+	Error      *EventFailure `json:"error,omitempty"`
+	Summary    *EventResult  `json:"summary,omitempty"`
+	DryRun     bool          `json:"dry_run,omitempty"`
+}
+
+// EventStep describes a step in the plan event
+type EventStep struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// EventFailure carries the StepError and ExecError context of a failure
+type EventFailure struct {
+	Message     string `json:"message"`
+	Description string `json:"description,omitempty"`
+	Command     string `json:"command,omitempty"`
+	ExitCode    int    `json:"exit_code,omitempty"`
+	Stderr      string `json:"stderr,omitempty"`
+	Stdout      string `json:"stdout,omitempty"`
+	Cause       string `json:"cause,omitempty"`
+	Action      string `json:"action"` // What the run did about it: skip or abort
+}
+
+// EventResult is the outcome reported by the summary event
+type EventResult struct {
+	Success    bool                   `json:"success"`
+	Done       int                    `json:"done"`
+	Skipped    int                    `json:"skipped"`
+	Failed     int                    `json:"failed"`
+	Planned    int                    `json:"planned,omitempty"` // Dry-run actions
+	LogFile    string                 `json:"log_file,omitempty"`
+	Journal    string                 `json:"journal,omitempty"` // Set when the run can be resumed
+	BackupDir  string                 `json:"backup_dir,omitempty"`
+	RolledBack *system.RollbackReport `json:"rolled_back,omitempty"`
+}
+
+// emitEvent writes ev as one JSON line when JSON output is enabled
+func emitEvent(ev Event) {
+	if !jsonOutput() {
+		return
+	}
+	ev.Time = time.Now()
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+
+	eventMu.Lock()
+	defer eventMu.Unlock()
+	eventOut.Write(append(data, '\n'))
+}
+
+// eventFailureFor converts a step failure into the error event payload
+func eventFailureFor(step InstallStep, err error, action string) *EventFailure {
+	d := errorDetailsFor(step, err)
+	return &EventFailure{
+		Message:     err.Error(),
+		Description: d.Description,
+		Command:     d.Command,
+		ExitCode:    d.ExitCode,
+		Stderr:      d.Stderr,
+		Stdout:      d.Stdout,
+		Cause:       d.Cause,
+		Action:      action,
+	}
+}
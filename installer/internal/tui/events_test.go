@@ -0,0 +1,153 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
+)
+
+// captureEvents switches to JSON output and collects the emitted events
+func captureEvents(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prevOut := eventOut
+	eventOut = &buf
+	SetOutputFormat(OutputJSON)
+	SetNonInteractiveMode(true)
+	t.Cleanup(func() {
+		eventOut = prevOut
+		SetOutputFormat(OutputText)
+		SetNonInteractiveMode(false)
+	})
+	return &buf
+}
+
+func decodeEvents(t *testing.T, buf *bytes.Buffer) []Event {
+	t.Helper()
+	var events []Event
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var ev Event
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			t.Fatalf("line is not a JSON event: %q (%v)", line, err)
+		}
+		events = append(events, ev)
+	}
+	return events
+}
+
+func eventTypes(events []Event) []string {
+	var types []string
+	for _, ev := range events {
+		if ev.Type != EventLog {
+			types = append(types, ev.Type)
+		}
+	}
+	return types
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	for value, want := range map[string]OutputFormat{"": OutputText, "text": OutputText, "JSON": OutputJSON} {
+		if got, err := ParseOutputFormat(value); err != nil || got != want {
+			t.Errorf("ParseOutputFormat(%q) = %q, %v", value, got, err)
+		}
+	}
+	if _, err := ParseOutputFormat("yaml"); err == nil {
+		t.Error("unknown format should be rejected")
+	}
+}
+
+func TestJSONEvents(t *testing.T) {
+	t.Run("dry run emits plan, steps, logs and summary", func(t *testing.T) {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		t.Setenv("GENTLEMAN_DRY_RUN", "1")
+		buf := captureEvents(t)
+		system.TakePlan()
+
+		model := &Model{
+			SystemInfo: &system.SystemInfo{OS: system.OSLinux},
+			Choices:    UserChoices{Shell: "fish", WindowMgr: "zellij"},
+			RepoDir:    filepath.Join(home, "Javi.Dots"),
+		}
+		if err := runStepsNonInteractive(model, []InstallStep{{ID: "shell", Name: "Install Fish"}}, nil); err != nil {
+			t.Fatalf("dry run failed: %v", err)
+		}
+
+		events := decodeEvents(t, buf)
+		want := []string{EventPlan, EventStepStarted, EventStepFinished, EventSummary}
+		if got := eventTypes(events); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Fatalf("event order = %v, want %v", got, want)
+		}
+
+		plan := events[0]
+		if !plan.DryRun || plan.Total != 1 || len(plan.Steps) != 1 || plan.Steps[0].ID != "shell" {
+			t.Errorf("unexpected plan event: %+v", plan)
+		}
+		for _, ev := range events {
+			if ev.Type == EventLog && ev.Step != "shell" {
+				t.Errorf("log event without its step: %+v", ev)
+			}
+			if ev.Type == EventStepFinished && (ev.Status != "done" || ev.Index != 1 || len(ev.Actions) == 0 || ev.DurationMs == nil) {
+				t.Errorf("unexpected step_finished event: %+v", ev)
+			}
+		}
+		last := events[len(events)-1]
+		if last.Summary == nil || !last.Summary.Success || last.Summary.Done != 1 || last.Summary.Planned == 0 {
+			t.Errorf("unexpected summary: %+v", last.Summary)
+		}
+	})
+
+	t.Run("failure emits error and a failed summary", func(t *testing.T) {
+		t.Setenv("HOME", t.TempDir())
+		t.Setenv("GENTLEMAN_DRY_RUN", "")
+		buf := captureEvents(t)
+		defer CloseSessionLog()
+
+		model := &Model{SystemInfo: &system.SystemInfo{}, RepoDir: t.TempDir()}
+		if err := runStepsNonInteractive(model, []InstallStep{{ID: "bogus", Name: "Always fails"}}, nil); err == nil {
+			t.Fatal("expected the run to fail")
+		}
+
+		events := decodeEvents(t, buf)
+		want := []string{EventPlan, EventStepStarted, EventError, EventStepFinished, EventSummary}
+		if got := eventTypes(events); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Fatalf("event order = %v, want %v", got, want)
+		}
+		for _, ev := range events {
+			if ev.Type == EventError && (ev.Step != "bogus" || ev.Error == nil || ev.Error.Action != "abort") {
+				t.Errorf("unexpected error event: %+v", ev)
+			}
+		}
+		summary := events[len(events)-1].Summary
+		if summary.Success || summary.Failed != 1 || summary.Journal != JournalPath() || summary.LogFile == "" {
+			t.Errorf("unexpected summary: %+v", summary)
+		}
+		if _, err := os.Stat(summary.LogFile); err != nil {
+			t.Errorf("summary should point at the session log: %v", err)
+		}
+	})
+}
+
+func TestEventFailureFor(t *testing.T) {
+	step := InstallStep{ID: "shell", Name: "Install Fish"}
+	err := wrapStepError("shell", "Install Fish", "Failed to install fish", &system.ExecError{
+		Command:  "brew install fish",
+		ExitCode: 1,
+		Stderr:   "Error: fish: SHA256 mismatch\n",
+		Wrapped:  errors.New("exit status 1"),
+	})
+
+	got := eventFailureFor(step, err, "abort")
+	if got.Command != "brew install fish" || got.ExitCode != 1 || got.Stderr != "Error: fish: SHA256 mismatch" {
+		t.Errorf("exec error fields missing: %+v", got)
+	}
+	if got.Description != "Failed to install fish" || got.Cause != "exit status 1" || got.Message == "" {
+		t.Errorf("step error fields missing: %+v", got)
+	}
+}
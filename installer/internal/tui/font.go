@@ -0,0 +1,82 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
+)
+
+// fontSizeStep is how much left and right change the font size
+const fontSizeStep = 1.0
+
+// termuxFont is installed on Termux when no font was picked
+const termuxFont = "jetbrains-mono"
+
+// openFontSelect shows the font picker on the font that will be installed,
+// looking up which fonts the system already has
+func (m *Model) openFontSelect() {
+	m.Screen = ScreenFontSelect
+	m.FontsInstalled = map[string]bool{}
+	for _, font := range system.Fonts {
+		m.FontsInstalled[font.ID] = system.FontInstalled(font)
+	}
+	m.Cursor = max(m.fontIndex(), 0)
+	if m.Choices.FontSize == 0 {
+		m.Choices.FontSize = system.DefaultFontSize
+	}
+}
+
+// fontIndex is the position of the chosen font in system.Fonts
+func (m Model) fontIndex() int {
+	id := m.Choices.Font
+	if id == "" {
+		id = system.DefaultFont
+	}
+	for i, font := range system.Fonts {
+		if font.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// fontOptions lists the Nerd Fonts, marking the ones already installed
+func (m Model) fontOptions() []string {
+	var opts []string
+	for _, font := range system.Fonts {
+		opt := font.Name + " Nerd Font"
+		if m.FontsInstalled[font.ID] {
+			opt += " ✓ installed"
+		}
+		opts = append(opts, opt)
+	}
+	return append(opts, "No, keep my current font")
+}
+
+// installFont is the font the font step installs
+func (m Model) installFont() system.Font {
+	id := m.Choices.Font
+	if id == "" {
+		id = system.DefaultFont
+		if m.SystemInfo.IsTermux || m.Choices.OS == "termux" {
+			id = termuxFont
+		}
+	}
+	font, _ := system.FindFont(id)
+	return font
+}
+
+// RunFontNonInteractive runs the font step on its own without TUI: it
+// installs the chosen font when the system lacks it and sets it in the
+// installed configs
+func RunFontNonInteractive(choices UserChoices) error {
+	choices.InstallFont = true
+	model := newNonInteractiveModel(choices, "", "")
+	steps := []InstallStep{{ID: "font", Name: "Install " + model.installFont().Name + " Nerd Font"}}
+	return runStepsNonInteractive(model, withDependencies(steps), nil)
+}
+
+// fontSize formats a font size the way the configs write it
+func fontSize(size float64) string {
+	return fmt.Sprintf("%g", size)
+}
@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
+)
+
+func TestFontScreen(t *testing.T) {
+	m := NewModel()
+	m.Screen = ScreenTerminalSelect
+	m.Choices.OS = "mac"
+	m.Cursor = 0 // Alacritty
+	m = press(m, "enter")
+	if m.Screen != ScreenFontSelect || m.Cursor != 0 || m.Choices.FontSize != system.DefaultFontSize {
+		t.Fatalf("the font screen should open on the default font and size, got screen %v cursor %d size %v", m.Screen, m.Cursor, m.Choices.FontSize)
+	}
+	if opts := m.GetCurrentOptions(); len(opts) != len(system.Fonts)+1 || !strings.HasPrefix(opts[0], "Iosevka Term Nerd Font") {
+		t.Errorf("every font should be offered, plus skipping, got %v", opts)
+	}
+
+	m = press(m, "j", "right", "right", "left")
+	if m.Choices.FontSize != 15 {
+		t.Errorf("left and right should change the size, got %v", m.Choices.FontSize)
+	}
+	if view := m.View(); !strings.Contains(view, "Size: 15") {
+		t.Errorf("view should show the size:\n%s", view)
+	}
+
+	m = press(m, "enter")
+	if m.Screen != ScreenShellSelect || !m.Choices.InstallFont || m.Choices.Font != "jetbrains-mono" {
+		t.Errorf("enter should pick the font, got %+v", m.Choices)
+	}
+
+	if m = press(m, "esc"); m.Screen != ScreenFontSelect || m.Cursor != 1 {
+		t.Errorf("going back should open the font screen on the picked font, got screen %v cursor %d", m.Screen, m.Cursor)
+	}
+}
+
+func TestFontInstallStep(t *testing.T) {
+	m := NewModel()
+	m.Choices = UserChoices{OS: "linux", Terminal: "kitty", InstallFont: true, Font: "hack", Shell: "fish", WindowMgr: "none", InstallZed: true}
+	m.SetupInstallSteps()
+
+	for _, step := range m.Steps {
+		if step.ID != "font" {
+			continue
+		}
+		if step.Name != "Install Hack Nerd Font" {
+			t.Errorf("step should name the font, got %q", step.Name)
+		}
+		deps := strings.Join(step.DependsOn, ",")
+		if !strings.Contains(deps, "terminal") || !strings.Contains(deps, "zed") {
+			t.Errorf("the font should wait for the configs it updates, depends on %v", step.DependsOn)
+		}
+		return
+	}
+	t.Error("no font step")
+}
+
+func TestProfileFont(t *testing.T) {
+	p := DefaultProfile()
+	p.Shell = "fish"
+	p.FontFamily = "Fira-Code"
+	p.FontSize = 12
+	p.Normalize()
+	if err := p.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if c := p.Choices(); !c.InstallFont || c.Font != "fira-code" || c.FontSize != 12 {
+		t.Errorf("a font family should install the font, got %+v", c)
+	}
+
+	p.FontFamily = "comic-sans"
+	if err := p.Validate(); err == nil || !strings.Contains(err.Error(), "invalid font family") {
+		t.Errorf("expected an invalid font family error, got %v", err)
+	}
+}
@@ -180,13 +180,17 @@ func TestStepInstallShellZsh(t *testing.T) {
 		tmpDir := t.TempDir()
 
 		// Create mock .zshrc
-		zshrc := `WM_VAR="/$TMUX"
+		zshrc := `# >>> gentleman:fzf >>>
+eval "$(fzf --zsh)"
+# <<< gentleman:fzf <<<
+# >>> gentleman:wm >>>
+WM_VAR="/$TMUX"
 WM_CMD="tmux"
 function start_if_needed() {
     exec $WM_CMD
 }
-eval "$(fzf --zsh)"
-start_if_needed`
+start_if_needed
+# <<< gentleman:wm <<<`
 
 		zshrcPath := filepath.Join(tmpDir, ".zshrc")
 		os.WriteFile(zshrcPath, []byte(zshrc), 0644)
@@ -215,12 +219,14 @@ start_if_needed`
 	t.Run("zsh step patches config based on WM choice - zellij", func(t *testing.T) {
 		tmpDir := t.TempDir()
 
-		zshrc := `WM_VAR="/$TMUX"
+		zshrc := `# >>> gentleman:wm >>>
+WM_VAR="/$TMUX"
 WM_CMD="tmux"
 function start_if_needed() {
     exec $WM_CMD
 }
-start_if_needed`
+start_if_needed
+# <<< gentleman:wm <<<`
 
 		zshrcPath := filepath.Join(tmpDir, ".zshrc")
 		os.WriteFile(zshrcPath, []byte(zshrc), 0644)
@@ -244,9 +250,11 @@ start_if_needed`
 	t.Run("zsh step patches config based on WM choice - tmux", func(t *testing.T) {
 		tmpDir := t.TempDir()
 
-		zshrc := `WM_VAR="/$TMUX"
+		zshrc := `# >>> gentleman:wm >>>
+WM_VAR="/$TMUX"
 WM_CMD="tmux"
-start_if_needed`
+start_if_needed
+# <<< gentleman:wm <<<`
 
 		zshrcPath := filepath.Join(tmpDir, ".zshrc")
 		os.WriteFile(zshrcPath, []byte(zshrc), 0644)
@@ -273,10 +281,14 @@ func TestStepInstallShellFish(t *testing.T) {
 	t.Run("fish step patches config based on WM choice - none", func(t *testing.T) {
 		tmpDir := t.TempDir()
 
-		fish := `if not set -q TMUX
+		fish := `# >>> gentleman:wm >>>
+if not set -q TMUX
     tmux
 end
-fzf --fish | source`
+# <<< gentleman:wm <<<
+# >>> gentleman:fzf >>>
+fzf --fish | source
+# <<< gentleman:fzf <<<`
 
 		fishPath := filepath.Join(tmpDir, "config.fish")
 		os.WriteFile(fishPath, []byte(fish), 0644)
@@ -300,13 +312,11 @@ fzf --fish | source`
 	t.Run("fish step patches config based on WM choice - zellij", func(t *testing.T) {
 		tmpDir := t.TempDir()
 
-		fish := `if not set -q TMUX
+		fish := `# >>> gentleman:wm >>>
+if not set -q TMUX
     tmux
 end
-
-#if not set -q ZELLIJ 
-#  zellij
-#end`
+# <<< gentleman:wm <<<`
 
 		fishPath := filepath.Join(tmpDir, "config.fish")
 		os.WriteFile(fishPath, []byte(fish), 0644)
@@ -333,7 +343,8 @@ func TestStepInstallShellNushell(t *testing.T) {
 	t.Run("nushell step patches config based on WM choice - none", func(t *testing.T) {
 		tmpDir := t.TempDir()
 
-		nu := `let MULTIPLEXER = "tmux" 
+		nu := `# >>> gentleman:wm >>>
+let MULTIPLEXER = "tmux"
 let MULTIPLEXER_ENV_PREFIX = "TMUX"
 
 def start_multiplexer [] {
@@ -342,7 +353,8 @@ def start_multiplexer [] {
   }
 }
 
-start_multiplexer`
+start_multiplexer
+# <<< gentleman:wm <<<`
 
 		nuPath := filepath.Join(tmpDir, "config.nu")
 		os.WriteFile(nuPath, []byte(nu), 0644)
@@ -366,9 +378,11 @@ start_multiplexer`
 	t.Run("nushell step patches config based on WM choice - zellij", func(t *testing.T) {
 		tmpDir := t.TempDir()
 
-		nu := `let MULTIPLEXER = "tmux" 
+		nu := `# >>> gentleman:wm >>>
+let MULTIPLEXER = "tmux"
 let MULTIPLEXER_ENV_PREFIX = "TMUX"
-start_multiplexer`
+start_multiplexer
+# <<< gentleman:wm <<<`
 
 		nuPath := filepath.Join(tmpDir, "config.nu")
 		os.WriteFile(nuPath, []byte(nu), 0644)
@@ -392,7 +406,7 @@ start_multiplexer`
 
 // TestAllShellAndWMCombinations tests all shell+WM combinations
 func TestAllShellAndWMCombinations(t *testing.T) {
-	shells := []string{"zsh", "fish", "nushell"}
+	shells := []string{"zsh", "fish", "nushell", "bash"}
 	wms := []string{"tmux", "zellij", "none"}
 	nvimOptions := []bool{true, false}
 
@@ -412,35 +426,47 @@ func TestAllShellAndWMCombinations(t *testing.T) {
 
 					switch shell {
 					case "zsh":
-						content := `WM_VAR="/$TMUX"
+						content := `# >>> gentleman:fzf >>>
+eval "$(fzf --zsh)"
+# <<< gentleman:fzf <<<
+# >>> gentleman:wm >>>
+WM_VAR="/$TMUX"
 WM_CMD="tmux"
 function start_if_needed() { exec $WM_CMD; }
-eval "$(fzf --zsh)"
-start_if_needed`
+start_if_needed
+# <<< gentleman:wm <<<`
 						path := filepath.Join(tmpDir, ".zshrc")
 						os.WriteFile(path, []byte(content), 0644)
 						err = system.PatchZshForWM(path, wm, nvim)
 
 					case "fish":
-						content := `if not set -q TMUX
+						content := `# >>> gentleman:wm >>>
+if not set -q TMUX
     tmux
 end
-#if not set -q ZELLIJ 
-#  zellij
-#end
-fzf --fish | source`
+# <<< gentleman:wm <<<
+# >>> gentleman:fzf >>>
+fzf --fish | source
+# <<< gentleman:fzf <<<`
 						path := filepath.Join(tmpDir, "config.fish")
 						os.WriteFile(path, []byte(content), 0644)
 						err = system.PatchFishForWM(path, wm, nvim)
 
 					case "nushell":
-						content := `let MULTIPLEXER = "tmux" 
+						content := `# >>> gentleman:wm >>>
+let MULTIPLEXER = "tmux"
 let MULTIPLEXER_ENV_PREFIX = "TMUX"
 def start_multiplexer [] { run-external $MULTIPLEXER }
-start_multiplexer`
+start_multiplexer
+# <<< gentleman:wm <<<`
 						path := filepath.Join(tmpDir, "config.nu")
 						os.WriteFile(path, []byte(content), 0644)
 						err = system.PatchNushellForWM(path, wm)
+
+					case "bash":
+						path := filepath.Join(tmpDir, ".bashrc")
+						os.WriteFile(path, []byte(`eval "$(starship init bash)"`), 0644)
+						err = system.PatchBashForWM(path, wm)
 					}
 
 					if err != nil {
@@ -635,6 +661,7 @@ func TestEachShellSelection(t *testing.T) {
 		{"fish", 0, "fish"},
 		{"zsh", 1, "zsh"},
 		{"nushell", 2, "nushell"},
+		{"bash", 3, "bash"},
 	}
 
 	for _, tc := range shells {
@@ -722,7 +749,7 @@ func TestEachFontSelection(t *testing.T) {
 	t.Run("no", func(t *testing.T) {
 		m := NewModel()
 		m.Screen = ScreenFontSelect
-		m.Cursor = 1 // No
+		m.Cursor = len(m.GetCurrentOptions()) - 1 // No
 
 		m, _ = simulateKeyPress(m, "enter")
 		if m.Choices.InstallFont {
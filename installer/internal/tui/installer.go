@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -44,6 +45,15 @@ func wrapStepError(stepID, stepName, description string, cause error) error {
 	}
 }
 
+// takeStepPlan collects the dry-run actions recorded while a step ran
+func takeStepPlan(stepID string) []system.PlanAction {
+	actions := system.TakePlan()
+	for i := range actions {
+		actions[i].Step = stepID
+	}
+	return actions
+}
+
 // executeStep runs the actual installation for a step
 func executeStep(stepID string, m *Model) error {
 	switch stepID {
@@ -75,6 +85,8 @@ func executeStep(stepID string, m *Model) error {
 		return stepInstallAIFramework(m)
 	case "engram":
 		return stepInstallEngram(m)
+	case "theme":
+		return stepApplyTheme(m)
 	case "cleanup":
 		return stepCleanup(m)
 	case "setshell":
@@ -100,7 +112,7 @@ func stepBackupConfigs(m *Model) error {
 		SendLog(stepID, fmt.Sprintf("  → %s", config))
 	}
 
-	backupDir, err := system.CreateBackup(configKeys)
+	backupDir, err := system.CreateBackupFor(configKeys, "install", m.Choices)
 	if err != nil {
 		return fmt.Errorf("failed to create backup: %w", err)
 	}
@@ -114,10 +126,27 @@ func stepCloneRepo(m *Model) error {
 	stepID := "clone"
 	repoDir := m.RepoDir
 
+	// Linked configs point into the clone, so update it in place
+	if m.Choices.LinkConfigs {
+		if _, err := os.Stat(filepath.Join(repoDir, ".git")); err == nil {
+			SendLog(stepID, "Updating "+repoDir+"...")
+			result := system.RunWithLogs(m.ctx(), "git -C "+repoDir+" pull --ff-only --progress", nil, func(line string) {
+				SendLog(stepID, line)
+			})
+			if result.Error != nil {
+				return wrapStepError("clone", "Clone Repository",
+					"Failed to update "+repoDir+". Commit or stash local changes there and retry.",
+					result.Error)
+			}
+			SendLog(stepID, "✓ Repository updated")
+			return nil
+		}
+	}
+
 	// Check if already exists
 	if _, err := os.Stat(repoDir); err == nil {
 		SendLog(stepID, "Removing existing "+repoDir+" directory...")
-		result := system.RunWithLogs("rm -rf "+repoDir, nil, func(line string) {
+		result := system.RunWithLogs(m.ctx(), "rm -rf "+repoDir, nil, func(line string) {
 			SendLog(stepID, line)
 		})
 		if result.Error != nil {
@@ -128,7 +157,7 @@ func stepCloneRepo(m *Model) error {
 	}
 
 	SendLog(stepID, "Cloning repository from GitHub...")
-	result := system.RunWithLogs("git clone --progress "+m.RepoURL+" "+repoDir, nil, func(line string) {
+	result := system.RunWithLogs(m.ctx(), "git clone --progress "+m.RepoURL+" "+repoDir, nil, func(line string) {
 		SendLog(stepID, line)
 	})
 	if result.Error != nil {
@@ -138,7 +167,7 @@ func stepCloneRepo(m *Model) error {
 	}
 
 	// Verify clone was successful
-	if _, err := os.Stat(repoDir); os.IsNotExist(err) {
+	if _, err := os.Stat(repoDir); os.IsNotExist(err) && !system.IsDryRun() {
 		return wrapStepError("clone", "Clone Repository",
 			"Repository was cloned but directory not found",
 			fmt.Errorf("%s directory does not exist after clone", repoDir))
@@ -163,7 +192,7 @@ func stepInstallHomebrew(m *Model) error {
 	}
 
 	SendLog(stepID, "Installing Homebrew package manager...")
-	result := system.RunWithLogs(`/bin/bash -c "$(curl -fsSL https://raw.githubusercontent.com/Homebrew/install/HEAD/install.sh)"`, nil, func(line string) {
+	result := system.RunWithLogs(m.ctx(), `/bin/bash -c "$(curl -fsSL https://raw.githubusercontent.com/Homebrew/install/HEAD/install.sh)"`, nil, func(line string) {
 		SendLog(stepID, line)
 	})
 	if result.Error != nil {
@@ -182,14 +211,13 @@ func stepInstallHomebrew(m *Model) error {
 	// Add to common shell configs
 	for _, rcFile := range []string{".bashrc", ".zshrc"} {
 		rcPath := filepath.Join(homeDir, rcFile)
-		if f, err := os.OpenFile(rcPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); err == nil {
-			f.WriteString("\n" + shellConfig + "\n")
-			f.Close()
+		if _, err := system.SetRcBlock(rcPath, system.RcBlockBrew, shellConfig); err != nil {
+			SendLog(stepID, fmt.Sprintf("⚠️ Could not update %s: %v", rcPath, err))
 		}
 	}
 
 	// Source it now
-	system.Run(shellConfig, nil)
+	system.Run(m.ctx(), shellConfig, nil)
 
 	SendLog(stepID, "✓ Homebrew installed successfully")
 	return nil
@@ -201,28 +229,29 @@ func stepInstallDeps(m *Model) error {
 	// Termux: use pkg (no sudo needed)
 	// Check both SystemInfo and Choices.OS for redundancy
 	isTermux := m.SystemInfo.IsTermux || m.Choices.OS == "termux"
+	pm := system.PackageManagerFor(m.SystemInfo)
 	if isTermux {
-		SendLog(stepID, "Updating Termux packages...")
-		result := system.RunPkgWithLogs("update", nil, func(line string) {
-			SendLog(stepID, line)
-		})
-		if result.Error != nil {
-			return wrapStepError("deps", "Install Dependencies",
-				"Failed to update Termux packages",
-				result.Error)
-		}
-		result = system.RunPkgWithLogs("upgrade -y", nil, func(line string) {
-			SendLog(stepID, line)
-		})
+		pm = system.NewPkgManager()
+	}
+	logLine := func(line string) {
+		SendLog(stepID, line)
+	}
+
+	SendLog(stepID, fmt.Sprintf("Updating %s packages...", pm.Name()))
+	if result := pm.Update(m.ctx(), logLine); result.Error != nil {
+		return wrapStepError("deps", "Install Dependencies",
+			fmt.Sprintf("Failed to update %s packages", pm.Name()),
+			result.Error)
+	}
+
+	if isTermux {
+		result := system.RunPkgWithLogs(m.ctx(), "upgrade -y", nil, logLine)
 		if result.Error != nil {
 			// Upgrade failures are not critical
 			SendLog(stepID, "Warning: package upgrade had issues, continuing...")
 		}
 		SendLog(stepID, "Installing base dependencies...")
-		result = system.RunPkgInstall("git curl", nil, func(line string) {
-			SendLog(stepID, line)
-		})
-		if result.Error != nil {
+		if result := pm.Install(m.ctx(), logLine, "git", "curl"); result.Error != nil {
 			return wrapStepError("deps", "Install Dependencies",
 				"Failed to install base dependencies on Termux",
 				result.Error)
@@ -230,53 +259,28 @@ func stepInstallDeps(m *Model) error {
 		return nil
 	}
 
-	// Arch Linux
-	if m.SystemInfo.OS == system.OSArch {
-		result := system.RunSudo("pacman -Syu --noconfirm", nil)
-		if result.Error != nil {
-			return wrapStepError("deps", "Install Dependencies",
-				"Failed to update Arch Linux packages",
-				result.Error)
-		}
-		result = system.RunSudo("pacman -S --needed --noconfirm base-devel curl file git wget unzip fontconfig", nil)
-		if result.Error != nil {
-			return wrapStepError("deps", "Install Dependencies",
-				"Failed to install base dependencies on Arch Linux",
-				result.Error)
-		}
-		return nil
-	}
-
-	// Fedora/RHEL
-	if m.SystemInfo.OS == system.OSFedora {
-		result := system.RunSudo("dnf check-update || true", nil) // dnf check-update returns 100 if updates available
-		result = system.RunSudo("dnf install -y @development-tools curl file git wget unzip fontconfig", nil)
-		if result.Error != nil {
-			return wrapStepError("deps", "Install Dependencies",
-				"Failed to install base dependencies on Fedora/RHEL",
-				result.Error)
-		}
-		return nil
-	}
-
-	// Debian/Ubuntu
-	result := system.RunSudo("apt-get update", nil)
-	if result.Error != nil {
-		return wrapStepError("deps", "Install Dependencies",
-			"Failed to update apt package list",
-			result.Error)
-	}
-	result = system.RunSudo("apt-get install -y build-essential curl file git unzip fontconfig", nil)
+	SendLog(stepID, "Installing base dependencies...")
+	result := pm.Install(m.ctx(), logLine, "build-tools", "curl", "file", "git", "wget", "unzip", "fontconfig")
 	if result.Error != nil {
 		return wrapStepError("deps", "Install Dependencies",
-			"Failed to install base dependencies on Debian/Ubuntu",
+			fmt.Sprintf("Failed to install base dependencies on %s", m.SystemInfo.OSName),
 			result.Error)
 	}
 	return nil
 }
 
+// hasNativeTerminalPackages reports whether the distribution packages the
+// terminal emulators, so they come from its own package manager
+func hasNativeTerminalPackages(info *system.SystemInfo) bool {
+	switch info.OS {
+	case system.OSArch, system.OSFedora, system.OSAlpine, system.OSOpenSUSE, system.OSVoid:
+		return true
+	}
+	return false
+}
+
 func stepInstallXcode(m *Model) error {
-	result := system.Run("xcode-select --install", nil)
+	result := system.Run(m.ctx(), "xcode-select --install", nil)
 	if result.Error != nil {
 		// xcode-select returns error if already installed, which is fine
 		if result.ExitCode == 1 && strings.Contains(result.Stderr, "already installed") {
@@ -290,279 +294,218 @@ func stepInstallXcode(m *Model) error {
 }
 
 func stepInstallTerminal(m *Model) error {
-	terminal := m.Choices.Terminal
+	return installTool(m, "terminal", m.Choices.Terminal)
+}
+
+// alacrittyLabel warns that Alacritty is built from source on Debian/Ubuntu
+func alacrittyLabel(m Model) string {
+	// On Debian/Ubuntu, Alacritty needs to be built from source (PPAs are unreliable)
+	// This applies to ALL Debian-based systems, not just ARM
+	if m.SystemInfo != nil && (m.SystemInfo.OS == system.OSDebian || m.SystemInfo.OS == system.OSLinux) && m.Choices.OS == "linux" {
+		return "Alacritty ⏱️  (builds from source, installs Rust ~5-10 min)"
+	}
+	return "Alacritty"
+}
+
+func installAlacritty(m *Model, stepID string) error {
 	homeDir := os.Getenv("HOME")
-	repoDir := m.RepoDir
-	stepID := "terminal"
-
-	switch terminal {
-	case "alacritty":
-		if !system.CommandExists("alacritty") {
-			SendLog(stepID, "Installing Alacritty...")
-			var result *system.ExecResult
-			if m.SystemInfo.OS == system.OSArch {
-				result = system.RunSudoWithLogs("pacman -S --noconfirm alacritty", nil, func(line string) {
-					SendLog(stepID, line)
-				})
-			} else if m.SystemInfo.OS == system.OSMac {
-				result = system.RunBrewWithLogs("install --cask alacritty", nil, func(line string) {
-					SendLog(stepID, line)
-				})
-			} else if m.SystemInfo.OS == system.OSFedora {
-				// Fedora: install from dnf
-				result = system.RunSudoWithLogs("dnf install -y alacritty", nil, func(line string) {
-					SendLog(stepID, line)
-				})
-			} else if m.SystemInfo.OS == system.OSDebian || m.SystemInfo.OS == system.OSLinux {
-				// Debian/Ubuntu: compile from source (PPAs are unreliable)
-				SendLog(stepID, "Building Alacritty from source...")
-				SendLog(stepID, "Installing build dependencies...")
-				result = system.RunSudoWithLogs("apt-get install -y cmake pkg-config libfreetype6-dev libfontconfig1-dev libxcb-xfixes0-dev libxkbcommon-dev python3 gzip scdoc git curl", nil, func(line string) {
-					SendLog(stepID, line)
-				})
-				if result.Error != nil {
-					return wrapStepError("terminal", "Install Alacritty",
-						"Failed to install build dependencies",
-						result.Error)
-				}
-				// Install Rust/Cargo only for this build
-				cargoPath := filepath.Join(homeDir, ".cargo/bin/cargo")
-				if !system.CommandExists("cargo") && !system.CommandExists(cargoPath) {
-					SendLog(stepID, "Installing Rust/Cargo toolchain...")
-					result = system.RunWithLogs("curl --proto '=https' --tlsv1.2 -sSf https://sh.rustup.rs | sh -s -- -y", nil, func(line string) {
-						SendLog(stepID, line)
-					})
-					if result.Error != nil {
-						return wrapStepError("terminal", "Install Alacritty",
-							"Failed to install Rust",
-							result.Error)
-					}
-					cargoPath = filepath.Join(homeDir, ".cargo/bin/cargo")
-				}
-				// Clone and build Alacritty
-				SendLog(stepID, "Cloning Alacritty repository...")
-				alacrittyDir := filepath.Join(os.TempDir(), "alacritty-build")
-				os.RemoveAll(alacrittyDir)
-				result = system.RunWithLogs(fmt.Sprintf("git clone https://github.com/alacritty/alacritty.git %s", alacrittyDir), nil, func(line string) {
-					SendLog(stepID, line)
-				})
-				if result.Error != nil {
-					return wrapStepError("terminal", "Install Alacritty",
-						"Failed to clone Alacritty repository",
-						result.Error)
-				}
-				SendLog(stepID, "Building Alacritty (this may take 5-10 minutes)...")
-				if !system.CommandExists("cargo") {
-					cargoPath = filepath.Join(homeDir, ".cargo/bin/cargo")
-				} else {
-					cargoPath = "cargo"
-				}
-				result = system.RunWithLogs(fmt.Sprintf("%s build --release --manifest-path %s/Cargo.toml", cargoPath, alacrittyDir), nil, func(line string) {
-					SendLog(stepID, line)
-				})
-				if result.Error != nil {
-					return wrapStepError("terminal", "Install Alacritty",
-						"Failed to build Alacritty",
-						result.Error)
-				}
-				SendLog(stepID, "Installing Alacritty binary...")
-				result = system.RunSudoWithLogs(fmt.Sprintf("cp %s/target/release/alacritty /usr/local/bin/alacritty", alacrittyDir), nil, func(line string) {
-					SendLog(stepID, line)
-				})
-				if result.Error != nil {
-					return wrapStepError("terminal", "Install Alacritty",
-						"Failed to install Alacritty binary",
-						result.Error)
-				}
-				system.RunSudoWithLogs(fmt.Sprintf("cp %s/extra/linux/Alacritty.desktop /usr/share/applications/", alacrittyDir), nil, func(line string) {
-					SendLog(stepID, line)
-				})
-				os.RemoveAll(alacrittyDir)
-				SendLog(stepID, "✓ Alacritty built and installed from source")
-			} else {
-				return wrapStepError("terminal", "Install Alacritty",
-					"Unsupported operating system for Alacritty installation",
-					fmt.Errorf("OS type: %v", m.SystemInfo.OS))
-			}
-			if result.Error != nil {
-				return wrapStepError("terminal", "Install Alacritty",
-					"Failed to install Alacritty terminal emulator",
-					result.Error)
-			}
-		} else {
-			SendLog(stepID, "Alacritty already installed")
-		}
-		SendLog(stepID, "Copying Alacritty configuration...")
-		if err := system.EnsureDir(filepath.Join(homeDir, ".config/alacritty")); err != nil {
-			return wrapStepError("terminal", "Install Alacritty",
-				"Failed to create Alacritty config directory",
-				err)
-		}
-		if err := system.CopyFile(filepath.Join(repoDir, "alacritty.toml"), filepath.Join(homeDir, ".config/alacritty/alacritty.toml")); err != nil {
+	var result *system.ExecResult
+	if hasNativeTerminalPackages(m.SystemInfo) || m.SystemInfo.OS == system.OSMac {
+		result = system.PackageManagerFor(m.SystemInfo).Install(m.ctx(), func(line string) {
+			SendLog(stepID, line)
+		}, "alacritty")
+	} else if m.SystemInfo.OS == system.OSDebian || m.SystemInfo.OS == system.OSLinux {
+		// Debian/Ubuntu: compile from source (PPAs are unreliable)
+		SendLog(stepID, "Building Alacritty from source...")
+		SendLog(stepID, "Installing build dependencies...")
+		result = system.NewAptManager().Install(m.ctx(), func(line string) {
+			SendLog(stepID, line)
+		}, "cmake", "pkg-config", "libfreetype6-dev", "libfontconfig1-dev", "libxcb-xfixes0-dev", "libxkbcommon-dev", "python3", "gzip", "scdoc", "git", "curl")
+		if result.Error != nil {
 			return wrapStepError("terminal", "Install Alacritty",
-				"Failed to copy Alacritty configuration",
-				err)
-		}
-		SendLog(stepID, "✓ Alacritty configured")
-
-	case "wezterm":
-		if !system.CommandExists("wezterm") {
-			SendLog(stepID, "Installing WezTerm...")
-			var result *system.ExecResult
-			if m.SystemInfo.OS == system.OSArch {
-				result = system.RunSudoWithLogs("pacman -S --noconfirm wezterm", nil, func(line string) {
-					SendLog(stepID, line)
-				})
-			} else if m.SystemInfo.OS == system.OSFedora {
-				// Fedora: enable COPR and install
-				system.RunSudo("dnf copr enable -y wezfurlong/wezterm-nightly", nil)
-				result = system.RunSudoWithLogs("dnf install -y wezterm", nil, func(line string) {
-					SendLog(stepID, line)
-				})
-			} else if m.SystemInfo.OS == system.OSMac {
-				result = system.RunBrewWithLogs("install --cask wezterm", nil, func(line string) {
-					SendLog(stepID, line)
-				})
-			} else {
-				system.Run("brew tap wez/wezterm-linuxbrew", nil)
-				result = system.RunBrewWithLogs("install wezterm", nil, func(line string) {
-					SendLog(stepID, line)
-				})
-			}
-			if result.Error != nil {
-				return wrapStepError("terminal", "Install WezTerm",
-					"Failed to install WezTerm terminal emulator",
-					result.Error)
-			}
-		} else {
-			SendLog(stepID, "WezTerm already installed")
-		}
-		SendLog(stepID, "Copying WezTerm configuration...")
-		if err := system.EnsureDir(filepath.Join(homeDir, ".config/wezterm")); err != nil {
-			return wrapStepError("terminal", "Install WezTerm",
-				"Failed to create WezTerm config directory",
-				err)
-		}
-		if err := system.CopyFile(filepath.Join(repoDir, ".wezterm.lua"), filepath.Join(homeDir, ".config/wezterm/wezterm.lua")); err != nil {
-			return wrapStepError("terminal", "Install WezTerm",
-				"Failed to copy WezTerm configuration",
-				err)
+				"Failed to install build dependencies",
+				result.Error)
 		}
-		SendLog(stepID, "✓ WezTerm configured")
-
-	case "kitty":
-		if !system.CommandExists("kitty") && m.SystemInfo.OS == system.OSMac {
-			SendLog(stepID, "Installing Kitty...")
-			result := system.RunBrewWithLogs("install --cask kitty", nil, func(line string) {
+		// Install Rust/Cargo only for this build
+		cargoPath := filepath.Join(homeDir, ".cargo/bin/cargo")
+		if !system.CommandExists("cargo") && !system.CommandExists(cargoPath) {
+			SendLog(stepID, "Installing Rust/Cargo toolchain...")
+			result = system.RunWithLogs(m.ctx(), "curl --proto '=https' --tlsv1.2 -sSf https://sh.rustup.rs | sh -s -- -y", nil, func(line string) {
 				SendLog(stepID, line)
 			})
 			if result.Error != nil {
-				return wrapStepError("terminal", "Install Kitty",
-					"Failed to install Kitty terminal emulator",
+				return wrapStepError("terminal", "Install Alacritty",
+					"Failed to install Rust",
 					result.Error)
 			}
-		} else {
-			SendLog(stepID, "Kitty already installed")
-		}
-		SendLog(stepID, "Copying Kitty configuration...")
-		if err := system.EnsureDir(filepath.Join(homeDir, ".config/kitty")); err != nil {
-			return wrapStepError("terminal", "Install Kitty",
-				"Failed to create Kitty config directory",
-				err)
+			cargoPath = filepath.Join(homeDir, ".cargo/bin/cargo")
 		}
-		if err := system.CopyDir(filepath.Join(repoDir, "GentlemanKitty"), filepath.Join(homeDir, ".config", "kitty")); err != nil {
-			return wrapStepError("terminal", "Install Kitty",
-				"Failed to copy Kitty configuration",
-				err)
+		// Clone and build Alacritty
+		SendLog(stepID, "Cloning Alacritty repository...")
+		alacrittyDir := filepath.Join(os.TempDir(), "alacritty-build")
+		system.RemoveAll(alacrittyDir)
+		result = system.RunWithLogs(m.ctx(), fmt.Sprintf("git clone https://github.com/alacritty/alacritty.git %s", alacrittyDir), nil, func(line string) {
+			SendLog(stepID, line)
+		})
+		if result.Error != nil {
+			return wrapStepError("terminal", "Install Alacritty",
+				"Failed to clone Alacritty repository",
+				result.Error)
 		}
-		SendLog(stepID, "✓ Kitty configured")
-
-	case "ghostty":
-		if !system.CommandExists("ghostty") {
-			SendLog(stepID, "Installing Ghostty...")
-			var result *system.ExecResult
-			if m.SystemInfo.OS == system.OSArch {
-				result = system.RunSudoWithLogs("pacman -S --noconfirm ghostty", nil, func(line string) {
-					SendLog(stepID, line)
-				})
-			} else if m.SystemInfo.OS == system.OSFedora {
-				// Fedora: enable COPR and install
-				system.RunSudo("dnf copr enable -y pgdev/ghostty", nil)
-				result = system.RunSudoWithLogs("dnf install -y ghostty", nil, func(line string) {
-					SendLog(stepID, line)
-				})
-			} else if m.SystemInfo.OS == system.OSMac {
-				result = system.RunBrewWithLogs("install --cask ghostty", nil, func(line string) {
-					SendLog(stepID, line)
-				})
-			} else {
-				result = system.RunWithLogs(`/bin/bash -c "$(curl -fsSL https://raw.githubusercontent.com/mkasberg/ghostty-ubuntu/HEAD/install.sh)"`, nil, func(line string) {
-					SendLog(stepID, line)
-				})
-			}
-			if result.Error != nil {
-				return wrapStepError("terminal", "Install Ghostty",
-					"Failed to install Ghostty terminal emulator",
-					result.Error)
-			}
+		SendLog(stepID, "Building Alacritty (this may take 5-10 minutes)...")
+		if !system.CommandExists("cargo") {
+			cargoPath = filepath.Join(homeDir, ".cargo/bin/cargo")
 		} else {
-			SendLog(stepID, "Ghostty already installed")
+			cargoPath = "cargo"
 		}
-		SendLog(stepID, "Copying Ghostty configuration...")
-		if err := system.EnsureDir(filepath.Join(homeDir, ".config/ghostty")); err != nil {
-			return wrapStepError("terminal", "Install Ghostty",
-				"Failed to create Ghostty config directory",
-				err)
+		result = system.RunWithLogs(m.ctx(), fmt.Sprintf("%s build --release --manifest-path %s/Cargo.toml", cargoPath, alacrittyDir), nil, func(line string) {
+			SendLog(stepID, line)
+		})
+		if result.Error != nil {
+			return wrapStepError("terminal", "Install Alacritty",
+				"Failed to build Alacritty",
+				result.Error)
 		}
-		if err := system.CopyDir(filepath.Join(repoDir, "GentlemanGhostty"), filepath.Join(homeDir, ".config", "ghostty")); err != nil {
-			return wrapStepError("terminal", "Install Ghostty",
-				"Failed to copy Ghostty configuration",
-				err)
+		SendLog(stepID, "Installing Alacritty binary...")
+		result = system.RunSudoWithLogs(m.ctx(), fmt.Sprintf("cp %s/target/release/alacritty /usr/local/bin/alacritty", alacrittyDir), nil, func(line string) {
+			SendLog(stepID, line)
+		})
+		if result.Error != nil {
+			return wrapStepError("terminal", "Install Alacritty",
+				"Failed to install Alacritty binary",
+				result.Error)
 		}
-		SendLog(stepID, "✓ Ghostty configured")
+		system.RunSudoWithLogs(m.ctx(), fmt.Sprintf("cp %s/extra/linux/Alacritty.desktop /usr/share/applications/", alacrittyDir), nil, func(line string) {
+			SendLog(stepID, line)
+		})
+		system.RemoveAll(alacrittyDir)
+		SendLog(stepID, "✓ Alacritty built and installed from source")
+	} else {
+		return wrapStepError("terminal", "Install Alacritty",
+			"Unsupported operating system for Alacritty installation",
+			fmt.Errorf("OS type: %v", m.SystemInfo.OS))
+	}
+	if result.Error != nil {
+		return wrapStepError("terminal", "Install Alacritty",
+			"Failed to install Alacritty terminal emulator",
+			result.Error)
 	}
+	return nil
+}
 
+func installWezTerm(m *Model, stepID string) error {
+	pm := system.PackageManagerFor(m.SystemInfo)
+	if m.SystemInfo.OS == system.OSFedora {
+		// Fedora: enable COPR first
+		system.RunSudo(m.ctx(), "dnf copr enable -y wezfurlong/wezterm-nightly", nil)
+	} else if !hasNativeTerminalPackages(m.SystemInfo) && m.SystemInfo.OS != system.OSMac {
+		// Other Linux: Homebrew tap
+		pm = system.NewBrewManager(m.SystemInfo)
+	}
+	result := pm.Install(m.ctx(), func(line string) {
+		SendLog(stepID, line)
+	}, "wezterm")
+	if result.Error != nil {
+		return wrapStepError("terminal", "Install WezTerm",
+			"Failed to install WezTerm terminal emulator",
+			result.Error)
+	}
+	return nil
+}
+
+func installKitty(m *Model, stepID string) error {
+	if m.SystemInfo.OS != system.OSMac {
+		SendLog(stepID, "Kitty is only installed automatically on macOS, copying its configuration")
+		return nil
+	}
+	result := system.NewBrewManager(m.SystemInfo).Install(m.ctx(), func(line string) {
+		SendLog(stepID, line)
+	}, "kitty")
+	if result.Error != nil {
+		return wrapStepError("terminal", "Install Kitty",
+			"Failed to install Kitty terminal emulator",
+			result.Error)
+	}
+	return nil
+}
+
+func installGhostty(m *Model, stepID string) error {
+	var result *system.ExecResult
+	if hasNativeTerminalPackages(m.SystemInfo) || m.SystemInfo.OS == system.OSMac {
+		if m.SystemInfo.OS == system.OSFedora {
+			// Fedora: enable COPR first
+			system.RunSudo(m.ctx(), "dnf copr enable -y pgdev/ghostty", nil)
+		}
+		result = system.PackageManagerFor(m.SystemInfo).Install(m.ctx(), func(line string) {
+			SendLog(stepID, line)
+		}, "ghostty")
+	} else {
+		result = system.RunWithLogs(m.ctx(), `/bin/bash -c "$(curl -fsSL https://raw.githubusercontent.com/mkasberg/ghostty-ubuntu/HEAD/install.sh)"`, nil, func(line string) {
+			SendLog(stepID, line)
+		})
+	}
+	if result.Error != nil {
+		return wrapStepError("terminal", "Install Ghostty",
+			"Failed to install Ghostty terminal emulator",
+			result.Error)
+	}
 	return nil
 }
 
 func stepInstallFont(m *Model) error {
 	homeDir := os.Getenv("HOME")
 	stepID := "font"
+	font := m.installFont()
+	name := "Install " + font.Name + " Nerd Font"
 
 	// Termux: fonts work differently - copy to ~/.termux/font.ttf
 	isTermux := m.SystemInfo.IsTermux || m.Choices.OS == "termux"
 	if isTermux {
-		SendLog(stepID, "Downloading JetBrainsMono Nerd Font for Termux...")
+		SendLog(stepID, fmt.Sprintf("Downloading %s Nerd Font for Termux...", font.Name))
 		termuxDir := filepath.Join(homeDir, ".termux")
 		if err := system.EnsureDir(termuxDir); err != nil {
-			return wrapStepError("font", "Install Nerd Font",
+			return wrapStepError("font", name,
 				"Failed to create .termux directory",
 				err)
 		}
 
 		// Download a single TTF file for Termux
-		result := system.RunWithLogs(fmt.Sprintf("curl -fsSL -o %s/font.ttf https://github.com/ryanoasis/nerd-fonts/raw/HEAD/patched-fonts/JetBrainsMono/Ligatures/Regular/JetBrainsMonoNerdFont-Regular.ttf", termuxDir), nil, func(line string) {
+		result := system.RunWithLogs(m.ctx(), fmt.Sprintf("curl -fsSL -o %s/font.ttf %s", termuxDir, font.TermuxURL()), nil, func(line string) {
 			SendLog(stepID, line)
 		})
 		if result.Error != nil {
-			return wrapStepError("font", "Install Nerd Font",
+			return wrapStepError("font", name,
 				"Failed to download font. Check your internet connection.",
 				result.Error)
 		}
 
 		SendLog(stepID, "Reloading Termux settings...")
-		system.Run("termux-reload-settings", nil)
+		system.Run(m.ctx(), "termux-reload-settings", nil)
 		SendLog(stepID, "✓ Font installed - restart Termux to apply")
 		return nil
 	}
 
+	if system.FontInstalled(font) {
+		SendLog(stepID, fmt.Sprintf("✓ %s already installed", font.Family))
+	} else if err := installFontFiles(m, font); err != nil {
+		return err
+	}
+	return applyFont(m, font)
+}
+
+// installFontFiles installs the font with Homebrew on macOS and from the
+// nerd-fonts release archive on Linux
+func installFontFiles(m *Model, font system.Font) error {
+	stepID := "font"
+	name := "Install " + font.Name + " Nerd Font"
+
 	if m.SystemInfo.OS == system.OSMac {
-		SendLog(stepID, "Installing Iosevka Term Nerd Font...")
-		result := system.RunBrewWithLogs("install --cask font-iosevka-term-nerd-font", nil, func(line string) {
+		SendLog(stepID, fmt.Sprintf("Installing %s Nerd Font...", font.Name))
+		result := system.NewBrewManager(m.SystemInfo).Install(m.ctx(), func(line string) {
 			SendLog(stepID, line)
-		})
+		}, font.Package)
 		if result.Error != nil {
-			return wrapStepError("font", "Install Iosevka Nerd Font",
+			return wrapStepError("font", name,
 				"Failed to install font via Homebrew. Try installing manually from https://www.nerdfonts.com/",
 				result.Error)
 		}
@@ -571,417 +514,167 @@ func stepInstallFont(m *Model) error {
 	}
 
 	// Linux
-	fontDir := filepath.Join(homeDir, ".local/share/fonts")
+	// Minimal installs (Alpine containers, Void) may lack the archive and cache tools
+	if !system.CommandExists("unzip") || !system.CommandExists("fc-cache") {
+		SendLog(stepID, "Installing unzip and fontconfig...")
+		result := system.PackageManagerFor(m.SystemInfo).Install(m.ctx(), func(line string) {
+			SendLog(stepID, line)
+		}, "unzip", "fontconfig")
+		if result.Error != nil {
+			return wrapStepError("font", name,
+				"Failed to install unzip and fontconfig",
+				result.Error)
+		}
+	}
+
+	fontDir := filepath.Join(os.Getenv("HOME"), ".local/share/fonts")
 	SendLog(stepID, "Creating fonts directory...")
 	if err := system.EnsureDir(fontDir); err != nil {
-		return wrapStepError("font", "Install Iosevka Nerd Font",
+		return wrapStepError("font", name,
 			"Failed to create fonts directory",
 			err)
 	}
 
-	SendLog(stepID, "Downloading Iosevka Term Nerd Font...")
-	result := system.RunWithLogs(fmt.Sprintf("curl -fsSL -o %s/IosevkaTerm.zip https://github.com/ryanoasis/nerd-fonts/releases/download/v3.3.0/IosevkaTerm.zip", fontDir), nil, func(line string) {
+	SendLog(stepID, fmt.Sprintf("Downloading %s Nerd Font...", font.Name))
+	archive := filepath.Join(fontDir, font.Archive+".zip")
+	result := system.RunWithLogs(m.ctx(), fmt.Sprintf("curl -fsSL -o %s %s", archive, font.ArchiveURL()), nil, func(line string) {
 		SendLog(stepID, line)
 	})
 	if result.Error != nil {
-		return wrapStepError("font", "Install Iosevka Nerd Font",
+		return wrapStepError("font", name,
 			"Failed to download font. Check your internet connection.",
 			result.Error)
 	}
 
 	SendLog(stepID, "Extracting font archive...")
-	result = system.RunWithLogs(fmt.Sprintf("unzip -o %s/IosevkaTerm.zip -d %s/", fontDir, fontDir), nil, func(line string) {
+	result = system.RunWithLogs(m.ctx(), fmt.Sprintf("unzip -o %s -d %s/", archive, fontDir), nil, func(line string) {
 		SendLog(stepID, line)
 	})
 	if result.Error != nil {
-		return wrapStepError("font", "Install Iosevka Nerd Font",
+		return wrapStepError("font", name,
 			"Failed to extract font archive",
 			result.Error)
 	}
 
 	SendLog(stepID, "Updating font cache...")
-	system.RunWithLogs("fc-cache -fv", nil, func(line string) {
+	system.RunWithLogs(m.ctx(), "fc-cache -fv", nil, func(line string) {
 		SendLog(stepID, line)
 	})
 	SendLog(stepID, "✓ Font installed")
 	return nil
 }
 
+// applyFont points the installed terminal and editor configs at the picked
+// font. Without a pick they keep the font they ship with.
+func applyFont(m *Model, font system.Font) error {
+	stepID := "font"
+	if m.Choices.Font == "" && m.Choices.FontSize == 0 {
+		return nil
+	}
+	updated, err := system.ApplyFont(font.ID, m.Choices.FontSize)
+	if err != nil {
+		return wrapStepError("font", "Install "+font.Name+" Nerd Font",
+			"Failed to set the font in the installed configs",
+			err)
+	}
+	if len(updated) > 0 {
+		SendLog(stepID, fmt.Sprintf("✓ %s set in %s", font.Family, strings.Join(updated, ", ")))
+	}
+	return nil
+}
+
 func stepInstallShell(m *Model) error {
 	homeDir := os.Getenv("HOME")
-	repoDir := m.RepoDir
-	shell := m.Choices.Shell
-	stepID := "shell"
 
 	// Common dependencies
-	SendLog(stepID, "Creating required directories...")
+	SendLog("shell", "Creating required directories...")
 	system.EnsureDir(filepath.Join(homeDir, ".config"))
 	system.EnsureDir(filepath.Join(homeDir, ".cache/starship"))
 	system.EnsureDir(filepath.Join(homeDir, ".cache/carapace"))
 	system.EnsureDir(filepath.Join(homeDir, ".local/share/atuin"))
 
-	switch shell {
-	case "fish":
-		SendLog(stepID, "Installing Fish shell and plugins...")
-		var result *system.ExecResult
-		if m.SystemInfo.IsTermux {
-			result = system.RunPkgInstall("fish starship zoxide", nil, func(line string) {
-				SendLog(stepID, line)
-			})
-		} else {
-			result = system.RunBrewWithLogs("install fish carapace zoxide atuin starship", nil, func(line string) {
-				SendLog(stepID, line)
-			})
-		}
-		if result.Error != nil {
-			return wrapStepError("shell", "Install Fish",
-				"Failed to install Fish shell and dependencies",
-				result.Error)
-		}
-		SendLog(stepID, "Copying Fish configuration...")
-		if err := system.CopyFile(filepath.Join(repoDir, "starship.toml"), filepath.Join(homeDir, ".config/starship.toml")); err != nil {
-			return wrapStepError("shell", "Install Fish",
-				"Failed to copy starship configuration",
-				err)
-		}
-		if err := system.CopyDir(filepath.Join(repoDir, "GentlemanFish", "fish"), filepath.Join(homeDir, ".config", "fish")); err != nil {
-			return wrapStepError("shell", "Install Fish",
-				"Failed to copy Fish configuration",
-				err)
-		}
-		// Patch config.fish based on WM choice
-		SendLog(stepID, "Configuring shell for window manager...")
-		if err := system.PatchFishForWM(filepath.Join(homeDir, ".config/fish/config.fish"), m.Choices.WindowMgr, m.Choices.InstallNvim); err != nil {
-			return wrapStepError("shell", "Install Fish",
-				"Failed to configure config.fish for window manager",
-				err)
-		}
-		// Remove tmux.fish function if not using tmux
-		if m.Choices.WindowMgr != "tmux" {
-			os.Remove(filepath.Join(homeDir, ".config/fish/functions/tmux.fish"))
-		}
-		// Termux: Add fish to $PREFIX/etc/shells so tmux doesn't complain
-		if m.SystemInfo.IsTermux {
-			SendLog(stepID, "Adding fish to Termux shells...")
-			prefix := os.Getenv("PREFIX")
-			if prefix == "" {
-				prefix = "/data/data/com.termux/files/usr"
-			}
-			shellsFile := filepath.Join(prefix, "etc", "shells")
-			system.EnsureDir(filepath.Join(prefix, "etc"))
-			f, err := os.OpenFile(shellsFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-			if err == nil {
-				f.WriteString(filepath.Join(prefix, "bin", "fish") + "\n")
-				f.Close()
-			}
-		}
-		SendLog(stepID, "✓ Fish shell configured")
-
-	case "zsh":
-		SendLog(stepID, "Installing Zsh and plugins...")
-		var result *system.ExecResult
-		if m.SystemInfo.IsTermux {
-			// Termux has zsh in pkg, but plugins need to be installed differently
-			result = system.RunPkgInstall("zsh starship zoxide", nil, func(line string) {
-				SendLog(stepID, line)
-			})
-		} else {
-			result = system.RunBrewWithLogs("install zsh carapace zoxide atuin zsh-autosuggestions zsh-syntax-highlighting zsh-autocomplete powerlevel10k", nil, func(line string) {
-				SendLog(stepID, line)
-			})
-		}
-		if result.Error != nil {
-			return wrapStepError("shell", "Install Zsh",
-				"Failed to install Zsh and plugins",
-				result.Error)
-		}
-		SendLog(stepID, "Copying Zsh configuration...")
-		if err := system.CopyFile(filepath.Join(repoDir, "GentlemanZsh/.zshrc"), filepath.Join(homeDir, ".zshrc")); err != nil {
-			return wrapStepError("shell", "Install Zsh",
-				"Failed to copy .zshrc configuration",
-				err)
-		}
-		// Patch .zshrc based on WM choice
-		SendLog(stepID, "Configuring shell for window manager...")
-		if err := system.PatchZshForWM(filepath.Join(homeDir, ".zshrc"), m.Choices.WindowMgr, m.Choices.InstallNvim); err != nil {
-			return wrapStepError("shell", "Install Zsh",
-				"Failed to configure .zshrc for window manager",
-				err)
-		}
-		if err := system.CopyFile(filepath.Join(repoDir, "GentlemanZsh/.p10k.zsh"), filepath.Join(homeDir, ".p10k.zsh")); err != nil {
-			return wrapStepError("shell", "Install Zsh",
-				"Failed to copy Powerlevel10k configuration",
-				err)
-		}
-		if err := system.CopyDir(filepath.Join(repoDir, "GentlemanZsh", ".oh-my-zsh"), filepath.Join(homeDir, ".oh-my-zsh")); err != nil {
-			return wrapStepError("shell", "Install Zsh",
-				"Failed to copy Oh-My-Zsh directory",
-				err)
-		}
-		// Termux: Add zsh to $PREFIX/etc/shells so tmux doesn't complain
-		if m.SystemInfo.IsTermux {
-			SendLog(stepID, "Adding zsh to Termux shells...")
-			prefix := os.Getenv("PREFIX")
-			if prefix == "" {
-				prefix = "/data/data/com.termux/files/usr"
-			}
-			shellsFile := filepath.Join(prefix, "etc", "shells")
-			system.EnsureDir(filepath.Join(prefix, "etc"))
-			f, err := os.OpenFile(shellsFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-			if err == nil {
-				f.WriteString(filepath.Join(prefix, "bin", "zsh") + "\n")
-				f.Close()
-			}
-		}
-		SendLog(stepID, "✓ Zsh configured with Powerlevel10k")
-
-	case "nushell":
-		SendLog(stepID, "Installing Nushell and dependencies...")
-		var result *system.ExecResult
-		if m.SystemInfo.IsTermux {
-			result = system.RunPkgInstall("nushell starship zoxide jq", nil, func(line string) {
-				SendLog(stepID, line)
-			})
-		} else {
-			result = system.RunBrewWithLogs("install nushell carapace zoxide atuin jq bash starship", nil, func(line string) {
-				SendLog(stepID, line)
-			})
-		}
-		if result.Error != nil {
-			return wrapStepError("shell", "Install Nushell",
-				"Failed to install Nushell and dependencies",
-				result.Error)
-		}
-		SendLog(stepID, "Copying Nushell configuration...")
-		if err := system.CopyFile(filepath.Join(repoDir, "starship.toml"), filepath.Join(homeDir, ".config/starship.toml")); err != nil {
-			return wrapStepError("shell", "Install Nushell",
-				"Failed to copy starship configuration",
-				err)
-		}
-		if err := system.CopyFile(filepath.Join(repoDir, "bash-env-json"), filepath.Join(homeDir, ".config/bash-env-json")); err != nil {
-			return wrapStepError("shell", "Install Nushell",
-				"Failed to copy bash-env-json",
-				err)
-		}
-		if err := system.CopyFile(filepath.Join(repoDir, "bash-env.nu"), filepath.Join(homeDir, ".config/bash-env.nu")); err != nil {
-			return wrapStepError("shell", "Install Nushell",
-				"Failed to copy bash-env.nu",
-				err)
-		}
+	return installTool(m, "shell", m.Choices.Shell)
+}
 
-		var nuDir string
-		if runtime.GOOS == "darwin" {
-			nuDir = filepath.Join(homeDir, "Library/Application Support/nushell")
-		} else {
-			nuDir = filepath.Join(homeDir, ".config/nushell")
-		}
-		if err := system.EnsureDir(nuDir); err != nil {
-			return wrapStepError("shell", "Install Nushell",
-				"Failed to create Nushell config directory",
-				err)
-		}
-		if err := system.CopyDir(filepath.Join(repoDir, "GentlemanNushell"), nuDir); err != nil {
-			return wrapStepError("shell", "Install Nushell",
-				"Failed to copy Nushell configuration",
-				err)
-		}
-		// Patch config.nu based on WM choice
-		SendLog(stepID, "Configuring shell for window manager...")
-		if err := system.PatchNushellForWM(filepath.Join(nuDir, "config.nu"), m.Choices.WindowMgr); err != nil {
-			return wrapStepError("shell", "Install Nushell",
-				"Failed to configure config.nu for window manager",
-				err)
-		}
-		// Termux: Add nu to $PREFIX/etc/shells so tmux doesn't complain
-		if m.SystemInfo.IsTermux {
-			SendLog(stepID, "Adding nushell to Termux shells...")
-			prefix := os.Getenv("PREFIX")
-			if prefix == "" {
-				prefix = "/data/data/com.termux/files/usr"
-			}
-			shellsFile := filepath.Join(prefix, "etc", "shells")
-			system.EnsureDir(filepath.Join(prefix, "etc"))
-			f, err := os.OpenFile(shellsFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-			if err == nil {
-				f.WriteString(filepath.Join(prefix, "bin", "nu") + "\n")
-				f.Close()
-			}
-		}
-		SendLog(stepID, "✓ Nushell configured")
+// setupFish fits config.fish to the window manager choice
+func setupFish(m *Model, stepID string) error {
+	fishDir := filepath.Join(os.Getenv("HOME"), ".config", "fish")
+	if err := system.PatchFishForWM(filepath.Join(fishDir, "config.fish"), m.Choices.WindowMgr, m.Choices.InstallNvim); err != nil {
+		return err
+	}
+	// Remove tmux.fish function if not using tmux
+	if m.Choices.WindowMgr != "tmux" {
+		return system.RemoveAll(filepath.Join(fishDir, "functions", "tmux.fish"))
 	}
-
 	return nil
 }
 
 func stepInstallWM(m *Model) error {
-	homeDir := os.Getenv("HOME")
-	repoDir := m.RepoDir
-	wm := m.Choices.WindowMgr
-	stepID := "wm"
-
-	switch wm {
-	case "tmux":
-		if !system.CommandExists("tmux") {
-			SendLog(stepID, "Installing Tmux...")
-			var result *system.ExecResult
-			if m.SystemInfo.IsTermux {
-				result = system.RunPkgInstall("tmux", nil, func(line string) {
-					SendLog(stepID, line)
-				})
-			} else {
-				result = system.RunBrewWithLogs("install tmux", nil, func(line string) {
-					SendLog(stepID, line)
-				})
-			}
-			if result.Error != nil {
-				return wrapStepError("wm", "Install Tmux",
-					"Failed to install Tmux",
-					result.Error)
-			}
-		} else {
-			SendLog(stepID, "Tmux already installed")
-		}
+	return installTool(m, "wm", m.Choices.WindowMgr)
+}
 
-		// TPM
-		tpmDir := filepath.Join(homeDir, ".tmux/plugins/tpm")
-		if _, err := os.Stat(tpmDir); os.IsNotExist(err) {
-			SendLog(stepID, "Cloning TPM (Tmux Plugin Manager)...")
-			result := system.RunWithLogs(fmt.Sprintf("git clone https://github.com/tmux-plugins/tpm %s", tpmDir), nil, func(line string) {
-				SendLog(stepID, line)
-			})
-			if result.Error != nil {
-				return wrapStepError("wm", "Install Tmux",
-					"Failed to clone TPM (Tmux Plugin Manager)",
-					result.Error)
-			}
-		}
+// setupTmux installs TPM and its plugins and makes the chosen shell the
+// tmux default
+func setupTmux(m *Model, stepID string) error {
+	homeDir := os.Getenv("HOME")
 
-		SendLog(stepID, "Copying Tmux configuration...")
-		if err := system.EnsureDir(filepath.Join(homeDir, ".tmux")); err != nil {
-			return wrapStepError("wm", "Install Tmux",
-				"Failed to create .tmux directory",
-				err)
-		}
-		if err := system.CopyDir(filepath.Join(repoDir, "GentlemanTmux", "plugins"), filepath.Join(homeDir, ".tmux", "plugins")); err != nil {
-			return wrapStepError("wm", "Install Tmux",
-				"Failed to copy Tmux plugins",
-				err)
-		}
-		if err := system.CopyFile(filepath.Join(repoDir, "GentlemanTmux/tmux.conf"), filepath.Join(homeDir, ".tmux.conf")); err != nil {
+	// TPM
+	tpmDir := filepath.Join(homeDir, ".tmux/plugins/tpm")
+	if _, err := os.Stat(tpmDir); os.IsNotExist(err) {
+		SendLog(stepID, "Cloning TPM (Tmux Plugin Manager)...")
+		result := system.RunWithLogs(m.ctx(), fmt.Sprintf("git clone https://github.com/tmux-plugins/tpm %s", tpmDir), nil, func(line string) {
+			SendLog(stepID, line)
+		})
+		if result.Error != nil {
 			return wrapStepError("wm", "Install Tmux",
-				"Failed to copy tmux.conf",
-				err)
+				"Failed to clone TPM (Tmux Plugin Manager)",
+				result.Error)
 		}
+	}
 
-		// Configure tmux to use the user's chosen shell
+	// Configure tmux to use the user's chosen shell
+	if shellName := shellCommand(m.Choices.Shell); shellName != "" {
 		SendLog(stepID, "Configuring tmux default shell...")
-		tmuxConfPath := filepath.Join(homeDir, ".tmux.conf")
-		shellName := ""
-		switch m.Choices.Shell {
-		case "fish":
-			shellName = "fish"
-		case "zsh":
-			shellName = "zsh"
-		case "nushell":
-			shellName = "nu"
-		}
-		if shellName != "" {
-			// Find the full path to the shell
-			shellFullPath := ""
-			if m.SystemInfo.IsTermux {
-				// In Termux, construct the path directly (which command has issues)
-				prefix := os.Getenv("PREFIX")
-				if prefix == "" {
-					prefix = "/data/data/com.termux/files/usr"
-				}
-				shellFullPath = filepath.Join(prefix, "bin", shellName)
-			} else {
-				result := system.Run(fmt.Sprintf("which %s", shellName), nil)
-				if result.Error == nil && result.Output != "" {
-					shellFullPath = strings.TrimSpace(result.Output)
-				}
-			}
-			if shellFullPath == "" {
-				shellFullPath = shellName // Fallback
-			}
-
-			// Replace placeholder in tmux.conf with actual shell config
-			content, err := os.ReadFile(tmuxConfPath)
-			if err == nil {
-				shellConfig := fmt.Sprintf("set -g default-command \"%s\"\nset -g default-shell \"%s\"", shellFullPath, shellFullPath)
-				newContent := strings.Replace(string(content), "# GENTLEMAN_DEFAULT_SHELL", shellConfig, 1)
-				os.WriteFile(tmuxConfPath, []byte(newContent), 0644)
-			}
-		}
-
-		// Install plugins
-		SendLog(stepID, "Installing Tmux plugins...")
-		system.RunWithLogs(filepath.Join(homeDir, ".tmux/plugins/tpm/bin/install_plugins"), nil, func(line string) {
-			SendLog(stepID, line)
-		})
-		SendLog(stepID, "✓ Tmux configured")
-
-	case "zellij":
-		if !system.CommandExists("zellij") {
-			SendLog(stepID, "Installing Zellij...")
-			var result *system.ExecResult
-			if m.SystemInfo.IsTermux {
-				result = system.RunPkgInstall("zellij", nil, func(line string) {
-					SendLog(stepID, line)
-				})
-			} else {
-				result = system.RunBrewWithLogs("install zellij", nil, func(line string) {
-					SendLog(stepID, line)
-				})
-			}
-			if result.Error != nil {
-				return wrapStepError("wm", "Install Zellij",
-					"Failed to install Zellij",
-					result.Error)
-			}
+		// Find the full path to the shell
+		shellFullPath := ""
+		if m.SystemInfo.IsTermux {
+			// In Termux, construct the path directly (which command has issues)
+			shellFullPath = filepath.Join(termuxPrefix(), "bin", shellName)
 		} else {
-			SendLog(stepID, "Zellij already installed")
-		}
-
-		SendLog(stepID, "Copying Zellij configuration...")
-		zellijDir := filepath.Join(homeDir, ".config/zellij")
-		if err := system.EnsureDir(zellijDir); err != nil {
-			return wrapStepError("wm", "Install Zellij",
-				"Failed to create Zellij config directory",
-				err)
+			result := system.Run(m.ctx(), fmt.Sprintf("which %s", shellName), nil)
+			if result.Error == nil && result.Output != "" {
+				shellFullPath = strings.TrimSpace(result.Output)
+			}
 		}
-		if err := system.CopyDir(filepath.Join(repoDir, "GentlemanZellij", "zellij"), zellijDir); err != nil {
-			return wrapStepError("wm", "Install Zellij",
-				"Failed to copy Zellij configuration",
-				err)
+		if shellFullPath == "" {
+			shellFullPath = shellName // Fallback
 		}
 
-		// Configure zellij to use the user's chosen shell
-		SendLog(stepID, "Configuring zellij default shell...")
-		zellijConfPath := filepath.Join(zellijDir, "config.kdl")
-		shellPath := ""
-		switch m.Choices.Shell {
-		case "fish":
-			shellPath = "fish"
-		case "zsh":
-			shellPath = "zsh"
-		case "nushell":
-			shellPath = "nu"
-		}
-		if shellPath != "" {
-			// Append default_shell config to zellij config.kdl
-			f, err := os.OpenFile(zellijConfPath, os.O_APPEND|os.O_WRONLY, 0644)
-			if err == nil {
-				f.WriteString(fmt.Sprintf("\n// Default shell (configured by Gentleman.Dots)\ndefault_shell \"%s\"\n", shellPath))
-				f.Close()
-			}
-		}
-		SendLog(stepID, "✓ Zellij configured")
+		// Replace placeholder in tmux.conf with actual shell config
+		shellConfig := fmt.Sprintf("set -g default-command \"%s\"\nset -g default-shell \"%s\"", shellFullPath, shellFullPath)
+		system.ReplaceInFile(filepath.Join(homeDir, ".tmux.conf"), "# GENTLEMAN_DEFAULT_SHELL", shellConfig)
 	}
 
+	// Install plugins
+	SendLog(stepID, "Installing Tmux plugins...")
+	system.RunWithLogs(m.ctx(), filepath.Join(homeDir, ".tmux/plugins/tpm/bin/install_plugins"), nil, func(line string) {
+		SendLog(stepID, line)
+	})
 	return nil
 }
 
+// setupZellij makes the chosen shell the zellij default
+func setupZellij(m *Model, stepID string) error {
+	shellName := shellCommand(m.Choices.Shell)
+	if shellName == "" {
+		return nil
+	}
+	SendLog(stepID, "Configuring zellij default shell...")
+	zellijConfPath := filepath.Join(os.Getenv("HOME"), ".config/zellij/config.kdl")
+	_, err := system.SetRcBlock(zellijConfPath, system.RcBlockDefaultShell, fmt.Sprintf("default_shell \"%s\"", shellName))
+	return err
+}
+
 func stepInstallNvim(m *Model) error {
 	homeDir := os.Getenv("HOME")
 	repoDir := m.RepoDir
@@ -992,22 +685,14 @@ func stepInstallNvim(m *Model) error {
 		SendLog(stepID, "Installing Obsidian app...")
 		var obsResult *system.ExecResult
 		switch m.SystemInfo.OS {
-		case system.OSMac:
-			obsResult = system.RunBrewWithLogs("install --cask obsidian", nil, func(line string) {
-				SendLog(stepID, line)
-			})
-		case system.OSArch:
-			obsResult = system.RunSudoWithLogs("pacman -S --noconfirm obsidian", nil, func(line string) {
+		case system.OSMac, system.OSArch:
+			obsResult = system.PackageManagerFor(m.SystemInfo).Install(m.ctx(), func(line string) {
 				SendLog(stepID, line)
-			})
-		case system.OSDebian, system.OSLinux:
-			obsResult = system.RunWithLogs("flatpak install -y flathub md.obsidian.Obsidian", nil, func(line string) {
-				SendLog(stepID, line)
-			})
-		case system.OSFedora:
-			obsResult = system.RunWithLogs("flatpak install -y flathub md.obsidian.Obsidian", nil, func(line string) {
+			}, "obsidian")
+		case system.OSDebian, system.OSLinux, system.OSFedora:
+			obsResult = system.NewFlatpakManager().Install(m.ctx(), func(line string) {
 				SendLog(stepID, line)
-			})
+			}, "obsidian")
 		}
 		if obsResult != nil && obsResult.Error != nil {
 			SendLog(stepID, "⚠ Obsidian install failed: "+obsResult.Error.Error())
@@ -1026,16 +711,9 @@ func stepInstallNvim(m *Model) error {
 	// Check Node.js
 	if !system.CommandExists("node") {
 		SendLog(stepID, "Installing Node.js...")
-		var result *system.ExecResult
-		if m.SystemInfo.IsTermux {
-			result = system.RunPkgInstall("nodejs", nil, func(line string) {
-				SendLog(stepID, line)
-			})
-		} else {
-			result = system.RunBrewWithLogs("install node", nil, func(line string) {
-				SendLog(stepID, line)
-			})
-		}
+		result := system.ToolManagerFor(m.SystemInfo).Install(m.ctx(), func(line string) {
+			SendLog(stepID, line)
+		}, "node")
 		if result.Error != nil {
 			return wrapStepError("nvim", "Install Neovim",
 				"Failed to install Node.js (required for LSP servers)",
@@ -1047,17 +725,9 @@ func stepInstallNvim(m *Model) error {
 
 	// Install dependencies
 	SendLog(stepID, "Installing Neovim and dependencies...")
-	var result *system.ExecResult
-	if m.SystemInfo.IsTermux {
-		// Termux package names (neovim instead of nvim, clang instead of gcc)
-		result = system.RunPkgInstall("neovim git clang fzf fd ripgrep bat curl lazygit", nil, func(line string) {
-			SendLog(stepID, line)
-		})
-	} else {
-		result = system.RunBrewWithLogs("install nvim git gcc fzf fd ripgrep coreutils bat curl lazygit tree-sitter", nil, func(line string) {
-			SendLog(stepID, line)
-		})
-	}
+	result := system.ToolManagerFor(m.SystemInfo).Install(m.ctx(), func(line string) {
+		SendLog(stepID, line)
+	}, "neovim", "git", "c-compiler", "fzf", "fd", "ripgrep", "coreutils", "bat", "curl", "lazygit", "tree-sitter")
 	if result.Error != nil {
 		return wrapStepError("nvim", "Install Neovim",
 			"Failed to install Neovim and dependencies",
@@ -1100,22 +770,21 @@ func stepInstallZed(m *Model) error {
 		SendLog(stepID, "Installing Zed editor...")
 		var result *system.ExecResult
 		switch m.SystemInfo.OS {
-		case system.OSMac:
-			result = system.RunBrewWithLogs("install --cask zed", nil, func(line string) {
+		case system.OSMac, system.OSArch:
+			result = system.PackageManagerFor(m.SystemInfo).Install(m.ctx(), func(line string) {
 				SendLog(stepID, line)
-			})
-		case system.OSArch:
-			result = system.RunSudoWithLogs("pacman -S --noconfirm zed", nil, func(line string) {
-				SendLog(stepID, line)
-			})
-		case system.OSDebian, system.OSLinux, system.OSFedora:
-			result = system.RunWithLogs("bash -c 'curl -f https://zed.dev/install.sh | sh'", nil, func(line string) {
-				SendLog(stepID, line)
-			})
+			}, "zed")
 		default:
-			result = system.RunWithLogs("bash -c 'curl -f https://zed.dev/install.sh | sh'", nil, func(line string) {
+			// Official install script, Flathub when it fails
+			result = system.RunWithLogs(m.ctx(), "bash -c 'curl -f https://zed.dev/install.sh | sh'", nil, func(line string) {
 				SendLog(stepID, line)
 			})
+			if result.Error != nil && system.CommandExists("flatpak") {
+				SendLog(stepID, "Install script failed, trying Flathub...")
+				result = system.NewFlatpakManager().Install(m.ctx(), func(line string) {
+					SendLog(stepID, line)
+				}, "zed")
+			}
 		}
 		if result != nil && result.Error != nil {
 			SendLog(stepID, "Warning: Zed install failed: "+result.Error.Error())
@@ -1136,7 +805,7 @@ func stepInstallZed(m *Model) error {
 			err)
 	}
 
-	srcZed := filepath.Join(repoDir, "GentlemanZed")
+	srcZed := filepath.Join(repoDir, "GentlemanZed", "zed")
 	if err := system.CopyDir(srcZed, zedDir); err != nil {
 		return wrapStepError("zed", "Install Zed",
 			"Failed to copy Zed configuration",
@@ -1165,7 +834,7 @@ func stepInstallAITools(m *Model) error {
 	// Install and configure Claude Code
 	if hasAITool(m.Choices.AITools, "claude") {
 		SendLog(stepID, "Installing Claude Code...")
-		system.RunWithLogs(`curl -fsSL https://claude.ai/install.sh | bash`, nil, func(line string) {
+		system.RunWithLogs(m.ctx(), `curl -fsSL https://claude.ai/install.sh | bash`, nil, func(line string) {
 			SendLog(stepID, line)
 		})
 
@@ -1178,14 +847,14 @@ func stepInstallAITools(m *Model) error {
 		system.CopyFile(filepath.Join(repoDir, "GentlemanClaude/CLAUDE.md"), filepath.Join(claudeDir, "CLAUDE.md"))
 		system.CopyFile(filepath.Join(repoDir, "GentlemanClaude/settings.json"), filepath.Join(claudeDir, "settings.json"))
 		system.CopyFile(filepath.Join(repoDir, "GentlemanClaude/statusline.sh"), filepath.Join(claudeDir, "statusline.sh"))
-		system.Run(fmt.Sprintf("chmod +x %s", filepath.Join(claudeDir, "statusline.sh")), nil)
+		system.Run(m.ctx(), fmt.Sprintf("chmod +x %s", filepath.Join(claudeDir, "statusline.sh")), nil)
 		system.CopyFile(filepath.Join(repoDir, "GentlemanClaude/output-styles/gentleman.md"), filepath.Join(claudeDir, "output-styles/gentleman.md"))
 		system.CopyFile(filepath.Join(repoDir, "GentlemanClaude/mcp-servers.template.json"), filepath.Join(claudeDir, "mcp-servers.template.json"))
 		system.CopyFile(filepath.Join(repoDir, "GentlemanClaude/tweakcc-theme.json"), filepath.Join(claudeDir, "tweakcc-theme.json"))
 		SendLog(stepID, "⚙️ Copied CLAUDE.md, statusline, output styles, config")
 
 		SendLog(stepID, "Applying tweakcc theme...")
-		result := system.Run("npx tweakcc --apply", nil)
+		result := system.Run(m.ctx(), "npx tweakcc --apply", nil)
 		if result.Error == nil {
 			SendLog(stepID, "🎨 Applied tweakcc theme")
 		} else {
@@ -1204,7 +873,7 @@ func stepInstallAITools(m *Model) error {
 	// Install and configure OpenCode
 	if hasAITool(m.Choices.AITools, "opencode") {
 		SendLog(stepID, "Installing OpenCode...")
-		system.RunWithLogs(`curl -fsSL https://opencode.ai/install | bash`, nil, func(line string) {
+		system.RunWithLogs(m.ctx(), `curl -fsSL https://opencode.ai/install | bash`, nil, func(line string) {
 			SendLog(stepID, line)
 		})
 
@@ -1215,7 +884,7 @@ func stepInstallAITools(m *Model) error {
 
 		// Clean agents directory first to avoid duplicates from previous installs
 		agentsDir := filepath.Join(openCodeDir, "agents")
-		os.RemoveAll(agentsDir)
+		system.RemoveAll(agentsDir)
 		system.EnsureDir(agentsDir)
 
 		// Copy only orchestrators from Javi.Dots (not individual agents from other sources)
@@ -1239,7 +908,7 @@ func stepInstallAITools(m *Model) error {
 	// Install Gemini CLI
 	if hasAITool(m.Choices.AITools, "gemini") {
 		SendLog(stepID, "Installing Gemini CLI...")
-		result := system.RunWithLogs(`npm install -g @google/gemini-cli`, nil, func(line string) {
+		result := system.RunWithLogs(m.ctx(), `npm install -g @google/gemini-cli`, nil, func(line string) {
 			SendLog(stepID, line)
 		})
 		if result.Error != nil {
@@ -1252,7 +921,7 @@ func stepInstallAITools(m *Model) error {
 	// Install and configure OpenAI Codex CLI
 	if hasAITool(m.Choices.AITools, "codex") {
 		SendLog(stepID, "Installing Codex CLI...")
-		result := system.RunWithLogs(`npm install -g @openai/codex`, nil, func(line string) {
+		result := system.RunWithLogs(m.ctx(), `npm install -g @openai/codex`, nil, func(line string) {
 			SendLog(stepID, line)
 		})
 		if result.Error != nil {
@@ -1273,7 +942,7 @@ func stepInstallAITools(m *Model) error {
 	// Install and configure Qwen Code
 	if hasAITool(m.Choices.AITools, "qwen") {
 		SendLog(stepID, "Installing Qwen Code...")
-		result := system.RunWithLogs(`npm install -g @qwen-code/qwen-code@latest`, nil, func(line string) {
+		result := system.RunWithLogs(m.ctx(), `npm install -g @qwen-code/qwen-code@latest`, nil, func(line string) {
 			SendLog(stepID, line)
 		})
 		if result.Error != nil {
@@ -1294,7 +963,7 @@ func stepInstallAITools(m *Model) error {
 	// Install GitHub Copilot CLI (new standalone version)
 	if hasAITool(m.Choices.AITools, "copilot") {
 		SendLog(stepID, "Installing GitHub Copilot CLI...")
-		result := system.RunWithLogs(`curl -fsSL https://gh.io/copilot-install | bash`, nil, func(line string) {
+		result := system.RunWithLogs(m.ctx(), `curl -fsSL https://gh.io/copilot-install | bash`, nil, func(line string) {
 			SendLog(stepID, line)
 		})
 		if result.Error != nil {
@@ -1352,14 +1021,14 @@ func setupCentralizedSkills(m *Model) error {
 			needsClone = false
 			SendLog(stepID, "Using cached Gentleman-Skills repo")
 		} else {
-			os.RemoveAll(centralDir)
+			system.RemoveAll(centralDir)
 		}
 	}
 
 	if needsClone {
 		SendLog(stepID, "Cloning Gentleman-Skills...")
 		system.EnsureDir(filepath.Join(homeDir, ".gentleman"))
-		result := system.RunWithLogs(
+		result := system.RunWithLogs(m.ctx(),
 			"git clone --depth 1 https://github.com/Gentleman-Programming/Gentleman-Skills.git "+centralDir,
 			nil, func(line string) { SendLog(stepID, line) },
 		)
@@ -1375,14 +1044,14 @@ func setupCentralizedSkills(m *Model) error {
 			needsClonePSF = false
 			SendLog(stepID, "Using cached Project-Starter-Framework repo")
 		} else {
-			os.RemoveAll(psfDir)
+			system.RemoveAll(psfDir)
 		}
 	}
 
 	if needsClonePSF {
 		SendLog(stepID, "Cloning Project-Starter-Framework...")
 		system.EnsureDir(filepath.Join(homeDir, ".gentleman"))
-		result := system.RunWithLogs(
+		result := system.RunWithLogs(m.ctx(),
 			"git clone --depth 1 https://github.com/JNZader/project-starter-framework.git "+psfDir,
 			nil, func(line string) { SendLog(stepID, line) },
 		)
@@ -1398,14 +1067,14 @@ func setupCentralizedSkills(m *Model) error {
 			needsCloneATL = false
 			SendLog(stepID, "Using cached Agent-Teams-Lite repo")
 		} else {
-			os.RemoveAll(atlDir)
+			system.RemoveAll(atlDir)
 		}
 	}
 
 	if needsCloneATL {
 		SendLog(stepID, "Cloning Agent-Teams-Lite...")
 		system.EnsureDir(filepath.Join(homeDir, ".gentleman"))
-		result := system.RunWithLogs(
+		result := system.RunWithLogs(m.ctx(),
 			"git clone --depth 1 https://github.com/Gentleman-Programming/agent-teams-lite.git "+atlDir,
 			nil, func(line string) { SendLog(stepID, line) },
 		)
@@ -1484,8 +1153,8 @@ func setupCentralizedSkills(m *Model) error {
 			name := filepath.Base(sp)
 			dst := filepath.Join(claudeSkillsDir, name)
 			// Remove existing (file, dir, or stale symlink)
-			os.RemoveAll(dst)
-			if err := os.Symlink(sp, dst); err != nil {
+			system.RemoveAll(dst)
+			if err := system.Symlink(sp, dst); err != nil {
 				SendLog(stepID, fmt.Sprintf("⚠️ Could not symlink %s for Claude: %v", name, err))
 			} else {
 				linked++
@@ -1503,8 +1172,8 @@ func setupCentralizedSkills(m *Model) error {
 			name := filepath.Base(sp)
 			dst := filepath.Join(agentsSkillsDir, name)
 			// Remove existing (file, dir, or stale symlink)
-			os.RemoveAll(dst)
-			if err := os.Symlink(sp, dst); err != nil {
+			system.RemoveAll(dst)
+			if err := system.Symlink(sp, dst); err != nil {
 				SendLog(stepID, fmt.Sprintf("⚠️ Could not symlink %s for agents: %v", name, err))
 			} else {
 				linked++
@@ -1544,10 +1213,10 @@ func stepInstallAIFramework(m *Model) error {
 	// Run project-starter-framework setup if there are features to install
 	if len(features) > 0 {
 		// Clean up any leftover clone from a previous failed run
-		system.Run("rm -rf /tmp/project-starter-framework-install", nil)
+		system.Run(m.ctx(), "rm -rf /tmp/project-starter-framework-install", nil)
 
 		SendLog(stepID, "Cloning project-starter-framework...")
-		result := system.RunWithLogs(
+		result := system.RunWithLogs(m.ctx(),
 			"git clone --depth 1 https://github.com/JNZader/project-starter-framework.git /tmp/project-starter-framework-install",
 			nil, func(line string) { SendLog(stepID, line) },
 		)
@@ -1587,7 +1256,7 @@ func stepInstallAIFramework(m *Model) error {
 
 		SendLog(stepID, "Running framework setup...")
 		SendLog(stepID, fmt.Sprintf("Command: %s", setupCmd))
-		result = system.RunWithLogs(setupCmd, nil, func(line string) {
+		result = system.RunWithLogs(m.ctx(), setupCmd, nil, func(line string) {
 			SendLog(stepID, line)
 		})
 		if result.Error != nil {
@@ -1596,7 +1265,7 @@ func stepInstallAIFramework(m *Model) error {
 		}
 
 		// Cleanup cloned framework repo
-		system.Run("rm -rf /tmp/project-starter-framework-install", nil)
+		system.Run(m.ctx(), "rm -rf /tmp/project-starter-framework-install", nil)
 
 		SendLog(stepID, "✓ AI framework configured")
 	}
@@ -1641,9 +1310,9 @@ func stepInstallEngram(m *Model) error {
 
 	// Install engram via Homebrew
 	SendLog(stepID, "Installing Engram via Homebrew...")
-	result := system.RunWithLogs("brew install gentleman-programming/tap/engram", nil, func(line string) {
+	result := system.NewBrewManager(m.SystemInfo).Install(m.ctx(), func(line string) {
 		SendLog(stepID, line)
-	})
+	}, "gentleman-programming/tap/engram")
 	if result.Error != nil {
 		SendLog(stepID, "⚠️ Could not install Engram via Homebrew")
 		SendLog(stepID, "You can manually install from: https://github.com/Gentleman-Programming/engram")
@@ -1654,7 +1323,7 @@ func stepInstallEngram(m *Model) error {
 
 	// Configure for OpenCode
 	SendLog(stepID, "Configuring Engram for OpenCode...")
-	result = system.RunWithLogs("engram setup opencode", nil, func(line string) {
+	result = system.RunWithLogs(m.ctx(), "engram setup opencode", nil, func(line string) {
 		SendLog(stepID, line)
 	})
 	if result.Error != nil {
@@ -1681,9 +1350,9 @@ func setupEngramService(m *Model) bool {
 
 	switch runtime.GOOS {
 	case "linux":
-		return setupEngramSystemd(homeDir, stepID)
+		return setupEngramSystemd(m.ctx(), homeDir, stepID)
 	case "darwin":
-		return setupEngramLaunchd(homeDir, stepID)
+		return setupEngramLaunchd(m.ctx(), homeDir, stepID)
 	default:
 		SendLog(stepID, "⚠️ Auto-start service not supported on this OS")
 		return false
@@ -1691,12 +1360,12 @@ func setupEngramService(m *Model) bool {
 }
 
 // setupEngramSystemd creates systemd user service for Linux
-func setupEngramSystemd(homeDir, stepID string) bool {
+func setupEngramSystemd(ctx context.Context, homeDir, stepID string) bool {
 	configDir := filepath.Join(homeDir, ".config/systemd/user")
 	serviceFile := filepath.Join(configDir, "engram.service")
 
 	// Ensure directory exists
-	if err := os.MkdirAll(configDir, 0755); err != nil {
+	if err := system.EnsureDir(configDir); err != nil {
 		SendLog(stepID, fmt.Sprintf("⚠️ Could not create systemd directory: %v", err))
 		return false
 	}
@@ -1729,26 +1398,26 @@ WantedBy=default.target
 
 	content := fmt.Sprintf(serviceContent, homeDir, homeDir, homeDir)
 
-	if err := os.WriteFile(serviceFile, []byte(content), 0644); err != nil {
+	if err := system.WriteFile(serviceFile, []byte(content), 0644); err != nil {
 		SendLog(stepID, fmt.Sprintf("⚠️ Could not create systemd service: %v", err))
 		return false
 	}
 
 	// Enable and start service
-	result := system.Run("systemctl --user daemon-reload", nil)
+	result := system.Run(ctx, "systemctl --user daemon-reload", nil)
 	if result.Error != nil {
 		SendLog(stepID, fmt.Sprintf("⚠️ Could not reload systemd: %v", result.Error))
 		return false
 	}
 
-	result = system.Run("systemctl --user enable engram.service", nil)
+	result = system.Run(ctx, "systemctl --user enable engram.service", nil)
 	if result.Error != nil {
 		SendLog(stepID, fmt.Sprintf("⚠️ Could not enable engram service: %v", result.Error))
 		return false
 	}
 
 	// Try to start, but don't fail if it doesn't (might need logout/login)
-	result = system.Run("systemctl --user start engram.service", nil)
+	result = system.Run(ctx, "systemctl --user start engram.service", nil)
 	if result.Error != nil {
 		SendLog(stepID, "Note: Engram service enabled but not started (will start on next login)")
 	} else {
@@ -1759,12 +1428,12 @@ WantedBy=default.target
 }
 
 // setupEngramLaunchd creates launchd plist for macOS
-func setupEngramLaunchd(homeDir, stepID string) bool {
+func setupEngramLaunchd(ctx context.Context, homeDir, stepID string) bool {
 	launchAgentsDir := filepath.Join(homeDir, "Library/LaunchAgents")
 	plistFile := filepath.Join(launchAgentsDir, "com.gentleman.engram.plist")
 
 	// Ensure directory exists
-	if err := os.MkdirAll(launchAgentsDir, 0755); err != nil {
+	if err := system.EnsureDir(launchAgentsDir); err != nil {
 		SendLog(stepID, fmt.Sprintf("⚠️ Could not create LaunchAgents directory: %v", err))
 		return false
 	}
@@ -1813,20 +1482,20 @@ func setupEngramLaunchd(homeDir, stepID string) bool {
 
 	content := fmt.Sprintf(plistContent, engramPath, homeDir, homeDir, homeDir, homeDir)
 
-	if err := os.WriteFile(plistFile, []byte(content), 0644); err != nil {
+	if err := system.WriteFile(plistFile, []byte(content), 0644); err != nil {
 		SendLog(stepID, fmt.Sprintf("⚠️ Could not create launchd plist: %v", err))
 		return false
 	}
 
 	// Load the plist
-	result := system.Run(fmt.Sprintf("launchctl load %s", plistFile), nil)
+	result := system.Run(ctx, fmt.Sprintf("launchctl load %s", plistFile), nil)
 	if result.Error != nil {
 		SendLog(stepID, fmt.Sprintf("⚠️ Could not load launchd service: %v", result.Error))
 		return false
 	}
 
 	// Try to start
-	result = system.Run("launchctl start com.gentleman.engram", nil)
+	result = system.Run(ctx, "launchctl start com.gentleman.engram", nil)
 	if result.Error != nil {
 		SendLog(stepID, "Note: Engram service loaded but not started (will start on next login)")
 	} else {
@@ -1843,10 +1512,10 @@ func installAgentTeamsLite(m *Model) error {
 	stepID := "aiframework"
 
 	// Cleanup any leftover
-	system.Run("rm -rf "+clonePath, nil)
+	system.Run(m.ctx(), "rm -rf "+clonePath, nil)
 
 	SendLog(stepID, "Cloning agent-teams-lite...")
-	result := system.RunWithLogs(
+	result := system.RunWithLogs(m.ctx(),
 		"git clone --depth 1 "+repoURL+" "+clonePath,
 		nil, func(line string) { SendLog(stepID, line) },
 	)
@@ -1855,7 +1524,7 @@ func installAgentTeamsLite(m *Model) error {
 	}
 
 	// Make install script executable
-	system.Run("chmod +x "+clonePath+"/scripts/install.sh", nil)
+	system.Run(m.ctx(), "chmod +x "+clonePath+"/scripts/install.sh", nil)
 
 	// Map our AI tool IDs to agent-teams-lite agent names
 	agentMap := map[string]string{
@@ -1874,7 +1543,7 @@ func installAgentTeamsLite(m *Model) error {
 		}
 		SendLog(stepID, fmt.Sprintf("Installing Agent Teams Lite for %s...", agentName))
 		installCmd := fmt.Sprintf("%s/scripts/install.sh --agent %s", clonePath, agentName)
-		result = system.RunWithLogs(installCmd, nil, func(line string) {
+		result = system.RunWithLogs(m.ctx(), installCmd, nil, func(line string) {
 			SendLog(stepID, line)
 		})
 		if result.Error != nil {
@@ -1885,7 +1554,7 @@ func installAgentTeamsLite(m *Model) error {
 	}
 
 	// Cleanup
-	system.Run("rm -rf "+clonePath, nil)
+	system.Run(m.ctx(), "rm -rf "+clonePath, nil)
 
 	if installed == 0 {
 		return fmt.Errorf("no AI tools could be configured with Agent Teams Lite")
@@ -1893,11 +1562,32 @@ func installAgentTeamsLite(m *Model) error {
 	return nil
 }
 
+func stepApplyTheme(m *Model) error {
+	stepID := "theme"
+	SendLog(stepID, "Applying the "+m.Choices.Theme+" theme...")
+	styled, err := system.ApplyTheme(m.Choices.Theme)
+	if err != nil {
+		return wrapStepError(stepID, "Apply Theme",
+			"Failed to write the theme colors into the configs",
+			err)
+	}
+	if len(styled) == 0 {
+		SendLog(stepID, "No installed config to restyle")
+		return nil
+	}
+	SendLog(stepID, "🎨 Restyled "+strings.Join(styled, ", "))
+	return nil
+}
+
 func stepCleanup(m *Model) error {
 	stepID := "cleanup"
+	if m.Choices.LinkConfigs {
+		SendLog(stepID, "Keeping "+m.RepoDir+", the linked configs point into it")
+		return nil
+	}
 	SendLog(stepID, "Removing temporary files...")
 	// Only remove the cloned repo - no sudo needed
-	result := system.Run("rm -rf "+m.RepoDir, nil)
+	result := system.Run(m.ctx(), "rm -rf "+m.RepoDir, nil)
 	if result.Error != nil {
 		// Non-critical error, just log it
 		SendLog(stepID, "Warning: Could not remove temporary directory")
@@ -1915,66 +1605,50 @@ func stepSetDefaultShell(m *Model) error {
 	shell := m.Choices.Shell
 	homeDir := os.Getenv("HOME")
 
-	var shellCmd string
-	switch shell {
-	case "fish":
-		shellCmd = "fish"
-	case "zsh":
-		shellCmd = "zsh"
-	case "nushell":
-		shellCmd = "nu"
-	default:
+	shellCmd := shellCommand(shell)
+	if shellCmd == "" {
 		SendLog(stepID, fmt.Sprintf("Unknown shell: %s, skipping", shell))
 		return nil
 	}
 
 	// Termux: no chsh available, modify .bashrc to auto-start shell
 	if m.SystemInfo.IsTermux {
-		SendLog(stepID, "Configuring shell auto-start for Termux...")
-
-		// Find the shell path
-		shellPath := system.Run(fmt.Sprintf("which %s", shellCmd), nil)
-		if shellPath.Error != nil || strings.TrimSpace(shellPath.Output) == "" {
-			SendLog(stepID, fmt.Sprintf("Shell '%s' not found in PATH, skipping", shellCmd))
+		bashrcPath := filepath.Join(homeDir, ".bashrc")
+		if shellCmd == "bash" {
+			// Bash is already the Termux shell, an auto-start would only nest it
+			if _, err := system.RemoveRcBlock(bashrcPath, system.RcBlockAutoStart); err != nil {
+				return wrapStepError("setshell", "Set Default Shell",
+					"Failed to remove the shell auto-start from ~/.bashrc",
+					err)
+			}
+			SendLog(stepID, "✓ Bash is already the Termux shell")
 			return nil
 		}
-		shellPathStr := strings.TrimSpace(shellPath.Output)
 
-		// Read existing .bashrc
-		bashrcPath := filepath.Join(homeDir, ".bashrc")
-		existingContent := ""
-		if data, err := os.ReadFile(bashrcPath); err == nil {
-			existingContent = string(data)
-		}
+		SendLog(stepID, "Configuring shell auto-start for Termux...")
 
-		// Check if already configured
-		if strings.Contains(existingContent, "# Gentleman.Dots shell auto-start") {
-			SendLog(stepID, "Shell auto-start already configured in ~/.bashrc")
+		// Find the shell path
+		shellPathStr, ok := lookupShellPath(m.ctx(), shellCmd)
+		if !ok {
+			SendLog(stepID, fmt.Sprintf("Shell '%s' not found in PATH, skipping", shellCmd))
 			return nil
 		}
 
-		// Append auto-start configuration
-		autoStartConfig := fmt.Sprintf(`
-# Gentleman.Dots shell auto-start
-if [ -x "%s" ] && [ -z "$GENTLEMANDOTS_SHELL_STARTED" ]; then
+		autoStartConfig := fmt.Sprintf(`if [ -x "%s" ] && [ -z "$GENTLEMANDOTS_SHELL_STARTED" ]; then
     export GENTLEMANDOTS_SHELL_STARTED=1
     exec %s
-fi
-`, shellPathStr, shellPathStr)
+fi`, shellPathStr, shellPathStr)
 
-		f, err := os.OpenFile(bashrcPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		changed, err := system.SetRcBlock(bashrcPath, system.RcBlockAutoStart, autoStartConfig)
 		if err != nil {
-			return wrapStepError("setshell", "Set Default Shell",
-				"Failed to open ~/.bashrc for writing",
-				err)
-		}
-		defer f.Close()
-
-		if _, err := f.WriteString(autoStartConfig); err != nil {
 			return wrapStepError("setshell", "Set Default Shell",
 				"Failed to write shell auto-start to ~/.bashrc",
 				err)
 		}
+		if !changed {
+			SendLog(stepID, "Shell auto-start already configured in ~/.bashrc")
+			return nil
+		}
 
 		SendLog(stepID, fmt.Sprintf("✓ Configured %s to auto-start in ~/.bashrc", shell))
 		SendLog(stepID, "Close and reopen Termux for changes to take effect")
@@ -1983,12 +1657,11 @@ fi
 
 	// Non-Termux: Try to set shell using sudo usermod (works if NOPASSWD configured)
 	// Find the shell path first
-	shellPath := system.Run(fmt.Sprintf("which %s", shellCmd), nil)
-	if shellPath.Error != nil || strings.TrimSpace(shellPath.Output) == "" {
+	shellPathStr, ok := lookupShellPath(m.ctx(), shellCmd)
+	if !ok {
 		SendLog(stepID, fmt.Sprintf("Shell '%s' not found in PATH, skipping", shellCmd))
 		return nil
 	}
-	shellPathStr := strings.TrimSpace(shellPath.Output)
 
 	// Get current username
 	currentUser := os.Getenv("USER")
@@ -1997,7 +1670,7 @@ fi
 	}
 	if currentUser == "" {
 		// Fallback to whoami command (useful in Docker containers)
-		whoamiResult := system.Run("whoami", nil)
+		whoamiResult := system.Run(m.ctx(), "whoami", nil)
 		if whoamiResult.Error == nil {
 			currentUser = strings.TrimSpace(whoamiResult.Output)
 		}
@@ -2009,22 +1682,21 @@ fi
 
 	// First, ensure shell is in /etc/shells
 	SendLog(stepID, fmt.Sprintf("Adding %s to /etc/shells if needed...", shellPathStr))
-	checkShells := system.Run(fmt.Sprintf("grep -q '^%s$' /etc/shells", shellPathStr), nil)
+	checkShells := system.Run(m.ctx(), fmt.Sprintf("grep -q '^%s$' /etc/shells", shellPathStr), nil)
 	if checkShells.Error != nil {
 		// Shell not in /etc/shells, try to add it
-		addResult := system.RunSudo(fmt.Sprintf("sh -c 'echo \"%s\" >> /etc/shells'", shellPathStr), nil)
-		if addResult.Error != nil {
+		if _, err := system.SetRootRcBlock(m.ctx(), "/etc/shells", system.RcBlockShell(shellCmd), shellPathStr); err != nil {
 			SendLog(stepID, fmt.Sprintf("Could not add %s to /etc/shells (may need manual setup)", shellPathStr))
 		}
 	}
 
 	// Try sudo usermod first (more reliable than chsh in scripts)
 	SendLog(stepID, fmt.Sprintf("Setting %s as default shell for %s...", shell, currentUser))
-	result := system.RunSudo(fmt.Sprintf("usermod -s %s %s", shellPathStr, currentUser), nil)
+	result := system.RunSudo(m.ctx(), fmt.Sprintf("usermod -s %s %s", shellPathStr, currentUser), nil)
 	if result.Error != nil {
 		// usermod failed, try chsh as fallback
 		SendLog(stepID, "usermod failed, trying chsh...")
-		result = system.RunSudo(fmt.Sprintf("chsh -s %s %s", shellPathStr, currentUser), nil)
+		result = system.RunSudo(m.ctx(), fmt.Sprintf("chsh -s %s %s", shellPathStr, currentUser), nil)
 		if result.Error != nil {
 			// Both failed - not critical, just inform user
 			SendLog(stepID, fmt.Sprintf("Could not set default shell automatically"))
@@ -2038,6 +1710,19 @@ fi
 	return nil
 }
 
+// lookupShellPath resolves the absolute path of a shell binary.
+// In dry-run mode the shell may not be installed yet, so the bare name is used.
+func lookupShellPath(ctx context.Context, shellCmd string) (string, bool) {
+	if system.IsDryRun() {
+		return shellCmd, true
+	}
+	result := system.Run(ctx, fmt.Sprintf("which %s", shellCmd), nil)
+	if result.Error != nil || strings.TrimSpace(result.Output) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(result.Output), true
+}
+
 // runProjectInitScript clones project-starter-framework and runs init-project.sh.
 // If memory is "obsidian-brain" and rolePacks is non-empty, it also copies
 // role pack templates into the project vault after the script finishes.
@@ -2267,53 +1952,44 @@ func copyRolePackTemplates(repoDir, projectPath string, rolePacks []string) erro
 }
 
 // ensureLocalBinInPATH ensures ~/.local/bin is in the user's PATH
-// by adding it to the appropriate shell rc file
+// by adding it to the appropriate shell rc file. An rc file that already
+// puts it on PATH (like the Javi.Dots configs) does not need the block.
 func ensureLocalBinInPATH(homeDir, shell string) error {
 	localBinPath := filepath.Join(homeDir, ".local/bin")
 
 	// Determine which rc file to use based on shell
-	var rcFile string
+	var rcFile, pathLine string
 	switch shell {
 	case "zsh":
 		rcFile = filepath.Join(homeDir, ".zshrc")
+		pathLine = fmt.Sprintf("export PATH=\"%s:$PATH\"", localBinPath)
 	case "fish":
 		rcFile = filepath.Join(homeDir, ".config/fish/config.fish")
+		pathLine = "fish_add_path " + localBinPath
 	case "nu", "nushell":
 		// Nushell uses env.nu or config.nu
 		rcFile = filepath.Join(homeDir, ".config/nushell/env.nu")
+		pathLine = fmt.Sprintf("$env.PATH = ($env.PATH | prepend \"%s\")", localBinPath)
 	default:
 		rcFile = filepath.Join(homeDir, ".bashrc")
+		pathLine = fmt.Sprintf("export PATH=\"%s:$PATH\"", localBinPath)
 	}
 
-	// Check if already in PATH
-	if _, err := os.Stat(rcFile); err == nil {
-		content, err := os.ReadFile(rcFile)
-		if err == nil && strings.Contains(string(content), ".local/bin") {
-			return nil // Already configured
-		}
-	}
-
-	// Add to PATH based on shell
-	var exportLine string
-	switch shell {
-	case "fish":
-		exportLine = fmt.Sprintf("\n# Added by Javi.Dots installer\nfish_add_path %s\n", localBinPath)
-	case "nu", "nushell":
-		exportLine = fmt.Sprintf("\n# Added by Javi.Dots installer\n$env.PATH = ($env.PATH | prepend \"%s\")\n", localBinPath)
-	default:
-		exportLine = fmt.Sprintf("\n# Added by Javi.Dots installer\nexport PATH=\"%s:$PATH\"\n", localBinPath)
+	var err error
+	if rcSetsLocalBin(rcFile) {
+		_, err = system.RemoveRcBlock(rcFile, system.RcBlockLocalBin)
+	} else {
+		_, err = system.SetRcBlock(rcFile, system.RcBlockLocalBin, pathLine)
 	}
-
-	// Append to rc file
-	f, err := os.OpenFile(rcFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
-		return fmt.Errorf("failed to open %s: %w", rcFile, err)
-	}
-	defer f.Close()
-
-	if _, err := f.WriteString(exportLine); err != nil {
 		return fmt.Errorf("failed to write to %s: %w", rcFile, err)
 	}
-
 	return nil
 }
+
+// rcSetsLocalBin reports whether an rc file puts ~/.local/bin on PATH
+// outside the installer's own block
+func rcSetsLocalBin(rcFile string) bool {
+	content, err := system.RcFileWithout(rcFile, system.RcBlockLocalBin)
+	return err == nil && strings.Contains(content, ".local/bin")
+}
@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
 )
 
 func TestCopyRolePackTemplates(t *testing.T) {
@@ -199,3 +201,125 @@ func TestCopyRolePackTemplates(t *testing.T) {
 		}
 	})
 }
+
+func TestDryRunShellStepLeavesHomeUntouched(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "1")
+	system.TakePlan()
+
+	m := &Model{
+		SystemInfo: &system.SystemInfo{OS: system.OSLinux},
+		Choices:    UserChoices{Shell: "fish", WindowMgr: "zellij"},
+		RepoDir:    filepath.Join(home, "Javi.Dots"),
+	}
+
+	if err := executeStep("shell", m); err != nil {
+		t.Fatalf("dry-run shell step failed: %v", err)
+	}
+
+	entries, _ := os.ReadDir(home)
+	if len(entries) != 0 {
+		t.Errorf("dry run created %d entries in HOME", len(entries))
+	}
+
+	plan := takeStepPlan("shell")
+	kinds := map[system.PlanKind]bool{}
+	for _, action := range plan {
+		if action.Step != "shell" {
+			t.Errorf("action %v not tagged with its step", action)
+		}
+		kinds[action.Kind] = true
+	}
+	for _, want := range []system.PlanKind{system.PlanPackage, system.PlanCopy, system.PlanPatch} {
+		if !kinds[want] {
+			t.Errorf("expected a %s action in plan: %v", want, plan)
+		}
+	}
+}
+
+func TestDryRunDepsUseDistroPackageManager(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GENTLEMAN_DRY_RUN", "1")
+
+	tests := []struct {
+		os   system.OSType
+		want string
+	}{
+		{system.OSAlpine, system.Privileged("apk add build-base curl file git wget unzip fontconfig")},
+		{system.OSOpenSUSE, system.Privileged("zypper --non-interactive install gcc gcc-c++ make curl file git wget unzip fontconfig")},
+		{system.OSVoid, system.Privileged("xbps-install -y base-devel curl file git wget unzip fontconfig")},
+	}
+
+	for _, tt := range tests {
+		system.TakePlan()
+		m := &Model{SystemInfo: &system.SystemInfo{OS: tt.os}, Choices: UserChoices{OS: "linux"}}
+		if err := executeStep("deps", m); err != nil {
+			t.Fatalf("dry-run deps step failed: %v", err)
+		}
+		plan := takeStepPlan("deps")
+		if len(plan) != 2 || plan[1].Target != tt.want {
+			t.Errorf("OS %v: expected an update and %q, got %v", tt.os, tt.want, plan)
+		}
+	}
+}
+
+func TestEnsureLocalBinInPATH(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+
+	t.Run("adds one managed block", func(t *testing.T) {
+		writeHomeFile(t, home, ".bashrc", "alias ll='ls -l'\n\n# Added by Javi.Dots installer\nexport PATH=\"/old/bin:$PATH\"\n")
+		for range 2 {
+			if err := ensureLocalBinInPATH(home, "bash"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		want := "alias ll='ls -l'\n\n# >>> gentleman:local-bin >>>\nexport PATH=\"" + filepath.Join(home, ".local/bin") + ":$PATH\"\n# <<< gentleman:local-bin <<<\n"
+		if got, _ := os.ReadFile(filepath.Join(home, ".bashrc")); string(got) != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("rc files that set it already are left alone", func(t *testing.T) {
+		writeHomeFile(t, home, ".config/fish/config.fish", "set -x PATH $HOME/.local/bin $PATH\n")
+		if err := ensureLocalBinInPATH(home, "fish"); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := os.ReadFile(filepath.Join(home, ".config/fish/config.fish")); string(got) != "set -x PATH $HOME/.local/bin $PATH\n" {
+			t.Errorf("config.fish should be unchanged, got %q", got)
+		}
+	})
+}
+
+func TestTermuxBashSetShell(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+
+	m := NewModel()
+	m.SystemInfo.IsTermux = true
+	m.Choices.Shell = "bash"
+	bashrc := "alias ll='ls -l'\n" + system.FormatRcBlock(".bashrc", system.RcBlockAutoStart, "exec zsh")
+	writeHomeFile(t, home, ".bashrc", bashrc)
+
+	if setShellInteractive(&m) {
+		t.Fatal("setshell should not need the terminal for bash on Termux")
+	}
+
+	// Building the script must not touch ~/.bashrc
+	if script, err := getSetShellScript(&m); script != "" || err != nil {
+		t.Fatalf("got script %q, err %v", script, err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(home, ".bashrc")); string(got) != bashrc {
+		t.Fatalf("getSetShellScript changed ~/.bashrc: %q", got)
+	}
+
+	if err := stepSetDefaultShell(&m); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(home, ".bashrc")); string(got) != "alias ll='ls -l'\n" {
+		t.Errorf("the auto-start should be removed, got %q", got)
+	}
+}
@@ -386,9 +386,9 @@ func TestRestoreFlow(t *testing.T) {
 
 		options := m.GetCurrentOptions()
 
-		// Should have 2 backups + separator + back = 4 options
-		if len(options) != 4 {
-			t.Errorf("Expected 4 options, got %d: %v", len(options), options)
+		// Should have 2 backups + separator + import + back = 5 options
+		if len(options) != 5 {
+			t.Errorf("Expected 5 options, got %d: %v", len(options), options)
 		}
 	})
 }
@@ -63,6 +63,7 @@ func getHomebrewScript(m *Model) (string, error) {
 	}
 
 	brewPrefix := system.GetBrewPrefix()
+	brewBlock := system.FormatRcBlock(".bashrc", system.RcBlockBrew, fmt.Sprintf(`eval "$(%s/bin/brew shellenv)"`, brewPrefix))
 	script := fmt.Sprintf(`#!/bin/sh
 set -e
 echo ""
@@ -74,14 +75,14 @@ echo ""
 echo ""
 echo "📝 Configuring shell to use Homebrew..."
 
-# Add to shell configs
-BREW_CONFIG='eval "$(%s/bin/brew shellenv)"'
+# Add to shell configs, in the installer's managed block, unless they
+# already load brew shellenv (in the block or not)
+BREW_BLOCK='%s'
 
 for RC_FILE in "$HOME/.bashrc" "$HOME/.zshrc"; do
     if [ -f "$RC_FILE" ]; then
         if ! grep -q "brew shellenv" "$RC_FILE" 2>/dev/null; then
-            echo "" >> "$RC_FILE"
-            echo "$BREW_CONFIG" >> "$RC_FILE"
+            printf '\n%%s' "$BREW_BLOCK" >> "$RC_FILE"
         fi
     fi
 done
@@ -94,7 +95,7 @@ echo "✅ Homebrew installed successfully!"
 echo ""
 echo "Press Enter to continue..."
 read dummy
-`, brewPrefix, brewPrefix)
+`, brewBlock, brewPrefix)
 
 	return script, nil
 }
@@ -137,6 +138,66 @@ echo "✅ Dependencies installed successfully!"
 echo ""
 echo "Press Enter to continue..."
 read dummy
+`
+	} else if m.SystemInfo.OS == system.OSAlpine {
+		script = `#!/bin/sh
+set -e
+# Containers run as root, often without sudo
+SUDO=sudo
+[ "$(id -u)" -eq 0 ] && SUDO=
+echo ""
+echo "🔄 Updating Alpine package index..."
+echo "   (You may be prompted for your password)"
+echo ""
+$SUDO apk update
+echo ""
+echo "📦 Installing base dependencies..."
+$SUDO apk add build-base curl file git wget unzip fontconfig
+echo ""
+echo "✅ Dependencies installed successfully!"
+echo ""
+echo "Press Enter to continue..."
+read dummy
+`
+	} else if m.SystemInfo.OS == system.OSOpenSUSE {
+		script = `#!/bin/sh
+set -e
+# Containers run as root, often without sudo
+SUDO=sudo
+[ "$(id -u)" -eq 0 ] && SUDO=
+echo ""
+echo "🔄 Refreshing openSUSE repositories..."
+echo "   (You may be prompted for your password)"
+echo ""
+$SUDO zypper --non-interactive refresh
+echo ""
+echo "📦 Installing base dependencies..."
+$SUDO zypper --non-interactive install gcc gcc-c++ make curl file git wget unzip fontconfig
+echo ""
+echo "✅ Dependencies installed successfully!"
+echo ""
+echo "Press Enter to continue..."
+read dummy
+`
+	} else if m.SystemInfo.OS == system.OSVoid {
+		script = `#!/bin/sh
+set -e
+# Containers run as root, often without sudo
+SUDO=sudo
+[ "$(id -u)" -eq 0 ] && SUDO=
+echo ""
+echo "🔄 Syncing Void Linux repositories..."
+echo "   (You may be prompted for your password)"
+echo ""
+$SUDO xbps-install -S
+echo ""
+echo "📦 Installing base dependencies..."
+$SUDO xbps-install -y base-devel curl file git wget unzip fontconfig
+echo ""
+echo "✅ Dependencies installed successfully!"
+echo ""
+echo "Press Enter to continue..."
+read dummy
 `
 	} else {
 		// Debian/Ubuntu
@@ -161,6 +222,20 @@ read dummy
 	return script, nil
 }
 
+// nativeTerminalInstall returns the command installing a terminal on Alpine,
+// openSUSE and Void, or "" on other systems
+func nativeTerminalInstall(info *system.SystemInfo, terminal string) string {
+	switch info.OS {
+	case system.OSAlpine:
+		return system.Privileged("apk add " + terminal)
+	case system.OSOpenSUSE:
+		return system.Privileged("zypper --non-interactive install " + terminal)
+	case system.OSVoid:
+		return system.Privileged("xbps-install -Sy " + terminal)
+	}
+	return ""
+}
+
 // getTerminalScript returns script to install terminal on Linux (needs sudo)
 func getTerminalScript(m *Model) (string, error) {
 	terminal := m.Choices.Terminal
@@ -177,6 +252,8 @@ func getTerminalScript(m *Model) (string, error) {
 			installCmd = `sudo pacman -S --noconfirm alacritty`
 		} else if m.SystemInfo.OS == system.OSFedora {
 			installCmd = `sudo dnf install -y alacritty`
+		} else if cmd := nativeTerminalInstall(m.SystemInfo, "alacritty"); cmd != "" {
+			installCmd = cmd
 		} else {
 			// Debian/Ubuntu: compile from source (PPAs are unreliable)
 			installCmd = `echo "📦 Installing build dependencies..."
@@ -221,6 +298,8 @@ cp "Gentleman.Dots/alacritty.toml" "%s/.config/alacritty/alacritty.toml"`, homeD
 		} else if m.SystemInfo.OS == system.OSFedora {
 			installCmd = `sudo dnf copr enable -y wezfurlong/wezterm-nightly
 sudo dnf install -y wezterm`
+		} else if cmd := nativeTerminalInstall(m.SystemInfo, "wezterm"); cmd != "" {
+			installCmd = cmd
 		} else {
 			// Debian uses brew, not interactive
 			return "", nil
@@ -236,6 +315,8 @@ cp "Gentleman.Dots/.wezterm.lua" "%s/.config/wezterm/wezterm.lua"`, homeDir, hom
 		} else if m.SystemInfo.OS == system.OSFedora {
 			installCmd = `sudo dnf copr enable -y pgdev/ghostty
 sudo dnf install -y ghostty`
+		} else if cmd := nativeTerminalInstall(m.SystemInfo, "ghostty"); cmd != "" {
+			installCmd = cmd
 		} else {
 			// Debian uses install script
 			installCmd = `curl -fsSL https://raw.githubusercontent.com/mkasberg/ghostty-ubuntu/HEAD/install.sh | bash`
@@ -267,28 +348,32 @@ read dummy
 	return script, nil
 }
 
+// setShellInteractive reports whether setting the default shell needs the
+// terminal. Bash on Termux only drops the shell auto-start from ~/.bashrc.
+func setShellInteractive(m *Model) bool {
+	return !m.SystemInfo.IsTermux || shellCommand(m.Choices.Shell) != "bash"
+}
+
 // getSetShellScript returns script to set the default shell (needs chsh password)
 func getSetShellScript(m *Model) (string, error) {
 	shell := m.Choices.Shell
-	var shellCmd string
-
-	switch shell {
-	case "fish":
-		shellCmd = "fish"
-	case "zsh":
-		shellCmd = "zsh"
-	case "nushell":
-		shellCmd = "nu"
-	default:
+	shellCmd := shellCommand(shell)
+	if shellCmd == "" {
 		return "", fmt.Errorf("unknown shell: %s", shell)
 	}
 
 	// Termux: no chsh, we modify ~/.bashrc to start the shell
 	if m.SystemInfo.IsTermux {
+		if shellCmd == "bash" {
+			// Bash is already the Termux shell, the step only drops any
+			// auto-start, see stepSetDefaultShell
+			return "", nil
+		}
 		return getSetShellScriptTermux(shellCmd)
 	}
 
 	brewPrefix := system.GetBrewPrefix()
+	start, stop := system.RcBlockMarkers("/etc/shells", system.RcBlockShell(shellCmd))
 
 	script := fmt.Sprintf(`#!/bin/sh
 set -e
@@ -313,7 +398,7 @@ echo ""
 # Check if shell is already in /etc/shells
 if ! grep -q "^$SHELL_PATH$" /etc/shells 2>/dev/null; then
     echo "📝 Adding $SHELL_PATH to /etc/shells (requires sudo)..."
-    echo "$SHELL_PATH" | sudo tee -a /etc/shells > /dev/null
+    printf '\n%%s\n%%s\n%%s\n' '%s' "$SHELL_PATH" '%s' | sudo tee -a /etc/shells > /dev/null
 fi
 
 # Change shell
@@ -329,7 +414,7 @@ echo "   Please log out and log back in for changes to take effect."
 echo ""
 echo "Press Enter to continue..."
 read dummy
-`, brewPrefix, shellCmd, shellCmd)
+`, brewPrefix, shellCmd, shellCmd, start, stop)
 
 	return script, nil
 }
@@ -337,6 +422,7 @@ read dummy
 // getSetShellScriptTermux returns script to set default shell in Termux
 // Termux doesn't have chsh, so we add shell launch to ~/.bashrc
 func getSetShellScriptTermux(shellCmd string) (string, error) {
+	start, stop := system.RcBlockMarkers(".bashrc", system.RcBlockAutoStart)
 	script := fmt.Sprintf(`#!/data/data/com.termux/files/usr/bin/sh
 set -e
 
@@ -357,18 +443,21 @@ echo ""
 # Termux doesn't have chsh, so we add to ~/.bashrc
 BASHRC="$HOME/.bashrc"
 
-# Check if already configured
-if grep -q "# Gentleman.Dots shell auto-start" "$BASHRC" 2>/dev/null; then
-    echo "Shell auto-start already configured in ~/.bashrc"
+# Replace the installer's auto-start block (and the unmarked one older
+# versions wrote), keeping the rest of ~/.bashrc
+if grep -qF '%s' "$BASHRC" 2>/dev/null; then
+    sed -i '/^%s$/,/^%s$/d' "$BASHRC"
 else
     echo "" >> "$BASHRC"
-    echo "# Gentleman.Dots shell auto-start" >> "$BASHRC"
-    echo "if [ -x \"$SHELL_PATH\" ] && [ -z \"\$GENTLEMANDOTS_SHELL_STARTED\" ]; then" >> "$BASHRC"
-    echo "    export GENTLEMANDOTS_SHELL_STARTED=1" >> "$BASHRC"
-    echo "    exec $SHELL_PATH" >> "$BASHRC"
-    echo "fi" >> "$BASHRC"
-    echo "✅ Added shell auto-start to ~/.bashrc"
 fi
+sed -i '/^# Gentleman.Dots shell auto-start$/,/^fi$/d' "$BASHRC" 2>/dev/null || true
+echo '%s' >> "$BASHRC"
+echo "if [ -x \"$SHELL_PATH\" ] && [ -z \"\$GENTLEMANDOTS_SHELL_STARTED\" ]; then" >> "$BASHRC"
+echo "    export GENTLEMANDOTS_SHELL_STARTED=1" >> "$BASHRC"
+echo "    exec $SHELL_PATH" >> "$BASHRC"
+echo "fi" >> "$BASHRC"
+echo '%s' >> "$BASHRC"
+echo "✅ Added shell auto-start to ~/.bashrc"
 
 echo ""
 echo "✅ Default shell set to $SHELL_PATH"
@@ -376,7 +465,7 @@ echo "   Close and reopen Termux for changes to take effect."
 echo ""
 echo "Press Enter to continue..."
 read dummy
-`, shellCmd, shellCmd)
+`, shellCmd, shellCmd, start, start, stop, start, stop)
 
 	return script, nil
 }
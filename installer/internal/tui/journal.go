@@ -0,0 +1,223 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
+)
+
+// JournalVersion is the current version of the step journal format
+const JournalVersion = 1
+
+var stepStatusNames = map[StepStatus]string{
+	StatusPending: "pending",
+	StatusRunning: "running",
+	StatusDone:    "done",
+	StatusFailed:  "failed",
+	StatusSkipped: "skipped",
+}
+
+func (s StepStatus) String() string {
+	if name, ok := stepStatusNames[s]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// JournalStep is the persisted state of a single install step
+type JournalStep struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Journal persists the progress of an installation so that a failed run
+// can be resumed without repeating the steps that already finished
+type Journal struct {
+	Version   int             `json:"version"`
+	StartedAt time.Time       `json:"started_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	Choices   UserChoices     `json:"choices"`
+	RepoDir   string          `json:"repo_dir"` // Absolute, so resuming works from any directory
+	RepoURL   string          `json:"repo_url"`
+	BackupDir string          `json:"backup_dir,omitempty"`
+	Steps     []JournalStep   `json:"steps"`
+	Changes   []system.Change `json:"changes,omitempty"` // Paths written so far, for rollback
+}
+
+// JournalPath returns the location of the step journal
+func JournalPath() string {
+	return filepath.Join(system.StateDir(), "journal.json")
+}
+
+// NewJournal creates a journal for the model's current steps and choices
+func NewJournal(m *Model) *Journal {
+	repoDir := m.RepoDir
+	if abs, err := filepath.Abs(repoDir); err == nil {
+		repoDir = abs
+	}
+
+	j := &Journal{
+		Version:   JournalVersion,
+		StartedAt: time.Now(),
+		Choices:   m.Choices,
+		RepoDir:   repoDir,
+		RepoURL:   m.RepoURL,
+		BackupDir: m.BackupDir,
+	}
+	for _, step := range m.Steps {
+		j.Steps = append(j.Steps, JournalStep{ID: step.ID, Name: step.Name, Status: step.Status.String()})
+	}
+	return j
+}
+
+// LoadJournal reads the journal left by a previous run.
+// It returns nil without error when there is nothing to resume.
+func LoadJournal() (*Journal, error) {
+	data, err := os.ReadFile(JournalPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	var j Journal
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, fmt.Errorf("failed to parse journal %s: %w", JournalPath(), err)
+	}
+	if j.Version != JournalVersion {
+		return nil, fmt.Errorf("journal %s has unsupported version %d", JournalPath(), j.Version)
+	}
+	return &j, nil
+}
+
+// Save writes the journal atomically, and adds the files written so far to
+// the install manifest. Dry runs never persist anything.
+func (j *Journal) Save() error {
+	if system.IsDryRun() {
+		return nil
+	}
+	if err := system.SaveManifest(j.RepoDir, j.RepoURL); err != nil {
+		return err
+	}
+
+	j.UpdatedAt = time.Now()
+	j.Changes = system.Changes()
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode journal: %w", err)
+	}
+
+	path := JournalPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Record updates a step's status and saves the journal
+func (j *Journal) Record(stepID string, status StepStatus, stepErr error) error {
+	for i := range j.Steps {
+		if j.Steps[i].ID == stepID {
+			j.Steps[i].Status = status.String()
+			j.Steps[i].Error = ""
+			if stepErr != nil {
+				j.Steps[i].Error = stepErr.Error()
+			}
+			break
+		}
+	}
+	return j.Save()
+}
+
+// Remove deletes the journal file once an installation has finished
+func (j *Journal) Remove() error {
+	if system.IsDryRun() {
+		return nil
+	}
+	if err := os.Remove(JournalPath()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// IsDone reports whether a step finished in the journaled run
+func (j *Journal) IsDone(stepID string) bool {
+	for _, step := range j.Steps {
+		if step.ID == stepID {
+			return step.Status == StatusDone.String() || step.Status == StatusSkipped.String()
+		}
+	}
+	return false
+}
+
+// ResumeStep returns the first step that still has to run, or nil when the
+// journaled installation already finished
+func (j *Journal) ResumeStep() *JournalStep {
+	for i := range j.Steps {
+		if !j.IsDone(j.Steps[i].ID) {
+			return &j.Steps[i]
+		}
+	}
+	return nil
+}
+
+// ApplyTo restricts freshly built steps to the ones in the journal and marks
+// the already finished ones as done. If the cloned repository is gone, the
+// clone step runs again since later steps copy files from it.
+func (j *Journal) ApplyTo(steps []InstallStep) []InstallStep {
+	repoMissing := false
+	if _, err := os.Stat(j.RepoDir); err != nil {
+		repoMissing = true
+	}
+
+	var resumed []InstallStep
+	for _, step := range steps {
+		known := false
+		for _, js := range j.Steps {
+			if js.ID == step.ID {
+				known = true
+				break
+			}
+		}
+		if !known {
+			continue
+		}
+		if j.IsDone(step.ID) && !(step.ID == "clone" && repoMissing) {
+			step.Status = StatusDone
+			step.Progress = 1.0
+		}
+		resumed = append(resumed, step)
+	}
+	return resumed
+}
+
+// ResumeFromJournal restores the choices of a journaled run and prepares the
+// remaining steps so the installation continues from the failed one
+func (m *Model) ResumeFromJournal(j *Journal) {
+	m.Choices = j.Choices
+	m.RepoDir = j.RepoDir
+	m.RepoURL = j.RepoURL
+	m.BackupDir = j.BackupDir
+	if m.Choices.CreateBackup {
+		m.ExistingConfigs = system.DetectExistingConfigs()
+	}
+
+	m.SetupInstallSteps()
+	m.Steps = j.ApplyTo(m.Steps)
+	system.ResetChanges(j.Changes)
+	m.CurrentStep = 0
+	m.Journal = j
+	m.PendingJournal = nil
+	m.Screen = ScreenInstalling
+}
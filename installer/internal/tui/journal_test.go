@@ -0,0 +1,196 @@
+package tui
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func journalModel(t *testing.T) Model {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+
+	m := NewModel()
+	m.RepoDir = t.TempDir() // Pretend the repo is already cloned
+	m.Choices = UserChoices{OS: "linux", Shell: "fish", Terminal: "none", WindowMgr: "tmux", InstallNvim: true}
+	m.SetupInstallSteps()
+	return m
+}
+
+func TestJournal(t *testing.T) {
+	t.Run("no journal means nothing to resume", func(t *testing.T) {
+		t.Setenv("HOME", t.TempDir())
+		j, err := LoadJournal()
+		if err != nil || j != nil {
+			t.Fatalf("expected nil journal, got %v, %v", j, err)
+		}
+	})
+
+	t.Run("records survive a reload", func(t *testing.T) {
+		m := journalModel(t)
+		j := NewJournal(&m)
+		if err := j.Record("clone", StatusDone, nil); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+		j.Record("shell", StatusFailed, errors.New("brew download timed out"))
+
+		loaded, err := LoadJournal()
+		if err != nil || loaded == nil {
+			t.Fatalf("LoadJournal failed: %v", err)
+		}
+		if loaded.Choices.Shell != "fish" || !loaded.Choices.InstallNvim {
+			t.Errorf("choices not persisted: %+v", loaded.Choices)
+		}
+		if !loaded.IsDone("clone") || loaded.IsDone("shell") {
+			t.Error("step statuses not persisted")
+		}
+		next := loaded.ResumeStep()
+		if next == nil || next.ID == "clone" {
+			t.Fatalf("resume step should be the first unfinished one, got %+v", next)
+		}
+		for _, step := range loaded.Steps {
+			if step.ID == "shell" && step.Error != "brew download timed out" {
+				t.Errorf("error not persisted: %q", step.Error)
+			}
+		}
+	})
+
+	t.Run("remove deletes the file", func(t *testing.T) {
+		m := journalModel(t)
+		j := NewJournal(&m)
+		j.Save()
+		if err := j.Remove(); err != nil {
+			t.Fatalf("Remove failed: %v", err)
+		}
+		if _, err := os.Stat(JournalPath()); !os.IsNotExist(err) {
+			t.Error("journal file should be gone")
+		}
+	})
+
+	t.Run("dry run never writes a journal", func(t *testing.T) {
+		m := journalModel(t)
+		t.Setenv("GENTLEMAN_DRY_RUN", "1")
+		NewJournal(&m).Save()
+		if _, err := os.Stat(JournalPath()); !os.IsNotExist(err) {
+			t.Error("dry run should not persist a journal")
+		}
+	})
+
+	t.Run("apply keeps journaled steps and marks done ones", func(t *testing.T) {
+		m := journalModel(t)
+		j := NewJournal(&m)
+		j.Record("clone", StatusDone, nil)
+
+		extra := append(m.Steps, InstallStep{ID: "backup", Name: "Backup"})
+		steps := j.ApplyTo(extra)
+		if len(steps) != len(m.Steps) {
+			t.Errorf("steps not in the journal should be dropped, got %d want %d", len(steps), len(m.Steps))
+		}
+		for _, step := range steps {
+			if step.ID == "clone" && step.Status != StatusDone {
+				t.Error("clone should be marked done")
+			}
+			if step.ID != "clone" && step.Status == StatusDone {
+				t.Errorf("%s should still be pending", step.ID)
+			}
+		}
+	})
+
+	t.Run("clone runs again when the repo is gone", func(t *testing.T) {
+		m := journalModel(t)
+		j := NewJournal(&m)
+		j.Record("clone", StatusDone, nil)
+		os.RemoveAll(j.RepoDir)
+
+		for _, step := range j.ApplyTo(m.Steps) {
+			if step.ID == "clone" && step.Status == StatusDone {
+				t.Error("clone should run again when the repo directory is missing")
+			}
+		}
+	})
+}
+
+func TestResumeFromJournal(t *testing.T) {
+	m := journalModel(t)
+	j := NewJournal(&m)
+	j.Record("clone", StatusDone, nil)
+	j.Record("deps", StatusFailed, errors.New("network"))
+
+	fresh := NewModel()
+	fresh.PendingJournal = j
+	fresh.Screen = ScreenResumeInstall
+
+	result, cmd := fresh.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	resumed := result.(Model)
+
+	if resumed.Screen != ScreenInstalling {
+		t.Fatalf("expected ScreenInstalling, got %v", resumed.Screen)
+	}
+	if cmd == nil {
+		t.Fatal("resuming should start the installation")
+	}
+	if resumed.Choices.Shell != "fish" || resumed.RepoDir != j.RepoDir {
+		t.Errorf("choices not restored: %+v", resumed.Choices)
+	}
+	if resumed.Journal != j || resumed.PendingJournal != nil {
+		t.Error("journal should become the active one")
+	}
+
+	t.Run("finished steps are skipped without running", func(t *testing.T) {
+		next := resumed
+		if cmd := next.scheduleSteps(); cmd == nil {
+			t.Fatal("scheduling should start the remaining steps")
+		}
+		for _, step := range next.Steps {
+			if step.ID == "clone" && step.Status != StatusDone {
+				t.Errorf("clone should not run again, status %v", step.Status)
+			}
+		}
+		if next.Steps[next.CurrentStep].ID == "clone" || runningSteps(next.Steps) == 0 {
+			t.Errorf("expected the steps after clone to start, current is %s", next.Steps[next.CurrentStep].ID)
+		}
+	})
+
+	t.Run("failure is journaled", func(t *testing.T) {
+		result, _ := resumed.Update(stepCompleteMsg{stepID: "deps", err: errors.New("still offline")})
+		failed := result.(Model)
+		if failed.Screen != ScreenError {
+			t.Errorf("expected ScreenError, got %v", failed.Screen)
+		}
+		loaded, _ := LoadJournal()
+		if loaded == nil || loaded.IsDone("deps") {
+			t.Error("failed step should remain resumable")
+		}
+	})
+
+	t.Run("completion removes the journal", func(t *testing.T) {
+		result, _ := resumed.Update(installCompleteMsg{})
+		if result.(Model).Journal != nil {
+			t.Error("journal should be cleared after completion")
+		}
+		if _, err := os.Stat(JournalPath()); !os.IsNotExist(err) {
+			t.Error("journal file should be removed after completion")
+		}
+	})
+}
+
+func TestResumePromptDiscard(t *testing.T) {
+	m := journalModel(t)
+	j := NewJournal(&m)
+	j.Save()
+
+	m.PendingJournal = j
+	m.Screen = ScreenResumeInstall
+	m.Cursor = 1 // Discard
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if result.(Model).Screen != ScreenWelcome {
+		t.Errorf("expected ScreenWelcome, got %v", result.(Model).Screen)
+	}
+	if _, err := os.Stat(JournalPath()); !os.IsNotExist(err) {
+		t.Error("discarding should delete the journal")
+	}
+}
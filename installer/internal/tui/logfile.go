@@ -0,0 +1,193 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
+)
+
+// maxLogEntries bounds the log kept in memory for the viewer; the session
+// log file always has everything
+const maxLogEntries = 5000
+
+// LogEntry is a single line of installer output
+type LogEntry struct {
+	Time   time.Time
+	StepID string
+	Line   string
+}
+
+// String formats the entry the way it is written to the session log
+func (e LogEntry) String() string {
+	stepID := e.StepID
+	if stepID == "" {
+		stepID = "-"
+	}
+	return fmt.Sprintf("%s [%s] %s", e.Time.Format("2006-01-02 15:04:05"), stepID, e.Line)
+}
+
+var (
+	sessionLogMu   sync.Mutex
+	sessionLogFile *os.File
+)
+
+// LogsDir returns the directory that holds the per-session install logs
+func LogsDir() string {
+	return filepath.Join(system.StateDir(), "logs")
+}
+
+// StartSessionLog opens a new log file that every SendLog line is written
+// to, and returns its path. Dry runs change nothing, so they log nothing.
+func StartSessionLog() (string, error) {
+	if system.IsDryRun() {
+		return "", nil
+	}
+	if err := os.MkdirAll(LogsDir(), 0755); err != nil {
+		return "", fmt.Errorf("failed to create logs directory: %w", err)
+	}
+	path := filepath.Join(LogsDir(), "install-"+time.Now().Format("2006-01-02-150405")+".log")
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to open session log: %w", err)
+	}
+
+	sessionLogMu.Lock()
+	defer sessionLogMu.Unlock()
+	if sessionLogFile != nil {
+		sessionLogFile.Close()
+	}
+	sessionLogFile = f
+	return path, nil
+}
+
+// SessionLogPath returns the path of the open session log, or "" when none
+func SessionLogPath() string {
+	sessionLogMu.Lock()
+	defer sessionLogMu.Unlock()
+	if sessionLogFile == nil {
+		return ""
+	}
+	return sessionLogFile.Name()
+}
+
+// CloseSessionLog closes the session log file
+func CloseSessionLog() {
+	sessionLogMu.Lock()
+	defer sessionLogMu.Unlock()
+	if sessionLogFile != nil {
+		sessionLogFile.Close()
+		sessionLogFile = nil
+	}
+}
+
+// writeSessionLog appends a line to the session log, if one is open
+func writeSessionLog(entry LogEntry) {
+	sessionLogMu.Lock()
+	defer sessionLogMu.Unlock()
+	if sessionLogFile == nil {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimRight(entry.Line, "\n"), "\n") {
+		entry.Line = line
+		fmt.Fprintln(sessionLogFile, entry.String())
+	}
+}
+
+// logStepEvent records an installer event for a step in the session log
+// and the log viewer
+func (m *Model) logStepEvent(stepID, line string) {
+	entry := LogEntry{Time: time.Now(), StepID: stepID, Line: line}
+	writeSessionLog(entry)
+	m.appendLogEntry(entry)
+}
+
+// appendLogEntry keeps a line for the log viewer
+func (m *Model) appendLogEntry(entry LogEntry) {
+	m.LogEntries = append(m.LogEntries, entry)
+	if len(m.LogEntries) > maxLogEntries {
+		m.LogEntries = m.LogEntries[len(m.LogEntries)-maxLogEntries:]
+	}
+}
+
+// visibleLogEntries returns the entries that match the viewer's step filter
+// and search query
+func (m Model) visibleLogEntries() []LogEntry {
+	query := strings.ToLower(m.LogSearch)
+	var entries []LogEntry
+	for _, entry := range m.LogEntries {
+		if m.LogFilter != "" && entry.StepID != m.LogFilter {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(entry.Line), query) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// logStepIDs returns the step IDs that produced output, in order of appearance
+func (m Model) logStepIDs() []string {
+	var ids []string
+	for _, entry := range m.LogEntries {
+		if entry.StepID != "" && !slices.Contains(ids, entry.StepID) {
+			ids = append(ids, entry.StepID)
+		}
+	}
+	return ids
+}
+
+// nextLogFilter cycles the step filter: all steps, then each step in turn
+func (m Model) nextLogFilter() string {
+	ids := m.logStepIDs()
+	if m.LogFilter == "" {
+		if len(ids) == 0 {
+			return ""
+		}
+		return ids[0]
+	}
+	i := slices.Index(ids, m.LogFilter)
+	if i < 0 || i == len(ids)-1 {
+		return ""
+	}
+	return ids[i+1]
+}
+
+// logViewHeight is the number of log lines the viewer shows at once
+func (m Model) logViewHeight() int {
+	return max(m.Height-8, 5)
+}
+
+// logViewStart returns the first visible line for n filtered lines
+func (m Model) logViewStart(n int) int {
+	last := max(n-m.logViewHeight(), 0)
+	if m.LogFollow {
+		return last
+	}
+	return min(max(m.LogScroll, 0), last)
+}
+
+// showInstallScreen switches to an installation result screen. If the log
+// viewer is open it stays open and closing it leads to the new screen.
+func (m *Model) showInstallScreen(screen Screen) {
+	if m.Screen == ScreenLogViewer {
+		m.LogPrevScreen = screen
+		return
+	}
+	m.Screen = screen
+}
+
+// openLogViewer shows the full log, following new output
+func (m Model) openLogViewer() Model {
+	m.LogPrevScreen = m.Screen
+	m.Screen = ScreenLogViewer
+	m.LogFollow = true
+	m.LogSearching = false
+	return m
+}
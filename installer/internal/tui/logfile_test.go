@@ -0,0 +1,184 @@
+package tui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSessionLog(t *testing.T) {
+	t.Run("lines are written with timestamp and step", func(t *testing.T) {
+		t.Setenv("HOME", t.TempDir())
+		t.Setenv("GENTLEMAN_DRY_RUN", "")
+		defer CloseSessionLog()
+
+		path, err := StartSessionLog()
+		if err != nil || path == "" {
+			t.Fatalf("StartSessionLog failed: %q, %v", path, err)
+		}
+		if filepath.Dir(path) != LogsDir() || SessionLogPath() != path {
+			t.Errorf("unexpected session log path %q", path)
+		}
+
+		SendLog("deps", "==> Installing fish\n==> Pouring fish.bottle")
+		data, _ := os.ReadFile(path)
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("expected one log line per output line, got %q", data)
+		}
+		if !strings.HasSuffix(lines[0], " [deps] ==> Installing fish") {
+			t.Errorf("line should carry the step ID: %q", lines[0])
+		}
+		if _, err := time.Parse("2006-01-02 15:04:05", lines[0][:19]); err != nil {
+			t.Errorf("line should start with a timestamp: %q", lines[0])
+		}
+	})
+
+	t.Run("dry run writes no log", func(t *testing.T) {
+		t.Setenv("HOME", t.TempDir())
+		t.Setenv("GENTLEMAN_DRY_RUN", "1")
+		if path, err := StartSessionLog(); path != "" || err != nil {
+			t.Errorf("dry run should not open a log, got %q, %v", path, err)
+		}
+		if _, err := os.Stat(LogsDir()); !os.IsNotExist(err) {
+			t.Error("dry run should not create the logs directory")
+		}
+	})
+}
+
+func logModel() Model {
+	m := NewModel()
+	m.Screen = ScreenInstalling
+	m.Height = 13 // Five visible log lines
+	for i := 0; i < 20; i++ {
+		stepID := "deps"
+		if i%2 == 1 {
+			stepID = "shell"
+		}
+		m.appendLogEntry(LogEntry{Time: time.Now(), StepID: stepID, Line: "line " + string(rune('a'+i))})
+	}
+	return m
+}
+
+func press(m Model, keys ...string) Model {
+	for _, key := range keys {
+		var msg tea.KeyMsg
+		switch key {
+		case " ":
+			msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		}
+		result, _ := m.Update(msg)
+		m = result.(Model)
+	}
+	return m
+}
+
+func TestLogViewer(t *testing.T) {
+	t.Run("space l opens it during installation and esc returns", func(t *testing.T) {
+		m := press(logModel(), " ", "l")
+		if m.Screen != ScreenLogViewer || m.LogPrevScreen != ScreenInstalling {
+			t.Fatalf("expected log viewer over installing, got %v", m.Screen)
+		}
+		if view := m.View(); !strings.Contains(view, "line t") || strings.Contains(view, "line a") {
+			t.Error("viewer should follow the newest lines")
+		}
+		if m = press(m, "esc"); m.Screen != ScreenInstalling {
+			t.Errorf("esc should return to installing, got %v", m.Screen)
+		}
+	})
+
+	t.Run("scrolling", func(t *testing.T) {
+		m := press(logModel().openLogViewer(), "g")
+		if !strings.Contains(m.View(), "line a") {
+			t.Error("g should jump to the top")
+		}
+		m = press(m, "j", "j")
+		if m.logViewStart(len(m.visibleLogEntries())) != 2 {
+			t.Errorf("j should scroll down, start is %d", m.logViewStart(20))
+		}
+		m = press(m, "G")
+		if !m.LogFollow {
+			t.Error("G should follow the end again")
+		}
+	})
+
+	t.Run("filter cycles through steps", func(t *testing.T) {
+		m := press(logModel().openLogViewer(), "f")
+		if m.LogFilter != "deps" {
+			t.Fatalf("first filter should be the first step, got %q", m.LogFilter)
+		}
+		for _, e := range m.visibleLogEntries() {
+			if e.StepID != "deps" {
+				t.Fatalf("filter let %q through", e.StepID)
+			}
+		}
+		if m = press(m, "f", "f"); m.LogFilter != "" {
+			t.Errorf("filter should wrap back to all steps, got %q", m.LogFilter)
+		}
+	})
+
+	t.Run("slash searches", func(t *testing.T) {
+		m := press(logModel().openLogViewer(), "/", "l", "i", "n", "e", " ", "c", "enter")
+		if m.LogSearching || m.LogSearch != "line c" {
+			t.Fatalf("unexpected search state %q (typing %v)", m.LogSearch, m.LogSearching)
+		}
+		if got := m.visibleLogEntries(); len(got) != 1 || got[0].Line != "line c" {
+			t.Errorf("search should keep matching lines only, got %v", got)
+		}
+		if m.Screen != ScreenLogViewer {
+			t.Error("keys typed into the search must not act as commands")
+		}
+		m = press(m, "/", "esc")
+		if m.LogSearch != "" || m.Screen != ScreenLogViewer {
+			t.Error("esc while searching should clear the search, not close the viewer")
+		}
+	})
+
+	t.Run("install result keeps the viewer open", func(t *testing.T) {
+		m := logModel().openLogViewer()
+		result, _ := m.Update(installCompleteMsg{})
+		m = result.(Model)
+		if m.Screen != ScreenLogViewer || m.LogPrevScreen != ScreenComplete {
+			t.Errorf("completion should not close the viewer: screen %v, prev %v", m.Screen, m.LogPrevScreen)
+		}
+		if m = press(m, "esc"); m.Screen != ScreenComplete {
+			t.Errorf("closing the viewer should show the result, got %v", m.Screen)
+		}
+	})
+}
+
+func TestLogPathOnResultScreens(t *testing.T) {
+	m := failingModel(t)
+	m.LogPath = "/tmp/install.log"
+	m.CurrentStep = 2
+	m.Steps[2].Status = StatusRunning
+
+	result, _ := m.Update(stepCompleteMsg{stepID: "shell", err: errors.New("boom")})
+	failed := result.(Model)
+	if !strings.Contains(failed.View(), "Full log: /tmp/install.log") {
+		t.Error("error screen should show the log path")
+	}
+	if last := failed.LogEntries[len(failed.LogEntries)-1]; last.StepID != "shell" || !strings.Contains(last.Line, "boom") {
+		t.Errorf("failure should be logged for the step, got %+v", last)
+	}
+
+	if viewer := press(failed, "l"); viewer.Screen != ScreenLogViewer {
+		t.Error("l on the error screen should open the log")
+	}
+
+	m.Screen = ScreenComplete
+	if !strings.Contains(m.View(), "Full log: /tmp/install.log") {
+		t.Error("complete screen should show the log path")
+	}
+}
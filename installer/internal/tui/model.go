@@ -1,9 +1,11 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
 	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/tui/trainer"
@@ -56,6 +58,9 @@ const (
 	ScreenBackupConfirm
 	ScreenRestoreBackup
 	ScreenRestoreConfirm
+	ScreenRestoreDiff   // Scrollable diff of the selected entries
+	ScreenRestoreResult // Restored entries and undo
+	ScreenBackupImport  // Path of an exported backup to import
 	// AI Framework screens
 	ScreenAIToolsSelect            // Select which AI coding tools to install
 	ScreenAIFrameworkConfirm       // Confirm AI framework installation
@@ -89,6 +94,25 @@ const (
 	ScreenSkillRemove  // Multi-select from installed skills
 	ScreenSkillResult  // Success/error output
 	ScreenSkillUpdate  // Updating catalog (git pull)
+	// Resume screen
+	ScreenResumeInstall // Offer to continue an interrupted installation
+	// Rollback screen
+	ScreenRollback // Report of a rolled back installation
+	// Cancel screen
+	ScreenInstallCancelled // Quit and resume later, or roll back
+	// Log screen
+	ScreenLogViewer // Full-screen, scrollable installation log
+	// Doctor screen
+	ScreenDoctor // Health report of an existing setup
+	// Uninstall screens
+	ScreenUninstallSelect  // Multi-select: components to uninstall
+	ScreenUninstallConfirm // Summary before uninstalling
+	ScreenUninstallResult  // Progress and report
+	// Config update screens
+	ScreenConfigUpdate    // Update plan, progress and result
+	ScreenConfigConflicts // Per-hunk conflict resolution
+	// Theme screen
+	ScreenTheme // Pick the palette of every installed config
 )
 
 // Path input modes
@@ -106,7 +130,8 @@ type InstallStep struct {
 	Status      StepStatus
 	Progress    float64
 	Error       error
-	Interactive bool // If true, this step needs terminal control (sudo, chsh, etc)
+	Interactive bool     // If true, this step needs terminal control (sudo, chsh, etc)
+	DependsOn   []string // IDs of the steps that must finish before this one starts
 }
 
 type StepStatus int
@@ -122,19 +147,24 @@ const (
 // UserChoices stores all user selections
 type UserChoices struct {
 	OS           string // "mac", "linux"
-	Terminal     string // "alacritty", "wezterm", "kitty", "ghostty", "none"
+	Terminal     string // a system.ToolTerminal ID or "none"
 	InstallFont  bool
-	Shell        string // "fish", "zsh", "nushell"
-	WindowMgr    string // "tmux", "zellij", "none"
+	Font         string  // a system.Fonts ID, "" installs the platform default
+	FontSize     float64 // written into the configs with the font, 0 keeps the configured size
+	Shell        string  // a system.ToolShell ID
+	WindowMgr    string  // a system.ToolWM ID or "none"
 	InstallNvim  bool
 	InstallZed   bool
-	CreateBackup bool // Whether to backup existing configs
+	CreateBackup bool   // Whether to backup existing configs
+	LinkConfigs  bool   // Symlink configs into a permanent clone instead of copying (--link)
+	Theme        string // a system.Themes ID applied after installing, "" keeps the current colors
 	// AI Tools and Framework
 	AITools               []string // Selected AI tools: "claude", "opencode"
 	InstallAIFramework    bool     // Whether to install project-starter-framework
 	AIFrameworkPreset     string   // Preset: "minimal", "frontend", "backend", "fullstack", "data", "complete"
 	AIFrameworkModules    []string // Individual module names when preset is "custom"
 	InstallAgentTeamsLite bool     // Whether to install agent-teams-lite SDD framework
+	Skills                []string // Skill names to link (profiles and --skill-install)
 	// Project init
 	InitProject      bool
 	ProjectPath      string
@@ -156,6 +186,7 @@ type Model struct {
 	Choices     UserChoices
 	RepoDir     string // Directory name for the cloned repo (overridable for forks)
 	RepoURL     string // Git URL for the dots repo (overridable for forks)
+	LinkConfigs bool   // --link: symlink the configs, applied to the choices when installing
 	Steps       []InstallStep
 	CurrentStep int
 	Cursor      int
@@ -192,7 +223,66 @@ type Model struct {
 	ExistingConfigs  []string            // Configs that will be overwritten
 	AvailableBackups []system.BackupInfo // Available backups for restore
 	SelectedBackup   int                 // Selected backup index
-	BackupDir        string              // Last backup directory created
+	RestoreSelected  []bool              // Toggle state for each entry of the selected backup
+	RestoreDiff      []string            // Preview of the selected entries, one line each
+	RestoreScroll    int
+	RestoreSnapshot  string // Configs replaced by the last restore, put back by undo
+	RestoreUndone    bool
+	BackupMsg        string // Outcome of the last export or import
+	ImportPath       string // Typed path of the archive to import
+	BackupDir        string // Last backup directory created
+	// Dry-run plan collected from every executed step
+	DryRunPlan []system.PlanAction
+	// Result of the last "save as profile" action
+	ProfileMsg string
+	// Step journal of the running installation, and one left by a previous run
+	Journal        *Journal
+	PendingJournal *Journal
+	// Full installation log for the viewer, and the session log file
+	LogEntries    []LogEntry
+	LogPath       string
+	LogPrevScreen Screen // Screen to return to when the viewer closes
+	LogScroll     int    // First visible line of the filtered log
+	LogFollow     bool   // Keep the newest lines in view
+	LogFilter     string // Only show lines of this step ID ("" = all)
+	LogSearch     string // Only show lines containing this text
+	LogSearching  bool   // Typing a search query
+	// Result of rolling back a failed installation
+	Rollback    *system.RollbackReport
+	RollbackErr error
+	// Cancelling the installation with ctrl+c. The steps run their commands
+	// under InstallCtx, which CancelInstall cancels.
+	InstallCtx    context.Context
+	CancelInstall context.CancelFunc
+	CancelConfirm bool // Asking whether to cancel
+	Cancelling    bool // Waiting for the running steps to stop
+	RollbackNext  bool // Roll back once the running steps stopped
+	// Doctor report
+	DoctorChecks  []DoctorCheck
+	DoctorRunning bool
+	DoctorScroll  int
+	// Uninstall
+	UninstallSelected []bool // Toggle state for each UninstallComponents entry
+	UninstallPackages bool   // Also remove the packages of the components
+	UninstallRunning  bool
+	UninstallReport   *UninstallReport
+	UninstallErr      error
+	// Config update
+	ConfigUpdatePlan    *system.UpdatePlan
+	ConfigUpdateRunning bool
+	ConfigUpdateApplied bool
+	ConfigUpdateBackup  string
+	ConfigUpdateErr     error
+	ConflictFile        int // Index into the conflicting files of the plan
+	ConflictHunk        int // Index into the conflicts of that file
+	// Font picker
+	FontsInstalled map[string]bool // Nerd Fonts the system already has, by ID
+
+	// Theme switcher
+	ThemeCurrent string // ID of the applied theme
+	ThemeRunning bool
+	ThemeStyled  []string // Tools the last switch restyled
+	ThemeErr     error
 	// Vim Trainer mode
 	TrainerStats       *trainer.UserStats   // User's training stats
 	TrainerGameState   *trainer.GameState   // Current game session state
@@ -259,13 +349,13 @@ func NewModel() Model {
 		KeymapCategories:        GetNvimKeymaps(),
 		SelectedCategory:        0,
 		KeymapScroll:            0,
-		TmuxKeymapCategories:    GetTmuxKeymaps(),
+		TmuxKeymapCategories:    toolKeymaps("tmux"),
 		TmuxSelectedCategory:    0,
 		TmuxKeymapScroll:        0,
-		ZellijKeymapCategories:  GetZellijKeymaps(),
+		ZellijKeymapCategories:  toolKeymaps("zellij"),
 		ZellijSelectedCategory:  0,
 		ZellijKeymapScroll:      0,
-		GhosttyKeymapCategories: GetGhosttyKeymaps(),
+		GhosttyKeymapCategories: toolKeymaps("ghostty"),
 		GhosttySelectedCategory: 0,
 		GhosttyKeymapScroll:     0,
 		LazyVimTopics:           GetLazyVimTopics(),
@@ -336,10 +426,12 @@ func SetNonInteractiveMode(enabled bool) {
 
 // SendLog sends a log message to the TUI during installation
 func SendLog(stepID string, log string) {
+	writeSessionLog(LogEntry{Time: time.Now(), StepID: stepID, Line: log})
 	if nonInteractiveMode {
+		emitEvent(Event{Type: EventLog, Step: stepID, Line: log})
 		// In non-interactive mode, print to stdout if verbose
 		if os.Getenv("GENTLEMAN_VERBOSE") == "1" {
-			fmt.Printf("    %s\n", log)
+			textf("    %s\n", log)
 		}
 		return
 	}
@@ -364,12 +456,18 @@ func (m Model) GetCurrentOptions() []string {
 			"🚀 Start Installation",
 			"📚 Learn & Practice",
 		}
-		// Add restore option if backups exist
+		// Add restore option if backups exist, else one to bring them in
 		if len(m.AvailableBackups) > 0 {
 			opts = append(opts, "🔄 Restore from Backup")
+		} else {
+			opts = append(opts, importBackupOption)
 		}
 		opts = append(opts, "📦 Initialize Project")
 		opts = append(opts, "🎯 Skill Manager")
+		opts = append(opts, "⬆️  Update Configs")
+		opts = append(opts, "🎨 Theme")
+		opts = append(opts, "🩺 Doctor")
+		opts = append(opts, "🗑️  Uninstall")
 		opts = append(opts, "❌ Exit")
 		return opts
 	case ScreenLearnMenu:
@@ -381,8 +479,14 @@ func (m Model) GetCurrentOptions() []string {
 			"─────────────",
 			"← Back",
 		}
+	case ScreenTheme:
+		return m.themeOptions()
 	case ScreenKeymapsMenu:
-		return []string{"Neovim", "Tmux", "Zellij", "Ghostty", "─────────────", "← Back"}
+		opts := []string{"Neovim"}
+		for _, tool := range keymapTools() {
+			opts = append(opts, tool.Name)
+		}
+		return append(opts, "─────────────", "← Back")
 	case ScreenOSSelect:
 		macLabel := "macOS"
 		linuxLabel := "Linux"
@@ -391,27 +495,18 @@ func (m Model) GetCurrentOptions() []string {
 			macLabel = "macOS (detected)"
 		} else if m.SystemInfo.OS == system.OSTermux {
 			termuxLabel = "Termux (detected)"
-		} else if m.SystemInfo.OS == system.OSLinux || m.SystemInfo.OS == system.OSArch || m.SystemInfo.OS == system.OSDebian || m.SystemInfo.OS == system.OSFedora {
+		} else if m.SystemInfo.OS != system.OSUnknown {
 			linuxLabel = "Linux (detected)"
 		}
 		return []string{macLabel, linuxLabel, termuxLabel}
 	case ScreenTerminalSelect:
-		alacrittyLabel := "Alacritty"
-		// On Debian/Ubuntu, Alacritty needs to be built from source (PPAs are unreliable)
-		// This applies to ALL Debian-based systems, not just ARM
-		if m.SystemInfo != nil && (m.SystemInfo.OS == system.OSDebian || m.SystemInfo.OS == system.OSLinux) && m.Choices.OS == "linux" {
-			alacrittyLabel = "Alacritty ⏱️  (builds from source, installs Rust ~5-10 min)"
-		}
-		if m.Choices.OS == "mac" {
-			return []string{alacrittyLabel, "WezTerm", "Kitty", "Ghostty", "None", "─────────────", "ℹ️  Learn about terminals"}
-		}
-		return []string{alacrittyLabel, "WezTerm", "Ghostty", "None", "─────────────", "ℹ️  Learn about terminals"}
+		return append(m.toolOptions(system.ToolTerminal), "None", "─────────────", "ℹ️  Learn about terminals")
 	case ScreenFontSelect:
-		return []string{"Yes, install Iosevka Term Nerd Font", "No, I already have it"}
+		return m.fontOptions()
 	case ScreenShellSelect:
-		return []string{"Fish", "Zsh", "Nushell", "─────────────", "ℹ️  Learn about shells"}
+		return append(m.toolOptions(system.ToolShell), "─────────────", "ℹ️  Learn about shells")
 	case ScreenWMSelect:
-		return []string{"Tmux", "Zellij", "None", "─────────────", "ℹ️  Learn about multiplexers"}
+		return append(m.toolOptions(system.ToolWM), "None", "─────────────", "ℹ️  Learn about multiplexers")
 	case ScreenNvimSelect:
 		return []string{"Yes, install Neovim with config", "No, skip Neovim", "─────────────", "ℹ️  Learn about Neovim", "⌨️  View Keymaps", "📖 LazyVim Guide"}
 	case ScreenZedSelect:
@@ -468,19 +563,39 @@ func (m Model) GetCurrentOptions() []string {
 			"❌ Cancel",
 		}
 	case ScreenRestoreBackup:
-		opts := make([]string, len(m.AvailableBackups)+2)
+		opts := make([]string, len(m.AvailableBackups)+3)
 		for i, backup := range m.AvailableBackups {
 			// Format: timestamp + file count
 			opts[i] = fmt.Sprintf("%s (%d items)", backup.Timestamp.Format("2006-01-02 15:04:05"), len(backup.Files))
 		}
 		opts[len(m.AvailableBackups)] = "─────────────"
-		opts[len(m.AvailableBackups)+1] = "← Back"
+		opts[len(m.AvailableBackups)+1] = importBackupOption
+		opts[len(m.AvailableBackups)+2] = "← Back"
 		return opts
 	case ScreenRestoreConfirm:
+		return m.restoreConfirmOptions()
+	case ScreenRestoreResult:
+		if m.RestoreUndone || m.RestoreSnapshot == "" {
+			return []string{"← Back to main menu"}
+		}
+		return []string{"↩️  Undo restore", "← Back to main menu"}
+	case ScreenInstallCancelled:
+		return []string{quitCancelledOption, rollbackCancelledOption}
+	case ScreenError:
+		if i := m.failedStepIndex(); i >= 0 {
+			return []string{
+				fmt.Sprintf("🔁 Retry '%s'", m.Steps[i].Name),
+				"⏭️  Skip this step and continue",
+				"↩️  Abort and roll back changes",
+				"🛑 Abort and keep changes",
+			}
+		}
+		return nil
+	case ScreenResumeInstall:
 		return []string{
-			"✅ Yes, restore this backup",
-			"🗑️  Delete this backup",
-			"❌ Cancel",
+			"▶️  Resume installation",
+			"🔄 Discard and start over",
+			"⏭️  Not now",
 		}
 	case ScreenGhosttyWarning:
 		return []string{
@@ -489,11 +604,11 @@ func (m Model) GetCurrentOptions() []string {
 			"❌ Cancel installation",
 		}
 	case ScreenLearnTerminals:
-		return []string{"Alacritty", "WezTerm", "Kitty", "Ghostty", "─────────────", "← Back"}
+		return append(toolNames(system.ToolTerminal), "─────────────", "← Back")
 	case ScreenLearnShells:
-		return []string{"Fish", "Zsh", "Nushell", "─────────────", "← Back"}
+		return append(toolNames(system.ToolShell), "─────────────", "← Back")
 	case ScreenLearnWM:
-		return []string{"Tmux", "Zellij", "─────────────", "← Back"}
+		return append(toolNames(system.ToolWM), "─────────────", "← Back")
 	case ScreenLearnNvim:
 		return []string{"View Features", "View Keymaps", "📖 LazyVim Guide", "─────────────", "← Back"}
 	case ScreenKeymaps:
@@ -557,6 +672,35 @@ func (m Model) GetCurrentOptions() []string {
 		return []string{coreLabel, devLabel, pmLabel, "─────────────", "✅ Confirm selection"}
 	case ScreenProjectCI:
 		return []string{"GitHub Actions", "GitLab CI", "Woodpecker", "None"}
+	case ScreenUninstallSelect:
+		opts := make([]string, 0, len(UninstallComponents)+3)
+		for i, c := range UninstallComponents {
+			label := "[ ] " + c.Name
+			if i < len(m.UninstallSelected) && m.UninstallSelected[i] {
+				label = "[x] " + c.Name
+			}
+			opts = append(opts, label)
+		}
+		packages := "[ ] 📦 Also remove packages"
+		if m.UninstallPackages {
+			packages = "[x] 📦 Also remove packages"
+		}
+		return append(opts, "─────────────", packages, "✅ Continue")
+	case ScreenUninstallConfirm:
+		return []string{"🗑️  Uninstall now", "← Back"}
+	case ScreenConfigUpdate:
+		plan := m.ConfigUpdatePlan
+		if m.ConfigUpdateRunning || m.ConfigUpdateApplied || plan == nil {
+			return []string{}
+		}
+		var opts []string
+		if plan.Count(system.UpdateConflict) > 0 {
+			opts = append(opts, "⚔️  Resolve conflicts")
+		}
+		if len(plan.Files) > 0 {
+			opts = append(opts, "✅ Apply update")
+		}
+		return append(opts, "← Back")
 	case ScreenProjectConfirm:
 		return []string{"✅ Confirm & Initialize", "❌ Cancel"}
 	// Skill Manager screens
@@ -616,6 +760,30 @@ func (m Model) GetScreenTitle() string {
 		return "🔄 Restore from Backup"
 	case ScreenRestoreConfirm:
 		return "🔄 Confirm Restore"
+	case ScreenRestoreDiff:
+		return "🔍 Restore Preview"
+	case ScreenRestoreResult:
+		return "🔄 Backup Restored"
+	case ScreenBackupImport:
+		return "📥 Import Backup"
+	case ScreenResumeInstall:
+		return "⏯️  Unfinished Installation Found"
+	case ScreenRollback:
+		return "↩️  Installation Rolled Back"
+	case ScreenInstallCancelled:
+		return "⏹️  Installation Cancelled"
+	case ScreenLogViewer:
+		return "📜 Installation Log"
+	case ScreenDoctor:
+		return "🩺 Doctor"
+	case ScreenUninstallSelect, ScreenUninstallConfirm, ScreenUninstallResult:
+		return "🗑️  Uninstall"
+	case ScreenConfigUpdate:
+		return "⬆️  Update Configs"
+	case ScreenConfigConflicts:
+		return "⚔️  Resolve Conflicts"
+	case ScreenTheme:
+		return "🎨 Theme"
 	case ScreenGhosttyWarning:
 		return "⚠️  Ghostty Compatibility Warning"
 	case ScreenInstalling:
@@ -737,7 +905,7 @@ func (m Model) GetScreenDescription() string {
 		}
 		return "Select your preferred terminal emulator"
 	case ScreenFontSelect:
-		return "Iosevka Term Nerd Font is required for icons and glyphs"
+		return "A Nerd Font is required for icons and glyphs"
 	case ScreenShellSelect:
 		return "Current shell: " + m.SystemInfo.UserShell
 	case ScreenWMSelect:
@@ -774,6 +942,10 @@ func (m Model) GetScreenDescription() string {
 		return "Add Engram persistent memory alongside Obsidian Brain?"
 	case ScreenProjectRolePack:
 		return "Select role packs for your Obsidian Brain vault"
+	case ScreenUninstallSelect:
+		return "Configs are restored from the latest backup, or removed when there is none"
+	case ScreenConfigUpdate:
+		return "Upstream changes are merged into your configs, keeping your own edits"
 	case ScreenProjectCI:
 		return "Select CI/CD provider for your project"
 	case ScreenProjectConfirm:
@@ -984,6 +1156,19 @@ func (m Model) getInstalledSkills() []SkillInfo {
 	return result
 }
 
+// applyLinkMode keeps the repository of a link install at its permanent
+// location and turns link mode on for the config copies
+func (m *Model) applyLinkMode() {
+	if !m.Choices.LinkConfigs {
+		system.SetLinkRepo("")
+		return
+	}
+	if m.RepoDir == DefaultRepoDir {
+		m.RepoDir = system.LinkRepoDir()
+	}
+	system.SetLinkRepo(m.RepoDir)
+}
+
 // SetupInstallSteps creates the installation steps based on user choices
 func (m *Model) SetupInstallSteps() {
 	m.Steps = []InstallStep{}
@@ -1061,7 +1246,7 @@ func (m *Model) SetupInstallSteps() {
 	if m.Choices.InstallFont {
 		m.Steps = append(m.Steps, InstallStep{
 			ID:          "font",
-			Name:        "Install Iosevka Nerd Font",
+			Name:        "Install " + m.installFont().Name + " Nerd Font",
 			Description: "Nerd font with icons",
 			Status:      StatusPending,
 		})
@@ -1130,13 +1315,23 @@ func (m *Model) SetupInstallSteps() {
 		})
 	}
 
+	// Theme (not interactive - rewrites the installed configs)
+	if m.Choices.Theme != "" {
+		m.Steps = append(m.Steps, InstallStep{
+			ID:          "theme",
+			Name:        "Apply Theme",
+			Description: m.Choices.Theme,
+			Status:      StatusPending,
+		})
+	}
+
 	// Set default shell (interactive - chsh needs password)
 	m.Steps = append(m.Steps, InstallStep{
 		ID:          "setshell",
 		Name:        "Set Default Shell",
 		Description: "Configure default shell",
 		Status:      StatusPending,
-		Interactive: true,
+		Interactive: setShellInteractive(m),
 	})
 
 	// Cleanup (not interactive - just file deletion)
@@ -1146,4 +1341,6 @@ func (m *Model) SetupInstallSteps() {
 		Description: "Removing temporary files",
 		Status:      StatusPending,
 	})
+
+	m.Steps = withDependencies(m.Steps)
 }
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"slices"
 	"strings"
 	"testing"
 
@@ -103,11 +104,11 @@ func TestGetCurrentOptions(t *testing.T) {
 		m.Screen = ScreenShellSelect
 		opts := m.GetCurrentOptions()
 
-		// Should have: Fish, Zsh, Nushell, separator, Learn
-		if len(opts) != 5 {
-			t.Errorf("Expected 5 shell options (including separator and learn), got %d", len(opts))
+		// Should have: Fish, Zsh, Nushell, Bash, separator, Learn
+		if len(opts) != 6 {
+			t.Errorf("Expected 6 shell options (including separator and learn), got %d", len(opts))
 		}
-		expected := []string{"Fish", "Zsh", "Nushell"}
+		expected := []string{"Fish", "Zsh", "Nushell", "Bash"}
 		for i, exp := range expected {
 			if opts[i] != exp {
 				t.Errorf("Expected %s at position %d, got %s", exp, i, opts[i])
@@ -411,9 +412,9 @@ func TestBackupScreenOptions(t *testing.T) {
 
 		opts := m.GetCurrentOptions()
 
-		// Should have: 2 backups + separator + Back = 4 options
-		if len(opts) != 4 {
-			t.Errorf("Expected 4 options for RestoreBackup with 2 backups, got %d", len(opts))
+		// Should have: 2 backups + separator + Import + Back = 5 options
+		if len(opts) != 5 {
+			t.Errorf("Expected 5 options for RestoreBackup with 2 backups, got %d", len(opts))
 		}
 
 		// Last option should be Back
@@ -428,8 +429,9 @@ func TestBackupScreenOptions(t *testing.T) {
 
 		opts := m.GetCurrentOptions()
 
-		if len(opts) != 3 {
-			t.Errorf("Expected 3 options for RestoreConfirm, got %d", len(opts))
+		want := []string{"─────────────", restorePreviewOption, restoreOption, restoreExportOption, restoreDeleteOption, restoreCancelOption}
+		if !slices.Equal(opts, want) {
+			t.Errorf("Expected only the actions without a backup, got %v", opts)
 		}
 	})
 }
@@ -3,6 +3,7 @@ package tui
 import (
 	"fmt"
 	"runtime"
+	"time"
 
 	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
 )
@@ -11,6 +12,27 @@ import (
 // repoDir overrides the default repo directory name used for cloning.
 // repoURL overrides the default git URL for the dots repository.
 func RunNonInteractive(choices UserChoices, repoDir string, repoURL string) error {
+	model := newNonInteractiveModel(choices, repoDir, repoURL)
+
+	// Define steps to run based on choices
+	steps := buildStepsForChoices(model)
+
+	return runStepsNonInteractive(model, steps, nil)
+}
+
+// ResumeNonInteractive continues a journaled installation without TUI,
+// skipping the steps that already finished
+func ResumeNonInteractive(j *Journal) error {
+	model := newNonInteractiveModel(j.Choices, j.RepoDir, j.RepoURL)
+	model.BackupDir = j.BackupDir
+
+	steps := j.ApplyTo(buildStepsForChoices(model))
+
+	return runStepsNonInteractive(model, steps, j)
+}
+
+// newNonInteractiveModel creates a minimal model for the installation functions
+func newNonInteractiveModel(choices UserChoices, repoDir string, repoURL string) *Model {
 	// Enable non-interactive mode for logging
 	SetNonInteractiveMode(true)
 
@@ -24,7 +46,6 @@ func RunNonInteractive(choices UserChoices, repoDir string, repoURL string) erro
 	}
 	choices.OS = osChoice
 
-	// Create a minimal model for the installation functions
 	model := &Model{
 		SystemInfo: sysInfo,
 		Choices:    choices,
@@ -32,37 +53,250 @@ func RunNonInteractive(choices UserChoices, repoDir string, repoURL string) erro
 		RepoURL:    repoURL,
 		LogLines:   []string{},
 	}
+	model.applyLinkMode()
 
 	// Detect existing configs for backup functionality
 	if choices.CreateBackup {
 		model.ExistingConfigs = system.DetectExistingConfigs()
 	}
+	return model
+}
 
-	// Define steps to run based on choices
-	steps := buildStepsForChoices(model)
+// runStepsNonInteractive executes steps once their dependencies finished,
+// up to the worker limit at a time, journaling each one.
+// A nil journal starts a new one. Progress is printed as text or emitted
+// as JSON events, see SetOutputFormat.
+func runStepsNonInteractive(model *Model, steps []InstallStep, journal *Journal) error {
+	dryRun := system.IsDryRun()
+	if dryRun {
+		textf("📋 Dry-run plan for %d installation steps (nothing will be changed)\n\n", len(steps))
+	} else if journal != nil {
+		textf("📋 Resuming %d installation steps...\n\n", len(steps))
+	} else {
+		textf("📋 Running %d installation steps...\n\n", len(steps))
+	}
 
-	fmt.Printf("📋 Running %d installation steps...\n\n", len(steps))
+	if model.LogPath == "" {
+		model.LogPath, _ = StartSessionLog()
+	}
+	if model.LogPath != "" {
+		textf("📝 Full log: %s\n\n", model.LogPath)
+	}
+
+	if journal == nil {
+		system.ResetChanges(nil)
+		model.Steps = steps
+		journal = NewJournal(model)
+	} else {
+		system.ResetChanges(journal.Changes)
+	}
+	_ = journal.Save()
+
+	plan := Event{Type: EventPlan, Total: len(steps), DryRun: dryRun}
+	for _, step := range steps {
+		plan.Steps = append(plan.Steps, EventStep{ID: step.ID, Name: step.Name, Status: step.Status.String()})
+	}
+	emitEvent(plan)
+
+	// Execute the steps, running independent ones at the same time
+	result := &EventResult{LogFile: model.LogPath}
+	summary := func(success bool) {
+		result.Success = success
+		result.BackupDir = model.BackupDir
+		emitEvent(Event{Type: EventSummary, DryRun: dryRun, Summary: result})
+	}
+	parallel := effectiveJobs() > 1
+	// label names the step on result lines, which interleave when parallel
+	label := func(i int) string {
+		if parallel {
+			return fmt.Sprintf(" (%s)", steps[i].Name)
+		}
+		return ""
+	}
+	stepEvent := func(eventType string, i int) Event {
+		return Event{Type: eventType, Step: steps[i].ID, Name: steps[i].Name, Index: i + 1, Total: len(steps)}
+	}
 
-	// Execute each step
 	for i, step := range steps {
-		fmt.Printf("[%d/%d] %s...\n", i+1, len(steps), step.Name)
+		if step.Status == StatusDone {
+			textf("[%d/%d] %s...\n", i+1, len(steps), step.Name)
+			textf("    ↷ Already done\n")
+			finished := stepEvent(EventStepFinished, i)
+			finished.Status = StatusDone.String()
+			finished.DurationMs = new(int64)
+			emitEvent(finished)
+			result.Done++
+		}
+	}
+
+	results := make(chan stepOutcome)
+	running := 0
+	var failed *stepOutcome
+	for {
+		if failed == nil {
+			for _, i := range nextSteps(steps, effectiveJobs()) {
+				steps[i].Status = StatusRunning
+				textf("[%d/%d] %s...\n", i+1, len(steps), steps[i].Name)
+				emitEvent(stepEvent(EventStepStarted, i))
+				_ = journal.Record(steps[i].ID, StatusRunning, nil)
+				model.logStepEvent(steps[i].ID, "▶ "+steps[i].Name)
 
-		err := executeStep(step.ID, model)
+				running++
+				snapshot := *model
+				go func(i int) {
+					results <- runStepNonInteractive(i, steps[i], &snapshot, label(i))
+				}(i)
+			}
+		}
+		if running == 0 {
+			break
+		}
+
+		out := <-results
+		running--
+		i, step, err := out.index, steps[out.index], out.err
+		finished := stepEvent(EventStepFinished, i)
+		for _, action := range out.plan {
+			result.Planned++
+			textf("    %3d. %s\n", result.Planned, action)
+			finished.Actions = append(finished.Actions, action.String())
+		}
+		if out.backupDir != "" {
+			model.BackupDir = out.backupDir
+			journal.BackupDir = out.backupDir
+		}
+		duration := out.duration.Milliseconds()
+		finished.DurationMs = &duration
+
+		if err != nil && (IsOptionalStep(step.ID) || errorPolicy.Action == ErrorSkip) {
+			steps[i].Status = StatusSkipped
+			_ = journal.Record(step.ID, StatusSkipped, err)
+			model.logStepEvent(step.ID, "⏭ skipped after failure: "+err.Error())
+			textf("    ⏭️  Skipped after failure%s: %s\n", label(i), firstLine(err.Error()))
+			emitEvent(Event{Type: EventError, Step: step.ID, Name: step.Name, Error: eventFailureFor(step, err, string(ErrorSkip))})
+			finished.Status = StatusSkipped.String()
+			emitEvent(finished)
+			result.Skipped++
+			continue
+		}
 		if err != nil {
-			fmt.Printf("    ❌ FAILED: %v\n", err)
-			return fmt.Errorf("step '%s' failed: %w", step.Name, err)
+			steps[i].Status = StatusFailed
+			_ = journal.Record(step.ID, StatusFailed, err)
+			model.logStepEvent(step.ID, "✗ failed: "+err.Error())
+			textf("    ❌ FAILED%s: %v\n", label(i), err)
+			emitEvent(Event{Type: EventError, Step: step.ID, Name: step.Name, Error: eventFailureFor(step, err, string(ErrorAbort))})
+			finished.Status = StatusFailed.String()
+			emitEvent(finished)
+			result.Failed++
+			// Nothing new starts, the running steps are waited for
+			if failed == nil {
+				failed = &out
+			}
+			continue
 		}
-		fmt.Printf("    ✓ Done\n")
+		steps[i].Status = StatusDone
+		_ = journal.Record(step.ID, StatusDone, nil)
+		model.logStepEvent(step.ID, "✓ done")
+		textf("    ✓ Done%s\n", label(i))
+		finished.Status = StatusDone.String()
+		emitEvent(finished)
+		result.Done++
 	}
 
-	fmt.Println()
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Println("✅ Installation complete!")
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	if failed != nil {
+		if model.LogPath != "" {
+			textf("    Full log: %s\n", model.LogPath)
+		}
+		if transactional && !dryRun {
+			report, ok := rollbackNonInteractive(model.BackupDir, journal)
+			result.RolledBack = &report
+			if !ok {
+				result.Journal = JournalPath()
+			}
+		} else if !dryRun {
+			textf("    Progress saved to %s, run again with --resume to continue from this step\n", JournalPath())
+			result.Journal = JournalPath()
+		}
+		summary(false)
+		return fmt.Errorf("step '%s' failed: %w", steps[failed.index].Name, failed.err)
+	}
+	_ = journal.Remove()
+
+	textf("\n")
+	textf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	if dryRun {
+		textf("🧪 Dry run complete: %d actions planned, nothing was changed\n", result.Planned)
+	} else {
+		textf("✅ Installation complete!\n")
+		if model.LogPath != "" {
+			textf("📝 Full log: %s\n", model.LogPath)
+		}
+	}
+	textf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	summary(true)
 
 	return nil
 }
 
+// stepOutcome is the result of a step run by runStepNonInteractive
+type stepOutcome struct {
+	index     int
+	err       error
+	plan      []system.PlanAction
+	backupDir string
+	duration  time.Duration
+}
+
+// runStepNonInteractive executes a step on its own copy of the model,
+// retrying it as the error policy allows
+func runStepNonInteractive(i int, step InstallStep, m *Model, label string) stepOutcome {
+	started := time.Now()
+	err := executeStep(step.ID, m)
+	for attempt := 1; err != nil && errorPolicy.Action == ErrorRetry && attempt <= errorPolicy.Retries; attempt++ {
+		textf("    ⚠️  %s%s\n", firstLine(err.Error()), label)
+		textf("    🔁 Retrying%s (%d/%d)...\n", label, attempt, errorPolicy.Retries)
+		line := fmt.Sprintf("retry %d/%d after: %v", attempt, errorPolicy.Retries, err)
+		writeSessionLog(LogEntry{Time: time.Now(), StepID: step.ID, Line: line})
+		emitEvent(Event{Type: EventLog, Step: step.ID, Line: line})
+		err = executeStep(step.ID, m)
+	}
+	return stepOutcome{
+		index:     i,
+		err:       err,
+		plan:      takeStepPlan(step.ID),
+		backupDir: m.BackupDir,
+		duration:  time.Since(started),
+	}
+}
+
+// rollbackNonInteractive reverts a failed transactional run and prints what
+// was undone. It reports false when the rollback did not finish.
+func rollbackNonInteractive(backupDir string, journal *Journal) (system.RollbackReport, bool) {
+	textf("\n")
+	textf("↩️  Rolling back changes...\n")
+	report, err := system.Rollback(backupDir)
+	for _, entry := range report.Restored {
+		textf("    ✓ Restored %s\n", entry)
+	}
+	for _, path := range report.Removed {
+		textf("    ✓ Removed %s\n", path)
+	}
+	for _, path := range report.Kept {
+		textf("    ⚠️  Kept %s (no backup to restore)\n", path)
+	}
+	if err != nil {
+		textf("    ❌ Rollback did not finish: %v\n", err)
+		textf("    Progress saved to %s\n", JournalPath())
+		emitEvent(Event{Type: EventLog, Line: "rollback did not finish: " + err.Error()})
+		return report, false
+	}
+	if report.Empty() {
+		textf("    Nothing was changed\n")
+	}
+	_ = journal.Remove()
+	return report, true
+}
+
 // buildStepsForChoices creates the list of steps based on user choices
 func buildStepsForChoices(m *Model) []InstallStep {
 	var steps []InstallStep
@@ -77,11 +311,11 @@ func buildStepsForChoices(m *Model) []InstallStep {
 
 	// Homebrew (for Mac and Debian/Ubuntu Linux - NOT Fedora/Arch which use native package managers)
 	if m.SystemInfo.OS == system.OSMac || m.SystemInfo.OS == system.OSDebian || m.SystemInfo.OS == system.OSLinux {
-		steps = append(steps, InstallStep{ID: "homebrew", Name: "Install/Update Homebrew"})
+		steps = append(steps, InstallStep{ID: "homebrew", Name: "Install/Update Homebrew", Interactive: true})
 	}
 
 	// Dependencies
-	steps = append(steps, InstallStep{ID: "deps", Name: "Install dependencies"})
+	steps = append(steps, InstallStep{ID: "deps", Name: "Install dependencies", Interactive: m.Choices.OS == "linux" && !m.SystemInfo.IsTermux})
 
 	// Xcode (Mac only)
 	if m.SystemInfo.OS == system.OSMac {
@@ -90,12 +324,12 @@ func buildStepsForChoices(m *Model) []InstallStep {
 
 	// Terminal
 	if m.Choices.Terminal != "none" {
-		steps = append(steps, InstallStep{ID: "terminal", Name: fmt.Sprintf("Install %s terminal", m.Choices.Terminal)})
+		steps = append(steps, InstallStep{ID: "terminal", Name: fmt.Sprintf("Install %s terminal", m.Choices.Terminal), Interactive: m.Choices.OS == "linux"})
 	}
 
 	// Font
 	if m.Choices.InstallFont {
-		steps = append(steps, InstallStep{ID: "font", Name: "Install Nerd Font"})
+		steps = append(steps, InstallStep{ID: "font", Name: "Install " + m.installFont().Name + " Nerd Font"})
 	}
 
 	// Shell
@@ -131,11 +365,16 @@ func buildStepsForChoices(m *Model) []InstallStep {
 		steps = append(steps, InstallStep{ID: "aiframework", Name: "Install AI framework"})
 	}
 
+	// Theme
+	if m.Choices.Theme != "" {
+		steps = append(steps, InstallStep{ID: "theme", Name: fmt.Sprintf("Apply %s theme", m.Choices.Theme)})
+	}
+
 	// Set shell as default
-	steps = append(steps, InstallStep{ID: "setshell", Name: "Set shell as default"})
+	steps = append(steps, InstallStep{ID: "setshell", Name: "Set shell as default", Interactive: setShellInteractive(m)})
 
 	// Cleanup
 	steps = append(steps, InstallStep{ID: "cleanup", Name: "Cleanup"})
 
-	return steps
+	return withDependencies(steps)
 }
@@ -0,0 +1,354 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
+)
+
+// ProfileVersion is the current version of the install profile format
+const ProfileVersion = 1
+
+// Valid option values shared by CLI flags, profiles and the TUI
+var (
+	ValidTerminals      = system.ToolIDs(system.ToolTerminal, "none")
+	ValidShells         = system.ToolIDs(system.ToolShell)
+	ValidWindowManagers = system.ToolIDs(system.ToolWM, "none")
+	ValidAITools        = []string{"claude", "opencode", "gemini", "copilot", "codex", "qwen"}
+	ValidAIPresets      = []string{"minimal", "frontend", "backend", "fullstack", "data", "complete"}
+	ValidAIFeatures     = []string{"hooks", "commands", "skills", "agents", "sdd", "mcp"}
+	ValidProjectMemory  = []string{"obsidian-brain", "vibekanban", "engram", "simple", "none"}
+	ValidProjectCI      = []string{"github", "gitlab", "woodpecker", "none"}
+	ValidRolePacks      = []string{"developer", "pm-lead"}
+	ValidThemes         = system.ThemeIDs()
+	ValidFonts          = system.FontIDs()
+)
+
+// ValidateOption returns an error listing the valid values if value is not one of them
+func ValidateOption(what, value string, valid []string) error {
+	if slices.Contains(valid, value) {
+		return nil
+	}
+	return fmt.Errorf("invalid %s: %s (valid: %s)", what, value, strings.Join(valid, ", "))
+}
+
+// NormalizeRolePacks validates Obsidian Brain role packs and prepends the
+// implicit "core" pack when memory is obsidian-brain
+func NormalizeRolePacks(packs []string, memory string) ([]string, error) {
+	var rolePacks []string
+	for _, pack := range packs {
+		pack = strings.TrimSpace(strings.ToLower(pack))
+		if pack == "" {
+			continue
+		}
+		if memory != "obsidian-brain" {
+			return nil, fmt.Errorf("role packs require project memory obsidian-brain")
+		}
+		if pack == "core" {
+			continue // core is implicit
+		}
+		if err := ValidateOption("role pack", pack, ValidRolePacks); err != nil {
+			return nil, err
+		}
+		rolePacks = append(rolePacks, pack)
+	}
+	// Core is always included when obsidian-brain
+	if memory == "obsidian-brain" {
+		rolePacks = append([]string{"core"}, rolePacks...)
+	}
+	return rolePacks, nil
+}
+
+// Profile is a declarative, versioned description of an installation.
+// Every field maps onto UserChoices; the OS is always detected at runtime.
+type Profile struct {
+	Version     int            `toml:"version"`
+	Description string         `toml:"description,omitempty"`
+	Terminal    string         `toml:"terminal"`
+	Shell       string         `toml:"shell"`
+	WindowMgr   string         `toml:"window_manager"`
+	Nvim        bool           `toml:"nvim"`
+	Zed         bool           `toml:"zed"`
+	Font        bool           `toml:"font"`
+	FontFamily  string         `toml:"font_family,omitempty"`
+	FontSize    float64        `toml:"font_size,omitempty"`
+	Backup      bool           `toml:"backup"`
+	Link        bool           `toml:"link"`
+	Theme       string         `toml:"theme,omitempty"`
+	Skills      []string       `toml:"skills"`
+	AI          ProfileAI      `toml:"ai"`
+	Project     ProfileProject `toml:"project"`
+}
+
+// ProfileAI holds the AI tools and framework section of a profile
+type ProfileAI struct {
+	Tools          []string `toml:"tools"`
+	Framework      bool     `toml:"framework"`
+	Preset         string   `toml:"preset"`
+	Modules        []string `toml:"modules"`
+	AgentTeamsLite bool     `toml:"agent_teams_lite"`
+}
+
+// ProfileProject holds the project initialization section of a profile
+type ProfileProject struct {
+	Init      bool     `toml:"init"`
+	Path      string   `toml:"path"`
+	Stack     string   `toml:"stack"`
+	Memory    string   `toml:"memory"`
+	CI        string   `toml:"ci"`
+	Engram    bool     `toml:"engram"`
+	RolePacks []string `toml:"role_packs"`
+	Obsidian  bool     `toml:"obsidian"`
+}
+
+// DefaultProfile returns a profile with the same defaults as the CLI flags
+func DefaultProfile() Profile {
+	return Profile{
+		Version:   ProfileVersion,
+		Terminal:  "none",
+		WindowMgr: "none",
+		Backup:    true,
+		Project: ProfileProject{
+			Memory: "simple",
+			CI:     "none",
+		},
+	}
+}
+
+// LoadProfile reads and validates a profile file. Keys missing from the
+// file keep their DefaultProfile values.
+func LoadProfile(path string) (Profile, error) {
+	p := DefaultProfile()
+	p.Version = 0
+
+	meta, err := toml.DecodeFile(expandPath(path), &p)
+	if err != nil {
+		return p, fmt.Errorf("failed to read profile %s: %w", path, err)
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+		for i, key := range undecoded {
+			keys[i] = key.String()
+		}
+		return p, fmt.Errorf("profile %s has unknown keys: %s", path, strings.Join(keys, ", "))
+	}
+
+	switch {
+	case p.Version == 0:
+		return p, fmt.Errorf("profile %s is missing 'version' (current: %d)", path, ProfileVersion)
+	case p.Version > ProfileVersion:
+		return p, fmt.Errorf("profile %s has version %d, this installer supports up to %d", path, p.Version, ProfileVersion)
+	}
+
+	p.Normalize()
+	if err := p.Validate(); err != nil {
+		return p, fmt.Errorf("profile %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Normalize lowercases option values and trims list entries
+func (p *Profile) Normalize() {
+	lower := func(s string) string { return strings.TrimSpace(strings.ToLower(s)) }
+	lowerList := func(list []string) []string {
+		var out []string
+		for _, item := range list {
+			if item = lower(item); item != "" {
+				out = append(out, item)
+			}
+		}
+		return out
+	}
+
+	p.Terminal = lower(p.Terminal)
+	p.Shell = lower(p.Shell)
+	p.WindowMgr = lower(p.WindowMgr)
+	p.FontFamily = lower(p.FontFamily)
+	p.Theme = lower(p.Theme)
+	p.AI.Tools = lowerList(p.AI.Tools)
+	p.AI.Preset = lower(p.AI.Preset)
+	p.AI.Modules = lowerList(p.AI.Modules)
+	p.Project.Memory = lower(p.Project.Memory)
+	p.Project.CI = lower(p.Project.CI)
+	p.Project.RolePacks = lowerList(p.Project.RolePacks)
+	for i := range p.Skills {
+		p.Skills[i] = strings.TrimSpace(p.Skills[i])
+	}
+}
+
+// Validate checks every option against the valid values
+func (p Profile) Validate() error {
+	if p.Terminal != "" {
+		if err := ValidateOption("terminal", p.Terminal, ValidTerminals); err != nil {
+			return err
+		}
+	}
+	if p.Shell != "" {
+		if err := ValidateOption("shell", p.Shell, ValidShells); err != nil {
+			return err
+		}
+	}
+	if p.WindowMgr != "" {
+		if err := ValidateOption("window manager", p.WindowMgr, ValidWindowManagers); err != nil {
+			return err
+		}
+	}
+	if p.FontFamily != "" {
+		if err := ValidateOption("font family", p.FontFamily, ValidFonts); err != nil {
+			return err
+		}
+	}
+	if p.FontSize < 0 {
+		return fmt.Errorf("invalid font size: %g", p.FontSize)
+	}
+	if p.Theme != "" {
+		if err := ValidateOption("theme", p.Theme, ValidThemes); err != nil {
+			return err
+		}
+	}
+	for _, tool := range p.AI.Tools {
+		if err := ValidateOption("AI tool", tool, ValidAITools); err != nil {
+			return err
+		}
+	}
+	if p.AI.Preset != "" {
+		if err := ValidateOption("AI preset", p.AI.Preset, ValidAIPresets); err != nil {
+			return err
+		}
+	}
+	for _, mod := range p.AI.Modules {
+		if err := ValidateOption("AI feature", mod, ValidAIFeatures); err != nil {
+			return err
+		}
+	}
+	if p.Project.Memory != "" {
+		if err := ValidateOption("memory module", p.Project.Memory, ValidProjectMemory); err != nil {
+			return err
+		}
+	}
+	if p.Project.CI != "" {
+		if err := ValidateOption("CI provider", p.Project.CI, ValidProjectCI); err != nil {
+			return err
+		}
+	}
+	if p.Project.Engram && p.Project.Memory != "obsidian-brain" {
+		return fmt.Errorf("project engram requires project memory obsidian-brain")
+	}
+	if _, err := NormalizeRolePacks(p.Project.RolePacks, p.Project.Memory); err != nil {
+		return err
+	}
+	if p.Project.Init && p.Project.Path == "" {
+		return fmt.Errorf("project path is required when project init is enabled")
+	}
+	return nil
+}
+
+// Choices converts the profile into UserChoices
+func (p Profile) Choices() UserChoices {
+	terminal := p.Terminal
+	if terminal == "" {
+		terminal = "none"
+	}
+	wm := p.WindowMgr
+	if wm == "" {
+		wm = "none"
+	}
+	rolePacks, _ := NormalizeRolePacks(p.Project.RolePacks, p.Project.Memory)
+
+	return UserChoices{
+		Terminal:              terminal,
+		InstallFont:           p.Font || p.FontFamily != "" || p.FontSize > 0,
+		Font:                  p.FontFamily,
+		FontSize:              p.FontSize,
+		Shell:                 p.Shell,
+		WindowMgr:             wm,
+		InstallNvim:           p.Nvim,
+		InstallZed:            p.Zed,
+		CreateBackup:          p.Backup,
+		LinkConfigs:           p.Link,
+		Theme:                 p.Theme,
+		AITools:               p.AI.Tools,
+		InstallAIFramework:    p.AI.Framework || p.AI.Preset != "" || len(p.AI.Modules) > 0 || p.AI.AgentTeamsLite,
+		AIFrameworkPreset:     p.AI.Preset,
+		AIFrameworkModules:    p.AI.Modules,
+		InstallAgentTeamsLite: p.AI.AgentTeamsLite,
+		Skills:                p.Skills,
+		InitProject:           p.Project.Init,
+		ProjectPath:           p.Project.Path,
+		ProjectStack:          p.Project.Stack,
+		ProjectMemory:         p.Project.Memory,
+		ProjectCI:             p.Project.CI,
+		ProjectEngram:         p.Project.Engram,
+		ProjectRolePacks:      rolePacks,
+		InstallObsidian:       p.Project.Obsidian,
+	}
+}
+
+// ProfileFromChoices builds a profile that reproduces the given choices
+func ProfileFromChoices(c UserChoices) Profile {
+	p := DefaultProfile()
+	p.Terminal = c.Terminal
+	p.Shell = c.Shell
+	p.WindowMgr = c.WindowMgr
+	p.Nvim = c.InstallNvim
+	p.Zed = c.InstallZed
+	p.Font = c.InstallFont
+	p.FontFamily = c.Font
+	p.FontSize = c.FontSize
+	p.Backup = c.CreateBackup
+	p.Link = c.LinkConfigs
+	p.Theme = c.Theme
+	p.Skills = c.Skills
+	p.AI = ProfileAI{
+		Tools:          c.AITools,
+		Framework:      c.InstallAIFramework,
+		Preset:         c.AIFrameworkPreset,
+		Modules:        c.AIFrameworkModules,
+		AgentTeamsLite: c.InstallAgentTeamsLite,
+	}
+	p.Project.Init = c.InitProject
+	p.Project.Path = c.ProjectPath
+	p.Project.Stack = c.ProjectStack
+	p.Project.Engram = c.ProjectEngram
+	p.Project.Obsidian = c.InstallObsidian
+	if c.ProjectMemory != "" {
+		p.Project.Memory = c.ProjectMemory
+	}
+	if c.ProjectCI != "" {
+		p.Project.CI = c.ProjectCI
+	}
+	for _, pack := range c.ProjectRolePacks {
+		if pack != "core" {
+			p.Project.RolePacks = append(p.Project.RolePacks, pack)
+		}
+	}
+	return p
+}
+
+// SaveProfile writes a profile as TOML, creating parent directories
+func SaveProfile(path string, p Profile) error {
+	var buf bytes.Buffer
+	buf.WriteString("# Javi.Dots install profile\n")
+	buf.WriteString("# Apply with: gentleman.dots --profile " + filepath.Base(path) + "\n\n")
+	if err := toml.NewEncoder(&buf).Encode(p); err != nil {
+		return fmt.Errorf("failed to encode profile: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create profile directory: %w", err)
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// DefaultProfilePath returns a timestamped path under the installer state dir
+func DefaultProfilePath() string {
+	name := "profile-" + time.Now().Format("2006-01-02-150405") + ".toml"
+	return filepath.Join(system.StateDir(), "profiles", name)
+}
@@ -0,0 +1,185 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func writeProfile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "profile.toml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write profile: %v", err)
+	}
+	return path
+}
+
+func TestLoadProfile(t *testing.T) {
+	t.Run("full profile maps to choices", func(t *testing.T) {
+		path := writeProfile(t, `
+version = 1
+description = "Team laptop"
+terminal = "Ghostty"
+shell = "zsh"
+window_manager = "tmux"
+nvim = true
+font = true
+skills = ["react-19", "typescript"]
+
+[ai]
+tools = ["claude", "opencode"]
+preset = "fullstack"
+
+[project]
+memory = "obsidian-brain"
+role_packs = ["developer"]
+`)
+		p, err := LoadProfile(path)
+		if err != nil {
+			t.Fatalf("LoadProfile failed: %v", err)
+		}
+		c := p.Choices()
+		if c.Terminal != "ghostty" || c.Shell != "zsh" || c.WindowMgr != "tmux" {
+			t.Errorf("unexpected core choices: %+v", c)
+		}
+		if !c.InstallNvim || !c.InstallFont || c.InstallZed {
+			t.Errorf("unexpected toggles: nvim=%v font=%v zed=%v", c.InstallNvim, c.InstallFont, c.InstallZed)
+		}
+		if !c.CreateBackup {
+			t.Error("backup should default to true when omitted")
+		}
+		if !c.InstallAIFramework || c.AIFrameworkPreset != "fullstack" {
+			t.Errorf("preset should enable the framework: %+v", c)
+		}
+		if strings.Join(c.ProjectRolePacks, ",") != "core,developer" {
+			t.Errorf("role packs = %v, want [core developer]", c.ProjectRolePacks)
+		}
+		if len(c.Skills) != 2 {
+			t.Errorf("skills = %v", c.Skills)
+		}
+	})
+
+	t.Run("omitted keys keep defaults", func(t *testing.T) {
+		p, err := LoadProfile(writeProfile(t, "version = 1\nshell = \"fish\"\n"))
+		if err != nil {
+			t.Fatalf("LoadProfile failed: %v", err)
+		}
+		c := p.Choices()
+		if c.Terminal != "none" || c.WindowMgr != "none" || c.ProjectMemory != "simple" || c.ProjectCI != "none" {
+			t.Errorf("defaults not applied: %+v", c)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		cases := []struct {
+			name    string
+			content string
+			want    string
+		}{
+			{"missing version", `shell = "fish"`, "missing 'version'"},
+			{"newer version", "version = 99\n", "supports up to"},
+			{"unknown key", "version = 1\nshel = \"fish\"\n", "unknown keys: shel"},
+			{"unknown nested key", "version = 1\n[ai]\npresets = \"data\"\n", "ai.presets"},
+			{"invalid shell", "version = 1\nshell = \"tcsh\"\n", "invalid shell: tcsh"},
+			{"invalid AI tool", "version = 1\n[ai]\ntools = [\"cursor\"]\n", "invalid AI tool: cursor"},
+			{"engram without obsidian", "version = 1\n[project]\nengram = true\n", "obsidian-brain"},
+			{"init without path", "version = 1\n[project]\ninit = true\n", "project path is required"},
+			{"malformed toml", "version = \n", "failed to read profile"},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := LoadProfile(writeProfile(t, tc.content))
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if !strings.Contains(err.Error(), tc.want) {
+					t.Errorf("error %q should contain %q", err, tc.want)
+				}
+			})
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := LoadProfile(filepath.Join(t.TempDir(), "nope.toml")); err == nil {
+			t.Error("expected an error for a missing file")
+		}
+	})
+}
+
+func TestSaveProfileRoundTrip(t *testing.T) {
+	choices := UserChoices{
+		Terminal:              "kitty",
+		Shell:                 "nushell",
+		WindowMgr:             "zellij",
+		InstallNvim:           true,
+		InstallZed:            true,
+		CreateBackup:          false,
+		AITools:               []string{"claude"},
+		InstallAIFramework:    true,
+		AIFrameworkModules:    []string{"hooks", "sdd"},
+		InstallAgentTeamsLite: true,
+		ProjectMemory:         "obsidian-brain",
+		ProjectCI:             "github",
+		ProjectEngram:         true,
+		ProjectRolePacks:      []string{"core", "pm-lead"},
+	}
+
+	path := filepath.Join(t.TempDir(), "profiles", "mine.toml")
+	if err := SaveProfile(path, ProfileFromChoices(choices)); err != nil {
+		t.Fatalf("SaveProfile failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(data), "# Javi.Dots install profile") {
+		t.Errorf("saved profile should start with a header comment:\n%s", data)
+	}
+
+	p, err := LoadProfile(path)
+	if err != nil {
+		t.Fatalf("saved profile does not load: %v", err)
+	}
+	got := p.Choices()
+	if got.Terminal != "kitty" || got.Shell != "nushell" || got.WindowMgr != "zellij" {
+		t.Errorf("core choices lost: %+v", got)
+	}
+	if !got.InstallNvim || !got.InstallZed || got.CreateBackup {
+		t.Errorf("toggles lost: %+v", got)
+	}
+	if strings.Join(got.AIFrameworkModules, ",") != "hooks,sdd" || !got.InstallAgentTeamsLite {
+		t.Errorf("AI framework lost: %+v", got)
+	}
+	if strings.Join(got.ProjectRolePacks, ",") != "core,pm-lead" || !got.ProjectEngram || got.ProjectCI != "github" {
+		t.Errorf("project choices lost: %+v", got)
+	}
+}
+
+func TestSaveProfileKey(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	m := NewModel()
+	m.Screen = ScreenBackupConfirm
+	m.Choices = UserChoices{Terminal: "alacritty", Shell: "fish", WindowMgr: "tmux"}
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	m = result.(Model)
+
+	if m.Screen != ScreenBackupConfirm {
+		t.Errorf("saving a profile should not leave the screen, got %v", m.Screen)
+	}
+	if !strings.HasPrefix(m.ProfileMsg, "💾 Profile saved: ") {
+		t.Fatalf("unexpected profile message: %q", m.ProfileMsg)
+	}
+
+	path := strings.TrimPrefix(m.ProfileMsg, "💾 Profile saved: ")
+	p, err := LoadProfile(path)
+	if err != nil {
+		t.Fatalf("saved profile does not load: %v", err)
+	}
+	if p.Shell != "fish" || p.Terminal != "alacritty" {
+		t.Errorf("saved profile does not match choices: %+v", p)
+	}
+}
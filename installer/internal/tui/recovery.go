@@ -0,0 +1,223 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
+)
+
+// ErrorAction is what the installer does when a step fails
+type ErrorAction string
+
+const (
+	ErrorAbort ErrorAction = "abort"
+	ErrorSkip  ErrorAction = "skip"
+	ErrorRetry ErrorAction = "retry"
+)
+
+// ErrorPolicy decides how non-interactive runs react to a failed step
+type ErrorPolicy struct {
+	Action  ErrorAction
+	Retries int // Extra attempts when Action is ErrorRetry; the run aborts if all fail
+}
+
+func (p ErrorPolicy) String() string {
+	if p.Action == ErrorRetry {
+		return fmt.Sprintf("retry:%d", p.Retries)
+	}
+	return string(p.Action)
+}
+
+// ParseErrorPolicy parses an --on-error value: abort, skip or retry:N
+func ParseErrorPolicy(value string) (ErrorPolicy, error) {
+	value = strings.TrimSpace(strings.ToLower(value))
+	switch {
+	case value == "" || value == string(ErrorAbort):
+		return ErrorPolicy{Action: ErrorAbort}, nil
+	case value == string(ErrorSkip):
+		return ErrorPolicy{Action: ErrorSkip}, nil
+	case value == string(ErrorRetry):
+		return ErrorPolicy{Action: ErrorRetry, Retries: 1}, nil
+	case strings.HasPrefix(value, "retry:"):
+		n, err := strconv.Atoi(strings.TrimPrefix(value, "retry:"))
+		if err != nil || n < 1 {
+			return ErrorPolicy{}, fmt.Errorf("invalid retry count in --on-error=%s (use retry:N with N >= 1)", value)
+		}
+		return ErrorPolicy{Action: ErrorRetry, Retries: n}, nil
+	}
+	return ErrorPolicy{}, fmt.Errorf("invalid --on-error value: %s (valid: abort, skip, retry:N)", value)
+}
+
+// errorPolicy is the failure policy for non-interactive runs
+var errorPolicy = ErrorPolicy{Action: ErrorAbort}
+
+// SetErrorPolicy sets how non-interactive runs react to a failed step
+func SetErrorPolicy(p ErrorPolicy) {
+	errorPolicy = p
+}
+
+// transactional makes non-interactive runs roll back when they abort
+var transactional bool
+
+// SetTransactional enables rolling back a failed non-interactive run
+func SetTransactional(enabled bool) {
+	transactional = enabled
+}
+
+// optionalSteps never block the installation: a failure is reported and
+// the step is skipped so the shell setup can finish
+var optionalSteps = map[string]bool{
+	"font": true,
+	"zed":  true,
+}
+
+// IsOptionalStep reports whether a failure of stepID should be skipped automatically
+func IsOptionalStep(stepID string) bool {
+	return optionalSteps[stepID]
+}
+
+// ErrorDetails is the structured view of a step failure
+type ErrorDetails struct {
+	StepName    string
+	Description string
+	Cause       string // Underlying error when it is not a command failure
+	Command     string
+	ExitCode    int
+	Stderr      string
+	Stdout      string
+}
+
+// errorDetailsFor extracts StepError and ExecError context from err
+func errorDetailsFor(step InstallStep, err error) ErrorDetails {
+	d := ErrorDetails{StepName: step.Name, Description: step.Description}
+
+	var stepErr *StepError
+	if errors.As(err, &stepErr) {
+		d.StepName = stepErr.StepName
+		d.Description = stepErr.Description
+		err = stepErr.Cause
+	}
+
+	var execErr *system.ExecError
+	if errors.As(err, &execErr) {
+		d.Command = execErr.Command
+		d.ExitCode = execErr.ExitCode
+		d.Stderr = strings.TrimSpace(execErr.Stderr)
+		d.Stdout = strings.TrimSpace(execErr.Stdout)
+		if execErr.Wrapped != nil {
+			d.Cause = execErr.Wrapped.Error()
+		}
+	} else if err != nil {
+		d.Cause = err.Error()
+	}
+	return d
+}
+
+// lastLines returns at most n trailing non-empty lines of s
+func lastLines(s string, n int) []string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
+
+// firstLine returns the first line of a possibly multi-line message
+func firstLine(s string) string {
+	return strings.SplitN(s, "\n", 2)[0]
+}
+
+// failedStepIndex returns the index of the step that stopped the
+// installation, or -1 when the error did not come from a step
+func (m Model) failedStepIndex() int {
+	for i, step := range m.Steps {
+		if step.Status == StatusFailed {
+			return i
+		}
+	}
+	return -1
+}
+
+// handleStepFailure marks a step as failed and shows the error screen.
+// Optional steps are skipped instead so they never block the installation.
+func (m Model) handleStepFailure(i int, err error) Model {
+	m.Steps[i].Error = err
+
+	if IsOptionalStep(m.Steps[i].ID) {
+		m.Steps[i].Status = StatusSkipped
+		m.LogLines = append(m.LogLines, fmt.Sprintf("⚠️  %s failed, skipped (optional): %s", m.Steps[i].Name, firstLine(err.Error())))
+		if m.Journal != nil {
+			_ = m.Journal.Record(m.Steps[i].ID, StatusSkipped, err)
+		}
+		return m
+	}
+
+	// A step failing while another failure is shown waits for its turn
+	first := m.failedStepIndex()
+	m.Steps[i].Status = StatusFailed
+	if first < 0 {
+		m.showStepError(i)
+	}
+	return m
+}
+
+// showStepError shows the error screen for a failed step
+func (m *Model) showStepError(i int) {
+	m.showInstallScreen(ScreenError)
+	m.Cursor = 0
+	// Include step name in error message for clarity
+	m.ErrorMsg = fmt.Sprintf("Step '%s' failed:\n%s", m.Steps[i].Name, m.Steps[i].Error.Error())
+}
+
+// retryFailedStep runs the failed step again
+func (m Model) retryFailedStep() (Model, bool) {
+	i := m.failedStepIndex()
+	if i < 0 {
+		return m, false
+	}
+	m.Steps[i].Status = StatusPending
+	m.Steps[i].Error = nil
+	m.Steps[i].Progress = 0
+	m.ErrorMsg = ""
+	m.Screen = ScreenInstalling
+	return m, true
+}
+
+// skipFailedStep marks the failed step as skipped and moves on
+func (m Model) skipFailedStep() (Model, bool) {
+	i := m.failedStepIndex()
+	if i < 0 {
+		return m, false
+	}
+	m.Steps[i].Status = StatusSkipped
+	if m.Journal != nil {
+		_ = m.Journal.Record(m.Steps[i].ID, StatusSkipped, m.Steps[i].Error)
+	}
+	m.ErrorMsg = ""
+	m.Screen = ScreenInstalling
+	return m, true
+}
+
+// rollbackInstall reverts everything the installation changed, restoring
+// configs from the pre-install backup, and shows what was undone
+func (m Model) rollbackInstall() Model {
+	report, err := system.Rollback(m.BackupDir)
+	m.Rollback = &report
+	m.RollbackErr = err
+	if err == nil && m.Journal != nil {
+		_ = m.Journal.Remove()
+		m.Journal = nil
+	}
+	m.ErrorMsg = ""
+	m.Screen = ScreenRollback
+	m.Cursor = 0
+	return m
+}
@@ -0,0 +1,273 @@
+package tui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestParseErrorPolicy(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    ErrorPolicy
+		wantErr bool
+	}{
+		{"", ErrorPolicy{Action: ErrorAbort}, false},
+		{"abort", ErrorPolicy{Action: ErrorAbort}, false},
+		{"SKIP", ErrorPolicy{Action: ErrorSkip}, false},
+		{"retry", ErrorPolicy{Action: ErrorRetry, Retries: 1}, false},
+		{"retry:3", ErrorPolicy{Action: ErrorRetry, Retries: 3}, false},
+		{"retry:0", ErrorPolicy{}, true},
+		{"retry:x", ErrorPolicy{}, true},
+		{"ignore", ErrorPolicy{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseErrorPolicy(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseErrorPolicy(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseErrorPolicy(%q) = %+v, want %+v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorDetailsFor(t *testing.T) {
+	step := InstallStep{ID: "shell", Name: "Install Fish", Description: "Shell setup"}
+
+	t.Run("step error wrapping an exec error", func(t *testing.T) {
+		err := wrapStepError("shell", "Install Fish Shell", "Failed to install fish", &system.ExecError{
+			Command:  "brew install fish",
+			ExitCode: 1,
+			Stderr:   "curl: (28) Operation timed out\n",
+		})
+		d := errorDetailsFor(step, err)
+		if d.StepName != "Install Fish Shell" || d.Description != "Failed to install fish" {
+			t.Errorf("step context not extracted: %+v", d)
+		}
+		if d.Command != "brew install fish" || d.ExitCode != 1 || d.Stderr != "curl: (28) Operation timed out" {
+			t.Errorf("exec context not extracted: %+v", d)
+		}
+	})
+
+	t.Run("plain error falls back to the step", func(t *testing.T) {
+		d := errorDetailsFor(step, errors.New("exit status 1"))
+		if d.StepName != "Install Fish" || d.Cause != "exit status 1" || d.Command != "" {
+			t.Errorf("unexpected details: %+v", d)
+		}
+	})
+}
+
+func failingModel(t *testing.T) Model {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	m := NewModel()
+	m.Screen = ScreenInstalling
+	m.Steps = []InstallStep{
+		{ID: "clone", Name: "Clone Repository", Status: StatusDone},
+		{ID: "font", Name: "Install Nerd Font", Status: StatusRunning},
+		{ID: "shell", Name: "Install Fish", Status: StatusPending},
+		{ID: "cleanup", Name: "Cleanup", Status: StatusPending},
+	}
+	m.CurrentStep = 1
+	m.Journal = NewJournal(&m)
+	return m
+}
+
+func TestStepFailureRecovery(t *testing.T) {
+	t.Run("optional step failure is skipped", func(t *testing.T) {
+		m := failingModel(t)
+		result, cmd := m.Update(stepCompleteMsg{stepID: "font", err: errors.New("download failed")})
+		next := result.(Model)
+
+		if next.Screen != ScreenInstalling {
+			t.Fatalf("optional step should not block, got screen %v", next.Screen)
+		}
+		if next.Steps[1].Status != StatusSkipped || next.CurrentStep != 2 || cmd == nil {
+			t.Errorf("font should be skipped and installation continue: status=%v current=%d", next.Steps[1].Status, next.CurrentStep)
+		}
+		if !next.Journal.IsDone("font") {
+			t.Error("skipped step should be journaled as finished")
+		}
+	})
+
+	failShell := func(t *testing.T) Model {
+		m := failingModel(t)
+		m.CurrentStep = 2
+		m.Steps[1].Status = StatusDone
+		m.Steps[2].Status = StatusRunning
+		err := wrapStepError("shell", "Install Fish", "Failed to install fish", &system.ExecError{
+			Command: "brew install fish", ExitCode: 1, Stderr: "Error: fish: SHA256 mismatch",
+		})
+		result, _ := m.Update(stepCompleteMsg{stepID: "shell", err: err})
+		return result.(Model)
+	}
+
+	t.Run("required step failure shows details and options", func(t *testing.T) {
+		m := failShell(t)
+		if m.Screen != ScreenError || m.failedStepIndex() != 2 {
+			t.Fatalf("expected error screen for shell, got %v (failed %d)", m.Screen, m.failedStepIndex())
+		}
+		if len(m.GetCurrentOptions()) != 4 {
+			t.Errorf("expected retry/skip/roll back/abort options, got %v", m.GetCurrentOptions())
+		}
+		view := m.View()
+		for _, want := range []string{"Install Fish", "brew install fish", "SHA256 mismatch", "Retry", "Skip", "roll back", "keep changes"} {
+			if !strings.Contains(view, want) {
+				t.Errorf("error screen missing %q", want)
+			}
+		}
+	})
+
+	t.Run("retry runs the step again", func(t *testing.T) {
+		m := failShell(t)
+		result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+		next := result.(Model)
+		if next.Screen != ScreenInstalling || next.CurrentStep != 2 || cmd == nil {
+			t.Errorf("retry should resume installing at the same step: screen=%v current=%d", next.Screen, next.CurrentStep)
+		}
+		if next.Steps[2].Error != nil {
+			t.Error("retry should clear the previous error")
+		}
+	})
+
+	t.Run("skip continues with the next step", func(t *testing.T) {
+		m := failShell(t)
+		m.Cursor = 1
+		result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		next := result.(Model)
+		if next.Screen != ScreenInstalling || next.CurrentStep != 3 || cmd == nil {
+			t.Errorf("skip should move on: screen=%v current=%d", next.Screen, next.CurrentStep)
+		}
+		if next.Steps[2].Status != StatusSkipped {
+			t.Errorf("shell should be skipped, got %v", next.Steps[2].Status)
+		}
+	})
+
+	t.Run("roll back undoes changes and reports them", func(t *testing.T) {
+		m := failShell(t)
+		t.Setenv("GENTLEMAN_DRY_RUN", "")
+		fishDir := filepath.Join(os.Getenv("HOME"), ".config", "fish")
+		system.ResetChanges(nil)
+		system.EnsureDir(fishDir)
+		m.Journal.Save()
+
+		m.Cursor = 2
+		result, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		next := result.(Model)
+		if next.Screen != ScreenRollback || next.Rollback == nil || next.RollbackErr != nil {
+			t.Fatalf("expected rollback report, got screen %v (err %v)", next.Screen, next.RollbackErr)
+		}
+		if _, err := os.Stat(fishDir); !os.IsNotExist(err) {
+			t.Error("directory created by the install should be removed")
+		}
+		if next.Journal != nil {
+			t.Error("rolled back installation should not be resumable")
+		}
+		if _, err := os.Stat(JournalPath()); !os.IsNotExist(err) {
+			t.Error("journal file should be removed after rollback")
+		}
+		if !strings.Contains(next.View(), ".config") {
+			t.Error("report should list the removed path")
+		}
+
+		result, cmd := next.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		if !result.(Model).Quitting || cmd == nil {
+			t.Error("enter on the rollback report should quit")
+		}
+	})
+
+	t.Run("roll back stops the running steps first", func(t *testing.T) {
+		m := failShell(t)
+		t.Setenv("GENTLEMAN_DRY_RUN", "")
+		system.ResetChanges(nil)
+		m.Steps[3].Status = StatusRunning
+		m.startInstallContext()
+		ctx := m.ctx()
+
+		m = press(m, "b")
+		if m.Screen != ScreenInstalling || !m.Cancelling || ctx.Err() == nil {
+			t.Fatalf("roll back should cancel the running step, got %v (cancelling %v)", m.Screen, m.Cancelling)
+		}
+		if !strings.Contains(m.View(), "then rolling back") {
+			t.Error("the installing screen should say a roll back follows")
+		}
+
+		result, _ := m.Update(stepCompleteMsg{stepID: m.Steps[3].ID, err: &system.ExecError{Wrapped: system.ErrCancelled}})
+		next := result.(Model)
+		if next.Screen != ScreenRollback || next.Rollback == nil {
+			t.Fatalf("expected the rollback report once the step stopped, got %v", next.Screen)
+		}
+		if next.Cancelling || next.RollbackNext {
+			t.Error("nothing should be left pending after the roll back")
+		}
+	})
+
+	t.Run("abort quits", func(t *testing.T) {
+		m := failShell(t)
+		result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+		if !result.(Model).Quitting || cmd == nil {
+			t.Error("abort should quit")
+		}
+	})
+}
+
+func TestNonInteractiveErrorPolicy(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	defer SetErrorPolicy(ErrorPolicy{Action: ErrorAbort})
+
+	model := &Model{SystemInfo: &system.SystemInfo{}, RepoDir: t.TempDir()}
+	steps := func() []InstallStep {
+		return []InstallStep{{ID: "bogus", Name: "Always fails"}}
+	}
+
+	SetErrorPolicy(ErrorPolicy{Action: ErrorSkip})
+	if err := runStepsNonInteractive(model, steps(), nil); err != nil {
+		t.Errorf("skip policy should not fail the run: %v", err)
+	}
+
+	SetErrorPolicy(ErrorPolicy{Action: ErrorRetry, Retries: 2})
+	if err := runStepsNonInteractive(model, steps(), nil); err == nil {
+		t.Error("retry policy should fail once retries are exhausted")
+	}
+
+	SetErrorPolicy(ErrorPolicy{Action: ErrorAbort})
+	if err := runStepsNonInteractive(model, steps(), nil); err == nil {
+		t.Error("abort policy should fail the run")
+	}
+}
+
+func TestNonInteractiveTransactional(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+	SetTransactional(true)
+	defer SetTransactional(false)
+	defer system.ResetChanges(nil)
+
+	model := &Model{SystemInfo: &system.SystemInfo{}, RepoDir: t.TempDir()}
+	model.Steps = []InstallStep{{ID: "bogus", Name: "Always fails"}}
+	j := NewJournal(model)
+
+	// A previous run of this installation created a config directory
+	created := filepath.Join(os.Getenv("HOME"), ".config", "zellij")
+	os.MkdirAll(created, 0755)
+	j.Changes = []system.Change{{Path: created}}
+
+	if err := runStepsNonInteractive(model, model.Steps, j); err == nil {
+		t.Fatal("a failing run should still return an error")
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Error("transactional run should remove what the installation created")
+	}
+	if _, err := os.Stat(JournalPath()); !os.IsNotExist(err) {
+		t.Error("journal should be removed after a rollback")
+	}
+}
@@ -0,0 +1,400 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
+)
+
+// toolSpec is the installer side of a tool in system.Tools: how it is
+// offered, taught and set up beyond installing its packages and copying
+// its files
+type toolSpec struct {
+	Info      ToolInfo
+	Keymaps   func() []KeymapCategory // keymap reference, nil when there is none
+	KeymapsAt Screen                  // screen listing the keymap categories
+	Available func(m Model) bool      // nil when offered on every system
+	Label     func(m Model) string    // menu label, nil for the tool name
+
+	// Install replaces installing the tool packages, for tools that need
+	// more than the package manager. It only runs when the tool is missing.
+	Install func(m *Model, stepID string) error
+	// Setup runs once the files are copied, to fit them to the other choices
+	Setup func(m *Model, stepID string) error
+}
+
+// toolSpecs holds the spec of every tool in system.Tools, by ID
+var toolSpecs = map[string]toolSpec{
+	"alacritty": {
+		Label:   alacrittyLabel,
+		Install: installAlacritty,
+		Info: ToolInfo{
+			Name:        "Alacritty",
+			Description: "GPU-accelerated terminal emulator focused on simplicity and performance",
+			Pros: []string{
+				"Fastest terminal emulator (GPU rendering)",
+				"Very low latency",
+				"Simple TOML configuration",
+				"Cross-platform (Linux, macOS, Windows)",
+				"Low memory usage",
+			},
+			Cons: []string{
+				"No tabs/splits (use tmux/zellij)",
+				"No ligatures support",
+				"No built-in scrollback search (needs tmux)",
+				"Minimal features by design",
+			},
+			Website: "https://alacritty.org",
+		},
+	},
+	"wezterm": {
+		Install: installWezTerm,
+		Info: ToolInfo{
+			Name:        "WezTerm",
+			Description: "GPU-accelerated terminal with built-in multiplexer, configured in Lua",
+			Pros: []string{
+				"Built-in tabs and splits (no tmux needed)",
+				"Lua configuration (very flexible)",
+				"Ligatures and font fallback support",
+				"Image protocol support (sixel, iTerm2)",
+				"SSH multiplexing built-in",
+				"Excellent documentation",
+			},
+			Cons: []string{
+				"Higher memory usage than Alacritty",
+				"Lua config can be complex",
+				"Slightly higher latency",
+			},
+			Website: "https://wezfurlong.org/wezterm/",
+		},
+	},
+	"kitty": {
+		Available: func(m Model) bool { return m.Choices.OS == "mac" },
+		Install:   installKitty,
+		Info: ToolInfo{
+			Name:        "Kitty",
+			Description: "Fast, feature-rich terminal with graphics protocol support",
+			Pros: []string{
+				"Very fast (GPU accelerated)",
+				"Native image display (kitty protocol)",
+				"Ligatures support",
+				"Built-in tabs and layouts",
+				"Kitten extensions system",
+				"Great for image-heavy workflows",
+			},
+			Cons: []string{
+				"macOS only in this installer",
+				"Custom config format",
+				"Some apps need kitty-specific config",
+			},
+			Website: "https://sw.kovidgoyal.net/kitty/",
+		},
+	},
+	"ghostty": {
+		Install:   installGhostty,
+		Keymaps:   GetGhosttyKeymaps,
+		KeymapsAt: ScreenKeymapsGhostty,
+		Info: ToolInfo{
+			Name:        "Ghostty",
+			Description: "Native terminal by Mitchell Hashimoto (Hashicorp founder)",
+			Pros: []string{
+				"Native performance (not Electron)",
+				"Zero config needed to start",
+				"Native macOS/Linux look and feel",
+				"Built-in splits and tabs",
+				"Very fast rendering",
+				"Modern codebase (Zig)",
+			},
+			Cons: []string{
+				"Relatively new project",
+				"Smaller community",
+				"Fewer customization options (for now)",
+			},
+			Website: "https://ghostty.org",
+		},
+	},
+	"fish": {
+		Setup: setupFish,
+		Info: ToolInfo{
+			Name:        "Fish",
+			Description: "Friendly Interactive SHell - user-friendly with great defaults",
+			Pros: []string{
+				"Amazing autosuggestions out of the box",
+				"Syntax highlighting by default",
+				"Web-based configuration UI",
+				"Great error messages",
+				"No configuration needed to be productive",
+				"Fast and responsive",
+			},
+			Cons: []string{
+				"Not POSIX compliant (scripts differ)",
+				"Can't run bash scripts directly",
+				"Smaller plugin ecosystem than zsh",
+			},
+			Website: "https://fishshell.com",
+		},
+	},
+	"zsh": {
+		Setup: func(m *Model, stepID string) error {
+			return system.PatchZshForWM(filepath.Join(os.Getenv("HOME"), ".zshrc"), m.Choices.WindowMgr, m.Choices.InstallNvim)
+		},
+		Info: ToolInfo{
+			Name:        "Zsh",
+			Description: "Z Shell - powerful and highly customizable, POSIX-like",
+			Pros: []string{
+				"POSIX compatible (bash scripts work)",
+				"Huge plugin ecosystem (oh-my-zsh)",
+				"PowerLevel10k for amazing prompts",
+				"Very mature and stable",
+				"Great completion system",
+				"Default on macOS",
+			},
+			Cons: []string{
+				"Slow startup if misconfigured",
+				"Needs plugins for good defaults",
+				"Configuration can be complex",
+			},
+			Website: "https://www.zsh.org",
+		},
+	},
+	"nushell": {
+		Setup: func(m *Model, stepID string) error {
+			return system.PatchNushellForWM(filepath.Join(toolFileDest("nushell", "GentlemanNushell"), "config.nu"), m.Choices.WindowMgr)
+		},
+		Info: ToolInfo{
+			Name:        "Nushell",
+			Description: "Modern shell with structured data - thinks in tables, not text",
+			Pros: []string{
+				"Data-first: output is structured (tables)",
+				"Built-in support for JSON, YAML, TOML",
+				"Pipeline operations like filter, select, sort",
+				"Cross-platform consistency",
+				"Modern, clean syntax",
+				"Great for data manipulation",
+			},
+			Cons: []string{
+				"Not POSIX compatible at all",
+				"Steeper learning curve",
+				"Smaller ecosystem",
+				"Some tools need wrappers",
+			},
+			Website: "https://www.nushell.sh",
+		},
+	},
+	"bash": {
+		Setup: func(m *Model, stepID string) error {
+			return system.PatchBashForWM(filepath.Join(os.Getenv("HOME"), ".bashrc"), m.Choices.WindowMgr)
+		},
+		Info: ToolInfo{
+			Name:        "Bash",
+			Description: "The classic POSIX shell - already on almost every machine",
+			Pros: []string{
+				"Preinstalled on Linux, macOS and Termux",
+				"Scripts you write run everywhere",
+				"Every tutorial and answer online assumes it",
+				"Starship, zoxide, atuin and fzf work the same",
+				"No new syntax to learn",
+			},
+			Cons: []string{
+				"Plain defaults without the extras",
+				"No autosuggestions or syntax highlighting built in",
+				"Completion needs carapace or bash-completion",
+				"macOS ships an old 3.2 release",
+			},
+			Website: "https://www.gnu.org/software/bash",
+		},
+	},
+	"tmux": {
+		Setup:     setupTmux,
+		Keymaps:   GetTmuxKeymaps,
+		KeymapsAt: ScreenKeymapsTmux,
+		Info: ToolInfo{
+			Name:        "Tmux",
+			Description: "Terminal multiplexer - sessions, windows, and panes",
+			Pros: []string{
+				"Industry standard, everywhere",
+				"Persistent sessions (survives disconnects)",
+				"Huge plugin ecosystem (TPM)",
+				"Remote pairing support",
+				"Scriptable and automatable",
+				"Very stable and mature",
+			},
+			Cons: []string{
+				"Steep learning curve",
+				"Default keybindings are awkward",
+				"Configuration syntax is dated",
+				"No native mouse support (needs config)",
+			},
+			Website: "https://github.com/tmux/tmux",
+		},
+	},
+	"zellij": {
+		Setup:     setupZellij,
+		Keymaps:   GetZellijKeymaps,
+		KeymapsAt: ScreenKeymapsZellij,
+		Info: ToolInfo{
+			Name:        "Zellij",
+			Description: "Modern terminal workspace - batteries included",
+			Pros: []string{
+				"Great UI out of the box",
+				"Floating panes and tabs",
+				"WebAssembly plugins",
+				"Built-in session manager",
+				"Discoverable keybindings (shows hints)",
+				"Modern and actively developed",
+			},
+			Cons: []string{
+				"Younger project than tmux",
+				"Smaller plugin ecosystem",
+				"Higher memory usage",
+				"Less ubiquitous on servers",
+			},
+			Website: "https://zellij.dev",
+		},
+	},
+}
+
+// toolOptions returns the menu entries of the tools of a category offered
+// on this system
+func (m Model) toolOptions(category system.ToolCategory) []string {
+	var options []string
+	for _, tool := range system.ToolsIn(category) {
+		spec := toolSpecs[tool.ID]
+		if spec.Available != nil && !spec.Available(m) {
+			continue
+		}
+		label := tool.Name
+		if spec.Label != nil {
+			label = spec.Label(m)
+		}
+		options = append(options, label)
+	}
+	return options
+}
+
+// toolNames returns the names of every tool of a category, for the Learn
+// screens
+func toolNames(category system.ToolCategory) []string {
+	var names []string
+	for _, tool := range system.ToolsIn(category) {
+		names = append(names, tool.Name)
+	}
+	return names
+}
+
+// toolInfo returns the Learn info of the tools of a category, by ID
+func toolInfo(category system.ToolCategory) map[string]ToolInfo {
+	info := make(map[string]ToolInfo)
+	for _, tool := range system.ToolsIn(category) {
+		info[tool.ID] = toolSpecs[tool.ID].Info
+	}
+	return info
+}
+
+// toolKeymaps returns the keymap reference of a tool
+func toolKeymaps(id string) []KeymapCategory {
+	if spec := toolSpecs[id]; spec.Keymaps != nil {
+		return spec.Keymaps()
+	}
+	return nil
+}
+
+// keymapTools returns the tools with a keymap reference, in registry order
+func keymapTools() []system.Tool {
+	var tools []system.Tool
+	for _, tool := range system.Tools {
+		if toolSpecs[tool.ID].Keymaps != nil {
+			tools = append(tools, tool)
+		}
+	}
+	return tools
+}
+
+// shellCommand returns the binary of a shell choice, "" for none
+func shellCommand(shell string) string {
+	if tool, ok := system.FindTool(shell); ok && tool.Category == system.ToolShell {
+		return tool.Command
+	}
+	return ""
+}
+
+// toolFileDest returns where a tool installs one of its repository files
+func toolFileDest(id, source string) string {
+	tool, _ := system.FindTool(id)
+	for _, f := range tool.Files {
+		if f.Source == source {
+			return f.DestPath()
+		}
+	}
+	return ""
+}
+
+// installTool installs the tool chosen for a step: its packages (or its
+// own install), its files and then its setup. Choices that are not a
+// registered tool, like "none", install nothing.
+func installTool(m *Model, stepID, id string) error {
+	tool, ok := system.FindTool(id)
+	if !ok {
+		return nil
+	}
+	spec := toolSpecs[id]
+	action := "Install " + tool.Name
+	logLine := func(line string) {
+		SendLog(stepID, line)
+	}
+
+	// Shells install their companion tools even when the shell is there
+	if tool.Category != system.ToolShell && system.CommandExists(tool.Command) {
+		SendLog(stepID, tool.Name+" already installed")
+	} else if spec.Install != nil {
+		SendLog(stepID, fmt.Sprintf("Installing %s...", tool.Name))
+		if err := spec.Install(m, stepID); err != nil {
+			return err
+		}
+	} else {
+		SendLog(stepID, fmt.Sprintf("Installing %s...", tool.Name))
+		manager := system.ToolManagerFor(m.SystemInfo)
+		if result := manager.Install(m.ctx(), logLine, tool.PackagesFor(manager.Name())...); result.Error != nil {
+			return wrapStepError(stepID, action,
+				fmt.Sprintf("Failed to install %s and its dependencies", tool.Name),
+				result.Error)
+		}
+	}
+
+	SendLog(stepID, fmt.Sprintf("Copying %s configuration...", tool.Name))
+	if err := tool.InstallFiles(m.RepoDir); err != nil {
+		return wrapStepError(stepID, action,
+			fmt.Sprintf("Failed to copy %s configuration", tool.Name),
+			err)
+	}
+
+	if spec.Setup != nil {
+		SendLog(stepID, fmt.Sprintf("Configuring %s...", tool.Name))
+		if err := spec.Setup(m, stepID); err != nil {
+			return wrapStepError(stepID, action,
+				fmt.Sprintf("Failed to configure %s", tool.Name),
+				err)
+		}
+	}
+
+	// Termux: list the shell in $PREFIX/etc/shells so tmux doesn't complain
+	if tool.Category == system.ToolShell && m.SystemInfo.IsTermux {
+		SendLog(stepID, fmt.Sprintf("Adding %s to Termux shells...", tool.Command))
+		prefix := termuxPrefix()
+		system.EnsureDir(filepath.Join(prefix, "etc"))
+		system.SetRcBlock(filepath.Join(prefix, "etc", "shells"), system.RcBlockShell(tool.Command), filepath.Join(prefix, "bin", tool.Command))
+	}
+
+	SendLog(stepID, fmt.Sprintf("✓ %s configured", tool.Name))
+	return nil
+}
+
+// termuxPrefix returns the Termux installation prefix
+func termuxPrefix() string {
+	if prefix := os.Getenv("PREFIX"); prefix != "" {
+		return prefix
+	}
+	return "/data/data/com.termux/files/usr"
+}
@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
+)
+
+func TestToolSpecs(t *testing.T) {
+	t.Run("every tool has a spec", func(t *testing.T) {
+		for _, tool := range system.Tools {
+			spec, ok := toolSpecs[tool.ID]
+			if !ok {
+				t.Errorf("no toolSpec for %q", tool.ID)
+				continue
+			}
+			if spec.Info.Name != tool.Name {
+				t.Errorf("%s: info name %q, registry name %q", tool.ID, spec.Info.Name, tool.Name)
+			}
+			if spec.Keymaps != nil && spec.KeymapsAt == 0 {
+				t.Errorf("%s has keymaps but no screen to show them", tool.ID)
+			}
+		}
+	})
+
+	t.Run("no spec without a tool", func(t *testing.T) {
+		for id := range toolSpecs {
+			if _, ok := system.FindTool(id); !ok {
+				t.Errorf("toolSpec %q has no registry entry", id)
+			}
+		}
+	})
+
+	t.Run("kitty only offered on mac", func(t *testing.T) {
+		m := NewModel()
+		m.Choices.OS = "linux"
+		if slices.Contains(m.toolOptions(system.ToolTerminal), "Kitty") {
+			t.Error("Kitty should not be offered on linux")
+		}
+		m.Choices.OS = "mac"
+		if !slices.Contains(m.toolOptions(system.ToolTerminal), "Kitty") {
+			t.Error("Kitty should be offered on mac")
+		}
+	})
+
+	t.Run("shell commands", func(t *testing.T) {
+		for shell, want := range map[string]string{"nushell": "nu", "fish": "fish", "bash": "bash", "tmux": "", "none": ""} {
+			if got := shellCommand(shell); got != want {
+				t.Errorf("shellCommand(%q) = %q, want %q", shell, got, want)
+			}
+		}
+	})
+
+	t.Run("none installs nothing", func(t *testing.T) {
+		m := NewModel()
+		if err := installTool(&m, "terminal", "none"); err != nil {
+			t.Errorf("installTool(none) = %v", err)
+		}
+	})
+}
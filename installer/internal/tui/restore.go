@@ -0,0 +1,346 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Options of ScreenRestoreConfirm after the entry toggles
+const (
+	restorePreviewOption = "🔍 Preview changes"
+	restoreOption        = "✅ Restore selected"
+	restoreExportOption  = "📤 Export this backup"
+	restoreDeleteOption  = "🗑️  Delete this backup"
+	restoreCancelOption  = "❌ Cancel"
+)
+
+// importBackupOption opens ScreenBackupImport, from the main menu when there
+// are no backups yet and from the backup list
+const importBackupOption = "📥 Import Backup"
+
+// openRestoreConfirm shows the entries of the selected backup, all of them
+// picked
+func (m *Model) openRestoreConfirm(backup int) {
+	m.SelectedBackup = backup
+	m.RestoreSelected = make([]bool, len(m.AvailableBackups[backup].Files))
+	for i := range m.RestoreSelected {
+		m.RestoreSelected[i] = true
+	}
+	m.Screen = ScreenRestoreConfirm
+	m.Cursor = 0
+	m.BackupMsg = ""
+}
+
+// selectedBackup returns the backup picked on ScreenRestoreBackup
+func (m Model) selectedBackup() (system.BackupInfo, bool) {
+	if m.SelectedBackup < 0 || m.SelectedBackup >= len(m.AvailableBackups) {
+		return system.BackupInfo{}, false
+	}
+	return m.AvailableBackups[m.SelectedBackup], true
+}
+
+// selectedRestoreKeys returns the picked entries of the selected backup
+func (m Model) selectedRestoreKeys() []string {
+	backup, ok := m.selectedBackup()
+	if !ok {
+		return nil
+	}
+	keys := []string{}
+	for i, key := range backup.Files {
+		if i < len(m.RestoreSelected) && m.RestoreSelected[i] {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// restoreConfirmOptions lists a toggle per backup entry, then the actions
+func (m Model) restoreConfirmOptions() []string {
+	var opts []string
+	if backup, ok := m.selectedBackup(); ok {
+		configPaths := system.ConfigPaths()
+		for i, key := range backup.Files {
+			label := key
+			if path, ok := configPaths[key]; ok {
+				label += " (" + path + ")"
+			}
+			if i < len(m.RestoreSelected) && m.RestoreSelected[i] {
+				opts = append(opts, "[x] "+label)
+			} else {
+				opts = append(opts, "[ ] "+label)
+			}
+		}
+	}
+	return append(opts, "─────────────", restorePreviewOption, restoreOption, restoreExportOption, restoreDeleteOption, restoreCancelOption)
+}
+
+// previewRestore diffs the selected entries with the current configs
+func (m Model) previewRestore() Model {
+	backup, _ := m.selectedBackup()
+	diffs, err := system.DiffBackup(backup.Path, m.selectedRestoreKeys())
+	text := system.FormatRestoreDiff(diffs, true)
+	if err != nil {
+		text = "❌ " + err.Error() + "\n"
+	}
+	if text == "" {
+		text = "Nothing to restore\n"
+	}
+	m.RestoreDiff = strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	m.RestoreScroll = 0
+	m.Screen = ScreenRestoreDiff
+	return m
+}
+
+// restoreSelected restores the selected entries, keeping the configs they
+// replace for undo
+func (m Model) restoreSelected() Model {
+	backup, _ := m.selectedBackup()
+	snapshot, err := system.RestoreBackupWithUndo(backup.Path, m.selectedRestoreKeys())
+	if err != nil {
+		m.Screen = ScreenError
+		m.ErrorMsg = "Failed to restore backup: " + err.Error()
+		return m
+	}
+	m.RestoreSnapshot = snapshot
+	m.RestoreUndone = false
+	m.Screen = ScreenRestoreResult
+	m.Cursor = 0
+	return m
+}
+
+func (m Model) handleRestoreDiffKeys(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "up", "k":
+		if m.RestoreScroll > 0 {
+			m.RestoreScroll--
+		}
+	case "down", "j":
+		if m.RestoreScroll < len(m.RestoreDiff)-1 {
+			m.RestoreScroll++
+		}
+	case "r":
+		return m.restoreSelected(), nil
+	case "enter":
+		m.Screen = ScreenRestoreConfirm
+	}
+	return m, nil
+}
+
+func (m Model) handleRestoreResultKeys(key string) (tea.Model, tea.Cmd) {
+	options := m.GetCurrentOptions()
+
+	switch key {
+	case "up", "k":
+		if m.Cursor > 0 {
+			m.Cursor--
+		}
+	case "down", "j":
+		if m.Cursor < len(options)-1 {
+			m.Cursor++
+		}
+	case "enter":
+		if strings.Contains(options[m.Cursor], "Undo") {
+			if err := system.UndoRestore(m.RestoreSnapshot); err != nil {
+				m.Screen = ScreenError
+				m.ErrorMsg = "Failed to undo the restore: " + err.Error()
+				return m, nil
+			}
+			m.RestoreUndone = true
+			m.Cursor = 0
+			return m, nil
+		}
+		return m.leaveRestoreResult(), nil
+	}
+	return m, nil
+}
+
+// leaveRestoreResult goes back to the main menu, listing the backups again
+// now that the restore added a snapshot
+func (m Model) leaveRestoreResult() Model {
+	m.AvailableBackups = system.ListBackups()
+	m.Screen = ScreenMainMenu
+	m.Cursor = 0
+	return m
+}
+
+func (m Model) renderRestoreDiff() string {
+	var s strings.Builder
+
+	s.WriteString(TitleStyle.Render(m.GetScreenTitle()))
+	s.WriteString("\n\n")
+
+	// Reserve space for: title(2) + scroll info(1) + help(2)
+	height := max(m.Height-5, 5)
+	start := min(m.RestoreScroll, max(len(m.RestoreDiff)-height, 0))
+	end := min(start+height, len(m.RestoreDiff))
+	for _, line := range m.RestoreDiff[start:end] {
+		style := InfoStyle
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"), strings.HasPrefix(line, "@@"):
+			style = MutedStyle
+		case strings.HasPrefix(line, "+"):
+			style = SuccessStyle
+		case strings.HasPrefix(line, "-"):
+			style = ErrorStyle
+		case !strings.HasPrefix(line, " "):
+			style = SubtitleStyle
+		}
+		s.WriteString(style.Render(line))
+		s.WriteString("\n")
+	}
+	if len(m.RestoreDiff) > height {
+		s.WriteString(MutedStyle.Render(fmt.Sprintf("[%d-%d of %d]", start+1, end, len(m.RestoreDiff))))
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	s.WriteString(HelpStyle.Render("↑/↓ scroll • [r] restore • [Enter/Esc] back"))
+
+	return s.String()
+}
+
+func (m Model) renderRestoreResult() string {
+	var s strings.Builder
+
+	s.WriteString(TitleStyle.Render(m.GetScreenTitle()))
+	s.WriteString("\n\n")
+
+	if m.RestoreUndone {
+		s.WriteString(SuccessStyle.Render("↩️  Restore undone: your previous configs are back"))
+		s.WriteString("\n\n")
+	} else {
+		s.WriteString(SuccessStyle.Render("✅ Restored: " + strings.Join(m.selectedRestoreKeys(), ", ")))
+		s.WriteString("\n")
+		if m.RestoreSnapshot != "" {
+			s.WriteString(InfoStyle.Render("💾 The configs it replaced were saved to: " + m.RestoreSnapshot))
+			s.WriteString("\n")
+		}
+		s.WriteString("\n")
+	}
+
+	for i, opt := range m.GetCurrentOptions() {
+		cursor := "  "
+		style := UnselectedStyle
+		if i == m.Cursor {
+			cursor = "▸ "
+			style = SelectedStyle
+		}
+		s.WriteString(style.Render(cursor + opt))
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	s.WriteString(HelpStyle.Render("↑/k up • ↓/j down • [Enter] select • [Esc] back"))
+
+	return s.String()
+}
+
+// exportBackup writes a backup to an archive in the home directory and
+// describes the outcome
+func exportBackup(backup system.BackupInfo) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "❌ " + err.Error()
+	}
+	dst := filepath.Join(home, "gentleman-backup-"+backup.ID()+".tar.gz")
+	if err := system.ExportBackup(backup.Path, dst); err != nil {
+		return "❌ Export failed: " + err.Error()
+	}
+	return "📤 Exported to " + dst
+}
+
+// openBackupImport asks for the path of an exported backup
+func (m *Model) openBackupImport() {
+	m.ImportPath = ""
+	m.BackupMsg = ""
+	m.Screen = ScreenBackupImport
+	m.Cursor = 0
+}
+
+// leaveBackupImport returns to the backup list, or to the main menu while
+// there is nothing to list
+func (m *Model) leaveBackupImport() {
+	m.Cursor = 0
+	if len(m.AvailableBackups) == 0 {
+		m.Screen = ScreenMainMenu
+		return
+	}
+	m.Screen = ScreenRestoreBackup
+}
+
+func (m Model) handleBackupImportKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.BackupMsg = ""
+		m.leaveBackupImport()
+	case tea.KeyEnter:
+		path := strings.TrimSpace(m.ImportPath)
+		if path == "" {
+			return m, nil
+		}
+		dir, err := system.ImportBackup(expandPath(path))
+		if err != nil {
+			m.BackupMsg = "❌ " + err.Error()
+			return m, nil
+		}
+		m.AvailableBackups = system.ListBackups()
+		m.BackupMsg = "📥 Imported " + filepath.Base(path)
+		m.leaveBackupImport()
+		for i, backup := range m.AvailableBackups {
+			if backup.Path == dir {
+				m.Cursor = i
+			}
+		}
+	case tea.KeyBackspace:
+		if len(m.ImportPath) > 0 {
+			runes := []rune(m.ImportPath)
+			m.ImportPath = string(runes[:len(runes)-1])
+		}
+	case tea.KeySpace:
+		m.ImportPath += " "
+	case tea.KeyRunes:
+		m.ImportPath += string(msg.Runes)
+	}
+	return m, nil
+}
+
+func (m Model) renderBackupImport() string {
+	var s strings.Builder
+
+	s.WriteString(TitleStyle.Render(m.GetScreenTitle()))
+	s.WriteString("\n")
+	s.WriteString(MutedStyle.Render("Path of a backup made with \"backup export\" or the 📤 Export action"))
+	s.WriteString("\n\n")
+
+	s.WriteString(SelectedStyle.Render("▸ " + m.ImportPath + "█"))
+	s.WriteString("\n")
+	if m.BackupMsg != "" {
+		s.WriteString("\n")
+		s.WriteString(ErrorStyle.Render(m.BackupMsg))
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	s.WriteString(MutedStyle.Render("The archive is checked against its manifest before it is added to your backups."))
+	s.WriteString("\n\n")
+	s.WriteString(HelpStyle.Render("[Enter] import • [Esc] back"))
+
+	return s.String()
+}
+
+// renderBackupMsg shows the outcome of the last export or import
+func (m Model) renderBackupMsg() string {
+	if m.BackupMsg == "" {
+		return ""
+	}
+	style := SuccessStyle
+	if strings.HasPrefix(m.BackupMsg, "❌") {
+		style = ErrorStyle
+	}
+	return style.Render(m.BackupMsg) + "\n\n"
+}
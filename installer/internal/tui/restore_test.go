@@ -0,0 +1,127 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
+)
+
+func TestSelectiveRestore(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+
+	tmux := filepath.Join(home, ".tmux.conf")
+	zshrc := filepath.Join(home, ".zshrc")
+	os.WriteFile(tmux, []byte("set -g mouse on\n"), 0644)
+	os.WriteFile(zshrc, []byte("export EDITOR=nvim\n"), 0644)
+	if _, err := system.CreateBackupFor([]string{"tmux", "zsh"}, "install", nil); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(tmux, []byte("set -g mouse off\n"), 0644)
+	os.WriteFile(zshrc, []byte("export EDITOR=vim\n"), 0644)
+	read := func(path string) string {
+		data, _ := os.ReadFile(path)
+		return string(data)
+	}
+
+	m := NewModel()
+	m.AvailableBackups = system.ListBackups()
+	m.Screen = ScreenRestoreBackup
+	m = press(m, "enter")
+	if m.Screen != ScreenRestoreConfirm || !slices.Equal(m.selectedRestoreKeys(), []string{"tmux", "zsh"}) {
+		t.Fatalf("every entry should start picked, got screen %v keys %v", m.Screen, m.selectedRestoreKeys())
+	}
+
+	// Leave zsh out
+	m = press(m, "j", " ")
+	if !slices.Equal(m.selectedRestoreKeys(), []string{"tmux"}) {
+		t.Fatalf("space should toggle the entry, got %v", m.selectedRestoreKeys())
+	}
+
+	m.Cursor = slices.Index(m.GetCurrentOptions(), restorePreviewOption)
+	m = press(m, "enter")
+	preview := strings.Join(m.RestoreDiff, "\n")
+	if m.Screen != ScreenRestoreDiff || !strings.Contains(preview, "-set -g mouse off\n+set -g mouse on") || strings.Contains(preview, "EDITOR") {
+		t.Fatalf("expected the tmux diff only, got screen %v:\n%s", m.Screen, preview)
+	}
+	if m = press(m, "esc"); m.Screen != ScreenRestoreConfirm {
+		t.Fatalf("esc should go back to the entries, got %v", m.Screen)
+	}
+
+	m.Cursor = slices.Index(m.GetCurrentOptions(), restoreOption)
+	m = press(m, "enter")
+	if m.Screen != ScreenRestoreResult || m.RestoreSnapshot == "" {
+		t.Fatalf("expected the result with a snapshot, got screen %v: %s", m.Screen, m.ErrorMsg)
+	}
+	if read(tmux) != "set -g mouse on\n" || read(zshrc) != "export EDITOR=vim\n" {
+		t.Error("only tmux should be restored")
+	}
+	if view := m.View(); !strings.Contains(view, "Restored: tmux") {
+		t.Errorf("view should list the restored entries:\n%s", view)
+	}
+
+	m = press(m, "enter") // Undo restore
+	if !m.RestoreUndone || read(tmux) != "set -g mouse off\n" {
+		t.Errorf("undo should put the replaced config back, got %q", read(tmux))
+	}
+	if opts := m.GetCurrentOptions(); len(opts) != 1 {
+		t.Errorf("a restore can only be undone once, got %v", opts)
+	}
+
+	m = press(m, "enter")
+	if m.Screen != ScreenMainMenu || len(m.AvailableBackups) != 2 {
+		t.Errorf("leaving should list the snapshot too, got screen %v and %d backups", m.Screen, len(m.AvailableBackups))
+	}
+}
+
+func TestBackupExportImport(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+
+	os.WriteFile(filepath.Join(home, ".tmux.conf"), []byte("set -g mouse on\n"), 0644)
+	backupDir, err := system.CreateBackupFor([]string{"tmux"}, "install", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewModel()
+	m.AvailableBackups = system.ListBackups()
+	m.Screen = ScreenRestoreBackup
+	m = press(m, "enter")
+	m.Cursor = slices.Index(m.GetCurrentOptions(), restoreExportOption)
+	m = press(m, "enter")
+	archive := filepath.Join(home, "gentleman-backup-"+m.AvailableBackups[0].ID()+".tar.gz")
+	if _, err := os.Stat(archive); err != nil || !strings.Contains(m.View(), "Exported to "+archive) {
+		t.Fatalf("expected the archive in the home directory, got %q, %v", m.BackupMsg, err)
+	}
+
+	// A fresh machine has no backups: the main menu offers the import
+	os.RemoveAll(backupDir)
+	m.AvailableBackups = nil
+	m.Screen = ScreenMainMenu
+	m.Cursor = slices.Index(m.GetCurrentOptions(), importBackupOption)
+	m = press(m, "enter")
+	if m.Screen != ScreenBackupImport {
+		t.Fatalf("expected the import screen, got %v", m.Screen)
+	}
+
+	m = press(m, "~/missing.tar.gz", "enter")
+	if m.Screen != ScreenBackupImport || !strings.HasPrefix(m.BackupMsg, "❌") {
+		t.Fatalf("a bad path should stay on the screen with an error, got %v %q", m.Screen, m.BackupMsg)
+	}
+
+	m.ImportPath = ""
+	m = press(m, "~/"+filepath.Base(archive), "enter")
+	if m.Screen != ScreenRestoreBackup || len(m.AvailableBackups) != 1 || m.Cursor != 0 {
+		t.Fatalf("expected the imported backup picked in the list, got screen %v with %d backups: %s", m.Screen, len(m.AvailableBackups), m.BackupMsg)
+	}
+	if imported := m.AvailableBackups[0]; imported.Manifest == nil || imported.Manifest.Imported != filepath.Base(archive) {
+		t.Errorf("the backup should record where it came from, got %+v", imported.Manifest)
+	}
+}
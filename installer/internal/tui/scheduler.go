@@ -0,0 +1,174 @@
+package tui
+
+import (
+	"slices"
+
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
+)
+
+// DefaultJobs is how many install steps run at the same time by default
+const DefaultJobs = 4
+
+// jobs is the worker limit for concurrent install steps
+var jobs = DefaultJobs
+
+// SetJobs sets how many non-interactive steps may run at the same time
+func SetJobs(n int) {
+	jobs = max(n, 1)
+}
+
+// effectiveJobs is the worker limit for this run. Dry runs record a single
+// global plan that is split by step, so their steps run one at a time.
+func effectiveJobs() int {
+	if system.IsDryRun() {
+		return 1
+	}
+	return jobs
+}
+
+// allSteps as a dependency makes a step wait for every other step
+const allSteps = "*"
+
+// stepDependencies lists the steps each step waits for. Dependencies that
+// are not part of the installation are ignored. Steps missing here wait for
+// every step before them. The font and theme steps both rewrite the terminal
+// and Zed configs, so the theme waits for the font.
+var stepDependencies = map[string][]string{
+	"backup":      {},
+	"clone":       {"backup"},
+	"homebrew":    {"clone"},
+	"deps":        {"clone", "homebrew"},
+	"xcode":       {"clone"},
+	"terminal":    {"deps", "xcode"},
+	"font":        {"deps", "xcode", "terminal", "zed"},
+	"shell":       {"deps", "xcode"},
+	"wm":          {"deps", "xcode"},
+	"nvim":        {"deps", "xcode"},
+	"zed":         {"deps", "xcode"},
+	"aitools":     {"deps", "xcode"},
+	"engram":      {"aitools"},
+	"aiframework": {"aitools"},
+	"theme":       {"terminal", "shell", "wm", "nvim", "zed", "aitools", "font"},
+	"setshell":    {"shell"},
+	"cleanup":     {allSteps},
+}
+
+// dependenciesFor resolves the steps that steps[i] waits for
+func dependenciesFor(steps []InstallStep, i int) []string {
+	declared, ok := stepDependencies[steps[i].ID]
+	if !ok {
+		var previous []string
+		for _, step := range steps[:i] {
+			previous = append(previous, step.ID)
+		}
+		return previous
+	}
+
+	var deps []string
+	for _, step := range steps {
+		if step.ID == steps[i].ID {
+			continue
+		}
+		if slices.Contains(declared, allSteps) || slices.Contains(declared, step.ID) {
+			deps = append(deps, step.ID)
+		}
+	}
+	return deps
+}
+
+// withDependencies fills in DependsOn for every step of an installation
+func withDependencies(steps []InstallStep) []InstallStep {
+	for i := range steps {
+		steps[i].DependsOn = dependenciesFor(steps, i)
+	}
+	return steps
+}
+
+// stepDeps returns the declared dependencies of steps[i], falling back to
+// the dependency table for steps built without them
+func stepDeps(steps []InstallStep, i int) []string {
+	if steps[i].DependsOn != nil {
+		return steps[i].DependsOn
+	}
+	return dependenciesFor(steps, i)
+}
+
+// isFinished reports whether a step no longer blocks the ones after it
+func isFinished(status StepStatus) bool {
+	return status == StatusDone || status == StatusSkipped
+}
+
+// isReady reports whether a pending step has all its dependencies finished
+func isReady(steps []InstallStep, i int) bool {
+	if steps[i].Status != StatusPending {
+		return false
+	}
+	for _, dep := range stepDeps(steps, i) {
+		for _, step := range steps {
+			if step.ID == dep && !isFinished(step.Status) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// runsAlone reports whether a step needs the terminal to itself
+func runsAlone(step InstallStep) bool {
+	return step.Interactive && !system.IsDryRun()
+}
+
+// nextSteps returns the indexes of the steps to start now: ready steps up
+// to the worker limit. Interactive steps only start when nothing else runs,
+// and nothing starts next to them.
+func nextSteps(steps []InstallStep, limit int) []int {
+	running := 0
+	for _, step := range steps {
+		if step.Status == StatusRunning {
+			if runsAlone(step) {
+				return nil
+			}
+			running++
+		}
+	}
+
+	var next []int
+	for i, step := range steps {
+		if running+len(next) >= limit {
+			break
+		}
+		if !isReady(steps, i) {
+			continue
+		}
+		if runsAlone(step) {
+			if running == 0 && len(next) == 0 {
+				return []int{i}
+			}
+			continue
+		}
+		next = append(next, i)
+	}
+	return next
+}
+
+// runningSteps counts the steps that are running
+func runningSteps(steps []InstallStep) int {
+	n := 0
+	for _, step := range steps {
+		if step.Status == StatusRunning {
+			n++
+		}
+	}
+	return n
+}
+
+// firstUnfinished returns the index of the first step that has not
+// finished, or len(steps) when all have
+func firstUnfinished(steps []InstallStep) int {
+	for i, step := range steps {
+		if !isFinished(step.Status) {
+			return i
+		}
+	}
+	return len(steps)
+}
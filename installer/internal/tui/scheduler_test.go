@@ -0,0 +1,202 @@
+package tui
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
+)
+
+// parallelSteps returns independent steps, each ready to run
+func parallelSteps(ids ...string) []InstallStep {
+	var steps []InstallStep
+	for _, id := range ids {
+		steps = append(steps, InstallStep{ID: id, Name: "Step " + id, DependsOn: []string{}})
+	}
+	return steps
+}
+
+func TestStepDependencies(t *testing.T) {
+	steps := withDependencies([]InstallStep{
+		{ID: "backup"}, {ID: "clone"}, {ID: "deps"}, {ID: "terminal"}, {ID: "shell"}, {ID: "custom"}, {ID: "cleanup"},
+	})
+	deps := map[string][]string{}
+	for _, step := range steps {
+		deps[step.ID] = step.DependsOn
+	}
+
+	if !slices.Equal(deps["clone"], []string{"backup"}) {
+		t.Errorf("clone should wait for backup, got %v", deps["clone"])
+	}
+	if !slices.Equal(deps["terminal"], []string{"deps"}) {
+		t.Errorf("dependencies outside the installation should be dropped, got %v", deps["terminal"])
+	}
+	if !slices.Equal(deps["custom"], []string{"backup", "clone", "deps", "terminal", "shell"}) {
+		t.Errorf("unknown steps should wait for every previous step, got %v", deps["custom"])
+	}
+	if len(deps["cleanup"]) != len(steps)-1 {
+		t.Errorf("cleanup should wait for all steps, got %v", deps["cleanup"])
+	}
+}
+
+func TestThemeWaitsForFont(t *testing.T) {
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+
+	steps := withDependencies([]InstallStep{
+		{ID: "clone", Status: StatusDone}, {ID: "deps", Status: StatusDone},
+		{ID: "terminal", Status: StatusDone}, {ID: "zed", Status: StatusDone},
+		{ID: "theme"}, {ID: "font"},
+	})
+	if got := nextSteps(steps, 4); !slices.Equal(got, []int{5}) {
+		t.Errorf("only the font should start, got %v", got)
+	}
+	steps[5].Status = StatusRunning
+	if got := nextSteps(steps, 4); got != nil {
+		t.Errorf("the theme should not run next to the font, got %v", got)
+	}
+	steps[5].Status = StatusDone
+	if got := nextSteps(steps, 4); !slices.Equal(got, []int{4}) {
+		t.Errorf("the theme should start once the font is done, got %v", got)
+	}
+}
+
+func TestNextSteps(t *testing.T) {
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+
+	t.Run("ready steps start up to the limit", func(t *testing.T) {
+		steps := withDependencies([]InstallStep{
+			{ID: "clone", Status: StatusDone}, {ID: "deps", Status: StatusDone},
+			{ID: "font"}, {ID: "shell"}, {ID: "nvim"}, {ID: "setshell"}, {ID: "cleanup"},
+		})
+		if got := nextSteps(steps, 2); !slices.Equal(got, []int{2, 3}) {
+			t.Errorf("expected font and shell, got %v", got)
+		}
+		steps[2].Status = StatusRunning
+		if got := nextSteps(steps, 2); !slices.Equal(got, []int{3}) {
+			t.Errorf("a running step takes a worker, got %v", got)
+		}
+		if got := nextSteps(steps, 10); !slices.Equal(got, []int{3, 4}) {
+			t.Errorf("setshell and cleanup should wait for their dependencies, got %v", got)
+		}
+	})
+
+	t.Run("interactive steps run alone", func(t *testing.T) {
+		steps := parallelSteps("a", "b", "c")
+		steps[1].Interactive = true
+		if got := nextSteps(steps, 4); !slices.Equal(got, []int{0, 2}) {
+			t.Errorf("interactive step should not start next to others, got %v", got)
+		}
+
+		steps[0].Status = StatusDone
+		steps[2].Status = StatusDone
+		if got := nextSteps(steps, 4); !slices.Equal(got, []int{1}) {
+			t.Errorf("interactive step should start once nothing runs, got %v", got)
+		}
+
+		steps = parallelSteps("a", "b")
+		steps[0].Interactive = true
+		steps[0].Status = StatusRunning
+		if got := nextSteps(steps, 4); got != nil {
+			t.Errorf("nothing should start next to an interactive step, got %v", got)
+		}
+	})
+
+	t.Run("dry run uses a single worker", func(t *testing.T) {
+		defer SetJobs(DefaultJobs)
+		SetJobs(8)
+		if effectiveJobs() != 8 {
+			t.Errorf("expected 8 jobs, got %d", effectiveJobs())
+		}
+		SetJobs(0)
+		if effectiveJobs() != 1 {
+			t.Errorf("jobs should be at least 1, got %d", effectiveJobs())
+		}
+		SetJobs(8)
+		t.Setenv("GENTLEMAN_DRY_RUN", "1")
+		if effectiveJobs() != 1 {
+			t.Errorf("dry run should force 1 job, got %d", effectiveJobs())
+		}
+	})
+}
+
+func TestParallelInstall(t *testing.T) {
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+
+	t.Run("several steps run at once", func(t *testing.T) {
+		m := NewModel()
+		m.Screen = ScreenInstalling
+		m.Steps = parallelSteps("a", "b", "c")
+
+		if cmd := m.scheduleSteps(); cmd == nil {
+			t.Fatal("expected the steps to start")
+		}
+		if runningSteps(m.Steps) != 3 {
+			t.Fatalf("expected all independent steps to run, got %d", runningSteps(m.Steps))
+		}
+		view := m.View()
+		for _, step := range m.Steps {
+			if !strings.Contains(view, step.Name) {
+				t.Errorf("view should list running step %s", step.Name)
+			}
+		}
+	})
+
+	t.Run("failure waits for running steps", func(t *testing.T) {
+		m := NewModel()
+		m.Screen = ScreenInstalling
+		m.Steps = parallelSteps("a", "b", "c")
+		m.Steps[0].Status = StatusRunning
+		m.Steps[1].Status = StatusRunning
+		m.Steps[2].Status = StatusRunning
+
+		result, cmd := m.Update(stepCompleteMsg{stepID: "a", err: errors.New("boom")})
+		m = result.(Model)
+		if m.Screen != ScreenError || cmd != nil {
+			t.Fatalf("expected the error screen and nothing new started, got %v", m.Screen)
+		}
+		if !strings.Contains(m.View(), "2 other step(s) still running") {
+			t.Error("error screen should mention the running steps")
+		}
+
+		result, _ = m.Update(stepCompleteMsg{stepID: "b", err: errors.New("also broken")})
+		m = result.(Model)
+		if !strings.Contains(m.ErrorMsg, "Step a") {
+			t.Errorf("the first failure should stay on screen, got %q", m.ErrorMsg)
+		}
+
+		result, _ = m.Update(stepCompleteMsg{stepID: "c"})
+		m = result.(Model)
+		m = press(m, "s")
+		if m.Screen != ScreenError || !strings.Contains(m.ErrorMsg, "Step b") {
+			t.Errorf("skipping should show the next failure, got %v %q", m.Screen, m.ErrorMsg)
+		}
+	})
+}
+
+func TestParallelNonInteractive(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+	buf := captureEvents(t)
+	defer CloseSessionLog()
+	defer SetErrorPolicy(ErrorPolicy{Action: ErrorAbort})
+	SetErrorPolicy(ErrorPolicy{Action: ErrorSkip})
+
+	model := &Model{SystemInfo: &system.SystemInfo{}, RepoDir: t.TempDir()}
+	if err := runStepsNonInteractive(model, parallelSteps("x1", "x2", "x3", "x4", "x5"), nil); err != nil {
+		t.Fatalf("skipped failures should not abort: %v", err)
+	}
+
+	events := decodeEvents(t, buf)
+	started := 0
+	for _, ev := range events {
+		if ev.Type == EventStepStarted {
+			started++
+		}
+	}
+	summary := events[len(events)-1].Summary
+	if started != 5 || summary == nil || summary.Skipped != 5 {
+		t.Errorf("expected every step to run and be skipped, started %d, summary %+v", started, summary)
+	}
+}
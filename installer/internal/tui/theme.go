@@ -0,0 +1,38 @@
+package tui
+
+import (
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// themeAppliedMsg carries the result of switching the theme
+type themeAppliedMsg struct {
+	theme  string
+	styled []string
+	err    error
+}
+
+// applyThemeCmd switches every installed config to theme id in the
+// background
+func applyThemeCmd(id string) tea.Cmd {
+	return func() tea.Msg {
+		styled, err := system.ApplyTheme(id)
+		return themeAppliedMsg{theme: id, styled: styled, err: err}
+	}
+}
+
+// themeOptions lists the themes, marking the applied one
+func (m Model) themeOptions() []string {
+	var opts []string
+	for _, theme := range system.Themes {
+		label := theme.Name
+		if theme.ID == system.DefaultTheme {
+			label += " (original)"
+		}
+		if theme.ID == m.ThemeCurrent {
+			label += " ✓"
+		}
+		opts = append(opts, label)
+	}
+	return append(opts, "─────────────", "← Back")
+}
@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestThemeScreen(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+	kitty := filepath.Join(home, ".config", "kitty", "kitty.conf")
+	os.MkdirAll(filepath.Dir(kitty), 0755)
+	os.WriteFile(kitty, []byte("background #06080f\n"), 0644)
+
+	m := NewModel()
+	m.Screen = ScreenMainMenu
+	for i, opt := range m.GetCurrentOptions() {
+		if strings.Contains(opt, "Theme") {
+			m.Cursor = i
+		}
+	}
+	m = press(m, "enter")
+	if m.Screen != ScreenTheme || m.ThemeCurrent != system.DefaultTheme || m.Cursor != 0 {
+		t.Fatalf("selecting Theme should open the theme screen on the current theme, got screen %v cursor %d", m.Screen, m.Cursor)
+	}
+	if opts := m.GetCurrentOptions(); !strings.HasSuffix(opts[0], "✓") {
+		t.Errorf("the applied theme should be marked, got %v", opts)
+	}
+
+	m = press(m, "j", "j", "j")
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = result.(Model)
+	if !m.ThemeRunning || cmd == nil {
+		t.Fatal("enter on a theme should apply it")
+	}
+	result, _ = m.Update(cmd())
+	m = result.(Model)
+	if m.ThemeRunning || m.ThemeCurrent != "tokyonight" {
+		t.Errorf("the switch should be done, current %q", m.ThemeCurrent)
+	}
+	if view := m.View(); !strings.Contains(view, "Restyled Kitty") {
+		t.Errorf("view should report the restyled tools:\n%s", view)
+	}
+	if system.CurrentTheme() != "tokyonight" {
+		t.Errorf("CurrentTheme = %q", system.CurrentTheme())
+	}
+
+	if m = press(m, "esc"); m.Screen != ScreenMainMenu {
+		t.Errorf("esc should go back to the main menu, got %v", m.Screen)
+	}
+}
+
+func TestThemeInstallStep(t *testing.T) {
+	m := NewModel()
+	m.Choices = UserChoices{OS: "linux", Terminal: "none", Shell: "fish", WindowMgr: "tmux", Theme: "kanagawa"}
+	m.SetupInstallSteps()
+
+	var deps []string
+	for _, step := range m.Steps {
+		if step.ID == "theme" {
+			deps = step.DependsOn
+		}
+	}
+	if !strings.Contains(strings.Join(deps, ","), "shell") || !strings.Contains(strings.Join(deps, ","), "wm") {
+		t.Errorf("the theme should wait for the configs it styles, depends on %v", deps)
+	}
+
+	if !slices.ContainsFunc(buildStepsForChoices(&m), func(s InstallStep) bool { return s.ID == "theme" }) {
+		t.Error("non-interactive installs should apply the theme too")
+	}
+
+	m.Choices.Theme = ""
+	m.SetupInstallSteps()
+	for _, step := range m.Steps {
+		if step.ID == "theme" {
+			t.Error("no theme step without a theme")
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package tui
 
+import "github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
+
 // ToolInfo contains information about a tool
 type ToolInfo struct {
 	Name        string
@@ -25,185 +27,17 @@ type Keymap struct {
 
 // GetTerminalInfo returns info about terminal emulators
 func GetTerminalInfo() map[string]ToolInfo {
-	return map[string]ToolInfo{
-		"alacritty": {
-			Name:        "Alacritty",
-			Description: "GPU-accelerated terminal emulator focused on simplicity and performance",
-			Pros: []string{
-				"Fastest terminal emulator (GPU rendering)",
-				"Very low latency",
-				"Simple TOML configuration",
-				"Cross-platform (Linux, macOS, Windows)",
-				"Low memory usage",
-			},
-			Cons: []string{
-				"No tabs/splits (use tmux/zellij)",
-				"No ligatures support",
-				"No built-in scrollback search (needs tmux)",
-				"Minimal features by design",
-			},
-			Website: "https://alacritty.org",
-		},
-		"wezterm": {
-			Name:        "WezTerm",
-			Description: "GPU-accelerated terminal with built-in multiplexer, configured in Lua",
-			Pros: []string{
-				"Built-in tabs and splits (no tmux needed)",
-				"Lua configuration (very flexible)",
-				"Ligatures and font fallback support",
-				"Image protocol support (sixel, iTerm2)",
-				"SSH multiplexing built-in",
-				"Excellent documentation",
-			},
-			Cons: []string{
-				"Higher memory usage than Alacritty",
-				"Lua config can be complex",
-				"Slightly higher latency",
-			},
-			Website: "https://wezfurlong.org/wezterm/",
-		},
-		"kitty": {
-			Name:        "Kitty",
-			Description: "Fast, feature-rich terminal with graphics protocol support",
-			Pros: []string{
-				"Very fast (GPU accelerated)",
-				"Native image display (kitty protocol)",
-				"Ligatures support",
-				"Built-in tabs and layouts",
-				"Kitten extensions system",
-				"Great for image-heavy workflows",
-			},
-			Cons: []string{
-				"macOS only in this installer",
-				"Custom config format",
-				"Some apps need kitty-specific config",
-			},
-			Website: "https://sw.kovidgoyal.net/kitty/",
-		},
-		"ghostty": {
-			Name:        "Ghostty",
-			Description: "Native terminal by Mitchell Hashimoto (Hashicorp founder)",
-			Pros: []string{
-				"Native performance (not Electron)",
-				"Zero config needed to start",
-				"Native macOS/Linux look and feel",
-				"Built-in splits and tabs",
-				"Very fast rendering",
-				"Modern codebase (Zig)",
-			},
-			Cons: []string{
-				"Relatively new project",
-				"Smaller community",
-				"Fewer customization options (for now)",
-			},
-			Website: "https://ghostty.org",
-		},
-	}
+	return toolInfo(system.ToolTerminal)
 }
 
 // GetShellInfo returns info about shells
 func GetShellInfo() map[string]ToolInfo {
-	return map[string]ToolInfo{
-		"fish": {
-			Name:        "Fish",
-			Description: "Friendly Interactive SHell - user-friendly with great defaults",
-			Pros: []string{
-				"Amazing autosuggestions out of the box",
-				"Syntax highlighting by default",
-				"Web-based configuration UI",
-				"Great error messages",
-				"No configuration needed to be productive",
-				"Fast and responsive",
-			},
-			Cons: []string{
-				"Not POSIX compliant (scripts differ)",
-				"Can't run bash scripts directly",
-				"Smaller plugin ecosystem than zsh",
-			},
-			Website: "https://fishshell.com",
-		},
-		"zsh": {
-			Name:        "Zsh",
-			Description: "Z Shell - powerful and highly customizable, POSIX-like",
-			Pros: []string{
-				"POSIX compatible (bash scripts work)",
-				"Huge plugin ecosystem (oh-my-zsh)",
-				"PowerLevel10k for amazing prompts",
-				"Very mature and stable",
-				"Great completion system",
-				"Default on macOS",
-			},
-			Cons: []string{
-				"Slow startup if misconfigured",
-				"Needs plugins for good defaults",
-				"Configuration can be complex",
-			},
-			Website: "https://www.zsh.org",
-		},
-		"nushell": {
-			Name:        "Nushell",
-			Description: "Modern shell with structured data - thinks in tables, not text",
-			Pros: []string{
-				"Data-first: output is structured (tables)",
-				"Built-in support for JSON, YAML, TOML",
-				"Pipeline operations like filter, select, sort",
-				"Cross-platform consistency",
-				"Modern, clean syntax",
-				"Great for data manipulation",
-			},
-			Cons: []string{
-				"Not POSIX compatible at all",
-				"Steeper learning curve",
-				"Smaller ecosystem",
-				"Some tools need wrappers",
-			},
-			Website: "https://www.nushell.sh",
-		},
-	}
+	return toolInfo(system.ToolShell)
 }
 
 // GetWMInfo returns info about window managers/multiplexers
 func GetWMInfo() map[string]ToolInfo {
-	return map[string]ToolInfo{
-		"tmux": {
-			Name:        "Tmux",
-			Description: "Terminal multiplexer - sessions, windows, and panes",
-			Pros: []string{
-				"Industry standard, everywhere",
-				"Persistent sessions (survives disconnects)",
-				"Huge plugin ecosystem (TPM)",
-				"Remote pairing support",
-				"Scriptable and automatable",
-				"Very stable and mature",
-			},
-			Cons: []string{
-				"Steep learning curve",
-				"Default keybindings are awkward",
-				"Configuration syntax is dated",
-				"No native mouse support (needs config)",
-			},
-			Website: "https://github.com/tmux/tmux",
-		},
-		"zellij": {
-			Name:        "Zellij",
-			Description: "Modern terminal workspace - batteries included",
-			Pros: []string{
-				"Great UI out of the box",
-				"Floating panes and tabs",
-				"WebAssembly plugins",
-				"Built-in session manager",
-				"Discoverable keybindings (shows hints)",
-				"Modern and actively developed",
-			},
-			Cons: []string{
-				"Younger project than tmux",
-				"Smaller plugin ecosystem",
-				"Higher memory usage",
-				"Less ubiquitous on servers",
-			},
-			Website: "https://zellij.dev",
-		},
-	}
+	return toolInfo(system.ToolWM)
 }
 
 // GetNvimKeymaps returns all Neovim keymaps organized by category
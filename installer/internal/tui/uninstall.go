@@ -0,0 +1,362 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// UninstallComponent is a part of the setup that can be uninstalled on its own
+type UninstallComponent struct {
+	ID       string
+	Name     string
+	Configs  []string // ConfigPaths keys
+	Packages []string // logical package names, only removed on request
+}
+
+// UninstallComponents lists what uninstall can remove, in the order it runs
+var UninstallComponents = []UninstallComponent{
+	{ID: "engram", Name: "Engram service", Packages: []string{"gentleman-programming/tap/engram"}},
+	{ID: "skills", Name: "Skill links"},
+	{ID: "nvim", Name: "Neovim", Configs: []string{"nvim"}, Packages: []string{"neovim"}},
+	{ID: "fish", Name: "Fish", Configs: []string{"fish"}, Packages: []string{"fish"}},
+	{ID: "zsh", Name: "Zsh", Configs: []string{"zsh", "zsh_p10k", "oh-my-zsh"}, Packages: []string{"zsh"}},
+	{ID: "nushell", Name: "Nushell", Configs: []string{"nushell"}, Packages: []string{"nushell"}},
+	{ID: "starship", Name: "Starship", Configs: []string{"starship"}, Packages: []string{"starship"}},
+	{ID: "tmux", Name: "Tmux", Configs: []string{"tmux"}, Packages: []string{"tmux"}},
+	{ID: "zellij", Name: "Zellij", Configs: []string{"zellij"}, Packages: []string{"zellij"}},
+	{ID: "alacritty", Name: "Alacritty", Configs: []string{"alacritty"}, Packages: []string{"alacritty"}},
+	{ID: "wezterm", Name: "WezTerm", Configs: []string{"wezterm"}, Packages: []string{"wezterm"}},
+	{ID: "kitty", Name: "Kitty", Configs: []string{"kitty"}, Packages: []string{"kitty"}},
+	{ID: "ghostty", Name: "Ghostty", Configs: []string{"ghostty"}, Packages: []string{"ghostty"}},
+	{ID: "zed", Name: "Zed", Configs: []string{"zed"}, Packages: []string{"zed"}},
+	{ID: "rc", Name: "Shell rc blocks"},
+}
+
+// UninstallComponentIDs returns the IDs accepted by ParseUninstallComponents
+func UninstallComponentIDs() []string {
+	ids := []string{"all"}
+	for _, c := range UninstallComponents {
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
+
+// ParseUninstallComponents resolves component IDs; none or "all" selects
+// every component
+func ParseUninstallComponents(ids []string) ([]UninstallComponent, error) {
+	if len(ids) == 0 || slices.Contains(ids, "all") {
+		return UninstallComponents, nil
+	}
+	var selected []UninstallComponent
+	for _, c := range UninstallComponents {
+		if slices.Contains(ids, c.ID) {
+			selected = append(selected, c)
+		}
+	}
+	for _, id := range ids {
+		if !slices.ContainsFunc(UninstallComponents, func(c UninstallComponent) bool { return c.ID == id }) {
+			return nil, fmt.Errorf("unknown component %q (valid: %s)", id, strings.Join(UninstallComponentIDs(), ", "))
+		}
+	}
+	return selected, nil
+}
+
+// UninstallPackages returns the packages of the components
+func UninstallPackages(components []UninstallComponent) []string {
+	var packages []string
+	for _, c := range components {
+		packages = append(packages, c.Packages...)
+	}
+	return packages
+}
+
+// uninstallComponentPresent reports whether a component left anything on
+// this machine, to preselect it
+func uninstallComponentPresent(c UninstallComponent) bool {
+	home := os.Getenv("HOME")
+	var paths []string
+	switch c.ID {
+	case "engram":
+		paths = []string{".config/systemd/user/engram.service", "Library/LaunchAgents/com.gentleman.engram.plist"}
+	case "skills":
+		paths = []string{".claude/skills", ".agents/skills"}
+	case "rc":
+		return true
+	}
+	for i := range paths {
+		paths[i] = filepath.Join(home, paths[i])
+	}
+	for _, key := range c.Configs {
+		paths = append(paths, system.ConfigPaths()[key])
+	}
+	for _, path := range paths {
+		if _, err := os.Lstat(path); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// UninstallReport lists what an uninstall changed
+type UninstallReport struct {
+	Restored []string // configs put back from the latest backup
+	Removed  []string // configs, services and links deleted
+	Stripped []string // rc files the installer blocks were removed from
+	Packages []string // packages removed
+}
+
+// Empty reports whether nothing was changed
+func (r *UninstallReport) Empty() bool {
+	return len(r.Restored)+len(r.Removed)+len(r.Stripped)+len(r.Packages) == 0
+}
+
+// Format renders the report as text
+func (r *UninstallReport) Format() string {
+	if r.Empty() {
+		return "Nothing to uninstall\n"
+	}
+	var s strings.Builder
+	sections := []struct {
+		title string
+		items []string
+	}{
+		{"Restored from backup", r.Restored},
+		{"Removed", r.Removed},
+		{"Installer blocks stripped", r.Stripped},
+		{"Packages removed", r.Packages},
+	}
+	for _, section := range sections {
+		if len(section.items) == 0 {
+			continue
+		}
+		fmt.Fprintf(&s, "%s:\n", section.title)
+		for _, item := range section.items {
+			fmt.Fprintf(&s, "  • %s\n", item)
+		}
+	}
+	return s.String()
+}
+
+// RunUninstall reverses what the installer did for the components: configs
+// are restored from the latest backup holding them, or removed, and the
+// Engram service, skill links and rc blocks are deleted. Packages are only
+// removed when removePackages is set. It keeps going after an error and
+// returns all of them.
+func RunUninstall(info *system.SystemInfo, components []UninstallComponent, removePackages bool, onLog func(string)) (*UninstallReport, error) {
+	if onLog == nil {
+		onLog = func(string) {}
+	}
+	report := &UninstallReport{}
+	var errs []error
+
+	for _, c := range components {
+		var err error
+		switch c.ID {
+		case "engram":
+			err = removeEngramService(report, onLog)
+		case "skills":
+			err = removeSkillLinks(report, onLog)
+		case "rc":
+			err = stripRcBlocks(report, onLog)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.Name, err))
+		}
+		for _, key := range c.Configs {
+			if err := uninstallConfig(key, report, onLog); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", c.Name, err))
+			}
+		}
+	}
+
+	if removePackages {
+		if err := removeComponentPackages(info, UninstallPackages(components), report, onLog); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := system.SaveManifest("", ""); err != nil {
+		errs = append(errs, err)
+	}
+	return report, errors.Join(errs...)
+}
+
+// uninstallConfig puts back what the user had before the first install of
+// a config, or removes it
+func uninstallConfig(key string, report *UninstallReport, onLog func(string)) error {
+	path := system.ConfigPaths()[key]
+	if backup, ok := system.OriginalBackup(key); ok {
+		onLog(fmt.Sprintf("Restoring %s from %s", path, filepath.Base(backup.Path)))
+		if err := system.RestoreBackupEntries(backup.Path, []string{key}); err != nil {
+			return err
+		}
+		report.Restored = append(report.Restored, fmt.Sprintf("%s (%s)", path, filepath.Base(backup.Path)))
+		return system.ForgetOriginal(key)
+	}
+	if _, err := os.Lstat(path); err != nil {
+		return nil
+	}
+	onLog("Removing " + path)
+	if err := system.RemoveAll(path); err != nil {
+		return err
+	}
+	report.Removed = append(report.Removed, path)
+	return nil
+}
+
+// removeEngramService disables and deletes the systemd unit or launchd
+// plist created for Engram
+func removeEngramService(report *UninstallReport, onLog func(string)) error {
+	home := os.Getenv("HOME")
+	var errs []error
+
+	unit := filepath.Join(home, ".config/systemd/user/engram.service")
+	if _, err := os.Stat(unit); err == nil {
+		onLog("Disabling engram.service")
+		// The service may already be stopped or disabled
+		system.Run(context.Background(), "systemctl --user disable --now engram.service", nil)
+		if err := system.RemoveAll(unit); err != nil {
+			errs = append(errs, err)
+		} else {
+			report.Removed = append(report.Removed, unit)
+		}
+		system.Run(context.Background(), "systemctl --user daemon-reload", nil)
+	}
+
+	plist := filepath.Join(home, "Library/LaunchAgents/com.gentleman.engram.plist")
+	if _, err := os.Stat(plist); err == nil {
+		onLog("Unloading com.gentleman.engram")
+		system.Run(context.Background(), "launchctl unload "+plist, nil)
+		if err := system.RemoveAll(plist); err != nil {
+			errs = append(errs, err)
+		} else {
+			report.Removed = append(report.Removed, plist)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// removeSkillLinks deletes the skill symlinks pointing into ~/.gentleman,
+// and dangling ones. Real directories are local skills and are kept.
+func removeSkillLinks(report *UninstallReport, onLog func(string)) error {
+	home := os.Getenv("HOME")
+	central := filepath.Join(home, ".gentleman") + string(filepath.Separator)
+	var errs []error
+
+	for _, dir := range []string{filepath.Join(home, ".claude", "skills"), filepath.Join(home, ".agents", "skills")} {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		removed := 0
+		for _, entry := range entries {
+			if entry.Type()&os.ModeSymlink == 0 {
+				continue
+			}
+			link := filepath.Join(dir, entry.Name())
+			target, err := os.Readlink(link)
+			if err != nil {
+				continue
+			}
+			_, statErr := os.Stat(link)
+			if !strings.HasPrefix(target, central) && statErr == nil {
+				continue
+			}
+			if err := system.RemoveAll(link); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			removed++
+		}
+		if removed > 0 {
+			onLog(fmt.Sprintf("Removed %d skill link(s) from %s", removed, dir))
+			report.Removed = append(report.Removed, fmt.Sprintf("%s (%d skill links)", dir, removed))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// stripRcBlocks removes the blocks the installer appended to shell rc files
+func stripRcBlocks(report *UninstallReport, onLog func(string)) error {
+	home := os.Getenv("HOME")
+	var errs []error
+	var paths []string
+	for _, rc := range []string{".bashrc", ".zshrc", ".config/fish/config.fish", ".config/nushell/env.nu", ".config/nushell/config.nu"} {
+		paths = append(paths, filepath.Join(home, rc))
+	}
+	// Termux lists the installed shells in $PREFIX/etc/shells
+	if prefix := os.Getenv("PREFIX"); prefix != "" {
+		paths = append(paths, filepath.Join(prefix, "etc", "shells"))
+	}
+	for _, path := range paths {
+		stripped, err := system.StripInstallerBlocks(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if stripped {
+			onLog("Stripped installer blocks from " + path)
+			report.Stripped = append(report.Stripped, path)
+		}
+	}
+	// Elsewhere the shell was listed in /etc/shells, which needs root
+	if os.Getenv("PREFIX") == "" {
+		stripped, err := system.StripRootInstallerBlocks(context.Background(), "/etc/shells")
+		if err != nil {
+			errs = append(errs, err)
+		} else if stripped {
+			onLog("Stripped installer blocks from /etc/shells")
+			report.Stripped = append(report.Stripped, "/etc/shells")
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// removeComponentPackages removes each package with the first manager
+// that has it installed
+func removeComponentPackages(info *system.SystemInfo, packages []string, report *UninstallReport, onLog func(string)) error {
+	managers := []system.PackageManager{system.PackageManagerFor(info)}
+	if tools := system.ToolManagerFor(info); tools.Name() != managers[0].Name() {
+		managers = append(managers, tools)
+	}
+	if system.CommandExists("flatpak") {
+		managers = append(managers, system.NewFlatpakManager())
+	}
+
+	var errs []error
+	for _, pkg := range packages {
+		i := slices.IndexFunc(managers, func(pm system.PackageManager) bool { return pm.IsInstalled(pkg) })
+		if i < 0 {
+			onLog(pkg + " is not installed, skipping")
+			continue
+		}
+		onLog(fmt.Sprintf("Removing %s with %s", pkg, managers[i].Name()))
+		if result := managers[i].Remove(context.Background(), onLog, pkg); result.Error != nil {
+			errs = append(errs, fmt.Errorf("remove %s: %w", pkg, result.Error))
+			continue
+		}
+		report.Packages = append(report.Packages, pkg)
+	}
+	return errors.Join(errs...)
+}
+
+// uninstallCompleteMsg carries the result of an uninstall run from the TUI
+type uninstallCompleteMsg struct {
+	report *UninstallReport
+	err    error
+}
+
+// runUninstallCmd uninstalls in the background
+func runUninstallCmd(info *system.SystemInfo, components []UninstallComponent, removePackages bool) tea.Cmd {
+	return func() tea.Msg {
+		report, err := RunUninstall(info, components, removePackages, nil)
+		return uninstallCompleteMsg{report: report, err: err}
+	}
+}
@@ -0,0 +1,186 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
+)
+
+func TestParseUninstallComponents(t *testing.T) {
+	all, err := ParseUninstallComponents(nil)
+	if err != nil || len(all) != len(UninstallComponents) {
+		t.Errorf("no components should select everything, got %d, %v", len(all), err)
+	}
+
+	got, err := ParseUninstallComponents([]string{"rc", "nvim"})
+	if err != nil || len(got) != 2 || got[0].ID != "nvim" || got[1].ID != "rc" {
+		t.Errorf("components should keep the uninstall order, got %+v, %v", got, err)
+	}
+
+	if _, err := ParseUninstallComponents([]string{"emacs"}); err == nil || !strings.Contains(err.Error(), "tmux") {
+		t.Errorf("unknown components should be rejected with the valid list, got %v", err)
+	}
+}
+
+func TestRunUninstall(t *testing.T) {
+	t.Setenv("GENTLEMAN_DRY_RUN", "")
+
+	t.Run("restores backups, removes configs, links and rc blocks", func(t *testing.T) {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		writeHomeFile(t, home, ".config/nvim/init.lua", "-- gentleman\n")
+		writeHomeFile(t, home, ".tmux.conf", "set -g mouse on # gentleman\n")
+		writeHomeFile(t, home, ".gentleman-backup-2026-01-01-100000/tmux", "# mine\n")
+		writeHomeFile(t, home, ".gentleman-backup-2026-05-01-100000/tmux", "# gentleman, second install\n")
+		writeHomeFile(t, home, ".bashrc", "alias ll='ls -l'\n\n# Added by Javi.Dots installer\nexport PATH=\"$HOME/.local/bin:$PATH\"\n")
+
+		skills := filepath.Join(home, ".claude", "skills")
+		writeHomeFile(t, home, ".gentleman/skills/react-19/SKILL.md", "# React\n")
+		writeHomeFile(t, home, ".claude/skills/my-local/SKILL.md", "# Local\n")
+		os.Symlink(filepath.Join(home, ".gentleman/skills/react-19"), filepath.Join(skills, "react-19"))
+		os.Symlink(filepath.Join(home, "elsewhere"), filepath.Join(skills, "dangling"))
+
+		components, _ := ParseUninstallComponents([]string{"nvim", "tmux", "skills", "rc"})
+		report, err := RunUninstall(&system.SystemInfo{OS: system.OSLinux}, components, false, nil)
+		if err != nil {
+			t.Fatalf("uninstall failed: %v", err)
+		}
+
+		if _, err := os.Stat(filepath.Join(home, ".config/nvim")); !os.IsNotExist(err) {
+			t.Error("nvim config without a backup should be removed")
+		}
+		if got, _ := os.ReadFile(filepath.Join(home, ".tmux.conf")); string(got) != "# mine\n" {
+			t.Errorf("tmux config should come from the first install backup, got %q", got)
+		}
+		if got, _ := os.ReadFile(filepath.Join(home, ".bashrc")); string(got) != "alias ll='ls -l'\n" {
+			t.Errorf("installer block should be stripped, got %q", got)
+		}
+		for name, kept := range map[string]bool{"react-19": false, "dangling": false, "my-local": true} {
+			if _, err := os.Lstat(filepath.Join(skills, name)); (err == nil) != kept {
+				t.Errorf("skill %s kept = %v, want %v", name, err == nil, kept)
+			}
+		}
+		if len(report.Restored) != 1 || len(report.Removed) != 2 || len(report.Stripped) != 1 || len(report.Packages) != 0 {
+			t.Errorf("unexpected report: %+v", report)
+		}
+	})
+
+	t.Run("engram service in dry run", func(t *testing.T) {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		t.Setenv("GENTLEMAN_DRY_RUN", "1")
+		writeHomeFile(t, home, ".config/systemd/user/engram.service", "[Unit]\n")
+		system.TakePlan()
+
+		components, _ := ParseUninstallComponents([]string{"engram"})
+		if _, err := RunUninstall(&system.SystemInfo{OS: system.OSLinux}, components, false, nil); err != nil {
+			t.Fatalf("dry run failed: %v", err)
+		}
+
+		var targets []string
+		for _, action := range system.TakePlan() {
+			targets = append(targets, action.Target)
+		}
+		if !slices.Contains(targets, "systemctl --user disable --now engram.service") ||
+			!slices.Contains(targets, filepath.Join(home, ".config/systemd/user/engram.service")) {
+			t.Errorf("service should be disabled and removed, plan %v", targets)
+		}
+		if _, err := os.Stat(filepath.Join(home, ".config/systemd/user/engram.service")); err != nil {
+			t.Error("dry run should not remove the unit")
+		}
+	})
+
+	t.Run("restore then uninstall puts back the user's config", func(t *testing.T) {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		writeHomeFile(t, home, ".tmux.conf", "# mine\n")
+		original, err := system.CreateBackupFor([]string{"tmux"}, "install", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeHomeFile(t, home, "repo/tmux.conf", "# gentleman\n")
+		if err := system.CopyFile(filepath.Join(home, "repo/tmux.conf"), filepath.Join(home, ".tmux.conf")); err != nil {
+			t.Fatal(err)
+		}
+		if err := system.SaveManifest("", ""); err != nil {
+			t.Fatal(err)
+		}
+
+		// The restore snapshot holds the installer's config
+		if _, err := system.RestoreBackupWithUndo(original, nil); err != nil {
+			t.Fatal(err)
+		}
+		components, _ := ParseUninstallComponents([]string{"tmux"})
+		report, err := RunUninstall(&system.SystemInfo{OS: system.OSLinux}, components, false, nil)
+		if err != nil {
+			t.Fatalf("uninstall failed: %v", err)
+		}
+		if got, _ := os.ReadFile(filepath.Join(home, ".tmux.conf")); string(got) != "# mine\n" {
+			t.Errorf("the config from before the install should be restored, got %q", got)
+		}
+		if len(report.Restored) != 1 || !strings.Contains(report.Restored[0], filepath.Base(original)) {
+			t.Errorf("expected a restore from %s, got %+v", original, report.Restored)
+		}
+	})
+}
+
+func TestUninstallScreens(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeHomeFile(t, home, ".tmux.conf", "set -g mouse on\n")
+
+	m := NewModel()
+	m.Screen = ScreenMainMenu
+	for i, opt := range m.GetCurrentOptions() {
+		if strings.Contains(opt, "Uninstall") {
+			m.Cursor = i
+		}
+	}
+	m = press(m, "enter")
+	if m.Screen != ScreenUninstallSelect {
+		t.Fatalf("expected the uninstall selection, got %v", m.Screen)
+	}
+	selected := m.selectedUninstallComponents()
+	if len(selected) != 2 || selected[0].ID != "tmux" || selected[1].ID != "rc" {
+		t.Errorf("only present components should be preselected, got %+v", selected)
+	}
+
+	// Toggle package removal, then continue
+	opts := m.GetCurrentOptions()
+	m.Cursor = len(opts) - 2
+	m = press(m, "enter")
+	m.Cursor = len(opts) - 1
+	m = press(m, "enter")
+	if m.Screen != ScreenUninstallConfirm || !m.UninstallPackages {
+		t.Fatalf("expected the confirmation with packages, got %v", m.Screen)
+	}
+	if view := m.View(); !strings.Contains(view, "These packages will be removed: tmux") {
+		t.Errorf("confirmation should list the packages:\n%s", view)
+	}
+
+	if m = press(m, "esc"); m.Screen != ScreenUninstallSelect {
+		t.Errorf("esc should go back to the selection, got %v", m.Screen)
+	}
+	m.Cursor = len(opts) - 1
+	m = press(m, "enter")
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = result.(Model)
+	if m.Screen != ScreenUninstallResult || !m.UninstallRunning || cmd == nil {
+		t.Fatalf("confirming should start the uninstall, got %v", m.Screen)
+	}
+
+	result, _ = m.Update(uninstallCompleteMsg{report: &UninstallReport{Removed: []string{"/home/u/.tmux.conf"}}})
+	m = result.(Model)
+	if !strings.Contains(m.View(), "/home/u/.tmux.conf") {
+		t.Error("result screen should show the report")
+	}
+	if m = press(m, "enter"); m.Screen != ScreenMainMenu {
+		t.Errorf("enter should go back to the main menu, got %v", m.Screen)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -24,8 +25,10 @@ type (
 
 	// stepCompleteMsg signals a step completed
 	stepCompleteMsg struct {
-		stepID string
-		err    error
+		stepID    string
+		err       error
+		plan      []system.PlanAction // Actions recorded in dry-run mode
+		backupDir string              // Set by the backup step
 	}
 
 	// stepProgressMsg updates progress of current step
@@ -149,8 +152,22 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, tickCmd()
 
 	case installStartMsg:
-		// Start the installation process
-		return m, m.runNextStep()
+		// Start the installation process, journaling progress so it can resume
+		if m.LogPath == "" {
+			m.LogPath, _ = StartSessionLog()
+		}
+		if m.LinkConfigs {
+			m.Choices.LinkConfigs = true
+		}
+		m.applyLinkMode()
+		m.startInstallContext()
+		if m.Journal == nil && !system.IsDryRun() {
+			system.ResetChanges(nil)
+			m.Journal = NewJournal(&m)
+			_ = m.Journal.Save()
+		}
+		cmd := m.scheduleSteps()
+		return m, cmd
 
 	case stepProgressMsg:
 		// Update progress
@@ -161,6 +178,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 		if msg.log != "" {
+			m.appendLogEntry(LogEntry{Time: time.Now(), StepID: msg.stepID, Line: msg.log})
 			m.LogLines = append(m.LogLines, msg.log)
 			// Keep only last 20 lines
 			if len(m.LogLines) > 20 {
@@ -170,28 +188,23 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case stepCompleteMsg:
-		// Mark step as complete
-		for i := range m.Steps {
-			if m.Steps[i].ID == msg.stepID {
-				if msg.err != nil {
-					m.Steps[i].Status = StatusFailed
-					m.Steps[i].Error = msg.err
-					m.Screen = ScreenError
-					// Include step name in error message for clarity
-					m.ErrorMsg = fmt.Sprintf("Step '%s' failed:\n%s", m.Steps[i].Name, msg.err.Error())
-					return m, nil
-				}
-				m.Steps[i].Status = StatusDone
-				m.Steps[i].Progress = 1.0
-				break
+		m.DryRunPlan = append(m.DryRunPlan, msg.plan...)
+		if msg.backupDir != "" {
+			m.BackupDir = msg.backupDir
+			if m.Journal != nil {
+				m.Journal.BackupDir = msg.backupDir
 			}
 		}
-		m.CurrentStep++
-		return m, m.runNextStep()
+		return m.finishStep(msg.stepID, msg.err)
 
 	case installCompleteMsg:
 		m.TotalTime = msg.totalTime
-		m.Screen = ScreenComplete
+		m.showInstallScreen(ScreenComplete)
+		// Nothing left to resume
+		if m.Journal != nil {
+			_ = m.Journal.Remove()
+			m.Journal = nil
+		}
 		return m, nil
 
 	case loadBackupsMsg:
@@ -200,28 +213,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case execFinishedMsg:
 		// Interactive process finished (sudo commands, chsh, etc)
-		for i := range m.Steps {
-			if m.Steps[i].ID == msg.stepID {
-				if msg.err != nil {
-					m.Steps[i].Status = StatusFailed
-					m.Steps[i].Error = msg.err
-					m.Screen = ScreenError
-					// Include step name in error message for clarity
-					m.ErrorMsg = fmt.Sprintf("Step '%s' failed:\n%s", m.Steps[i].Name, msg.err.Error())
-					return m, nil
-				}
-				m.Steps[i].Status = StatusDone
-				m.Steps[i].Progress = 1.0
-				break
-			}
-		}
-		m.CurrentStep++
-		return m, m.runNextStep()
+		return m.finishStep(msg.stepID, msg.err)
 
 	case projectInstallStartMsg:
 		return m, m.runProjectInit()
 
 	case projectInstallLogMsg:
+		writeSessionLog(LogEntry{Time: time.Now(), StepID: "project", Line: msg.line})
 		m.ProjectLogLines = append(m.ProjectLogLines, msg.line)
 		if len(m.ProjectLogLines) > 30 {
 			m.ProjectLogLines = m.ProjectLogLines[len(m.ProjectLogLines)-30:]
@@ -262,6 +260,40 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.Screen = ScreenSkillResult
 		return m, nil
 
+	case uninstallCompleteMsg:
+		m.UninstallReport = msg.report
+		m.UninstallErr = msg.err
+		m.UninstallRunning = false
+		return m, nil
+
+	case configUpdatePlanMsg:
+		m.ConfigUpdatePlan = msg.plan
+		m.ConfigUpdateErr = msg.err
+		m.ConfigUpdateRunning = false
+		m.Cursor = 0
+		return m, nil
+
+	case configUpdateAppliedMsg:
+		m.ConfigUpdateBackup = msg.backupDir
+		m.ConfigUpdateErr = msg.err
+		m.ConfigUpdateRunning = false
+		m.ConfigUpdateApplied = true
+		return m, nil
+
+	case themeAppliedMsg:
+		m.ThemeStyled = append([]string{}, msg.styled...) // Empty, not nil: the switch ran
+		m.ThemeErr = msg.err
+		m.ThemeRunning = false
+		if msg.err == nil {
+			m.ThemeCurrent = msg.theme
+		}
+		return m, nil
+
+	case doctorCompleteMsg:
+		m.DoctorChecks = msg.checks
+		m.DoctorRunning = false
+		return m, nil
+
 	case skillActionCompleteMsg:
 		m.SkillResultLog = msg.logLines
 		if msg.err != nil {
@@ -810,10 +842,16 @@ func removeSkillActionCmd(skills []SkillInfo) tea.Cmd {
 func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	key := msg.String()
 
-	// ctrl+c always quits immediately (no leader needed)
+	// ctrl+c quits immediately (no leader needed), but asks before
+	// cancelling a running installation
 	if key == "ctrl+c" {
-		m.Quitting = true
-		return m, tea.Quit
+		if m.installRunning() {
+			return m.handleCancelKey()
+		}
+		return m.quitNow()
+	}
+	if m.CancelConfirm {
+		return m.handleCancelConfirmKeys(key)
 	}
 
 	// Leader key mode: <space> activates, next key executes command
@@ -823,7 +861,7 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		switch key {
 		case "q":
 			// Quit application
-			if m.Screen != ScreenInstalling {
+			if m.Screen != ScreenInstalling && !(m.Screen == ScreenLogViewer && m.LogPrevScreen == ScreenInstalling) {
 				m.Quitting = true
 				return m, tea.Quit
 			}
@@ -834,6 +872,12 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.ShowDetails = !m.ShowDetails
 			}
 			return m, nil
+		case "l":
+			// Full log viewer during installation
+			if m.Screen == ScreenInstalling {
+				return m.openLogViewer(), nil
+			}
+			return m, nil
 		default:
 			// Unknown leader command, ignore
 			return m, nil
@@ -850,17 +894,30 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.Screen = ScreenMainMenu
 			m.Cursor = 0
 			return m, nil
-		case ScreenComplete, ScreenError:
+		case ScreenInstallCancelled:
+			// Space selects like enter
+			return m.handleInstallCancelledKeys(key)
+		case ScreenComplete, ScreenError, ScreenRollback:
+			// A failed step offers retry/skip/abort, space selects like enter
+			if m.Screen == ScreenError && m.failedStepIndex() >= 0 {
+				return m.handleStepErrorKeys(key)
+			}
 			// Complete/Error screens: space quits the app
 			m.Quitting = true
 			return m, tea.Quit
-		case ScreenProjectPath:
-			// Project path input: space is part of the path, pass through
+		case ScreenProjectPath, ScreenBackupImport:
+			// Path inputs: space is part of the path, pass through
 		case ScreenTrainerLesson, ScreenTrainerPractice, ScreenTrainerBoss:
 			// Trainer input screens: space is part of the input, pass through
 			// (handled below in screen-specific handlers)
-		case ScreenSkillInstall, ScreenSkillRemove, ScreenProjectRolePack:
+		case ScreenSkillInstall, ScreenSkillRemove, ScreenProjectRolePack, ScreenRestoreConfirm:
 			// Multi-select screens: space toggles selection, pass through
+		case ScreenLogViewer:
+			// Space is part of a search query, otherwise it activates leader mode
+			if !m.LogSearching {
+				m.LeaderMode = true
+				return m, nil
+			}
 		default:
 			// All other screens: activate leader mode
 			m.LeaderMode = true
@@ -868,6 +925,16 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// Typing a log search takes every key
+	if m.Screen == ScreenLogViewer && m.LogSearching {
+		return m.handleLogSearchKeys(msg)
+	}
+
+	// So does typing the archive to import
+	if m.Screen == ScreenBackupImport {
+		return m.handleBackupImportKeys(msg)
+	}
+
 	// ESC goes back from content/learn screens (and cancels leader mode implicitly)
 	if key == "esc" {
 		return m.handleEscape()
@@ -885,10 +952,13 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case ScreenMainMenu:
 		return m.handleMainMenuKeys(key)
 
-	case ScreenOSSelect, ScreenTerminalSelect, ScreenFontSelect, ScreenShellSelect, ScreenWMSelect, ScreenNvimSelect, ScreenZedSelect, ScreenAIFrameworkConfirm, ScreenAIFrameworkPreset, ScreenGhosttyWarning,
+	case ScreenOSSelect, ScreenTerminalSelect, ScreenShellSelect, ScreenWMSelect, ScreenNvimSelect, ScreenZedSelect, ScreenAIFrameworkConfirm, ScreenAIFrameworkPreset, ScreenGhosttyWarning,
 		ScreenProjectStack, ScreenProjectMemory, ScreenProjectObsidianInstall, ScreenProjectEngram, ScreenProjectCI, ScreenProjectConfirm, ScreenSkillMenu, ScreenLearnMenu:
 		return m.handleSelectionKeys(key)
 
+	case ScreenFontSelect:
+		return m.handleFontKeys(key)
+
 	case ScreenAIToolsSelect:
 		return m.handleAIToolsKeys(key)
 
@@ -946,6 +1016,15 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case ScreenRestoreConfirm:
 		return m.handleRestoreConfirmKeys(key)
 
+	case ScreenRestoreDiff:
+		return m.handleRestoreDiffKeys(key)
+
+	case ScreenRestoreResult:
+		return m.handleRestoreResultKeys(key)
+
+	case ScreenResumeInstall:
+		return m.handleResumeInstallKeys(key)
+
 	// Trainer screens
 	case ScreenTrainerMenu:
 		return m.handleTrainerMenuKeys(key)
@@ -993,9 +1072,55 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		case "enter", " ":
 			m.Quitting = true
 			return m, tea.Quit
+		case "s":
+			m.saveChoicesAsProfile()
+		case "l":
+			return m.openLogViewer(), nil
+		}
+
+	case ScreenInstallCancelled:
+		return m.handleInstallCancelledKeys(key)
+
+	case ScreenRollback:
+		switch key {
+		case "enter":
+			m.Quitting = true
+			return m, tea.Quit
+		case "l":
+			return m.openLogViewer(), nil
 		}
 
+	case ScreenLogViewer:
+		return m.handleLogViewerKeys(key)
+
+	case ScreenDoctor:
+		return m.handleDoctorKeys(key)
+
+	case ScreenTheme:
+		return m.handleThemeKeys(key)
+
+	case ScreenUninstallSelect:
+		return m.handleUninstallSelectKeys(key)
+
+	case ScreenUninstallConfirm:
+		return m.handleUninstallConfirmKeys(key)
+
+	case ScreenUninstallResult:
+		if key == "enter" && !m.UninstallRunning {
+			m.Screen = ScreenMainMenu
+			m.Cursor = 0
+		}
+
+	case ScreenConfigUpdate:
+		return m.handleConfigUpdateKeys(key)
+
+	case ScreenConfigConflicts:
+		return m.handleConflictKeys(key)
+
 	case ScreenError:
+		if m.failedStepIndex() >= 0 {
+			return m.handleStepErrorKeys(key)
+		}
 		switch key {
 		case "enter", " ":
 			m.Quitting = true
@@ -1004,6 +1129,8 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			// Retry - go back to beginning
 			m.Screen = ScreenWelcome
 			m.ErrorMsg = ""
+		case "l":
+			return m.openLogViewer(), nil
 		}
 	}
 
@@ -1063,10 +1190,45 @@ func (m Model) handleEscape() (tea.Model, tea.Cmd) {
 	case ScreenLearnMenu:
 		m.Screen = ScreenMainMenu
 		m.Cursor = 0
-	// Restore screens
-	case ScreenRestoreBackup, ScreenRestoreConfirm:
+	// Restore, doctor and uninstall screens
+	case ScreenRestoreBackup, ScreenRestoreConfirm, ScreenDoctor, ScreenUninstallSelect:
 		m.Screen = ScreenMainMenu
 		m.Cursor = 0
+	case ScreenRestoreDiff:
+		m.Screen = ScreenRestoreConfirm
+	case ScreenRestoreResult:
+		return m.leaveRestoreResult(), nil
+	case ScreenUninstallConfirm:
+		m.Screen = ScreenUninstallSelect
+		m.Cursor = 0
+	case ScreenUninstallResult:
+		if !m.UninstallRunning {
+			m.Screen = ScreenMainMenu
+			m.Cursor = 0
+		}
+	// Config update: the plan is dropped unless it was applied
+	case ScreenConfigUpdate:
+		if !m.ConfigUpdateRunning {
+			m.discardConfigUpdate()
+			m.Screen = ScreenMainMenu
+			m.Cursor = 0
+		}
+	case ScreenConfigConflicts:
+		m.Screen = ScreenConfigUpdate
+		m.Cursor = 0
+	case ScreenTheme:
+		if !m.ThemeRunning {
+			m.Screen = ScreenMainMenu
+			m.Cursor = 0
+		}
+	// Log viewer: back to the screen it was opened from
+	case ScreenLogViewer:
+		m.Screen = m.LogPrevScreen
+	// Resume prompt: keep the journal for the next launch
+	case ScreenResumeInstall:
+		m.PendingJournal = nil
+		m.Screen = ScreenWelcome
+		m.Cursor = 0
 	// Trainer screens
 	case ScreenTrainerMenu:
 		// Save stats and return to previous screen
@@ -1153,6 +1315,9 @@ func (m Model) handleMainMenuKeys(key string) (tea.Model, tea.Cmd) {
 		case strings.Contains(selected, "Restore from Backup") && hasRestoreOption:
 			m.Screen = ScreenRestoreBackup
 			m.Cursor = 0
+			m.BackupMsg = ""
+		case selected == importBackupOption:
+			m.openBackupImport()
 		case strings.Contains(selected, "Initialize Project"):
 			cwd, err := os.Getwd()
 			if err != nil {
@@ -1180,6 +1345,31 @@ func (m Model) handleMainMenuKeys(key string) (tea.Model, tea.Cmd) {
 		case strings.Contains(selected, "Skill Manager"):
 			m.Screen = ScreenSkillMenu
 			m.Cursor = 0
+		case strings.Contains(selected, "Update Configs"):
+			m.ConfigUpdatePlan = nil
+			m.ConfigUpdateErr = nil
+			m.ConfigUpdateApplied = false
+			m.ConfigUpdateBackup = ""
+			m.ConfigUpdateRunning = true
+			m.Screen = ScreenConfigUpdate
+			return m, planConfigUpdateCmd(m.RepoURL)
+		case strings.Contains(selected, "Theme"):
+			m.ThemeCurrent = system.CurrentTheme()
+			m.ThemeStyled = nil
+			m.ThemeErr = nil
+			m.Screen = ScreenTheme
+			m.Cursor = max(slices.Index(system.ThemeIDs(), m.ThemeCurrent), 0)
+		case strings.Contains(selected, "Doctor"):
+			m.Screen = ScreenDoctor
+			return m.startDoctor()
+		case strings.Contains(selected, "Uninstall"):
+			m.UninstallSelected = make([]bool, len(UninstallComponents))
+			for i, c := range UninstallComponents {
+				m.UninstallSelected[i] = uninstallComponentPresent(c)
+			}
+			m.UninstallPackages = false
+			m.Screen = ScreenUninstallSelect
+			m.Cursor = 0
 		case strings.Contains(selected, "Exit"):
 			m.Quitting = true
 			return m, tea.Quit
@@ -1226,6 +1416,21 @@ func (m Model) handleSelectionKeys(key string) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// handleFontKeys picks a Nerd Font; left and right change its size
+func (m Model) handleFontKeys(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "left", "h":
+		if m.Choices.FontSize > fontSizeStep {
+			m.Choices.FontSize -= fontSizeStep
+		}
+	case "right", "l":
+		m.Choices.FontSize += fontSizeStep
+	default:
+		return m.handleSelectionKeys(key)
+	}
+	return m, nil
+}
+
 // goBackInstallStep handles going back during installation wizard
 func (m Model) goBackInstallStep() (tea.Model, tea.Cmd) {
 	switch m.Screen {
@@ -1247,18 +1452,21 @@ func (m Model) goBackInstallStep() (tea.Model, tea.Cmd) {
 		m.Cursor = 0
 		// Reset font choice
 		m.Choices.InstallFont = false
+		m.Choices.Font = ""
+		m.Choices.FontSize = 0
 
 	case ScreenShellSelect:
 		// Termux: go back to OS selection (skipped terminal and font)
 		if m.SystemInfo.IsTermux {
 			m.Screen = ScreenOSSelect
+			m.Cursor = 0
 		} else if m.Choices.Terminal == "none" {
 			// If we skipped font selection (terminal = none), go back to terminal
 			m.Screen = ScreenTerminalSelect
+			m.Cursor = 0
 		} else {
-			m.Screen = ScreenFontSelect
+			m.openFontSelect()
 		}
-		m.Cursor = 0
 		m.Choices.Shell = ""
 
 	case ScreenWMSelect:
@@ -1434,14 +1642,18 @@ func (m Model) handleSelection() (tea.Model, tea.Cmd) {
 		}
 
 		if term != "none" {
-			m.Screen = ScreenFontSelect
+			m.openFontSelect()
 		} else {
 			m.Screen = ScreenShellSelect
+			m.Cursor = 0
 		}
-		m.Cursor = 0
 
 	case ScreenFontSelect:
-		m.Choices.InstallFont = m.Cursor == 0
+		m.Choices.InstallFont = m.Cursor < len(system.Fonts)
+		m.Choices.Font = ""
+		if m.Choices.InstallFont {
+			m.Choices.Font = system.Fonts[m.Cursor].ID
+		}
 		m.Screen = ScreenShellSelect
 		m.Cursor = 0
 
@@ -1453,8 +1665,7 @@ func (m Model) handleSelection() (tea.Model, tea.Cmd) {
 	case ScreenGhosttyWarning:
 		switch m.Cursor {
 		case 0: // Continue with Ghostty anyway
-			m.Screen = ScreenFontSelect
-			m.Cursor = 0
+			m.openFontSelect()
 		case 1: // Choose different terminal
 			m.Screen = ScreenTerminalSelect
 			m.Cursor = 0
@@ -1547,6 +1758,9 @@ func (m Model) handleSelection() (tea.Model, tea.Cmd) {
 			m.Choices.ProjectEngram = m.ProjectEngram
 			m.Choices.ProjectRolePacks = m.ProjectRolePacks
 			m.ProjectLogLines = []string{}
+			if m.LogPath == "" {
+				m.LogPath, _ = StartSessionLog()
+			}
 			m.Screen = ScreenProjectInstalling
 			return m, func() tea.Msg { return projectInstallStartMsg{} }
 		} else { // Cancel
@@ -2499,18 +2713,11 @@ func (m Model) handleToolKeymapsMenuKeys(key string) (tea.Model, tea.Cmd) {
 		}
 
 		// Navigate to specific tool's keymaps
-		switch m.Cursor {
-		case 0: // Neovim
+		if m.Cursor == 0 { // Neovim
 			m.Screen = ScreenKeymaps
 			m.Cursor = 0
-		case 1: // Tmux
-			m.Screen = ScreenKeymapsTmux
-			m.Cursor = 0
-		case 2: // Zellij
-			m.Screen = ScreenKeymapsZellij
-			m.Cursor = 0
-		case 3: // Ghostty
-			m.Screen = ScreenKeymapsGhostty
+		} else if tools := keymapTools(); m.Cursor <= len(tools) {
+			m.Screen = toolSpecs[tools[m.Cursor-1].ID].KeymapsAt
 			m.Cursor = 0
 		}
 	}
@@ -2842,6 +3049,8 @@ func (m Model) handleBackupConfirmKeys(key string) (tea.Model, tea.Cmd) {
 			// Reset choices when canceling
 			m.Choices = UserChoices{}
 		}
+	case "s":
+		m.saveChoicesAsProfile()
 	case "esc", "backspace":
 		// Go back to the last AI screen in the wizard flow
 		if len(m.Choices.AITools) > 0 && m.Choices.InstallAIFramework && m.AICategorySelected != nil {
@@ -2860,6 +3069,16 @@ func (m Model) handleBackupConfirmKeys(key string) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// saveChoicesAsProfile writes the current choices to a new profile file
+func (m *Model) saveChoicesAsProfile() {
+	path := DefaultProfilePath()
+	if err := SaveProfile(path, ProfileFromChoices(m.Choices)); err != nil {
+		m.ProfileMsg = "❌ Could not save profile: " + err.Error()
+		return
+	}
+	m.ProfileMsg = "💾 Profile saved: " + path
+}
+
 func (m Model) handleRestoreBackupKeys(key string) (tea.Model, tea.Cmd) {
 	options := m.GetCurrentOptions()
 
@@ -2887,15 +3106,17 @@ func (m Model) handleRestoreBackupKeys(key string) (tea.Model, tea.Cmd) {
 			m.Cursor = 0
 			return m, nil
 		}
+		if options[m.Cursor] == importBackupOption {
+			m.openBackupImport()
+			return m, nil
+		}
 		// Skip separator
 		if strings.HasPrefix(options[m.Cursor], "───") {
 			return m, nil
 		}
 		// Select a backup
 		if m.Cursor < len(m.AvailableBackups) {
-			m.SelectedBackup = m.Cursor
-			m.Screen = ScreenRestoreConfirm
-			m.Cursor = 0
+			m.openRestoreConfirm(m.Cursor)
 		}
 	case "esc":
 		m.Screen = ScreenMainMenu
@@ -2905,7 +3126,328 @@ func (m Model) handleRestoreBackupKeys(key string) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func (m Model) handleRestoreConfirmKeys(key string) (tea.Model, tea.Cmd) {
+// handleStepErrorKeys handles retry/skip/abort after a failed step
+func (m Model) handleStepErrorKeys(key string) (tea.Model, tea.Cmd) {
+	options := m.GetCurrentOptions()
+
+	switch key {
+	case "up", "k":
+		if m.Cursor > 0 {
+			m.Cursor--
+		}
+		return m, nil
+	case "down", "j":
+		if m.Cursor < len(options)-1 {
+			m.Cursor++
+		}
+		return m, nil
+	case "enter", " ":
+		key = []string{"r", "s", "b", "a"}[m.Cursor]
+	}
+
+	switch key {
+	case "r":
+		m, _ = m.retryFailedStep()
+		cmd := m.scheduleSteps()
+		return m, cmd
+	case "s":
+		m, _ = m.skipFailedStep()
+		cmd := m.scheduleSteps()
+		return m, cmd
+	case "b":
+		// Changes of steps still running could not be rolled back
+		if runningSteps(m.Steps) > 0 {
+			return m.cancelThenRollback()
+		}
+		return m.rollbackInstall(), nil
+	case "l":
+		return m.openLogViewer(), nil
+	case "a":
+		return m.quitNow()
+	}
+
+	return m, nil
+}
+
+func (m Model) handleLogViewerKeys(key string) (tea.Model, tea.Cmd) {
+	n := len(m.visibleLogEntries())
+	scroll := func(delta int) {
+		m.LogScroll = m.logViewStart(n) + delta
+		m.LogFollow = m.LogScroll >= n-m.logViewHeight()
+	}
+
+	switch key {
+	case "up", "k":
+		scroll(-1)
+	case "down", "j":
+		scroll(1)
+	case "pgup", "ctrl+u":
+		scroll(-m.logViewHeight())
+	case "pgdown", "ctrl+d":
+		scroll(m.logViewHeight())
+	case "g", "home":
+		m.LogScroll = 0
+		m.LogFollow = false
+	case "G", "end":
+		m.LogFollow = true
+	case "f", "tab":
+		m.LogFilter = m.nextLogFilter()
+		m.LogFollow = true
+	case "/":
+		m.LogSearching = true
+	case "enter", "q":
+		m.Screen = m.LogPrevScreen
+	}
+
+	return m, nil
+}
+
+// selectedUninstallComponents returns the components toggled on
+func (m Model) selectedUninstallComponents() []UninstallComponent {
+	var selected []UninstallComponent
+	for i, on := range m.UninstallSelected {
+		if on && i < len(UninstallComponents) {
+			selected = append(selected, UninstallComponents[i])
+		}
+	}
+	return selected
+}
+
+func (m Model) handleUninstallSelectKeys(key string) (tea.Model, tea.Cmd) {
+	options := m.GetCurrentOptions()
+	confirmIdx := len(options) - 1
+	packagesIdx := confirmIdx - 1
+
+	switch key {
+	case "up", "k":
+		if m.Cursor > 0 {
+			m.Cursor--
+			if strings.HasPrefix(options[m.Cursor], "───") && m.Cursor > 0 {
+				m.Cursor--
+			}
+		}
+	case "down", "j":
+		if m.Cursor < len(options)-1 {
+			m.Cursor++
+			if strings.HasPrefix(options[m.Cursor], "───") && m.Cursor < len(options)-1 {
+				m.Cursor++
+			}
+		}
+	case "enter", " ":
+		switch {
+		case m.Cursor < len(m.UninstallSelected):
+			m.UninstallSelected[m.Cursor] = !m.UninstallSelected[m.Cursor]
+		case m.Cursor == packagesIdx:
+			m.UninstallPackages = !m.UninstallPackages
+		case m.Cursor == confirmIdx:
+			if len(m.selectedUninstallComponents()) == 0 {
+				return m, nil // No-op if nothing selected
+			}
+			m.Screen = ScreenUninstallConfirm
+			m.Cursor = 0
+		}
+	}
+
+	return m, nil
+}
+
+func (m Model) handleUninstallConfirmKeys(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "up", "k":
+		if m.Cursor > 0 {
+			m.Cursor--
+		}
+	case "down", "j":
+		if m.Cursor < len(m.GetCurrentOptions())-1 {
+			m.Cursor++
+		}
+	case "enter", " ":
+		if m.Cursor != 0 {
+			m.Screen = ScreenUninstallSelect
+			m.Cursor = 0
+			return m, nil
+		}
+		m.UninstallReport = nil
+		m.UninstallErr = nil
+		m.UninstallRunning = true
+		m.Screen = ScreenUninstallResult
+		return m, runUninstallCmd(m.SystemInfo, m.selectedUninstallComponents(), m.UninstallPackages)
+	}
+
+	return m, nil
+}
+
+func (m Model) handleConfigUpdateKeys(key string) (tea.Model, tea.Cmd) {
+	if m.ConfigUpdateRunning {
+		return m, nil
+	}
+	options := m.GetCurrentOptions()
+	if len(options) == 0 {
+		// Error or result: nothing left but going back
+		if key == "enter" {
+			m.discardConfigUpdate()
+			m.Screen = ScreenMainMenu
+			m.Cursor = 0
+		}
+		return m, nil
+	}
+
+	switch key {
+	case "up", "k":
+		if m.Cursor > 0 {
+			m.Cursor--
+		}
+	case "down", "j":
+		if m.Cursor < len(options)-1 {
+			m.Cursor++
+		}
+	case "enter", " ":
+		selected := options[m.Cursor]
+		switch {
+		case strings.Contains(selected, "Resolve conflicts"):
+			m.ConflictFile = 0
+			m.ConflictHunk = 0
+			m.Screen = ScreenConfigConflicts
+		case strings.Contains(selected, "Apply update"):
+			m.ConfigUpdateRunning = true
+			return m, applyConfigUpdateCmd(m.ConfigUpdatePlan)
+		default:
+			m.discardConfigUpdate()
+			m.Screen = ScreenMainMenu
+			m.Cursor = 0
+		}
+	}
+
+	return m, nil
+}
+
+func (m Model) handleConflictKeys(key string) (tea.Model, tea.Cmd) {
+	file, conflict := m.currentConflict()
+	if file == nil {
+		m.Screen = ScreenConfigUpdate
+		return m, nil
+	}
+
+	resolve := map[string]system.Resolution{"o": system.KeepOurs, "t": system.TakeTheirs, "b": system.KeepBoth}
+	switch key {
+	case "o", "t", "b":
+		if conflict != nil {
+			conflict.Resolution = resolve[key]
+		}
+		m.nextConflict()
+	case "O", "T", "B":
+		file.Merge.ResolveAll(resolve[strings.ToLower(key)])
+		m.ConflictHunk = len(file.Merge.Conflicts()) - 1
+		m.nextConflict()
+	case "right", "l", "n":
+		m.nextConflict()
+	case "left", "h", "p":
+		m.prevConflict()
+	case "tab":
+		if m.ConflictFile < len(conflictFiles(m.ConfigUpdatePlan))-1 {
+			m.ConflictFile++
+			m.ConflictHunk = 0
+		}
+	case "shift+tab":
+		if m.ConflictFile > 0 {
+			m.ConflictFile--
+			m.ConflictHunk = 0
+		}
+	case "enter", "q":
+		m.Screen = ScreenConfigUpdate
+		m.Cursor = 0
+	}
+
+	return m, nil
+}
+
+// startDoctor clears the last report and runs the checks again
+func (m Model) startDoctor() (tea.Model, tea.Cmd) {
+	m.DoctorChecks = nil
+	m.DoctorRunning = true
+	m.DoctorScroll = 0
+	return m, runDoctorCmd(m.SystemInfo)
+}
+
+func (m Model) handleThemeKeys(key string) (tea.Model, tea.Cmd) {
+	if m.ThemeRunning {
+		return m, nil
+	}
+	options := m.GetCurrentOptions()
+
+	switch key {
+	case "up", "k":
+		if m.Cursor > 0 {
+			m.Cursor--
+			if strings.HasPrefix(options[m.Cursor], "───") && m.Cursor > 0 {
+				m.Cursor--
+			}
+		}
+	case "down", "j":
+		if m.Cursor < len(options)-1 {
+			m.Cursor++
+			if strings.HasPrefix(options[m.Cursor], "───") && m.Cursor < len(options)-1 {
+				m.Cursor++
+			}
+		}
+	case "enter", " ":
+		if m.Cursor < len(system.Themes) {
+			m.ThemeStyled = nil
+			m.ThemeErr = nil
+			m.ThemeRunning = true
+			return m, applyThemeCmd(system.Themes[m.Cursor].ID)
+		}
+		m.Screen = ScreenMainMenu
+		m.Cursor = 0
+	}
+
+	return m, nil
+}
+
+func (m Model) handleDoctorKeys(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "up", "k":
+		if m.DoctorScroll > 0 {
+			m.DoctorScroll--
+		}
+	case "down", "j":
+		if m.DoctorScroll < len(m.doctorLines())-1 {
+			m.DoctorScroll++
+		}
+	case "r":
+		if !m.DoctorRunning {
+			return m.startDoctor()
+		}
+	case "enter", "q":
+		m.Screen = ScreenMainMenu
+		m.Cursor = 0
+	}
+
+	return m, nil
+}
+
+func (m Model) handleLogSearchKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.LogSearch = ""
+		m.LogSearching = false
+	case tea.KeyEnter:
+		m.LogSearching = false
+	case tea.KeyBackspace:
+		if len(m.LogSearch) > 0 {
+			runes := []rune(m.LogSearch)
+			m.LogSearch = string(runes[:len(runes)-1])
+		}
+	case tea.KeySpace:
+		m.LogSearch += " "
+	case tea.KeyRunes:
+		m.LogSearch += string(msg.Runes)
+	}
+	m.LogFollow = true
+	return m, nil
+}
+
+func (m Model) handleResumeInstallKeys(key string) (tea.Model, tea.Cmd) {
 	options := m.GetCurrentOptions()
 
 	switch key {
@@ -2918,27 +3460,71 @@ func (m Model) handleRestoreConfirmKeys(key string) (tea.Model, tea.Cmd) {
 			m.Cursor++
 		}
 	case "enter", " ":
-		backup := m.AvailableBackups[m.SelectedBackup]
 		switch m.Cursor {
-		case 0: // Restore
-			err := system.RestoreBackup(backup.Path)
-			if err != nil {
-				m.Screen = ScreenError
-				m.ErrorMsg = "Failed to restore backup: " + err.Error()
-				return m, nil
+		case 0: // Resume
+			m.ResumeFromJournal(m.PendingJournal)
+			return m, func() tea.Msg { return installStartMsg{} }
+		case 1: // Discard
+			_ = m.PendingJournal.Remove()
+			m.PendingJournal = nil
+			m.Screen = ScreenWelcome
+		case 2: // Not now - keep the journal for the next launch
+			m.PendingJournal = nil
+			m.Screen = ScreenWelcome
+		}
+		m.Cursor = 0
+	}
+
+	return m, nil
+}
+
+func (m Model) handleRestoreConfirmKeys(key string) (tea.Model, tea.Cmd) {
+	options := m.GetCurrentOptions()
+
+	switch key {
+	case "up", "k":
+		if m.Cursor > 0 {
+			m.Cursor--
+			if strings.HasPrefix(options[m.Cursor], "───") && m.Cursor > 0 {
+				m.Cursor--
+			}
+		}
+	case "down", "j":
+		if m.Cursor < len(options)-1 {
+			m.Cursor++
+			if strings.HasPrefix(options[m.Cursor], "───") && m.Cursor < len(options)-1 {
+				m.Cursor++
+			}
+		}
+	case "enter", " ":
+		if m.Cursor < len(m.RestoreSelected) {
+			m.RestoreSelected[m.Cursor] = !m.RestoreSelected[m.Cursor]
+			return m, nil
+		}
+		backup, ok := m.selectedBackup()
+		switch options[m.Cursor] {
+		case restorePreviewOption:
+			if ok && len(m.selectedRestoreKeys()) > 0 {
+				return m.previewRestore(), nil
+			}
+		case restoreOption:
+			if ok && len(m.selectedRestoreKeys()) > 0 {
+				return m.restoreSelected(), nil
+			}
+		case restoreExportOption:
+			if ok {
+				m.BackupMsg = exportBackup(backup)
+			}
+		case restoreDeleteOption:
+			if ok {
+				_ = system.DeleteBackup(backup.Path)
 			}
-			// Refresh backups list
-			m.AvailableBackups = system.ListBackups()
-			m.Screen = ScreenComplete
-			m.Choices = UserChoices{} // Clear choices to indicate restore
-		case 1: // Delete
-			_ = system.DeleteBackup(backup.Path)
 			// Refresh backups list
 			m.AvailableBackups = system.ListBackups()
 			m.Screen = ScreenRestoreBackup
 			m.Cursor = 0
 			m.SelectedBackup = 0
-		case 2: // Cancel
+		case restoreCancelOption:
 			m.Screen = ScreenRestoreBackup
 			m.Cursor = m.SelectedBackup
 		}
@@ -2950,27 +3536,92 @@ func (m Model) handleRestoreConfirmKeys(key string) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// runNextStep starts the next installation step
-func (m Model) runNextStep() tea.Cmd {
+// scheduleSteps starts every step whose dependencies have finished, up to
+// the worker limit. Once all steps are finished the installation completes.
+func (m *Model) scheduleSteps() tea.Cmd {
+	// A failed step holds the installation until it is retried or skipped
+	if i := m.failedStepIndex(); i >= 0 {
+		if m.ErrorMsg == "" {
+			m.showStepError(i)
+		}
+		return nil
+	}
+
+	m.CurrentStep = firstUnfinished(m.Steps)
 	if m.CurrentStep >= len(m.Steps) {
 		return func() tea.Msg {
 			return installCompleteMsg{totalTime: 0}
 		}
 	}
 
-	step := &m.Steps[m.CurrentStep]
-	step.Status = StatusRunning
+	var cmds []tea.Cmd
+	for _, i := range nextSteps(m.Steps, effectiveJobs()) {
+		step := &m.Steps[i]
+		step.Status = StatusRunning
+		writeSessionLog(LogEntry{Time: time.Now(), StepID: step.ID, Line: "▶ " + step.Name})
+		if m.Journal != nil {
+			_ = m.Journal.Record(step.ID, StatusRunning, nil)
+		}
+
+		// Each step runs on its own copy of the model
+		stepID := step.ID
+		snapshot := *m
+
+		// Check if this step needs interactive input (sudo, chsh, etc)
+		// A dry run never hands over the terminal, it only records the commands
+		if runsAlone(*step) {
+			cmds = append(cmds, runInteractiveStep(stepID, &snapshot))
+			continue
+		}
+
+		cmds = append(cmds, func() tea.Msg {
+			err := executeStep(stepID, &snapshot)
+			return stepCompleteMsg{stepID: stepID, err: err, plan: takeStepPlan(stepID), backupDir: snapshot.BackupDir}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// finishStep marks a step as done, or failed when err is set, and starts
+// the steps that were waiting for it
+func (m Model) finishStep(stepID string, err error) (Model, tea.Cmd) {
+	if m.Cancelling {
+		return m.finishCancelledStep(stepID, err), nil
+	}
+	m.recordStep(stepID, err)
+	if err != nil {
+		m.logStepEvent(stepID, "✗ failed: "+err.Error())
+	} else {
+		m.logStepEvent(stepID, "✓ done")
+	}
 
-	// Check if this step needs interactive input (sudo, chsh, etc)
-	if step.Interactive {
-		return runInteractiveStep(step.ID, &m)
+	for i := range m.Steps {
+		if m.Steps[i].ID != stepID {
+			continue
+		}
+		if err != nil {
+			m = m.handleStepFailure(i, err)
+			break
+		}
+		m.Steps[i].Status = StatusDone
+		m.Steps[i].Progress = 1.0
+		break
 	}
 
-	return func() tea.Msg {
-		// Execute the step
-		err := executeStep(step.ID, &m)
-		return stepCompleteMsg{stepID: step.ID, err: err}
+	cmd := m.scheduleSteps()
+	return m, cmd
+}
+
+// recordStep persists a finished step in the journal, if one is active
+func (m *Model) recordStep(stepID string, err error) {
+	if m.Journal == nil {
+		return
+	}
+	status := StatusDone
+	if err != nil {
+		status = StatusFailed
 	}
+	_ = m.Journal.Record(stepID, status, err)
 }
 
 // ============================================================================
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"slices"
 	"testing"
 	"time"
 
@@ -211,7 +212,7 @@ func TestHandleRestoreConfirmKeys(t *testing.T) {
 			{Path: "/test/backup1"},
 		}
 		m.SelectedBackup = 0
-		m.Cursor = 2 // Cancel
+		m.Cursor = slices.Index(m.GetCurrentOptions(), restoreCancelOption)
 
 		result, _ := m.handleRestoreConfirmKeys("enter")
 		newModel := result.(Model)
@@ -262,9 +263,8 @@ func TestHandleMainMenuWithRestore(t *testing.T) {
 		m := NewModel()
 		m.Screen = ScreenMainMenu
 		m.AvailableBackups = []system.BackupInfo{} // No backups
-		// Options without restore: Start, Learn & Practice, Init Project, Skill Manager, Exit
-		// Exit is at index 4
-		m.Cursor = 4
+		// Without backups the menu offers importing one instead of restoring
+		m.Cursor = slices.Index(m.GetCurrentOptions(), "❌ Exit")
 
 		_, cmd := m.handleMainMenuKeys("enter")
 
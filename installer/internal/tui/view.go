@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/system"
 	"github.com/Gentleman-Programming/Gentleman.Dots/installer/internal/tui/trainer"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -95,6 +96,34 @@ func (m Model) View() string {
 		s.WriteString(m.renderRestoreBackup())
 	case ScreenRestoreConfirm:
 		s.WriteString(m.renderRestoreConfirm())
+	case ScreenRestoreDiff:
+		s.WriteString(m.renderRestoreDiff())
+	case ScreenRestoreResult:
+		s.WriteString(m.renderRestoreResult())
+	case ScreenBackupImport:
+		s.WriteString(m.renderBackupImport())
+	case ScreenResumeInstall:
+		s.WriteString(m.renderResumeInstall())
+	case ScreenRollback:
+		s.WriteString(m.renderRollback())
+	case ScreenInstallCancelled:
+		s.WriteString(m.renderInstallCancelled())
+	case ScreenLogViewer:
+		s.WriteString(m.renderLogViewer())
+	case ScreenDoctor:
+		s.WriteString(m.renderDoctor())
+	case ScreenTheme:
+		s.WriteString(m.renderTheme())
+	case ScreenUninstallSelect:
+		s.WriteString(m.renderRolePackSelection())
+	case ScreenUninstallConfirm:
+		s.WriteString(m.renderUninstallConfirm())
+	case ScreenUninstallResult:
+		s.WriteString(m.renderUninstallResult())
+	case ScreenConfigUpdate:
+		s.WriteString(m.renderConfigUpdate())
+	case ScreenConfigConflicts:
+		s.WriteString(m.renderConflicts())
 	case ScreenInstalling:
 		s.WriteString(m.renderInstalling())
 	case ScreenComplete:
@@ -247,6 +276,12 @@ func (m Model) renderSelection() string {
 	}
 
 	s.WriteString("\n")
+	if m.Screen == ScreenFontSelect {
+		s.WriteString(InfoStyle.Render("Size: " + fontSize(m.Choices.FontSize)))
+		s.WriteString("\n\n")
+		s.WriteString(HelpStyle.Render("↑/k up • ↓/j down • ←/h →/l size • [Enter] select • [Esc] back"))
+		return s.String()
+	}
 	s.WriteString(HelpStyle.Render("↑/k up • ↓/j down • [Enter] select • [Esc] back"))
 
 	return s.String()
@@ -1260,11 +1295,15 @@ var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "
 func (m Model) renderInstalling() string {
 	var s strings.Builder
 
-	s.WriteString(TitleStyle.Render("🚀 Installing Javi.Dots"))
+	if system.IsDryRun() {
+		s.WriteString(TitleStyle.Render("🧪 Planning Javi.Dots install (dry run)"))
+	} else {
+		s.WriteString(TitleStyle.Render("🚀 Installing Javi.Dots"))
+	}
 	s.WriteString("\n\n")
 
 	// Progress steps
-	for i, step := range m.Steps {
+	for _, step := range m.Steps {
 		var icon string
 		var style lipgloss.Style
 
@@ -1291,8 +1330,8 @@ func (m Model) renderInstalling() string {
 		s.WriteString(style.Render(line))
 		s.WriteString("\n")
 
-		// Show current step description
-		if i == m.CurrentStep && step.Status == StatusRunning {
+		// Show the description of every running step
+		if step.Status == StatusRunning {
 			s.WriteString(MutedStyle.Render("   " + step.Description))
 			s.WriteString("\n")
 		}
@@ -1305,12 +1344,25 @@ func (m Model) renderInstalling() string {
 	}
 
 	s.WriteString("\n")
-	s.WriteString(HelpStyle.Render("[space+d] toggle details"))
+	switch {
+	case m.Cancelling && m.RollbackNext:
+		s.WriteString(WarningStyle.Render("⏹️  Stopping the running steps, then rolling back... [ctrl+c] quit now"))
+	case m.Cancelling:
+		s.WriteString(WarningStyle.Render("⏹️  Cancelling: waiting for the running steps to stop... [ctrl+c] quit now"))
+	case m.CancelConfirm:
+		s.WriteString(WarningStyle.Render("Cancel the installation? Running steps are stopped, finished ones are kept. [y/N]"))
+	default:
+		s.WriteString(HelpStyle.Render("[space+d] toggle details • [space+l] full log • [ctrl+c] cancel"))
+	}
 
 	return s.String()
 }
 
 func (m Model) renderComplete() string {
+	if system.IsDryRun() {
+		return m.renderDryRunComplete()
+	}
+
 	var s strings.Builder
 
 	s.WriteString(SuccessStyle.Render("✨ Installation Complete! ✨"))
@@ -1328,7 +1380,11 @@ func (m Model) renderComplete() string {
 	}
 
 	if m.Choices.InstallFont {
-		items = append(items, "Font: Iosevka Term Nerd Font")
+		font := "Font: " + m.installFont().Name + " Nerd Font"
+		if m.Choices.FontSize > 0 {
+			font += ", size " + fontSize(m.Choices.FontSize)
+		}
+		items = append(items, font)
 	}
 	if m.Choices.InstallNvim {
 		items = append(items, "Editor: Neovim with Gentleman config")
@@ -1340,11 +1396,7 @@ func (m Model) renderComplete() string {
 	}
 
 	// Shell change instructions
-	shell := m.Choices.Shell
-	shellCmd := shell
-	if shell == "nushell" {
-		shellCmd = "nu"
-	}
+	shellCmd := shellCommand(m.Choices.Shell)
 
 	s.WriteString("\n")
 	s.WriteString(TitleStyle.Render("Next Step"))
@@ -1355,12 +1407,53 @@ func (m Model) renderComplete() string {
 	s.WriteString(HighlightStyle.Render(fmt.Sprintf("   exec %s", shellCmd)))
 	s.WriteString("\n\n")
 
-	s.WriteString(HelpStyle.Render("Press [Enter] or [q] to exit"))
+	if m.ProfileMsg != "" {
+		s.WriteString(InfoStyle.Render(m.ProfileMsg))
+		s.WriteString("\n\n")
+	}
+	s.WriteString(m.renderLogPath())
+	s.WriteString(HelpStyle.Render("Press [Enter] or [q] to exit • [s] save as profile • [l] full log"))
+
+	return s.String()
+}
+
+// renderDryRunComplete summarizes the recorded plan instead of the install
+func (m Model) renderDryRunComplete() string {
+	var s strings.Builder
+
+	s.WriteString(SuccessStyle.Render("🧪 Dry Run Complete"))
+	s.WriteString("\n\n")
+	s.WriteString(InfoStyle.Render(fmt.Sprintf("%d actions planned, nothing was changed.", len(m.DryRunPlan))))
+	s.WriteString("\n\n")
+
+	// Count actions per kind, keeping first-seen order
+	counts := map[system.PlanKind]int{}
+	var kinds []system.PlanKind
+	for _, action := range m.DryRunPlan {
+		if counts[action.Kind] == 0 {
+			kinds = append(kinds, action.Kind)
+		}
+		counts[action.Kind]++
+	}
+	for _, kind := range kinds {
+		s.WriteString(MutedStyle.Render(fmt.Sprintf("  • %-9s %d", kind, counts[kind])))
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	s.WriteString(InfoStyle.Render("The full ordered plan is printed when you exit."))
+	s.WriteString("\n\n")
+	s.WriteString(m.renderLogPath())
+	s.WriteString(HelpStyle.Render("Press [Enter] or [q] to exit • [l] full log"))
 
 	return s.String()
 }
 
 func (m Model) renderError() string {
+	if i := m.failedStepIndex(); i >= 0 {
+		return m.renderStepError(i)
+	}
+
 	var s strings.Builder
 
 	s.WriteString(ErrorStyle.Render("❌ Installation Failed"))
@@ -1387,7 +1480,82 @@ func (m Model) renderError() string {
 		s.WriteString("\n")
 	}
 
-	s.WriteString(HelpStyle.Render("[r] retry • [space+q] quit"))
+	s.WriteString(m.renderLogPath())
+	s.WriteString(HelpStyle.Render("[r] retry • [l] full log • [space+q] quit"))
+
+	return s.String()
+}
+
+// renderStepError shows why a step failed and offers retry, skip or abort
+func (m Model) renderStepError(i int) string {
+	var s strings.Builder
+	d := errorDetailsFor(m.Steps[i], m.Steps[i].Error)
+
+	s.WriteString(ErrorStyle.Render("❌ Installation Failed"))
+	s.WriteString("\n\n")
+
+	s.WriteString(MutedStyle.Render("Step:    ") + ErrorStyle.Render(d.StepName))
+	s.WriteString("\n")
+	if d.Description != "" {
+		s.WriteString(MutedStyle.Render("What:    ") + InfoStyle.Render(d.Description))
+		s.WriteString("\n")
+	}
+	if d.Command != "" {
+		s.WriteString(MutedStyle.Render("Command: ") + InfoStyle.Render(fmt.Sprintf("%s (exit %d)", truncateDesc(d.Command, 60), d.ExitCode)))
+		s.WriteString("\n")
+	}
+	if d.Cause != "" {
+		s.WriteString(MutedStyle.Render("Cause:   ") + InfoStyle.Render(truncateDesc(firstLine(d.Cause), 70)))
+		s.WriteString("\n")
+	}
+
+	// Command output, stderr preferred
+	output, label := d.Stderr, "stderr"
+	if output == "" {
+		output, label = d.Stdout, "stdout"
+	}
+	if output != "" {
+		s.WriteString("\n")
+		s.WriteString(MutedStyle.Render(label + ":"))
+		s.WriteString("\n")
+		for _, line := range lastLines(output, 6) {
+			s.WriteString(ErrorStyle.Render("  " + truncateDesc(line, 76)))
+			s.WriteString("\n")
+		}
+	} else if len(m.LogLines) > 0 {
+		s.WriteString("\n")
+		s.WriteString(MutedStyle.Render("Recent logs:"))
+		s.WriteString("\n")
+		for _, line := range lastLines(strings.Join(m.LogLines, "\n"), 5) {
+			s.WriteString(InfoStyle.Render("  " + truncateDesc(line, 76)))
+			s.WriteString("\n")
+		}
+	}
+	s.WriteString("\n")
+
+	options := m.GetCurrentOptions()
+	for j, opt := range options {
+		cursor := "  "
+		style := UnselectedStyle
+		if j == m.Cursor {
+			cursor = "▸ "
+			style = SelectedStyle
+		}
+		s.WriteString(style.Render(cursor + opt))
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	if running := runningSteps(m.Steps); running > 0 {
+		s.WriteString(WarningStyle.Render(fmt.Sprintf("⏳ %d other step(s) still running, rolling back stops them first.", running)))
+		s.WriteString("\n")
+	}
+	if m.Journal != nil {
+		s.WriteString(MutedStyle.Render("Progress is saved, --resume continues from this step after aborting."))
+		s.WriteString("\n")
+	}
+	s.WriteString(m.renderLogPath())
+	s.WriteString(HelpStyle.Render("↑/k up • ↓/j down • [Enter] select • [r] retry • [s] skip • [b] roll back • [a] abort • [l] full log"))
 
 	return s.String()
 }
@@ -1407,11 +1575,25 @@ func (m Model) renderBackupConfirm() string {
 	s.WriteString(MutedStyle.Render("The following configs will be overwritten:"))
 	s.WriteString("\n\n")
 
-	// List existing configs
+	// List existing configs, the AI tool configs apart
+	var aiConfigs []string
 	for _, config := range m.ExistingConfigs {
+		if system.IsAIConfig(config) {
+			aiConfigs = append(aiConfigs, config)
+			continue
+		}
 		s.WriteString(WarningStyle.Render("  ⚠️  " + config))
 		s.WriteString("\n")
 	}
+	if len(aiConfigs) > 0 {
+		s.WriteString("\n")
+		s.WriteString(SubtitleStyle.Render("AI tool configs:"))
+		s.WriteString("\n")
+		for _, config := range aiConfigs {
+			s.WriteString(WarningStyle.Render("  ⚠️  " + config))
+			s.WriteString("\n")
+		}
+	}
 
 	s.WriteString("\n")
 	s.WriteString(InfoStyle.Render("Creating a backup allows you to restore later if needed."))
@@ -1431,7 +1613,11 @@ func (m Model) renderBackupConfirm() string {
 	}
 
 	s.WriteString("\n")
-	s.WriteString(HelpStyle.Render("↑/k up • ↓/j down • [Enter] select • [Esc] back"))
+	if m.ProfileMsg != "" {
+		s.WriteString(InfoStyle.Render(m.ProfileMsg))
+		s.WriteString("\n\n")
+	}
+	s.WriteString(HelpStyle.Render("↑/k up • ↓/j down • [Enter] select • [s] save as profile • [Esc] back"))
 
 	return s.String()
 }
@@ -1441,8 +1627,9 @@ func (m Model) renderRestoreBackup() string {
 
 	s.WriteString(TitleStyle.Render(m.GetScreenTitle()))
 	s.WriteString("\n")
-	s.WriteString(MutedStyle.Render("Select a backup to restore or delete"))
+	s.WriteString(MutedStyle.Render("Select a backup to restore, export or delete"))
 	s.WriteString("\n\n")
+	s.WriteString(m.renderBackupMsg())
 
 	if len(m.AvailableBackups) == 0 {
 		s.WriteString(MutedStyle.Render("No backups found."))
@@ -1464,19 +1651,20 @@ func (m Model) renderRestoreBackup() string {
 		}
 	}
 
-	// Separator and Back
+	// Separator, Import and Back
 	s.WriteString(MutedStyle.Render("─────────────"))
 	s.WriteString("\n")
 
-	backIdx := len(m.AvailableBackups) + 1
-	cursor := "  "
-	style := UnselectedStyle
-	if m.Cursor == backIdx {
-		cursor = "▸ "
-		style = SelectedStyle
+	for i, opt := range []string{importBackupOption, "← Back"} {
+		cursor := "  "
+		style := UnselectedStyle
+		if m.Cursor == len(m.AvailableBackups)+1+i {
+			cursor = "▸ "
+			style = SelectedStyle
+		}
+		s.WriteString(style.Render(cursor + opt))
+		s.WriteString("\n")
 	}
-	s.WriteString(style.Render(cursor + "← Back"))
-	s.WriteString("\n")
 
 	s.WriteString("\n")
 	s.WriteString(HelpStyle.Render("↑/k up • ↓/j down • [Enter] select • [Esc] back"))
@@ -1497,20 +1685,521 @@ func (m Model) renderRestoreConfirm() string {
 	s.WriteString("\n")
 	s.WriteString(MutedStyle.Render("Backup from: " + backup.Timestamp.Format("2006-01-02 15:04:05")))
 	s.WriteString("\n\n")
+	s.WriteString(m.renderBackupMsg())
+
+	s.WriteString(SubtitleStyle.Render("Pick the configs to restore:"))
+	s.WriteString("\n")
+
+	for i, opt := range m.GetCurrentOptions() {
+		if strings.HasPrefix(opt, "───") {
+			s.WriteString(MutedStyle.Render(opt))
+			s.WriteString("\n")
+			continue
+		}
+		cursor := "  "
+		style := UnselectedStyle
+		if i == m.Cursor {
+			cursor = "▸ "
+			style = SelectedStyle
+		}
+		s.WriteString(style.Render(cursor + opt))
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	s.WriteString(WarningStyle.Render("⚠️  Restoring overwrites the selected configs. They are backed up first, so it can be undone."))
+	s.WriteString("\n\n")
+	s.WriteString(HelpStyle.Render("↑/k up • ↓/j down • [Space/Enter] toggle or select • [Esc] cancel"))
+
+	return s.String()
+}
+
+// renderLogPath points at the session log file, if one is being written
+func (m Model) renderLogPath() string {
+	if m.LogPath == "" {
+		return ""
+	}
+	return MutedStyle.Render("Full log: "+m.LogPath) + "\n"
+}
+
+// renderLogViewer shows the installation log, filtered by step and search
+func (m Model) renderLogViewer() string {
+	var s strings.Builder
 
-	// List files in backup
-	s.WriteString(SubtitleStyle.Render("Contents:"))
+	s.WriteString(TitleStyle.Render(m.GetScreenTitle()))
 	s.WriteString("\n")
-	for _, file := range backup.Files {
-		s.WriteString(InfoStyle.Render("  • " + file))
+
+	filter := "all steps"
+	if m.LogFilter != "" {
+		filter = "step " + m.LogFilter
+	}
+	status := "Showing " + filter
+	if m.LogSearch != "" {
+		status += fmt.Sprintf(" matching %q", m.LogSearch)
+	}
+	s.WriteString(MutedStyle.Render(status))
+	s.WriteString("\n\n")
+
+	entries := m.visibleLogEntries()
+	height := m.logViewHeight()
+	start := m.logViewStart(len(entries))
+	end := min(start+height, len(entries))
+
+	if len(entries) == 0 {
+		s.WriteString(MutedStyle.Render("  No log lines"))
+		s.WriteString("\n")
+	}
+	for _, entry := range entries[start:end] {
+		stepID := entry.StepID
+		if stepID == "" {
+			stepID = "-"
+		}
+		line := entry.Line
+		if m.Width > 0 {
+			line = truncateDesc(line, max(m.Width-len(stepID)-16, 20))
+		}
+		s.WriteString(MutedStyle.Render(entry.Time.Format("15:04:05") + " [" + stepID + "] "))
+		s.WriteString(line)
+		s.WriteString("\n")
+	}
+	if len(entries) > height {
+		s.WriteString(MutedStyle.Render(fmt.Sprintf("  lines %d-%d of %d", start+1, end, len(entries))))
 		s.WriteString("\n")
 	}
 
 	s.WriteString("\n")
-	s.WriteString(WarningStyle.Render("⚠️  Restoring will overwrite your current configs!"))
+	if m.LogSearching {
+		s.WriteString(InfoStyle.Render("/" + m.LogSearch + "█"))
+		s.WriteString("\n")
+		s.WriteString(HelpStyle.Render("[Enter] apply • [Esc] clear search"))
+		return s.String()
+	}
+	s.WriteString(m.renderLogPath())
+	s.WriteString(HelpStyle.Render("↑/k ↓/j scroll • PgUp/PgDn page • g/G top/bottom • [f] filter step • [/] search • [Esc] back"))
+
+	return s.String()
+}
+
+// renderRollback lists what a rollback restored, removed and kept
+func (m Model) renderRollback() string {
+	var s strings.Builder
+
+	s.WriteString(TitleStyle.Render(m.GetScreenTitle()))
+	s.WriteString("\n\n")
+
+	if m.RollbackErr != nil {
+		s.WriteString(ErrorStyle.Render("Rollback did not finish: " + m.RollbackErr.Error()))
+		s.WriteString("\n\n")
+	}
+
+	report := m.Rollback
+	if report == nil || report.Empty() {
+		s.WriteString(InfoStyle.Render("Nothing was changed, there was nothing to roll back."))
+		s.WriteString("\n\n")
+	} else {
+		sections := []struct {
+			title string
+			paths []string
+			style lipgloss.Style
+		}{
+			{"Restored from backup", report.Restored, SuccessStyle},
+			{"Removed", report.Removed, SuccessStyle},
+			{"Kept (no backup to restore)", report.Kept, WarningStyle},
+		}
+		for _, section := range sections {
+			if len(section.paths) == 0 {
+				continue
+			}
+			s.WriteString(section.style.Render(section.title + ":"))
+			s.WriteString("\n")
+			for _, path := range section.paths {
+				s.WriteString(MutedStyle.Render("  • " + path))
+				s.WriteString("\n")
+			}
+			s.WriteString("\n")
+		}
+	}
+
+	s.WriteString(HelpStyle.Render("Press [Enter] or [q] to exit"))
+
+	return s.String()
+}
+
+// doctorLines renders the doctor report one line per entry, so the
+// screen can scroll it
+func (m Model) doctorLines() []string {
+	var lines []string
+	for _, category := range []string{CategoryBinaries, CategoryConfigs, CategoryShells, CategoryEnv} {
+		header := false
+		for _, check := range m.DoctorChecks {
+			if check.Category != category {
+				continue
+			}
+			if !header {
+				if len(lines) > 0 {
+					lines = append(lines, "")
+				}
+				lines = append(lines, SubtitleStyle.Render(category))
+				header = true
+			}
+			style := SuccessStyle
+			switch check.Status {
+			case CheckWarn:
+				style = WarningStyle
+			case CheckFail:
+				style = ErrorStyle
+			}
+			lines = append(lines, style.Render(fmt.Sprintf("  %s %s", checkIcon(check.Status), check.Name))+
+				MutedStyle.Render(": "+check.Detail))
+			if check.Hint != "" {
+				lines = append(lines, InfoStyle.Render("     → "+check.Hint))
+			}
+		}
+	}
+	return lines
+}
+
+func (m Model) renderDoctor() string {
+	var s strings.Builder
+
+	s.WriteString(TitleStyle.Render(m.GetScreenTitle()))
+	s.WriteString("\n\n")
+
+	if m.DoctorRunning {
+		s.WriteString(InfoStyle.Render("Checking your setup..."))
+		s.WriteString("\n")
+		return s.String()
+	}
+	if len(m.DoctorChecks) == 0 {
+		s.WriteString(InfoStyle.Render("Nothing to check: no Gentleman.Dots tools are installed or configured."))
+		s.WriteString("\n\n")
+		s.WriteString(HelpStyle.Render("[r] run again • [Enter/Esc] back"))
+		return s.String()
+	}
+
+	pass, warn, fail := CountChecks(m.DoctorChecks)
+	summary := fmt.Sprintf("%d passed, %d warning(s), %d failed", pass, warn, fail)
+	switch {
+	case fail > 0:
+		s.WriteString(ErrorStyle.Render("❌ " + summary))
+	case warn > 0:
+		s.WriteString(WarningStyle.Render("⚠️  " + summary))
+	default:
+		s.WriteString(SuccessStyle.Render("✅ " + summary))
+	}
+	s.WriteString("\n\n")
+
+	// Reserve space for: title(2) + summary(2) + scroll info(1) + help(2)
+	lines := m.doctorLines()
+	height := max(m.Height-7, 5)
+	start := min(m.DoctorScroll, max(len(lines)-height, 0))
+	end := min(start+height, len(lines))
+	for _, line := range lines[start:end] {
+		s.WriteString(line)
+		s.WriteString("\n")
+	}
+	if len(lines) > height {
+		s.WriteString(MutedStyle.Render(fmt.Sprintf("[%d-%d of %d]", start+1, end, len(lines))))
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	s.WriteString(HelpStyle.Render("↑/↓ scroll • [r] run again • [Enter/Esc] back"))
+
+	return s.String()
+}
+
+func (m Model) renderUninstallConfirm() string {
+	var s strings.Builder
+
+	s.WriteString(TitleStyle.Render(m.GetScreenTitle()))
+	s.WriteString("\n\n")
+
+	components := m.selectedUninstallComponents()
+	s.WriteString(InfoStyle.Render("Components:"))
+	s.WriteString("\n")
+	for _, c := range components {
+		s.WriteString(MutedStyle.Render("  • " + c.Name))
+		s.WriteString("\n")
+	}
+	s.WriteString("\n")
+	s.WriteString(MutedStyle.Render("Configs are restored from the latest backup holding them, or removed."))
+	s.WriteString("\n\n")
+
+	if packages := UninstallPackages(components); m.UninstallPackages && len(packages) > 0 {
+		s.WriteString(WarningStyle.Render("⚠️  These packages will be removed: " + strings.Join(packages, ", ")))
+		s.WriteString("\n\n")
+	}
+
+	for i, opt := range m.GetCurrentOptions() {
+		cursor := "  "
+		style := UnselectedStyle
+		if i == m.Cursor {
+			cursor = "▸ "
+			style = SelectedStyle
+		}
+		s.WriteString(style.Render(cursor + opt))
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	s.WriteString(HelpStyle.Render("↑/k up • ↓/j down • [Enter] select • [Esc] back"))
+
+	return s.String()
+}
+
+func (m Model) renderTheme() string {
+	var s strings.Builder
+
+	s.WriteString(TitleStyle.Render(m.GetScreenTitle()))
+	s.WriteString("\n")
+	s.WriteString(MutedStyle.Render("Restyles the installed terminals, multiplexers, prompt, Neovim and OpenCode"))
+	s.WriteString("\n\n")
+
+	for i, opt := range m.GetCurrentOptions() {
+		if strings.HasPrefix(opt, "───") {
+			s.WriteString(MutedStyle.Render(opt))
+			s.WriteString("\n")
+			continue
+		}
+		cursor := "  "
+		style := UnselectedStyle
+		if i == m.Cursor {
+			cursor = "▸ "
+			style = SelectedStyle
+		}
+		s.WriteString(style.Render(cursor + opt))
+		s.WriteString("\n")
+	}
+
+	// Swatches of the highlighted theme
+	if m.Cursor < len(system.Themes) {
+		theme := system.Themes[m.Cursor]
+		s.WriteString("\n")
+		for _, row := range [][8]string{theme.Normal, theme.Bright} {
+			s.WriteString("  ")
+			for _, color := range row {
+				s.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render("███"))
+			}
+			s.WriteString("\n")
+		}
+	}
+	s.WriteString("\n")
+
+	switch {
+	case m.ThemeRunning:
+		s.WriteString(InfoStyle.Render("Applying theme..."))
+		s.WriteString("\n\n")
+	case m.ThemeErr != nil:
+		s.WriteString(ErrorStyle.Render("❌ " + m.ThemeErr.Error()))
+		s.WriteString("\n\n")
+	case m.ThemeStyled != nil && len(m.ThemeStyled) == 0:
+		s.WriteString(WarningStyle.Render("No installed config to restyle"))
+		s.WriteString("\n\n")
+	case m.ThemeStyled != nil:
+		s.WriteString(SuccessStyle.Render("✅ Restyled " + strings.Join(m.ThemeStyled, ", ")))
+		s.WriteString("\n")
+		s.WriteString(MutedStyle.Render("Open a new terminal and restart tmux or zellij to see the new colors"))
+		s.WriteString("\n\n")
+	}
+
+	s.WriteString(HelpStyle.Render("↑/k up • ↓/j down • [Enter] apply • [Esc] back"))
+
+	return s.String()
+}
+
+func (m Model) renderUninstallResult() string {
+	var s strings.Builder
+
+	s.WriteString(TitleStyle.Render(m.GetScreenTitle()))
+	s.WriteString("\n\n")
+
+	if m.UninstallRunning {
+		spinners := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+		s.WriteString(fmt.Sprintf("  %s Uninstalling...\n", spinners[m.SpinnerFrame%len(spinners)]))
+		return s.String()
+	}
+
+	if m.UninstallErr != nil {
+		s.WriteString(ErrorStyle.Render("Some items could not be uninstalled:"))
+		s.WriteString("\n")
+		for _, line := range strings.Split(m.UninstallErr.Error(), "\n") {
+			s.WriteString(ErrorStyle.Render("  " + line))
+			s.WriteString("\n")
+		}
+		s.WriteString("\n")
+	}
+	if m.UninstallReport != nil {
+		s.WriteString(InfoStyle.Render(m.UninstallReport.Format()))
+		s.WriteString("\n")
+	}
+
+	s.WriteString(HelpStyle.Render("Press [Enter] to go back to the main menu"))
+
+	return s.String()
+}
+
+func (m Model) renderConfigUpdate() string {
+	var s strings.Builder
+
+	s.WriteString(TitleStyle.Render(m.GetScreenTitle()))
+	s.WriteString("\n\n")
+
+	if m.ConfigUpdateRunning {
+		spinners := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+		label := "Fetching the latest configs..."
+		if m.ConfigUpdatePlan != nil {
+			label = "Backing up and updating..."
+		}
+		s.WriteString(fmt.Sprintf("  %s %s\n", spinners[m.SpinnerFrame%len(spinners)], label))
+		return s.String()
+	}
+
+	if m.ConfigUpdateErr != nil {
+		s.WriteString(ErrorStyle.Render("❌ " + m.ConfigUpdateErr.Error()))
+		s.WriteString("\n\n")
+	}
+	if m.ConfigUpdateApplied {
+		if m.ConfigUpdateBackup != "" {
+			s.WriteString(InfoStyle.Render("💾 Backup created at: " + m.ConfigUpdateBackup))
+			s.WriteString("\n")
+		}
+		if m.ConfigUpdateErr == nil {
+			s.WriteString(SuccessStyle.Render(fmt.Sprintf("✅ Updated %d file(s)", len(m.ConfigUpdatePlan.Files))))
+			s.WriteString("\n")
+		}
+	}
+	if m.ConfigUpdatePlan == nil || m.ConfigUpdateApplied {
+		s.WriteString("\n")
+		s.WriteString(HelpStyle.Render("Press [Enter] to go back to the main menu"))
+		return s.String()
+	}
+
+	s.WriteString(MutedStyle.Render(m.GetScreenDescription()))
+	s.WriteString("\n\n")
+	s.WriteString(InfoStyle.Render(system.FormatUpdatePlan(m.ConfigUpdatePlan)))
+	s.WriteString("\n")
+	if n := m.ConfigUpdatePlan.Unresolved(); n > 0 {
+		s.WriteString(WarningStyle.Render(fmt.Sprintf("⚠️  %d conflict(s) unresolved: they keep your version", n)))
+		s.WriteString("\n\n")
+	}
+
+	for i, opt := range m.GetCurrentOptions() {
+		cursor := "  "
+		style := UnselectedStyle
+		if i == m.Cursor {
+			cursor = "▸ "
+			style = SelectedStyle
+		}
+		s.WriteString(style.Render(cursor + opt))
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	s.WriteString(HelpStyle.Render("↑/k up • ↓/j down • [Enter] select • [Esc] back"))
+
+	return s.String()
+}
+
+// conflictSide renders one side of a conflict, cut to a few lines
+func conflictSide(title string, lines []string, style lipgloss.Style) string {
+	const maxLines = 8
+	var s strings.Builder
+	s.WriteString(MutedStyle.Render("── " + title + " ──"))
+	s.WriteString("\n")
+	if len(lines) == 0 {
+		s.WriteString(MutedStyle.Render("  (nothing)"))
+		s.WriteString("\n")
+	}
+	for i, line := range lines {
+		if i == maxLines {
+			s.WriteString(MutedStyle.Render(fmt.Sprintf("  … %d more line(s)", len(lines)-maxLines)))
+			s.WriteString("\n")
+			break
+		}
+		s.WriteString(style.Render("  " + strings.TrimRight(line, "\n")))
+		s.WriteString("\n")
+	}
+	return s.String()
+}
+
+func (m Model) renderConflicts() string {
+	var s strings.Builder
+
+	s.WriteString(TitleStyle.Render(m.GetScreenTitle()))
+	s.WriteString("\n\n")
+
+	file, conflict := m.currentConflict()
+	if conflict == nil {
+		s.WriteString(InfoStyle.Render("No conflicts"))
+		return s.String()
+	}
+	files := conflictFiles(m.ConfigUpdatePlan)
+	conflicts := file.Merge.Conflicts()
+
+	s.WriteString(InfoStyle.Render(fmt.Sprintf("File %d/%d: %s", m.ConflictFile+1, len(files), file.Dest)))
+	s.WriteString("\n")
+	s.WriteString(MutedStyle.Render(fmt.Sprintf("Conflict %d/%d: %s", m.ConflictHunk+1, len(conflicts), resolutionLabel(conflict.Resolution))))
+	s.WriteString("\n\n")
+
+	if len(conflict.Base) > 0 {
+		s.WriteString(conflictSide("Originally installed", conflict.Base, MutedStyle))
+	}
+	s.WriteString(conflictSide("Yours", conflict.Ours, WarningStyle))
+	s.WriteString(conflictSide("Upstream", conflict.Theirs, SuccessStyle))
+
+	s.WriteString("\n")
+	if n := m.ConfigUpdatePlan.Unresolved(); n > 0 {
+		s.WriteString(WarningStyle.Render(fmt.Sprintf("%d conflict(s) left", n)))
+	} else {
+		s.WriteString(SuccessStyle.Render("✅ All conflicts resolved"))
+	}
+	s.WriteString("\n\n")
+	s.WriteString(HelpStyle.Render("[o] keep yours • [t] take upstream • [b] keep both • [O/T/B] whole file"))
+	s.WriteString("\n")
+	s.WriteString(HelpStyle.Render("←/→ conflict • [Tab] next file • [Enter/Esc] done"))
+
+	return s.String()
+}
+
+func (m Model) renderResumeInstall() string {
+	var s strings.Builder
+
+	j := m.PendingJournal
+	if j == nil {
+		return ErrorStyle.Render("No unfinished installation")
+	}
+
+	s.WriteString(TitleStyle.Render(m.GetScreenTitle()))
+	s.WriteString("\n")
+	s.WriteString(MutedStyle.Render("Started: " + j.StartedAt.Format("2006-01-02 15:04:05")))
+	s.WriteString("\n\n")
+
+	for _, step := range j.Steps {
+		icon := "○"
+		style := MutedStyle
+		switch step.Status {
+		case StatusDone.String(), StatusSkipped.String():
+			icon = "✓"
+			style = SuccessStyle
+		case StatusFailed.String(), StatusRunning.String():
+			icon = "✗"
+			style = ErrorStyle
+		}
+		s.WriteString(style.Render(fmt.Sprintf("  %s %s", icon, step.Name)))
+		s.WriteString("\n")
+	}
+
+	if next := j.ResumeStep(); next != nil {
+		s.WriteString("\n")
+		s.WriteString(InfoStyle.Render(fmt.Sprintf("Finished steps are kept; resuming continues from '%s'.", next.Name)))
+		if next.Error != "" {
+			s.WriteString("\n")
+			s.WriteString(MutedStyle.Render("Last error: " + truncateDesc(firstLine(next.Error), 70)))
+		}
+	}
 	s.WriteString("\n\n")
 
-	// Options
 	options := m.GetCurrentOptions()
 	for i, opt := range options {
 		cursor := "  "
@@ -1524,7 +2213,7 @@ func (m Model) renderRestoreConfirm() string {
 	}
 
 	s.WriteString("\n")
-	s.WriteString(HelpStyle.Render("↑/k up • ↓/j down • [Enter] select • [Esc] cancel"))
+	s.WriteString(HelpStyle.Render("↑/k up • ↓/j down • [Enter] select • [Esc] not now"))
 
 	return s.String()
 }
@@ -2458,6 +3147,10 @@ func (m Model) renderProjectResult() string {
 		s.WriteString(SuccessStyle.Render("  ✅ Project initialized successfully!"))
 	}
 	s.WriteString("\n\n")
+	if m.LogPath != "" {
+		s.WriteString(MutedStyle.Render("  Full log: " + m.LogPath))
+		s.WriteString("\n\n")
+	}
 	s.WriteString(HelpStyle.Render("  Press Enter to return to the main menu"))
 	return s.String()
 }